@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/service"
+)
+
+// runBackup writes the backup to a temporary file first, so that a failed
+// backup never replaces a good one.
+func runBackup(args []string) {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := flags.String("out", "", "file to write the backup to")
+	flags.Parse(args)
+	if *out == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	backup := newBackupService()
+
+	f, err := os.CreateTemp(filepath.Dir(*out), ".backup-*")
+	if err != nil {
+		fatal("Failed to create backup", err)
+	}
+
+	tables, err := backup.Dump(context.Background(), f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), *out)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		fatal("Failed to create backup", err)
+	}
+
+	logTables("Backed up", tables)
+}
+
+func runRestore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := flags.String("in", "", "backup file to restore from")
+	flags.Parse(args)
+	if *in == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	backup := newBackupService()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		fatal("Failed to restore backup", err)
+	}
+	defer f.Close()
+
+	tables, err := backup.Restore(context.Background(), f)
+	if err != nil {
+		fatal("Failed to restore backup", err)
+	}
+
+	logTables("Restored", tables)
+}
+
+func newBackupService() service.Backup {
+	db, err := repository.NewPostgres(context.Background(), newDBConfig())
+	if err != nil {
+		fatal("Failed to init DB", err)
+	}
+	return service.NewBackupService(repository.NewBackupPostgres(db))
+}
+
+func logTables(action string, tables []todo.BackupTable) {
+	for _, table := range tables {
+		slog.Info(action, "table", table.Name, "rows", table.Rows)
+	}
+}
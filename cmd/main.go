@@ -2,55 +2,317 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/OrIX219/todo/pkg"
 	"github.com/OrIX219/todo/pkg/config"
 	"github.com/OrIX219/todo/pkg/handler"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/metrics"
 	"github.com/OrIX219/todo/pkg/repository"
 	"github.com/OrIX219/todo/pkg/service"
+	"github.com/OrIX219/todo/pkg/tracing"
 )
 
 func main() {
-	db, err := repository.NewPostgres(repository.Config{
-		Host:     config.Config["POSTGRES_HOST"],
-		Port:     config.Config["POSTGRES_PORT"],
-		Username: config.Config["POSTGRES_USER"],
-		Password: config.Config["POSTGRES_PASSWORD"],
-		DBName:   config.Config["POSTGRES_DB"],
-		SSLMode:  config.Config["POSTGRES_SSLMODE"],
-	})
+	logger, err := logging.New(os.Stderr, config.Get("LOG_FORMAT", "text"), config.Get("LOG_LEVEL", "info"))
 	if err != nil {
-		log.Fatalf("config.Config to init DB: %s", err.Error())
+		fatal("Failed to set up logging", err)
+	}
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backup":
+			runBackup(os.Args[2:])
+		case "restore":
+			runRestore(os.Args[2:])
+		default:
+			slog.Error("Unknown command, expected backup or restore", "command", os.Args[1])
+			os.Exit(1)
+		}
+		return
 	}
 
-	repos := repository.NewRepository(db)
+	shutdownTracing, err := tracing.Setup(context.Background(), newTracingConfig())
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+
+	dbConfig := newDBConfig()
+	db, err := repository.NewPostgres(context.Background(), dbConfig)
+	if err != nil {
+		fatal("Failed to init DB", err)
+	}
+	metrics.RegisterDBStats(db.DB)
+
+	repos := repository.NewRepository(db, dbConfig)
+	if config.Get("IDEMPOTENCY_STORE", "postgres") == "memory" {
+		repos.Idempotency = repository.NewIdempotencyMemory()
+	}
+	if config.Get("RATE_LIMIT_STORE", "postgres") == "memory" {
+		repos.RateLimit = repository.NewRateLimitMemory()
+	}
+	repos.BlobStore = newBlobStore()
 	services := service.NewService(repos)
 	handlers := handler.NewHandler(services)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// workers are waited for on shutdown, before the DB they use is closed
+	var workers sync.WaitGroup
+	runWorker := func(run func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run()
+		}()
+	}
+
+	runWorker(func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := services.Idempotency.DeleteExpired(ctx); err != nil {
+				slog.Error("Failed to delete expired idempotency keys", "error", err)
+			}
+			if err := services.RateLimit.DeleteExpired(ctx); err != nil {
+				slog.Error("Failed to delete expired rate limit buckets", "error", err)
+			}
+			if err := services.Events.DeleteExpired(ctx); err != nil {
+				slog.Error("Failed to delete expired events", "error", err)
+			}
+			if err := services.Authorization.DeleteExpired(ctx); err != nil {
+				slog.Error("Failed to delete expired sign-ins and sessions", "error", err)
+			}
+		}
+	})
+
+	runWorker(func() {
+		if err := services.Events.Run(ctx); err != nil {
+			slog.Error("Events stopped", "error", err)
+		}
+	})
+
+	runWorker(func() {
+		if err := services.Webhook.Run(ctx); err != nil {
+			slog.Error("Webhook deliveries stopped", "error", err)
+		}
+	})
+
+	runWorker(func() {
+		if err := services.Attachment.Run(ctx); err != nil {
+			slog.Error("Orphaned blob sweeps stopped", "error", err)
+		}
+	})
+
+	serverConfig := newServerConfig()
+	clientIPHeader := config.Get("CLIENT_IP_HEADER", "")
+	if serverConfig.UnixSocket() && clientIPHeader == "" {
+		// every client would share the rate limits of a single address
+		slog.Error("CLIENT_IP_HEADER is required with a unix socket LISTEN_ADDR")
+		os.Exit(1)
+	}
+	if !serverConfig.UnixSocket() && clientIPHeader != "" {
+		// any client reaching the port could pick its own address
+		slog.Error("CLIENT_IP_HEADER is only allowed with a unix socket LISTEN_ADDR")
+		os.Exit(1)
+	}
 	server := new(todo.Server)
 	go func() {
-		err := server.Run(config.Config["PORT"], handlers.InitRoutes())
+		err := server.Run(serverConfig, handlers.InitRoutes())
+		if err != nil && err != http.ErrServerClosed {
+			fatal("Server error", err)
+		}
+	}()
+
+	// the admin server isn't meant to be exposed, it serves what only
+	// operators should see
+	admin := new(todo.Server)
+	adminPort := config.Get("ADMIN_PORT", "9090")
+	go func() {
+		err := admin.Run(todo.ServerConfig{Addr: ":" + adminPort}, newAdminHandler())
 		if err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %s", err.Error())
+			fatal("Admin server error", err)
 		}
 	}()
-	log.Println("Server is up and running")
+	slog.Info("Server is up and running", "addr", serverConfig.Addr, "admin_port", adminPort)
+
+	// renewed certificates are picked up on SIGHUP
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := server.ReloadCertificates(); err != nil {
+				slog.Error("Failed to reload TLS certificates", "error", err)
+				continue
+			}
+			slog.Info("Reloaded TLS certificates")
+		}
+	}()
+
+	drainDelay := configDuration("SHUTDOWN_DRAIN_DELAY", "0s")
+	shutdownTimeout := configDuration("SHUTDOWN_TIMEOUT", "15s")
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
-	log.Println("Server is shutting down")
+	slog.Info("Server is shutting down")
 
-	if err := server.Shutdown(context.Background()); err != nil {
-		log.Printf("Error: %s", err.Error())
+	// fail readiness first and keep serving for a while, so load balancers
+	// stop sending requests before the server stops taking them
+	services.Health.Drain()
+	time.Sleep(drainDelay)
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	// stopping the workers ends event streams, and the hub disconnects the
+	// sockets the server doesn't track once they're upgraded
+	cancel()
+	services.Collab.Shutdown()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shut down the server", "error", err)
+	}
+	if err := admin.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shut down the admin server", "error", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		slog.Error("Workers didn't stop in time")
 	}
 
 	if err := db.Close(); err != nil {
-		log.Printf("Error: %s", err.Error())
+		slog.Error("Failed to close the DB", "error", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+}
+
+// fatal logs the error and exits, the way log.Fatal does.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func newAdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
+}
+
+func newBlobStore() repository.BlobStore {
+	switch store := config.Get("BLOB_STORE", "fs"); store {
+	case "fs":
+		return repository.NewBlobFS(config.Get("BLOB_DIR", "attachments"))
+	case "s3":
+		return repository.NewBlobS3(repository.S3Config{
+			Endpoint:  config.Get("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:    config.Get("S3_REGION", "us-east-1"),
+			Bucket:    config.Config["S3_BUCKET"],
+			AccessKey: config.Config["S3_ACCESS_KEY"],
+			SecretKey: config.Config["S3_SECRET_KEY"],
+		})
+	default:
+		slog.Error("Unknown BLOB_STORE, expected fs or s3", "store", store)
+		os.Exit(1)
+		return nil
+	}
+}
+
+func newServerConfig() todo.ServerConfig {
+	var domains []string
+	if list := config.Get("TLS_AUTOCERT_DOMAINS", ""); list != "" {
+		domains = strings.Split(list, ",")
+	}
+
+	return todo.ServerConfig{
+		Addr:              config.Get("LISTEN_ADDR", ":"+config.Config["PORT"]),
+		ReadTimeout:       configDuration("SERVER_READ_TIMEOUT", "10s"),
+		ReadHeaderTimeout: configDuration("SERVER_READ_HEADER_TIMEOUT", "5s"),
+		WriteTimeout:      configDuration("SERVER_WRITE_TIMEOUT", "10s"),
+		IdleTimeout:       configDuration("SERVER_IDLE_TIMEOUT", "60s"),
+		MaxHeaderBytes:    configInt("SERVER_MAX_HEADER_BYTES", "1048576"),
+		MaxBodyBytes:      int64(configInt("SERVER_MAX_BODY_BYTES", "0")),
+		H2C:               config.Get("SERVER_H2C", "false") == "true",
+		TLS: todo.TLSConfig{
+			CertFile:         config.Get("TLS_CERT_FILE", ""),
+			KeyFile:          config.Get("TLS_KEY_FILE", ""),
+			AutocertDomains:  domains,
+			AutocertCacheDir: config.Get("TLS_AUTOCERT_CACHE_DIR", "autocert"),
+			ClientCAFile:     config.Get("TLS_CLIENT_CA_FILE", ""),
+			ClientAuth:       config.Get("TLS_CLIENT_AUTH", "require"),
+			RedirectAddr:     config.Get("TLS_REDIRECT_ADDR", ""),
+		},
+	}
+}
+
+func newTracingConfig() tracing.Config {
+	ratio, err := strconv.ParseFloat(config.Get("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		fatal("Invalid TRACING_SAMPLE_RATIO", err)
+	}
+	return tracing.Config{
+		Exporter:    config.Get("TRACING_EXPORTER", "none"),
+		Endpoint:    config.Get("TRACING_OTLP_ENDPOINT", ""),
+		SampleRatio: ratio,
+		ServiceName: config.Get("TRACING_SERVICE_NAME", "todo-app"),
+	}
+}
+
+func newDBConfig() repository.Config {
+	return repository.Config{
+		Host:     config.Config["POSTGRES_HOST"],
+		Port:     config.Config["POSTGRES_PORT"],
+		Username: config.Config["POSTGRES_USER"],
+		Password: config.Config["POSTGRES_PASSWORD"],
+		DBName:   config.Config["POSTGRES_DB"],
+		SSLMode:  config.Config["POSTGRES_SSLMODE"],
+
+		MaxOpenConns:    configInt("POSTGRES_MAX_OPEN_CONNS", "25"),
+		MaxIdleConns:    configInt("POSTGRES_MAX_IDLE_CONNS", "25"),
+		ConnMaxLifetime: configDuration("POSTGRES_CONN_MAX_LIFETIME", "30m"),
+		ConnectRetries:  configInt("POSTGRES_CONNECT_RETRIES", "5"),
+		ConnectBackoff:  configDuration("POSTGRES_CONNECT_BACKOFF", "1s"),
+	}
+}
+
+func configInt(key, fallback string) int {
+	n, err := strconv.Atoi(config.Get(key, fallback))
+	if err != nil {
+		fatal("Invalid "+key, err)
+	}
+	return n
+}
+
+func configDuration(key, fallback string) time.Duration {
+	d, err := time.ParseDuration(config.Get(key, fallback))
+	if err != nil {
+		fatal("Invalid "+key, err)
 	}
+	return d
 }
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE idempotency_keys
+(
+    id           serial primary key,
+    user_id      int not null,
+    key          varchar(255) not null,
+    fingerprint  varchar(64) not null,
+    status_code  int not null default 0,
+    content_type varchar(255) not null default '',
+    response     bytea,
+    created_at   timestamp not null default now(),
+    unique (user_id, key)
+);
+
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE idempotency_keys;
+
+-- +goose StatementEnd
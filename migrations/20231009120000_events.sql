@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE events
+(
+    id         bigserial primary key,
+    payload    text not null,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX events_created_at_idx ON events (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE events;
+
+-- +goose StatementEnd
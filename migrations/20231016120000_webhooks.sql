@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE webhooks
+(
+    id            serial primary key,
+    user_id       int not null,
+    url           varchar(2048) not null,
+    events        text[] not null,
+    secret        varchar(255) not null,
+    active        boolean not null default true,
+    failure_count int not null default 0,
+    created_at    timestamptz not null default now(),
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              serial primary key,
+    webhook_id      int not null,
+    event_type      varchar(255) not null,
+    payload         text not null,
+    status          varchar(16) not null default 'pending',
+    attempts        int not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    response_status int not null default 0,
+    error           text not null default '',
+    created_at      timestamptz not null default now(),
+    delivered_at    timestamptz,
+    foreign key (webhook_id) references webhooks(id) on delete cascade
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE webhook_deliveries;
+
+DROP TABLE webhooks;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE todo_items ADD COLUMN due timestamptz;
+
+ALTER TABLE todo_items ADD COLUMN priority int not null default 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+ALTER TABLE todo_items DROP COLUMN priority;
+
+ALTER TABLE todo_items DROP COLUMN due;
+
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE calendar_feeds
+(
+    user_id int primary key,
+    token   varchar(64) not null unique,
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE calendar_feeds;
+
+-- +goose StatementEnd
@@ -0,0 +1,116 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE SEQUENCE caldav_sync_seq;
+
+ALTER TABLE todo_items ADD COLUMN sync_version bigint not null default nextval('caldav_sync_seq');
+
+ALTER TABLE todo_items ADD COLUMN modified_at timestamp not null default now();
+
+ALTER TABLE todo_items ADD COLUMN caldav_uid varchar(255);
+
+ALTER TABLE todo_items ADD COLUMN caldav_name varchar(255);
+
+UPDATE todo_items SET caldav_name = 'item-' || id || '.ics';
+
+ALTER TABLE todo_items ALTER COLUMN caldav_name SET NOT NULL;
+
+CREATE TABLE caldav_tombstones
+(
+    id           serial primary key,
+    list_id      int not null,
+    name         varchar(255) not null,
+    sync_version bigint not null,
+    foreign key (list_id) references todo_lists(id) on delete cascade
+);
+
+CREATE INDEX caldav_tombstones_list_id_idx ON caldav_tombstones (list_id, sync_version);
+
+-- Items created outside of CalDAV get a resource name derived from their id
+CREATE FUNCTION caldav_item_insert() RETURNS trigger AS $$
+BEGIN
+    IF NEW.caldav_name IS NULL THEN
+        NEW.caldav_name := 'item-' || NEW.id || '.ics';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION caldav_item_update() RETURNS trigger AS $$
+BEGIN
+    NEW.sync_version := nextval('caldav_sync_seq');
+    NEW.modified_at := now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Runs before the delete cascades to lists_items, so the lists are still known
+CREATE FUNCTION caldav_item_delete() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO caldav_tombstones (list_id, name, sync_version)
+        SELECT list_id, OLD.caldav_name, nextval('caldav_sync_seq')
+        FROM lists_items WHERE item_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A moved item disappears from the old list and shows up as changed in the new one
+CREATE FUNCTION caldav_item_move() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO caldav_tombstones (list_id, name, sync_version)
+        SELECT OLD.list_id, caldav_name, nextval('caldav_sync_seq')
+        FROM todo_items WHERE id = OLD.item_id;
+    UPDATE todo_items SET sync_version = nextval('caldav_sync_seq') WHERE id = NEW.item_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER caldav_item_insert BEFORE INSERT ON todo_items
+    FOR EACH ROW EXECUTE FUNCTION caldav_item_insert();
+
+CREATE TRIGGER caldav_item_update BEFORE UPDATE ON todo_items
+    FOR EACH ROW EXECUTE FUNCTION caldav_item_update();
+
+CREATE TRIGGER caldav_item_delete BEFORE DELETE ON todo_items
+    FOR EACH ROW EXECUTE FUNCTION caldav_item_delete();
+
+CREATE TRIGGER caldav_item_move AFTER UPDATE OF list_id ON lists_items
+    FOR EACH ROW WHEN (OLD.list_id IS DISTINCT FROM NEW.list_id)
+    EXECUTE FUNCTION caldav_item_move();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TRIGGER caldav_item_move ON lists_items;
+
+DROP TRIGGER caldav_item_delete ON todo_items;
+
+DROP TRIGGER caldav_item_update ON todo_items;
+
+DROP TRIGGER caldav_item_insert ON todo_items;
+
+DROP FUNCTION caldav_item_move();
+
+DROP FUNCTION caldav_item_delete();
+
+DROP FUNCTION caldav_item_update();
+
+DROP FUNCTION caldav_item_insert();
+
+DROP TABLE caldav_tombstones;
+
+ALTER TABLE todo_items DROP COLUMN caldav_name;
+
+ALTER TABLE todo_items DROP COLUMN caldav_uid;
+
+ALTER TABLE todo_items DROP COLUMN modified_at;
+
+ALTER TABLE todo_items DROP COLUMN sync_version;
+
+DROP SEQUENCE caldav_sync_seq;
+
+-- +goose StatementEnd
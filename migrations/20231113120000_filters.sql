@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE filters
+(
+    id         serial primary key,
+    user_id    int not null,
+    name       varchar(255) not null,
+    query      varchar(1000) not null,
+    created_at timestamp not null default now(),
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE filters;
+
+-- +goose StatementEnd
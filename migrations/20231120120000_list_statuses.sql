@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE list_statuses
+(
+    id          serial primary key,
+    list_id     int not null,
+    name        varchar(64) not null,
+    position    int not null,
+    terminal    boolean not null default false,
+    transitions jsonb not null default '[]',
+    foreign key (list_id) references todo_lists(id) on delete cascade
+);
+
+CREATE INDEX list_statuses_list_id_idx ON list_statuses (list_id, position);
+
+ALTER TABLE todo_items ADD COLUMN status varchar(64) not null default '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+ALTER TABLE todo_items DROP COLUMN status;
+
+DROP TABLE list_statuses;
+
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE item_dependencies
+(
+    id         serial primary key,
+    item_id    int not null,
+    blocker_id int not null,
+    foreign key (item_id) references todo_items(id) on delete cascade,
+    foreign key (blocker_id) references todo_items(id) on delete cascade,
+    unique (item_id, blocker_id),
+    check (item_id <> blocker_id)
+);
+
+CREATE INDEX item_dependencies_blocker_id_idx ON item_dependencies (blocker_id);
+
+ALTER TABLE todo_lists ADD COLUMN blocker_policy varchar(16) not null default 'reject'
+    check (blocker_policy in ('reject', 'warn'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+ALTER TABLE todo_lists DROP COLUMN blocker_policy;
+
+DROP TABLE item_dependencies;
+
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE comments
+(
+    id         serial primary key,
+    item_id    int not null,
+    user_id    int not null,
+    body       text not null,
+    created_at timestamp not null default now(),
+    updated_at timestamp,
+    foreign key (item_id) references todo_items(id) on delete cascade,
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+CREATE INDEX comments_item_id_idx ON comments (item_id, id);
+
+CREATE TABLE comment_mentions
+(
+    id         serial primary key,
+    comment_id int not null,
+    user_id    int not null,
+    foreign key (comment_id) references comments(id) on delete cascade,
+    foreign key (user_id) references users(id) on delete cascade,
+    unique (comment_id, user_id)
+);
+
+CREATE INDEX comment_mentions_user_id_idx ON comment_mentions (user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE comment_mentions;
+
+DROP TABLE comments;
+
+-- +goose StatementEnd
@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE attachments
+(
+    id           serial primary key,
+    item_id      int not null,
+    user_id      int not null,
+    name         varchar(255) not null,
+    content_type varchar(255) not null,
+    size         bigint not null,
+    blob_key     varchar(64) not null unique,
+    created_at   timestamp not null default now(),
+    foreign key (item_id) references todo_items(id) on delete cascade,
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+CREATE INDEX attachments_item_id_idx ON attachments (item_id, id);
+
+CREATE INDEX attachments_user_id_idx ON attachments (user_id);
+
+-- Blobs of deleted attachments, removed from the blob store in the background
+CREATE TABLE orphaned_blobs
+(
+    id         serial primary key,
+    blob_key   varchar(64) not null,
+    deleted_at timestamp not null default now()
+);
+
+CREATE FUNCTION attachment_delete() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO orphaned_blobs (blob_key) VALUES (OLD.blob_key);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Deleting a list leaves its items behind without any list, so their
+-- attachments go with the last link to a list
+CREATE FUNCTION attachment_item_unlink() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM attachments WHERE item_id = OLD.item_id
+        AND NOT EXISTS (SELECT 1 FROM lists_items WHERE item_id = OLD.item_id);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachment_delete AFTER DELETE ON attachments
+    FOR EACH ROW EXECUTE FUNCTION attachment_delete();
+
+CREATE TRIGGER attachment_item_unlink AFTER DELETE ON lists_items
+    FOR EACH ROW EXECUTE FUNCTION attachment_item_unlink();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TRIGGER attachment_item_unlink ON lists_items;
+
+DROP FUNCTION attachment_item_unlink();
+
+DROP TABLE orphaned_blobs;
+
+DROP TABLE attachments;
+
+DROP FUNCTION attachment_delete();
+
+-- +goose StatementEnd
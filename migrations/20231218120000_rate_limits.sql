@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE rate_limits
+(
+    key        varchar(255) primary key,
+    tokens     double precision not null,
+    updated_at timestamptz not null
+);
+
+CREATE INDEX rate_limits_updated_at_idx ON rate_limits (updated_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE rate_limits;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE login_failures
+(
+    username     varchar(255) primary key,
+    count        int not null,
+    failed_at    timestamptz not null,
+    locked_until timestamptz not null
+);
+
+CREATE TABLE sessions
+(
+    id         serial primary key,
+    user_id    int not null,
+    ip         varchar(64) not null,
+    user_agent varchar(512) not null,
+    created_at timestamptz not null,
+    expires_at timestamptz not null,
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id, expires_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE sessions;
+DROP TABLE login_failures;
+
+-- +goose StatementEnd
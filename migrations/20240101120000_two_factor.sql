@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE two_factor
+(
+    user_id   int primary key,
+    secret    varchar(64) not null,
+    enabled   boolean not null default false,
+    last_step bigint not null default 0,
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+CREATE TABLE recovery_codes
+(
+    id        serial primary key,
+    user_id   int not null,
+    code_hash varchar(64) not null,
+    used_at   timestamptz,
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP TABLE recovery_codes;
+DROP TABLE two_factor;
+
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+package todo
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxAttachmentNameLength = 255
+
+	DefaultAttachmentQuota = 100 << 20
+)
+
+// Attachment is a file uploaded to an item. Its contents live in a blob
+// store under Key, the row only keeps what describes them.
+type Attachment struct {
+	Id     int `json:"id" db:"id"`
+	ItemId int `json:"item_id" db:"item_id"`
+	// UserId is the user who uploaded the file, whose quota it counts
+	// against.
+	UserId      int       `json:"user_id" db:"user_id"`
+	Name        string    `json:"name" db:"name"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	Key         string    `json:"-" db:"blob_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type ErrNoSuchAttachment struct{}
+
+func (e *ErrNoSuchAttachment) Error() string {
+	return "No attachment with such id"
+}
+
+type ErrInvalidAttachment struct {
+	Reason string
+}
+
+func (e *ErrInvalidAttachment) Error() string {
+	return "Invalid attachment: " + e.Reason
+}
+
+type ErrQuotaExceeded struct {
+	Quota int64
+}
+
+func (e *ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("Attachments can't take more than %d bytes", e.Quota)
+}
+
+// AttachmentName cleans up the file name a client sent, keeping only the
+// last path element without control characters.
+func AttachmentName(name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, path.Base(name))
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "", &ErrInvalidAttachment{"file name must not be empty"}
+	}
+	if utf8.RuneCountInString(name) > maxAttachmentNameLength {
+		return "", &ErrInvalidAttachment{"file name is too long"}
+	}
+	return name, nil
+}
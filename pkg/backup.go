@@ -0,0 +1,155 @@
+package todo
+
+import "time"
+
+// Tables of a backup, in the order they are restored in.
+const (
+	BackupUsers         = "users"
+	BackupLists         = "todo_lists"
+	BackupUsersLists    = "users_lists"
+	BackupListStatuses  = "list_statuses"
+	BackupItems         = "todo_items"
+	BackupListsItems    = "lists_items"
+	BackupDependencies  = "item_dependencies"
+	BackupComments      = "comments"
+	BackupMentions      = "comment_mentions"
+	BackupAttachments   = "attachments"
+	BackupFilters       = "filters"
+	BackupTwoFactors    = "two_factor"
+	BackupRecoveryCodes = "recovery_codes"
+)
+
+var BackupTables = []string{
+	BackupUsers, BackupLists, BackupUsersLists, BackupListStatuses, BackupItems, BackupListsItems,
+	BackupDependencies, BackupComments, BackupMentions, BackupAttachments, BackupFilters,
+	BackupTwoFactors, BackupRecoveryCodes,
+}
+
+// BackupUser is a user with the password hash, which User doesn't expose.
+type BackupUser struct {
+	Id           int    `json:"id" db:"id"`
+	Name         string `json:"name" db:"name"`
+	Username     string `json:"username" db:"username"`
+	PasswordHash string `json:"password_hash" db:"password_hash"`
+}
+
+// BackupItem is an item with its CalDAV name and UID, so synced clients
+// still recognize it after a restore. Backups older than version 6 don't
+// have them and restored items get new names.
+type BackupItem struct {
+	TodoItem
+	CalDAVName *string `json:"caldav_name,omitempty" db:"caldav_name"`
+	CalDAVUID  *string `json:"caldav_uid,omitempty" db:"caldav_uid"`
+}
+
+// BackupComment is a comment with the id of its author instead of the
+// author.
+type BackupComment struct {
+	Id        int        `json:"id" db:"id"`
+	ItemId    int        `json:"item_id" db:"item_id"`
+	UserId    int        `json:"user_id" db:"user_id"`
+	Body      string     `json:"body" db:"body"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CommentMention links a comment to a user it mentions.
+type CommentMention struct {
+	Id        int `json:"id" db:"id"`
+	CommentId int `json:"comment_id" db:"comment_id"`
+	UserId    int `json:"user_id" db:"user_id"`
+}
+
+// BackupAttachment is an attachment with the key of its blob. Blobs are
+// not part of backups, the blob store is backed up on its own.
+type BackupAttachment struct {
+	Id          int       `json:"id" db:"id"`
+	ItemId      int       `json:"item_id" db:"item_id"`
+	UserId      int       `json:"user_id" db:"user_id"`
+	Name        string    `json:"name" db:"name"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	Key         string    `json:"blob_key" db:"blob_key"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// BackupFilter is a saved filter with the id of its owner.
+type BackupFilter struct {
+	Id        int       `json:"id" db:"id"`
+	UserId    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Query     string    `json:"query" db:"query"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// BackupTwoFactor is the TOTP secret of a user, so two-factor auth stays on
+// after a restore. Like password hashes, it makes backups as sensitive as
+// the database.
+type BackupTwoFactor struct {
+	UserId   int    `json:"user_id" db:"user_id"`
+	Secret   string `json:"secret" db:"secret"`
+	Enabled  bool   `json:"enabled" db:"enabled"`
+	LastStep int64  `json:"last_step" db:"last_step"`
+}
+
+// BackupRecoveryCode is the hash of a recovery code of a user.
+type BackupRecoveryCode struct {
+	Id       int        `json:"id" db:"id"`
+	UserId   int        `json:"user_id" db:"user_id"`
+	CodeHash string     `json:"code_hash" db:"code_hash"`
+	UsedAt   *time.Time `json:"used_at" db:"used_at"`
+}
+
+// NewBackupRow returns a pointer to a row of the table, or nil for unknown
+// tables.
+func NewBackupRow(table string) any {
+	switch table {
+	case BackupUsers:
+		return &BackupUser{}
+	case BackupLists:
+		// lists of backups older than blocker policies keep the default
+		return &TodoList{BlockerPolicy: BlockerPolicyReject}
+	case BackupUsersLists:
+		return &UsersList{}
+	case BackupListStatuses:
+		return &ListStatus{}
+	case BackupItems:
+		return &BackupItem{}
+	case BackupListsItems:
+		return &ListsItem{}
+	case BackupDependencies:
+		return &ItemDependency{}
+	case BackupComments:
+		return &BackupComment{}
+	case BackupMentions:
+		return &CommentMention{}
+	case BackupAttachments:
+		return &BackupAttachment{}
+	case BackupFilters:
+		return &BackupFilter{}
+	case BackupTwoFactors:
+		return &BackupTwoFactor{}
+	case BackupRecoveryCodes:
+		return &BackupRecoveryCode{}
+	}
+	return nil
+}
+
+type BackupTable struct {
+	Name string
+	Rows int
+}
+
+type ErrInvalidBackup struct {
+	Reason string
+}
+
+func (e *ErrInvalidBackup) Error() string {
+	return "Invalid backup: " + e.Reason
+}
+
+type ErrRestoreNotEmpty struct{}
+
+func (e *ErrRestoreNotEmpty) Error() string {
+	return "Backups can only be restored into an empty database"
+}
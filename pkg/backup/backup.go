@@ -0,0 +1,276 @@
+// Package backup reads and writes backups of the whole database in a format
+// that doesn't depend on the storage backend.
+//
+// A backup is a gzip compressed text file:
+//
+//	TODO-BACKUP <version>
+//	CREATED <RFC 3339 time>
+//	TABLE <name>
+//	<row as a JSON object>
+//	...
+//	END <name> <rows> <SHA-256 of the row lines>
+//	...
+//	END-BACKUP <tables>
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+// Version is increased whenever the tables or their rows change. Backups of
+// older versions can still be read.
+const Version = 8
+
+// added are the versions tables were added in.
+var added = map[string]int{
+	todo.BackupListStatuses:  2,
+	todo.BackupDependencies:  3,
+	todo.BackupComments:      4,
+	todo.BackupMentions:      4,
+	todo.BackupAttachments:   5,
+	todo.BackupFilters:       7,
+	todo.BackupTwoFactors:    8,
+	todo.BackupRecoveryCodes: 8,
+}
+
+// Tables returns the tables of a backup of the version, in the order they
+// are written in.
+func Tables(version int) []string {
+	tables := make([]string, 0, len(todo.BackupTables))
+	for _, table := range todo.BackupTables {
+		if added[table] <= version {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+const (
+	magicLine   = "TODO-BACKUP"
+	createdLine = "CREATED"
+	tableLine   = "TABLE"
+	endLine     = "END"
+	doneLine    = "END-BACKUP"
+)
+
+type Writer struct {
+	gz     *gzip.Writer
+	w      *bufio.Writer
+	tables int
+}
+
+func NewWriter(w io.Writer, created time.Time) (*Writer, error) {
+	gz := gzip.NewWriter(w)
+	bw := &Writer{gz: gz, w: bufio.NewWriter(gz)}
+
+	fmt.Fprintf(bw.w, "%s %d\n%s %s\n", magicLine, Version, createdLine, created.UTC().Format(time.RFC3339))
+	return bw, bw.w.Flush()
+}
+
+// WriteTable writes the rows the rows function passes to write and returns
+// their number.
+func (w *Writer) WriteTable(name string, rows func(write func(row any) error) error) (int, error) {
+	fmt.Fprintf(w.w, "%s %s\n", tableLine, name)
+
+	sum := sha256.New()
+	count := 0
+	err := rows(func(row any) error {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		sum.Write(line)
+		count++
+		_, err = w.w.Write(line)
+		return err
+	})
+	if err != nil {
+		return count, err
+	}
+
+	w.tables++
+	fmt.Fprintf(w.w, "%s %s %d %s\n", endLine, name, count, hex.EncodeToString(sum.Sum(nil)))
+	return count, w.w.Flush()
+}
+
+// Close finishes the backup, it doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	fmt.Fprintf(w.w, "%s %d\n", doneLine, w.tables)
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+type Reader struct {
+	r       *bufio.Reader
+	version int
+	created time.Time
+	table   string
+	tables  int
+	done    bool
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, &todo.ErrInvalidBackup{Reason: err.Error()}
+	}
+	br := &Reader{r: bufio.NewReader(gz)}
+
+	fields, err := br.readFields(magicLine, 1)
+	if err != nil {
+		return nil, err
+	}
+	if br.version, err = strconv.Atoi(fields[0]); err != nil || br.version < 1 || br.version > Version {
+		return nil, &todo.ErrInvalidBackup{Reason: fmt.Sprintf("unsupported version %s", fields[0])}
+	}
+
+	fields, err = br.readFields(createdLine, 1)
+	if err != nil {
+		return nil, err
+	}
+	if br.created, err = time.Parse(time.RFC3339, fields[0]); err != nil {
+		return nil, &todo.ErrInvalidBackup{Reason: "invalid creation time"}
+	}
+
+	return br, nil
+}
+
+func (r *Reader) Version() int {
+	return r.version
+}
+
+func (r *Reader) Created() time.Time {
+	return r.created
+}
+
+// Next moves to the next table and returns its name, or io.EOF after the
+// last one.
+func (r *Reader) Next() (string, error) {
+	if r.done {
+		return "", io.EOF
+	}
+	if r.table != "" {
+		return "", &todo.ErrInvalidBackup{Reason: "rows of " + r.table + " were not read"}
+	}
+
+	line, err := r.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(line)
+	switch {
+	case len(fields) == 2 && fields[0] == tableLine:
+		r.table = fields[1]
+		return r.table, nil
+	case len(fields) == 2 && fields[0] == doneLine:
+		if tables, err := strconv.Atoi(fields[1]); err != nil || tables != r.tables {
+			return "", &todo.ErrInvalidBackup{Reason: "wrong number of tables"}
+		}
+		// reading to the end verifies the gzip checksum
+		if _, err := r.r.ReadByte(); err != io.EOF {
+			if err == nil {
+				return "", &todo.ErrInvalidBackup{Reason: "data after the end of backup"}
+			}
+			return "", r.readError(err)
+		}
+		r.done = true
+		return "", io.EOF
+	}
+	return "", &todo.ErrInvalidBackup{Reason: "unexpected line " + strconv.Quote(line)}
+}
+
+// Rows decodes the rows of the current table into values returned by newRow
+// and passes them to fn. The number and checksum of the rows are verified
+// once all of them are read, so fn has to be undone if it fails.
+func (r *Reader) Rows(newRow func() any, fn func(row any) error) (int, error) {
+	if r.table == "" {
+		return 0, &todo.ErrInvalidBackup{Reason: "no table to read"}
+	}
+
+	sum := sha256.New()
+	count := 0
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil {
+			return count, r.readError(err)
+		}
+
+		if !bytes.HasPrefix(line, []byte("{")) {
+			return count, r.finishTable(strings.TrimSuffix(string(line), "\n"), count, sum)
+		}
+
+		sum.Write(line)
+		count++
+
+		row := newRow()
+		if err := json.Unmarshal(line, row); err != nil {
+			return count, &todo.ErrInvalidBackup{Reason: fmt.Sprintf("%s row %d: %s", r.table, count, err)}
+		}
+		if err := fn(row); err != nil {
+			return count, err
+		}
+	}
+}
+
+func (r *Reader) finishTable(line string, count int, sum hash.Hash) error {
+	fields := strings.Fields(line)
+	if len(fields) != 4 || fields[0] != endLine || fields[1] != r.table {
+		return &todo.ErrInvalidBackup{Reason: "unexpected line " + strconv.Quote(line)}
+	}
+	if rows, err := strconv.Atoi(fields[2]); err != nil || rows != count {
+		return &todo.ErrInvalidBackup{Reason: fmt.Sprintf("%s has %d rows, %s expected", r.table, count, fields[2])}
+	}
+	if fields[3] != hex.EncodeToString(sum.Sum(nil)) {
+		return &todo.ErrInvalidBackup{Reason: "checksum mismatch in " + r.table}
+	}
+
+	r.table = ""
+	r.tables++
+	return nil
+}
+
+func (r *Reader) readFields(name string, n int) ([]string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != n+1 || fields[0] != name {
+		return nil, &todo.ErrInvalidBackup{Reason: "expected " + name}
+	}
+	return fields[1:], nil
+}
+
+func (r *Reader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err != nil {
+		return "", r.readError(err)
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+func (r *Reader) readError(err error) error {
+	if err == io.EOF {
+		return &todo.ErrInvalidBackup{Reason: "unexpected end of backup"}
+	}
+	return &todo.ErrInvalidBackup{Reason: err.Error()}
+}
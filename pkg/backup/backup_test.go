@@ -0,0 +1,189 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+var created = time.Date(2023, 11, 1, 12, 0, 0, 0, time.UTC)
+
+func writeBackup(t *testing.T, tables map[string][]any, order ...string) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, created)
+	assert.Equal(t, nil, err)
+
+	for _, name := range order {
+		rows, err := w.WriteTable(name, func(write func(row any) error) error {
+			for _, row := range tables[name] {
+				if err := write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, len(tables[name]), rows)
+	}
+	assert.Equal(t, nil, w.Close())
+	return buf.Bytes()
+}
+
+func gzipText(t *testing.T, text string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	io.WriteString(gz, text)
+	assert.Equal(t, nil, gz.Close())
+	return buf.Bytes()
+}
+
+func gunzipText(t *testing.T, data []byte) string {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.Equal(t, nil, err)
+	text, err := io.ReadAll(gz)
+	assert.Equal(t, nil, err)
+	return string(text)
+}
+
+func TestRoundTrip(t *testing.T) {
+	due := time.Date(2023, 10, 2, 9, 0, 0, 0, time.UTC)
+	name, uid := "rent.ics", "1f0c2a4e@example.com"
+	tables := map[string][]any{
+		todo.BackupUsers: {
+			&todo.BackupUser{Id: 1, Name: "Test", Username: "test", PasswordHash: "hash"},
+		},
+		todo.BackupItems: {
+			&todo.BackupItem{
+				TodoItem:   todo.TodoItem{Id: 3, Title: "Pay rent", Due: &due, Priority: todo.PriorityHigh},
+				CalDAVName: &name,
+				CalDAVUID:  &uid,
+			},
+			&todo.BackupItem{TodoItem: todo.TodoItem{Id: 7, Title: "Buy milk", Description: "2%", Done: true}},
+		},
+	}
+	data := writeBackup(t, tables, todo.BackupUsers, todo.BackupLists, todo.BackupItems)
+
+	r, err := NewReader(bytes.NewReader(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, created, r.Created())
+
+	read := make(map[string][]any)
+	for {
+		name, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, nil, err)
+
+		rows, err := r.Rows(func() any {
+			return todo.NewBackupRow(name)
+		}, func(row any) error {
+			read[name] = append(read[name], row)
+			return nil
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, len(tables[name]), rows)
+	}
+
+	assert.Equal(t, tables, read)
+}
+
+func TestReader_OlderVersion(t *testing.T) {
+	valid := gunzipText(t, writeBackup(t, map[string][]any{}, todo.BackupUsers))
+	r, err := NewReader(bytes.NewReader(gzipText(t, strings.Replace(valid, fmt.Sprintf("TODO-BACKUP %d", Version), "TODO-BACKUP 1", 1))))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, r.Version())
+}
+
+func TestTables(t *testing.T) {
+	assert.Equal(t, []string{
+		todo.BackupUsers, todo.BackupLists, todo.BackupUsersLists, todo.BackupItems, todo.BackupListsItems,
+	}, Tables(1))
+	assert.Equal(t, []string{
+		todo.BackupUsers, todo.BackupLists, todo.BackupUsersLists, todo.BackupListStatuses, todo.BackupItems,
+		todo.BackupListsItems,
+	}, Tables(2))
+	assert.Equal(t, todo.BackupTables, Tables(Version))
+}
+
+func TestReader_Invalid(t *testing.T) {
+	valid := gunzipText(t, writeBackup(t, map[string][]any{
+		todo.BackupLists: {&todo.TodoList{Id: 1, Title: "Home"}},
+	}, todo.BackupLists))
+
+	testTable := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "Not gzip",
+			input: []byte(valid),
+		},
+		{
+			name: "Newer version",
+			input: gzipText(t, strings.Replace(valid, fmt.Sprintf("TODO-BACKUP %d", Version),
+				fmt.Sprintf("TODO-BACKUP %d", Version+1), 1)),
+		},
+		{
+			name:  "Changed row",
+			input: gzipText(t, strings.Replace(valid, `"Home"`, `"Work"`, 1)),
+		},
+		{
+			name:  "Missing row",
+			input: gzipText(t, strings.Replace(valid, `{"id":1,"title":"Home","description":""}`+"\n", "", 1)),
+		},
+		{
+			name:  "Truncated",
+			input: gzipText(t, valid[:strings.Index(valid, "END-BACKUP")]),
+		},
+		{
+			name:  "Missing table",
+			input: gzipText(t, strings.Replace(valid, "END-BACKUP 1", "END-BACKUP 2", 1)),
+		},
+		{
+			name:  "Trailing data",
+			input: gzipText(t, valid+"TABLE users\n"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := readAll(testCase.input)
+			_, ok := err.(*todo.ErrInvalidBackup)
+			assert.Equal(t, true, ok)
+		})
+	}
+}
+
+func readAll(data []byte) error {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	for {
+		name, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = r.Rows(func() any {
+			return todo.NewBackupRow(name)
+		}, func(row any) error {
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"fmt"
+	"time"
+)
+
+type ErrNoSuchFeed struct{}
+
+func (e *ErrNoSuchFeed) Error() string {
+	return "No such calendar feed"
+}
+
+// CalendarCollection is a list as seen by CalDAV clients.
+type CalendarCollection struct {
+	List      TodoList
+	SyncToken int64
+}
+
+// CalendarObject is an item stored as a CalDAV resource. Name is the resource
+// name chosen by the client that created it, UID is empty unless the client
+// supplied its own.
+type CalendarObject struct {
+	TodoItem
+	Name     string    `db:"caldav_name"`
+	UID      string    `db:"caldav_uid"`
+	Version  int64     `db:"sync_version"`
+	Modified time.Time `db:"modified_at"`
+	Data     string    `db:"-"`
+}
+
+func (o CalendarObject) ETag() string {
+	return fmt.Sprintf(`"%d"`, o.Version)
+}
+
+type ErrNoSuchCalendarObject struct{}
+
+func (e *ErrNoSuchCalendarObject) Error() string {
+	return "No such calendar object"
+}
+
+type ErrInvalidCalendarData struct {
+	Reason string
+}
+
+func (e *ErrInvalidCalendarData) Error() string {
+	return "Invalid calendar data: " + e.Reason
+}
+
+type ErrUnsupportedCalendarComponent struct{}
+
+func (e *ErrUnsupportedCalendarComponent) Error() string {
+	return "Only VTODO components are supported"
+}
+
+type ErrPreconditionFailed struct{}
+
+func (e *ErrPreconditionFailed) Error() string {
+	return "Precondition failed"
+}
+
+type ErrInvalidSyncToken struct{}
+
+func (e *ErrInvalidSyncToken) Error() string {
+	return "Invalid sync token"
+}
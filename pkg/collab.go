@@ -0,0 +1,26 @@
+package todo
+
+const (
+	CollabPresence = "presence"
+	CollabEditing  = "editing"
+	CollabEvent    = "event"
+)
+
+type CollabMessage struct {
+	Type    string `json:"type"`
+	ListId  int    `json:"list_id"`
+	UserId  int    `json:"user_id,omitempty"`
+	ItemId  int    `json:"item_id,omitempty"`
+	Editing *bool  `json:"editing,omitempty"`
+	Users   []int  `json:"users,omitempty"`
+	Event   *Event `json:"event,omitempty"`
+}
+
+type CollabClient struct {
+	ListId int
+	UserId int
+	Send   chan CollabMessage
+	// GoingAway is set before Send is closed when the server shuts down,
+	// instead of the client falling behind.
+	GoingAway bool
+}
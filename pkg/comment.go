@@ -0,0 +1,116 @@
+package todo
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxCommentLength = 10000
+	maxMentions      = 20
+
+	DefaultCommentsLimit = 50
+	MaxCommentsLimit     = 100
+)
+
+// CommentAuthor is the public part of the user who wrote a comment.
+type CommentAuthor struct {
+	Id       int    `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	Username string `json:"username" db:"username"`
+}
+
+// Comment is a note on an item. The body is Markdown, stored and returned
+// as written for clients to render.
+type Comment struct {
+	Id     int           `json:"id" db:"id"`
+	ItemId int           `json:"item_id" db:"item_id"`
+	Author CommentAuthor `json:"author" db:"author"`
+	Body   string        `json:"body" db:"body"`
+	// Mentions are the users mentioned with @username that can see the
+	// comment.
+	Mentions  Usernames  `json:"mentions,omitempty" db:"mentions"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// Usernames are selected as a JSON array, like tags.
+type Usernames []string
+
+func (u *Usernames) Scan(src any) error {
+	return (*Tags)(u).Scan(src)
+}
+
+// CommentsPage is a page of comments, oldest first. Next is the after
+// cursor of the following page, if there is one.
+type CommentsPage struct {
+	Data []Comment `json:"data"`
+	Next int       `json:"next,omitempty"`
+}
+
+type ErrNoSuchComment struct{}
+
+func (e *ErrNoSuchComment) Error() string {
+	return "No comment with such id"
+}
+
+type ErrInvalidCommentInput struct {
+	Reason string
+}
+
+func (e *ErrInvalidCommentInput) Error() string {
+	return "Invalid comment input: " + e.Reason
+}
+
+type ErrCommentForbidden struct {
+	Reason string
+}
+
+func (e *ErrCommentForbidden) Error() string {
+	return e.Reason
+}
+
+type CommentInput struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func (i CommentInput) Validate() error {
+	if strings.TrimSpace(i.Body) == "" {
+		return &ErrInvalidCommentInput{"body must not be empty"}
+	}
+	if utf8.RuneCountInString(i.Body) > maxCommentLength {
+		return &ErrInvalidCommentInput{"body is too long"}
+	}
+	return nil
+}
+
+var (
+	// code spans and fenced code blocks, where @ is no mention
+	markdownCode = regexp.MustCompile("(?s)```.*?(```|$)|`[^`\n]*`")
+	// @ starting a word, so that e-mail addresses aren't mentions
+	mention = regexp.MustCompile(`(?:^|[^\w@.])@(\w[\w.-]*)`)
+)
+
+// ParseMentions returns the usernames mentioned in a Markdown body, each
+// once in the order they first appear.
+func ParseMentions(body string) []string {
+	body = markdownCode.ReplaceAllString(body, " ")
+
+	mentions := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range mention.FindAllStringSubmatch(body, -1) {
+		username := strings.TrimRight(m[1], ".-")
+		key := strings.ToLower(username)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mentions = append(mentions, username)
+		if len(mentions) == maxMentions {
+			break
+		}
+	}
+	return mentions
+}
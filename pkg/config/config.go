@@ -42,3 +42,11 @@ func init() {
 		log.Fatal("AUTH_PRIVATE_KEY variable not specified")
 	}
 }
+
+// Get returns the value of an optional variable or fallback if it's unset.
+func Get(key, fallback string) string {
+	if value, ok := Config[key]; ok && value != "" {
+		return value
+	}
+	return fallback
+}
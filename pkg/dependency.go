@@ -0,0 +1,123 @@
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const maxBlockers = 50
+
+// Blocker policies decide what happens when an item with open blockers is
+// marked done. Lists reject it unless set to warn.
+const (
+	BlockerPolicyReject = "reject"
+	BlockerPolicyWarn   = "warn"
+)
+
+// ValidBlockerPolicy reports whether the policy is known, an empty one
+// meaning the default.
+func ValidBlockerPolicy(policy string) bool {
+	return policy == "" || policy == BlockerPolicyReject || policy == BlockerPolicyWarn
+}
+
+// ItemIds are the ids of the items blocking an item, stored as a JSON array.
+type ItemIds []int
+
+func (ids ItemIds) Validate() error {
+	if len(ids) > maxBlockers {
+		return fmt.Errorf("more than %d blockers", maxBlockers)
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return fmt.Errorf("invalid blocker %d", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("duplicate blocker %d", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func (ids *ItemIds) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*ids = nil
+		return nil
+	case []byte:
+		return ids.unmarshal(src)
+	case string:
+		return ids.unmarshal([]byte(src))
+	}
+	return fmt.Errorf("cannot scan %T into item ids", src)
+}
+
+func (ids *ItemIds) unmarshal(data []byte) error {
+	var list []int
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		list = nil
+	}
+	*ids = list
+	return nil
+}
+
+// ItemDependency links an item to an item blocking it.
+type ItemDependency struct {
+	Id        int `json:"id" db:"id"`
+	ItemId    int `json:"item_id" db:"item_id"`
+	BlockerId int `json:"blocker_id" db:"blocker_id"`
+}
+
+// ItemDependencies are the items an item waits for and the ones waiting for
+// it, leaving out items the user can't access.
+type ItemDependencies struct {
+	BlockedBy []TodoItem `json:"blocked_by"`
+	Blocking  []TodoItem `json:"blocking"`
+}
+
+// Warning tells about something that went through but likely wasn't meant
+// to, like completing a blocked item in a list that only warns about it.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ErrInvalidDependency struct {
+	Reason string
+}
+
+func (e *ErrInvalidDependency) Error() string {
+	return "Invalid dependency: " + e.Reason
+}
+
+// ErrDependencyCycle is the path from the item back to itself a change of
+// its blockers would create, leaving out the items the user can't access.
+type ErrDependencyCycle struct {
+	Cycle []int
+}
+
+func (e *ErrDependencyCycle) Error() string {
+	return "Dependencies can't form a cycle: " + joinIds(e.Cycle, " blocked by ")
+}
+
+type ErrItemBlocked struct {
+	Blockers []int
+}
+
+func (e *ErrItemBlocked) Error() string {
+	return "Item is blocked by open items " + joinIds(e.Blockers, ", ")
+}
+
+func joinIds(ids []int, sep string) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,40 @@
+package todo
+
+import "encoding/json"
+
+const (
+	EventListCreated = "list.created"
+	EventListUpdated = "list.updated"
+	EventListDeleted = "list.deleted"
+	EventItemCreated = "item.created"
+	EventItemUpdated = "item.updated"
+	EventItemDeleted = "item.deleted"
+	EventItemMoved   = "item.moved"
+)
+
+// Event is a change to a list or item. Completed marks updates that made an
+// open item done, it's only known to the publishing replica.
+type Event struct {
+	Id         int64           `json:"id"`
+	Type       string          `json:"type"`
+	ListId     int             `json:"list_id"`
+	ItemId     int             `json:"item_id,omitempty"`
+	ActorId    int             `json:"actor_id"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Completed  bool            `json:"-"`
+	Recipients []int           `json:"-"`
+}
+
+func (e Event) IsRecipient(userId int) bool {
+	for _, id := range e.Recipients {
+		if id == userId {
+			return true
+		}
+	}
+	return false
+}
+
+type EventSubscription struct {
+	UserId int
+	Events chan Event
+}
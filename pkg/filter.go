@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Filter is a smart list, the items matching a stored filter expression.
+type Filter struct {
+	Id        int       `json:"id" db:"id"`
+	UserId    int       `json:"-" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Query     string    `json:"query" db:"query"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type ErrNoSuchFilter struct{}
+
+func (e *ErrNoSuchFilter) Error() string {
+	return "No filter with such id"
+}
+
+type ErrInvalidFilterInput struct {
+	Reason string
+}
+
+func (e *ErrInvalidFilterInput) Error() string {
+	return "Invalid filter input: " + e.Reason
+}
+
+// FilterInput is validated here apart from the syntax of the query, which
+// is up to the filter package.
+type FilterInput struct {
+	Name  string `json:"name" binding:"required"`
+	Query string `json:"query" binding:"required"`
+}
+
+func (i FilterInput) Validate() error {
+	return validateFilterName(i.Name)
+}
+
+type UpdateFilterInput struct {
+	Name  *string `json:"name"`
+	Query *string `json:"query"`
+}
+
+func (i UpdateFilterInput) Validate() error {
+	if i.Name == nil && i.Query == nil {
+		return &ErrInvalidFilterInput{"nothing to update"}
+	}
+	if i.Name != nil {
+		return validateFilterName(*i.Name)
+	}
+	return nil
+}
+
+func validateFilterName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return &ErrInvalidFilterInput{"name must not be empty"}
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		return &ErrInvalidFilterInput{"name is too long"}
+	}
+	return nil
+}
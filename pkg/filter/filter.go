@@ -0,0 +1,173 @@
+// Package filter parses the expressions of saved filters, like
+//
+//	done:false AND due<7d AND (tag:work OR priority>=high)
+//
+// An expression is made of conditions combined with AND, OR, NOT and
+// parentheses. AND binds tighter than OR. A condition is a field, an
+// operator and a value:
+//
+//	done         : = !=              true, false, yes or no
+//	priority     : = != < <= > >=    none, low, medium, high or 0 to 3
+//	due          : = != < <= > >=    none, today, tomorrow, yesterday, a date
+//	                                 like 2023-10-20 or a time relative to now
+//	                                 like 7d, 2w, -12h (only with < <= > >=)
+//	tag          : = !=              a tag, ignoring case
+//	title        : = !=              : matches a part, = and != the whole text,
+//	description                      ignoring case
+//	list         : = !=              a list id or title
+//
+// Values with spaces or special characters and the keywords AND, OR and NOT
+// as values are quoted, like title:"pay rent".
+// Keywords and field names are case insensitive.
+package filter
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	FieldDone        = "done"
+	FieldPriority    = "priority"
+	FieldDue         = "due"
+	FieldTag         = "tag"
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldList        = "list"
+)
+
+// Fields lists the fields in the order they are suggested in errors.
+var Fields = []string{
+	FieldDone, FieldDue, FieldPriority, FieldTag, FieldTitle, FieldDescription, FieldList,
+}
+
+const (
+	OpMatch = ":"
+	OpEq    = "="
+	OpNe    = "!="
+	OpLt    = "<"
+	OpLe    = "<="
+	OpGt    = ">"
+	OpGe    = ">="
+)
+
+// Priorities match the ones of items.
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+const (
+	// MaxLength is the longest expression accepted.
+	MaxLength = 1000
+	// MaxDepth limits the nesting of NOT and parentheses.
+	MaxDepth = 32
+)
+
+// Expr is one of And, Or, Not and Condition.
+type Expr interface {
+	String() string
+}
+
+type And struct {
+	Left, Right Expr
+}
+
+func (e And) String() string {
+	return fmt.Sprintf("(%s AND %s)", e.Left, e.Right)
+}
+
+type Or struct {
+	Left, Right Expr
+}
+
+func (e Or) String() string {
+	return fmt.Sprintf("(%s OR %s)", e.Left, e.Right)
+}
+
+type Not struct {
+	Expr Expr
+}
+
+func (e Not) String() string {
+	return fmt.Sprintf("NOT %s", e.Expr)
+}
+
+// Condition compares a field with a value. The value depends on the field:
+//
+//	done                bool
+//	priority            int
+//	due                 nil for none, Days, Date or Offset
+//	tag, title          string
+//	description
+//	list                int for an id, string for a title
+type Condition struct {
+	Field string
+	Op    string
+	Value any
+}
+
+func (c Condition) String() string {
+	switch value := c.Value.(type) {
+	case nil:
+		return c.Field + c.Op + "none"
+	case string:
+		return fmt.Sprintf("%s%s%q", c.Field, c.Op, value)
+	default:
+		return fmt.Sprintf("%s%s%v", c.Field, c.Op, value)
+	}
+}
+
+// Days is a day relative to today, 0 being today.
+type Days int
+
+func (d Days) String() string {
+	return fmt.Sprintf("today%+dd", int(d))
+}
+
+// Date is a calendar day.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Offset is a point in time relative to now.
+type Offset time.Duration
+
+func (o Offset) String() string {
+	if o < 0 {
+		return fmt.Sprintf("now%v", time.Duration(o))
+	}
+	return fmt.Sprintf("now+%v", time.Duration(o))
+}
+
+// Day returns the start and the end of the day the value refers to, taking
+// today from now.
+func Day(value any, now time.Time) (time.Time, time.Time) {
+	var start time.Time
+	switch value := value.(type) {
+	case Days:
+		start = time.Date(now.Year(), now.Month(), now.Day()+int(value), 0, 0, 0, 0, now.Location())
+	case Date:
+		start = time.Date(value.Year, value.Month, value.Day, 0, 0, 0, 0, now.Location())
+	}
+	return start, start.AddDate(0, 0, 1)
+}
+
+// SyntaxError points at the column of the expression, counting from 1, that
+// couldn't be parsed.
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
@@ -0,0 +1,300 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	testTable := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "Request example",
+			query:    "done:false AND due<7d AND (tag:work OR priority>=high)",
+			expected: `((done:false AND due<now+168h0m0s) AND (tag:"work" OR priority>=3))`,
+		},
+		{
+			name:     "OR binds looser than AND",
+			query:    "tag:a OR tag:b AND tag:c",
+			expected: `(tag:"a" OR (tag:"b" AND tag:"c"))`,
+		},
+		{
+			name:     "Left associative",
+			query:    "tag:a OR tag:b OR tag:c",
+			expected: `((tag:"a" OR tag:"b") OR tag:"c")`,
+		},
+		{
+			name:     "NOT",
+			query:    "NOT done:true AND NOT (tag:a OR tag:b)",
+			expected: `(NOT done:true AND NOT (tag:"a" OR tag:"b"))`,
+		},
+		{
+			name:     "Double NOT",
+			query:    "not not done:yes",
+			expected: `NOT NOT done:true`,
+		},
+		{
+			name:     "Case insensitive",
+			query:    "Done:FALSE and Priority:High or TAG:Work",
+			expected: `((done:false AND priority:3) OR tag:"Work")`,
+		},
+		{
+			name:     "Spaces around operators",
+			query:    "priority >= 2 and due != none",
+			expected: `(priority>=2 AND due!=none)`,
+		},
+		{
+			name:     "No spaces around parentheses",
+			query:    "(tag:a)AND(tag:b)",
+			expected: `(tag:"a" AND tag:"b")`,
+		},
+		{
+			name:     "Quoted value",
+			query:    `title:"pay \"the\" rent" AND description="" AND list:"Home Stuff"`,
+			expected: `((title:"pay \"the\" rent" AND description="") AND list:"Home Stuff")`,
+		},
+		{
+			name:     "Keywords as values",
+			query:    `title:"and" AND tag:"or" AND tag:"NOT"`,
+			expected: `((title:"and" AND tag:"or") AND tag:"NOT")`,
+		},
+		{
+			name:     "List id",
+			query:    "list:12 OR list!=Inbox",
+			expected: `(list:12 OR list!="Inbox")`,
+		},
+		{
+			name:     "Due days",
+			query:    "due:today OR due<=tomorrow OR due>yesterday",
+			expected: `((due:today+0d OR due<=today+1d) OR due>today-1d)`,
+		},
+		{
+			name:     "Due date",
+			query:    "due>=2023-10-20",
+			expected: `due>=2023-10-20`,
+		},
+		{
+			name:     "Due relative",
+			query:    "due<-12h OR due>=+2w OR due>0d",
+			expected: `((due<now-12h0m0s OR due>=now+336h0m0s) OR due>now+0s)`,
+		},
+		{
+			name:     "Due none",
+			query:    "due=none",
+			expected: `due=none`,
+		},
+		{
+			name:     "Priority numbers",
+			query:    "priority<2 AND priority!=0",
+			expected: `(priority<2 AND priority!=0)`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			expr, err := Parse(testCase.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if expr.String() != testCase.expected {
+				t.Errorf("got %s, want %s", expr, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	testTable := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "Empty",
+			query:    "   ",
+			expected: "column 1: empty filter",
+		},
+		{
+			name:     "Unknown field close to a known one",
+			query:    "done:false AND prio>=high",
+			expected: `column 16: unknown field "prio", did you mean "priority"?`,
+		},
+		{
+			name:     "Misspelled field",
+			query:    "tga:work",
+			expected: `column 1: unknown field "tga", did you mean "tag"?`,
+		},
+		{
+			name:     "Unknown field",
+			query:    "colour:red",
+			expected: `column 1: unknown field "colour", expected one of done, due, priority, tag, title, description, list`,
+		},
+		{
+			name:     "Bare word",
+			query:    "urgent",
+			expected: `column 1: expected a condition like tag:work, got "urgent"`,
+		},
+		{
+			name:     "Missing operator",
+			query:    "due 7d",
+			expected: `column 5: expected an operator like : or = after "due"`,
+		},
+		{
+			name:     "Missing value",
+			query:    "tag: AND done:true",
+			expected: `column 6: missing value after "tag:", got "AND"`,
+		},
+		{
+			name:     "Missing value at the end",
+			query:    "due<",
+			expected: `column 5: missing value after "due<", got end of filter`,
+		},
+		{
+			name:     "Missing AND",
+			query:    "tag:work priority:high",
+			expected: `column 10: expected AND or OR before "priority"`,
+		},
+		{
+			name:     "Trailing AND",
+			query:    "tag:work AND",
+			expected: `column 13: expected a condition after "AND", got end of filter`,
+		},
+		{
+			name:     "Double OR",
+			query:    "tag:a OR OR tag:b",
+			expected: `column 10: expected a condition after "OR", got "OR"`,
+		},
+		{
+			name:     "Leading AND",
+			query:    "AND tag:a",
+			expected: `column 1: expected a condition, got "AND"`,
+		},
+		{
+			name:     "Unclosed parenthesis",
+			query:    "done:false AND (tag:a OR tag:b",
+			expected: `column 31: missing ")" for "(" at column 16`,
+		},
+		{
+			name:     "Unopened parenthesis",
+			query:    "tag:a) OR tag:b",
+			expected: `column 6: unexpected ")" without "("`,
+		},
+		{
+			name:     "Empty parentheses",
+			query:    "()",
+			expected: `column 2: expected a condition after "(", got ")"`,
+		},
+		{
+			name:     "Missing quote",
+			query:    `title:"pay rent`,
+			expected: `column 7: missing closing quote`,
+		},
+		{
+			name:     "Bang",
+			query:    "!done:true",
+			expected: `column 1: unexpected "!", use != or NOT`,
+		},
+		{
+			name:     "Invalid done",
+			query:    "done:maybe",
+			expected: `column 6: invalid done "maybe", expected true or false`,
+		},
+		{
+			name:     "Invalid priority",
+			query:    "priority>=urgent",
+			expected: `column 11: invalid priority "urgent", expected none, low, medium, high or 0 to 3`,
+		},
+		{
+			name:  "Invalid due",
+			query: "due<7x",
+			expected: `column 5: invalid due "7x", expected none, today, tomorrow, yesterday, ` +
+				`a date like 2023-10-20 or a relative time like 7d, 2w or -12h`,
+		},
+		{
+			name:  "Invalid date",
+			query: "due:2023-02-30",
+			expected: `column 5: invalid due "2023-02-30", expected none, today, tomorrow, yesterday, ` +
+				`a date like 2023-10-20 or a relative time like 7d, 2w or -12h`,
+		},
+		{
+			name:     "Ordered none",
+			query:    "due<none",
+			expected: `column 4: operator "<" can't be used with due:none, use :, = or !=`,
+		},
+		{
+			name:     "Unordered relative time",
+			query:    "due:7d",
+			expected: `column 4: operator ":" can't be used with relative times, use <, <=, > or >=`,
+		},
+		{
+			name:     "Ordered tag",
+			query:    "tag>work",
+			expected: `column 4: operator ">" can't be used with tag, use :, = or !=`,
+		},
+		{
+			name:     "Empty tag",
+			query:    `tag:""`,
+			expected: `column 5: tag must not be empty`,
+		},
+		{
+			name:     "Too long",
+			query:    "title:" + strings.Repeat("a", MaxLength),
+			expected: `column 1001: filter is longer than 1000 characters`,
+		},
+		{
+			name:     "Too deep",
+			query:    strings.Repeat("(", MaxDepth+1) + "tag:a" + strings.Repeat(")", MaxDepth+1),
+			expected: `column 34: filter is nested too deeply`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse(testCase.query)
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Fatalf("got %v, want a syntax error", err)
+			}
+			if err.Error() != testCase.expected {
+				t.Errorf("got %s, want %s", err, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestDay(t *testing.T) {
+	now := time.Date(2023, time.October, 18, 15, 0, 0, 0, time.UTC)
+	testTable := []struct {
+		name  string
+		value any
+		start time.Time
+	}{
+		{
+			name:  "Today",
+			value: Days(0),
+			start: time.Date(2023, time.October, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Yesterday",
+			value: Days(-1),
+			start: time.Date(2023, time.October, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Date",
+			value: Date{2023, time.December, 31},
+			start: time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			start, end := Day(testCase.value, now)
+			if !start.Equal(testCase.start) || !end.Equal(testCase.start.AddDate(0, 0, 1)) {
+				t.Errorf("got %s to %s, want a day from %s", start, end, testCase.start)
+			}
+		})
+	}
+}
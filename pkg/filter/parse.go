@@ -0,0 +1,397 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	tokEOF = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   int
+	text   string
+	column int
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, keyword)
+}
+
+// describe quotes the token for error messages.
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+func isSpecial(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()"<>=!:`, r)
+}
+
+func lex(query string) ([]token, error) {
+	runes := []rune(query)
+	tokens := make([]token, 0)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", column})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", column})
+			i++
+		case r == ':' || r == '=':
+			tokens = append(tokens, token{tokOp, string(r), column})
+			i++
+		case r == '<' || r == '>' || r == '!':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &SyntaxError{column, `unexpected "!", use != or NOT`}
+			}
+			tokens = append(tokens, token{tokOp, op, column})
+			i += len(op)
+		case r == '"':
+			var text strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				text.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &SyntaxError{column, "missing closing quote"}
+			}
+			tokens = append(tokens, token{tokString, text.String(), column})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !isSpecial(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokWord, string(runes[start:i]), column})
+		}
+	}
+	return append(tokens, token{tokEOF, "", len(runes) + 1}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) prev() token {
+	return p.tokens[p.pos-1]
+}
+
+// Parse parses an expression, returning a *SyntaxError for invalid ones.
+func Parse(query string) (Expr, error) {
+	if utf8.RuneCountInString(query) > MaxLength {
+		return nil, &SyntaxError{MaxLength + 1, fmt.Sprintf("filter is longer than %d characters", MaxLength)}
+	}
+
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokEOF {
+		return nil, &SyntaxError{1, "empty filter"}
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokRParen {
+		return nil, &SyntaxError{t.column, `unexpected ")" without "("`}
+	}
+	return expr, nil
+}
+
+func (p *parser) parseOr(depth int) (Expr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().is("OR") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.is("AND"):
+			p.next()
+			right, err := p.parseUnary(depth)
+			if err != nil {
+				return nil, err
+			}
+			left = And{left, right}
+		case t.kind == tokEOF || t.kind == tokRParen || t.is("OR"):
+			return left, nil
+		default:
+			return nil, &SyntaxError{t.column, fmt.Sprintf("expected AND or OR before %s", t.describe())}
+		}
+	}
+}
+
+func (p *parser) parseUnary(depth int) (Expr, error) {
+	t := p.peek()
+	if depth > MaxDepth {
+		return nil, &SyntaxError{t.column, "filter is nested too deeply"}
+	}
+
+	operand := p.tokens[min(p.pos+1, len(p.tokens)-1)].kind != tokOp
+	switch {
+	case t.is("NOT") && operand:
+		p.next()
+		expr, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{expr}, nil
+
+	case t.kind == tokLParen:
+		p.next()
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, &SyntaxError{p.peek().column,
+				fmt.Sprintf(`missing ")" for "(" at column %d`, t.column)}
+		}
+		p.next()
+		return expr, nil
+
+	case t.kind == tokWord && !((t.is("AND") || t.is("OR")) && operand):
+		return p.parseCondition()
+	}
+
+	if p.pos > 0 {
+		return nil, &SyntaxError{t.column,
+			fmt.Sprintf("expected a condition after %s, got %s", p.prev().describe(), t.describe())}
+	}
+	return nil, &SyntaxError{t.column, fmt.Sprintf("expected a condition, got %s", t.describe())}
+}
+
+func (p *parser) parseCondition() (Expr, error) {
+	fieldToken := p.next()
+	field := strings.ToLower(fieldToken.text)
+	known := false
+	for _, f := range Fields {
+		known = known || f == field
+	}
+
+	opToken := p.peek()
+	if opToken.kind != tokOp {
+		if known {
+			return nil, &SyntaxError{opToken.column,
+				fmt.Sprintf("expected an operator like : or = after %s", fieldToken.describe())}
+		}
+		return nil, &SyntaxError{fieldToken.column,
+			fmt.Sprintf("expected a condition like tag:work, got %s", fieldToken.describe())}
+	}
+	if !known {
+		return nil, &SyntaxError{fieldToken.column, unknownField(fieldToken.text)}
+	}
+	p.next()
+
+	// keywords are most likely a forgotten value
+	valueToken := p.peek()
+	if valueToken.kind != tokString && (valueToken.kind != tokWord ||
+		valueToken.is("AND") || valueToken.is("OR") || valueToken.is("NOT")) {
+		return nil, &SyntaxError{valueToken.column,
+			fmt.Sprintf("missing value after %q, got %s", fieldToken.text+opToken.text, valueToken.describe())}
+	}
+	p.next()
+
+	value, msg := parseValue(field, valueToken.text)
+	if msg != "" {
+		return nil, &SyntaxError{valueToken.column, msg}
+	}
+	if msg := checkOp(field, opToken.text, value); msg != "" {
+		return nil, &SyntaxError{opToken.column, msg}
+	}
+	return Condition{Field: field, Op: opToken.text, Value: value}, nil
+}
+
+var relativeTime = regexp.MustCompile(`^([+-]?)(\d{1,5})([hdw])$`)
+
+// parseValue returns the value of the field or the reason it's invalid.
+func parseValue(field, text string) (any, string) {
+	lower := strings.ToLower(text)
+	switch field {
+	case FieldDone:
+		switch lower {
+		case "true", "yes":
+			return true, ""
+		case "false", "no":
+			return false, ""
+		}
+		return nil, fmt.Sprintf("invalid done %q, expected true or false", text)
+
+	case FieldPriority:
+		switch lower {
+		case "none", "0":
+			return PriorityNone, ""
+		case "low", "1":
+			return PriorityLow, ""
+		case "medium", "2":
+			return PriorityMedium, ""
+		case "high", "3":
+			return PriorityHigh, ""
+		}
+		return nil, fmt.Sprintf("invalid priority %q, expected none, low, medium, high or 0 to 3", text)
+
+	case FieldDue:
+		switch lower {
+		case "none":
+			return nil, ""
+		case "today":
+			return Days(0), ""
+		case "tomorrow":
+			return Days(1), ""
+		case "yesterday":
+			return Days(-1), ""
+		}
+		if date, err := time.Parse("2006-01-02", text); err == nil {
+			return Date{date.Year(), date.Month(), date.Day()}, ""
+		}
+		if m := relativeTime.FindStringSubmatch(lower); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			unit := map[string]time.Duration{"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[3]]
+			offset := time.Duration(n) * unit
+			if m[1] == "-" {
+				offset = -offset
+			}
+			return Offset(offset), ""
+		}
+		return nil, fmt.Sprintf("invalid due %q, expected none, today, tomorrow, yesterday, "+
+			"a date like 2023-10-20 or a relative time like 7d, 2w or -12h", text)
+
+	case FieldTag:
+		if text == "" {
+			return nil, "tag must not be empty"
+		}
+		return text, ""
+
+	case FieldList:
+		if id, err := strconv.Atoi(text); err == nil && id > 0 {
+			return id, ""
+		}
+		if text == "" {
+			return nil, "list must not be empty"
+		}
+		return text, ""
+	}
+
+	return text, ""
+}
+
+// checkOp returns why op can't compare the field with the value, if it can't.
+func checkOp(field, op string, value any) string {
+	ordered := op == OpLt || op == OpLe || op == OpGt || op == OpGe
+	switch field {
+	case FieldPriority:
+		return ""
+	case FieldDue:
+		switch value.(type) {
+		case nil:
+			if ordered {
+				return fmt.Sprintf(`operator %q can't be used with due:none, use :, = or !=`, op)
+			}
+		case Offset:
+			if !ordered {
+				return fmt.Sprintf(`operator %q can't be used with relative times, use <, <=, > or >=`, op)
+			}
+		}
+		return ""
+	}
+
+	if ordered {
+		return fmt.Sprintf("operator %q can't be used with %s, use :, = or !=", op, field)
+	}
+	return ""
+}
+
+func unknownField(name string) string {
+	lower := strings.ToLower(name)
+	for _, field := range Fields {
+		if (len(lower) >= 2 && strings.HasPrefix(field, lower)) || distance(lower, field) <= 2 {
+			return fmt.Sprintf("unknown field %q, did you mean %q?", name, field)
+		}
+	}
+	return fmt.Sprintf("unknown field %q, expected one of %s", name, strings.Join(Fields, ", "))
+}
+
+// distance is the Levenshtein distance of a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			above := row[j]
+			row[j] = min(row[j]+1, row[j-1]+1, diagonal+cost)
+			diagonal = above
+		}
+	}
+	return row[len(rb)]
+}
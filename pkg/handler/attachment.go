@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentTimeout replaces the server's read and write timeouts for
+// uploads and downloads, which take as long as the file needs.
+const attachmentTimeout = 30 * time.Minute
+
+// attachmentError picks the status for err and the message to send with it.
+// Stores wrap errors, e.g. with the failure to abort an upload, so the known
+// ones are found with errors.As and only their own message is sent.
+func attachmentError(err error) (int, string) {
+	var (
+		noSuchItem       *todo.ErrNoSuchItem
+		noSuchAttachment *todo.ErrNoSuchAttachment
+		invalid          *todo.ErrInvalidAttachment
+		quotaExceeded    *todo.ErrQuotaExceeded
+	)
+	switch {
+	case errors.As(err, &noSuchItem):
+		return http.StatusOK, noSuchItem.Error()
+	case errors.As(err, &noSuchAttachment):
+		return http.StatusOK, noSuchAttachment.Error()
+	case errors.As(err, &invalid):
+		return http.StatusBadRequest, invalid.Error()
+	case errors.As(err, &quotaExceeded):
+		return http.StatusRequestEntityTooLarge, quotaExceeded.Error()
+	default:
+		return http.StatusInternalServerError, err.Error()
+	}
+}
+
+// extendDeadlines gives the request attachmentTimeout from now. Writers
+// that can't change deadlines, like the ones of tests, are left alone.
+func extendDeadlines(c *gin.Context) {
+	rc := http.NewResponseController(c.Writer)
+	deadline := time.Now().Add(attachmentTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil && err != http.ErrNotSupported {
+		logging.FromContext(c.Request.Context()).Error("Failed to extend read deadline", "error", err)
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil && err != http.ErrNotSupported {
+		logging.FromContext(c.Request.Context()).Error("Failed to extend write deadline", "error", err)
+	}
+}
+
+// bodyReader remembers why reading the request body failed, telling broken
+// uploads apart from failures to store them.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// createAttachment streams the file part of a multipart/form-data body to
+// storage without holding it in memory or on disk first.
+func (h *Handler) createAttachment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	extendDeadlines(c)
+
+	form, err := c.Request.MultipartReader()
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	for {
+		part, err := form.NextPart()
+		if err == io.EOF {
+			newErrorResponse(c, http.StatusBadRequest, "No file in request body")
+			return
+		}
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		body := &bodyReader{r: part}
+		attachment, err := h.services.Attachment.Create(c.Request.Context(), userId, itemId,
+			part.FileName(), body)
+		if body.err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if err != nil {
+			status, message := attachmentError(err)
+			newErrorResponse(c, status, message)
+			return
+		}
+
+		c.JSON(http.StatusOK, attachment)
+		return
+	}
+}
+
+func (h *Handler) getAllAttachments(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	attachments, err := h.services.Attachment.GetAll(c.Request.Context(), userId, itemId)
+	if err != nil {
+		status, message := attachmentError(err)
+		newErrorResponse(c, status, message)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// getAttachment downloads the file. It's always sent as an attachment with
+// sniffing disabled, so browsers don't render uploaded HTML as a page.
+func (h *Handler) getAttachment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	attachmentId, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid attachment id")
+		return
+	}
+
+	attachment, rc, err := h.services.Attachment.Open(c.Request.Context(), userId, itemId, attachmentId)
+	if err != nil {
+		status, message := attachmentError(err)
+		newErrorResponse(c, status, message)
+		return
+	}
+	defer rc.Close()
+
+	extendDeadlines(c)
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, rc, map[string]string{
+		"Content-Disposition":    disposition,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *Handler) deleteAttachment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	attachmentId, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid attachment id")
+		return
+	}
+
+	if err := h.services.Attachment.Delete(c.Request.Context(), userId, itemId, attachmentId); err != nil {
+		status, message := attachmentError(err)
+		newErrorResponse(c, status, message)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+var testAttachment = todo.Attachment{
+	Id:          2,
+	ItemId:      1,
+	UserId:      1,
+	Name:        "notes.txt",
+	ContentType: "text/plain; charset=utf-8",
+	Size:        8,
+	Key:         "0123456789abcdef0123456789abcdef",
+	CreatedAt:   time.Date(2023, 12, 11, 10, 0, 0, 0, time.UTC),
+}
+
+const testAttachmentJSON = `{"id":2,"item_id":1,"user_id":1,"name":"notes.txt",` +
+	`"content_type":"text/plain; charset=utf-8","size":8,"created_at":"2023-12-11T10:00:00Z"}`
+
+// multipartBody returns a form with a comment field and, unless fileName is
+// empty, a file field.
+func multipartBody(fileName, data string) (string, *bytes.Buffer) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	w.WriteField("comment", "ignored")
+	if fileName != "" {
+		part, _ := w.CreateFormFile("file", fileName)
+		part.Write([]byte(data))
+	}
+	w.Close()
+	return w.FormDataContentType(), body
+}
+
+func TestHandler_createAttachment(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAttachment)
+
+	readAll := func(attachment todo.Attachment, err error) func(ctx context.Context, userId, itemId int, name string, r io.Reader) (todo.Attachment, error) {
+		return func(ctx context.Context, userId, itemId int, name string, r io.Reader) (todo.Attachment, error) {
+			data, readErr := io.ReadAll(r)
+			if readErr != nil {
+				return todo.Attachment{}, readErr
+			}
+			if string(data) != "Buy milk" {
+				return todo.Attachment{}, errors.New("unexpected data " + string(data))
+			}
+			return attachment, err
+		}
+	}
+
+	testTable := []struct {
+		name             string
+		fileName         string
+		truncate         bool
+		contentType      string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:     "OK",
+			fileName: "notes.txt",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, "notes.txt", gomock.Any()).
+					DoAndReturn(readAll(testAttachment, nil))
+			},
+			expectedStatus:   200,
+			expectedResponse: testAttachmentJSON,
+		},
+		{
+			name:             "Not multipart",
+			contentType:      "application/json",
+			mockBehavior:     func(s *mock_service.MockAttachment) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:             "No file",
+			mockBehavior:     func(s *mock_service.MockAttachment) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"No file in request body"}`,
+		},
+		{
+			name:     "Truncated",
+			fileName: "notes.txt",
+			truncate: true,
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, "notes.txt", gomock.Any()).
+					DoAndReturn(readAll(testAttachment, nil))
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:     "Over quota",
+			fileName: "notes.txt",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, "notes.txt", gomock.Any()).
+					DoAndReturn(readAll(todo.Attachment{}, &todo.ErrQuotaExceeded{Quota: 4}))
+			},
+			expectedStatus:   413,
+			expectedResponse: `{"message":"Attachments can't take more than 4 bytes"}`,
+		},
+		{
+			name:     "Over quota, abort failed",
+			fileName: "notes.txt",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				err := fmt.Errorf("%w, aborting the upload: connection reset", &todo.ErrQuotaExceeded{Quota: 4})
+				s.EXPECT().Create(gomock.Any(), 1, 1, "notes.txt", gomock.Any()).
+					DoAndReturn(readAll(todo.Attachment{}, err))
+			},
+			expectedStatus:   413,
+			expectedResponse: `{"message":"Attachments can't take more than 4 bytes"}`,
+		},
+		{
+			name:     "No such item",
+			fileName: "notes.txt",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, "notes.txt", gomock.Any()).
+					Return(todo.Attachment{}, &todo.ErrNoSuchItem{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No item with such id"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			attachment := mock_service.NewMockAttachment(c)
+			testCase.mockBehavior(attachment)
+
+			services := &service.Service{Attachment: attachment}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/items/:id/attachments", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.createAttachment)
+
+			contentType, body := multipartBody(testCase.fileName, "Buy milk")
+			if testCase.truncate {
+				body.Truncate(strings.Index(body.String(), "Buy milk") + 3)
+			}
+			if testCase.contentType != "" {
+				contentType = testCase.contentType
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/items/1/attachments", body)
+			req.Header.Set("Content-Type", contentType)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
+
+func TestHandler_getAttachment(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAttachment)
+
+	testTable := []struct {
+		name                string
+		mockBehavior        mockBehavior
+		expectedStatus      int
+		expectedResponse    string
+		expectedType        string
+		expectedDisposition string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				attachment := testAttachment
+				attachment.Name = "Einkäufe.txt"
+				s.EXPECT().Open(gomock.Any(), 1, 1, 2).
+					Return(attachment, io.NopCloser(strings.NewReader("Buy milk")), nil)
+			},
+			expectedStatus:      200,
+			expectedResponse:    "Buy milk",
+			expectedType:        "text/plain; charset=utf-8",
+			expectedDisposition: "attachment; filename*=utf-8''Eink%C3%A4ufe.txt",
+		},
+		{
+			name: "No such attachment",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Open(gomock.Any(), 1, 1, 2).
+					Return(todo.Attachment{}, nil, &todo.ErrNoSuchAttachment{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No attachment with such id"}`,
+			expectedType:     "application/json; charset=utf-8",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			attachment := mock_service.NewMockAttachment(c)
+			testCase.mockBehavior(attachment)
+
+			services := &service.Service{Attachment: attachment}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/items/:id/attachments/:attachment_id", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getAttachment)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/items/1/attachments/2", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+			assert.Equal(t, w.Header().Get("Content-Type"), testCase.expectedType)
+			assert.Equal(t, w.Header().Get("Content-Disposition"), testCase.expectedDisposition)
+		})
+	}
+}
+
+func TestHandler_deleteAttachment(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAttachment)
+
+	testTable := []struct {
+		name             string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Delete(gomock.Any(), 1, 1, 2).Return(nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"status":"ok"}`,
+		},
+		{
+			name: "No such attachment",
+			mockBehavior: func(s *mock_service.MockAttachment) {
+				s.EXPECT().Delete(gomock.Any(), 1, 1, 2).Return(&todo.ErrNoSuchAttachment{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No attachment with such id"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			attachment := mock_service.NewMockAttachment(c)
+			testCase.mockBehavior(attachment)
+
+			services := &service.Service{Attachment: attachment}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.DELETE("/api/items/:id/attachments/:attachment_id", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.deleteAttachment)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/items/1/attachments/2", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,7 +16,7 @@ func (h *Handler) signUp(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.Authorization.CreateUser(input)
+	id, err := h.services.Authorization.CreateUser(c.Request.Context(), input)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -27,7 +28,7 @@ func (h *Handler) signUp(c *gin.Context) {
 }
 
 type signInInput struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required,max=255"`
 	Password string `json:"password" binding:"required"`
 }
 
@@ -39,19 +40,95 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+	token, err := h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password,
+		todo.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()})
 	if err != nil {
-		switch err.(type) {
+		switch err := err.(type) {
 		case *todo.ErrInvalidCredentials:
+			metrics.SignIns.WithLabelValues("failure").Inc()
 			newErrorResponse(c, http.StatusOK, err.Error())
 			return
+		case *todo.ErrTwoFactorRequired:
+			metrics.SignIns.WithLabelValues("challenge").Inc()
+			c.JSON(http.StatusOK, map[string]any{
+				"two_factor_required": true,
+				"challenge":           err.Challenge,
+			})
+			return
+		case *todo.ErrAccountLocked:
+			metrics.SignIns.WithLabelValues("locked").Inc()
+			c.Header("Retry-After", seconds(err.RetryAfter))
+			newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+			return
 		default:
+			metrics.SignIns.WithLabelValues("error").Inc()
 			newErrorResponse(c, http.StatusInternalServerError, err.Error())
 			return
 		}
 	}
+	metrics.SignIns.WithLabelValues("success").Inc()
 
 	c.JSON(http.StatusOK, map[string]any{
 		"token": token,
 	})
 }
+
+type verifyTwoFactorInput struct {
+	Challenge string `json:"challenge" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+func (h *Handler) verifyTwoFactor(c *gin.Context) {
+	var input verifyTwoFactorInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	token, err := h.services.Authorization.VerifyTwoFactor(c.Request.Context(), input.Challenge, input.Code,
+		todo.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+	if err != nil {
+		switch err := err.(type) {
+		case *todo.ErrInvalidChallenge, *todo.ErrInvalidTwoFactorCode:
+			metrics.SignIns.WithLabelValues("failure").Inc()
+			newErrorResponse(c, http.StatusOK, err.Error())
+			return
+		case *todo.ErrAccountLocked:
+			metrics.SignIns.WithLabelValues("locked").Inc()
+			c.Header("Retry-After", seconds(err.RetryAfter))
+			newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+			return
+		default:
+			metrics.SignIns.WithLabelValues("error").Inc()
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	metrics.SignIns.WithLabelValues("success").Inc()
+
+	c.JSON(http.StatusOK, map[string]any{
+		"token": token,
+	})
+}
+
+type getSessionsResponse struct {
+	Data []todo.Session `json:"data"`
+}
+
+func (h *Handler) getSessions(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	sessions, err := h.services.Authorization.GetSessions(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getSessionsResponse{
+		Data: sessions,
+	})
+}
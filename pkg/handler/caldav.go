@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	davRootPath       = "/dav/"
+	davPrincipalPath  = "/dav/principal/"
+	davCalendarsPath  = "/dav/calendars/"
+	davObjectType     = "text/calendar; charset=utf-8; component=VTODO"
+	davSyncTokenScope = "urn:todo-app:sync:"
+	davMaxObjectSize  = 1 << 20
+
+	davPrivileges = "<d:privilege><d:read/></d:privilege>" +
+		"<d:privilege><d:write/></d:privilege>" +
+		"<d:privilege><d:write-content/></d:privilege>" +
+		"<d:privilege><d:bind/></d:privilege>" +
+		"<d:privilege><d:unbind/></d:privilege>"
+	davReports = "<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>" +
+		"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>" +
+		"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>"
+)
+
+func calendarHref(listId int) string {
+	return fmt.Sprintf("%s%d/", davCalendarsPath, listId)
+}
+
+func objectHref(listId int, name string) string {
+	return calendarHref(listId) + url.PathEscape(name)
+}
+
+func formatSyncToken(token int64) string {
+	return davSyncTokenScope + strconv.FormatInt(token, 10)
+}
+
+func parseSyncToken(token string) (int64, error) {
+	if !strings.HasPrefix(token, davSyncTokenScope) {
+		return 0, &todo.ErrInvalidSyncToken{}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimPrefix(token, davSyncTokenScope), 10, 64)
+	if err != nil {
+		return 0, &todo.ErrInvalidSyncToken{}
+	}
+	return n, nil
+}
+
+func rootResource() davResource {
+	return davResource{
+		href: davRootPath,
+		props: map[xml.Name]string{
+			propResourceType:         "<d:collection/>",
+			propCurrentUserPrincipal: hrefElement(davPrincipalPath),
+		},
+	}
+}
+
+func principalResource() davResource {
+	return davResource{
+		href: davPrincipalPath,
+		props: map[xml.Name]string{
+			propResourceType:         "<d:collection/><d:principal/>",
+			propCurrentUserPrincipal: hrefElement(davPrincipalPath),
+			propPrincipalURL:         hrefElement(davPrincipalPath),
+			propCalendarHomeSet:      hrefElement(davCalendarsPath),
+		},
+	}
+}
+
+func homeResource() davResource {
+	return davResource{
+		href: davCalendarsPath,
+		props: map[xml.Name]string{
+			propResourceType:         "<d:collection/>",
+			propCurrentUserPrincipal: hrefElement(davPrincipalPath),
+		},
+	}
+}
+
+func calendarResource(calendar todo.CalendarCollection) davResource {
+	token := escapeXML(formatSyncToken(calendar.SyncToken))
+	return davResource{
+		href: calendarHref(calendar.List.Id),
+		props: map[xml.Name]string{
+			propResourceType:          "<d:collection/><c:calendar/>",
+			propDisplayName:           escapeXML(calendar.List.Title),
+			propCalendarDescription:   escapeXML(calendar.List.Description),
+			propSupportedComponentSet: `<c:comp name="VTODO"/>`,
+			propMaxResourceSize:       strconv.Itoa(davMaxObjectSize),
+			propSyncToken:             token,
+			propGetCTag:               token,
+			propCurrentUserPrincipal:  hrefElement(davPrincipalPath),
+			propCurrentUserPrivileges: davPrivileges,
+			propSupportedReportSet:    davReports,
+		},
+	}
+}
+
+func objectResource(listId int, object todo.CalendarObject) davResource {
+	return davResource{
+		href: objectHref(listId, object.Name),
+		props: map[xml.Name]string{
+			propResourceType:         "",
+			propGetETag:              escapeXML(object.ETag()),
+			propGetContentType:       davObjectType,
+			propGetContentLength:     strconv.Itoa(len(object.Data)),
+			propGetLastModified:      object.Modified.UTC().Format(http.TimeFormat),
+			propCalendarData:         escapeXML(object.Data),
+			propCurrentUserPrincipal: hrefElement(davPrincipalPath),
+		},
+	}
+}
+
+func (h *Handler) davWellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, davRootPath)
+}
+
+func (h *Handler) davOptions(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) propfindRoot(c *gin.Context) {
+	resources := []davResource{rootResource()}
+	if davDepth(c) > 0 {
+		resources = append(resources, principalResource(), homeResource())
+	}
+
+	renderPropfind(c, resources)
+}
+
+func (h *Handler) propfindPrincipal(c *gin.Context) {
+	renderPropfind(c, []davResource{principalResource()})
+}
+
+func (h *Handler) propfindCalendars(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	resources := []davResource{homeResource()}
+	if davDepth(c) > 0 {
+		calendars, err := h.services.CalDAV.Calendars(c.Request.Context(), userId)
+		if err != nil {
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, calendar := range calendars {
+			resources = append(resources, calendarResource(calendar))
+		}
+	}
+
+	renderPropfind(c, resources)
+}
+
+func (h *Handler) propfindCalendar(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Invalid list id")
+		return
+	}
+
+	calendar, err := h.services.CalDAV.Calendar(c.Request.Context(), userId, listId)
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	resources := []davResource{calendarResource(calendar)}
+	if davDepth(c) > 0 {
+		objects, err := h.services.CalDAV.Objects(c.Request.Context(), userId, listId)
+		if err != nil {
+			newCalDAVErrorResponse(c, err)
+			return
+		}
+		for _, object := range objects {
+			resources = append(resources, objectResource(listId, object))
+		}
+	}
+
+	renderPropfind(c, resources)
+}
+
+func (h *Handler) propfindObject(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Invalid list id")
+		return
+	}
+
+	object, err := h.services.CalDAV.Object(c.Request.Context(), userId, listId, c.Param("name"))
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	renderPropfind(c, []davResource{objectResource(listId, object)})
+}
+
+func (h *Handler) getObject(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Invalid list id")
+		return
+	}
+
+	object, err := h.services.CalDAV.Object(c.Request.Context(), userId, listId, c.Param("name"))
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	c.Header("ETag", object.ETag())
+	c.Header("Last-Modified", object.Modified.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, davObjectType, []byte(object.Data))
+}
+
+// putObject doesn't return an ETag, as the stored task only keeps the
+// properties items have and clients have to fetch it again.
+func (h *Handler) putObject(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Invalid list id")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, davMaxObjectSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			newDAVError(c, http.StatusForbidden, conditionMaxResourceSize, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	created, err := h.services.CalDAV.PutObject(c.Request.Context(), userId, listId, c.Param("name"),
+		bytes.NewReader(data), c.GetHeader("If-Match"), c.GetHeader("If-None-Match"))
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	if created {
+		c.Header("Location", objectHref(listId, c.Param("name")))
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) deleteObject(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Invalid list id")
+		return
+	}
+
+	err = h.services.CalDAV.DeleteObject(c.Request.Context(), userId, listId, c.Param("name"), c.GetHeader("If-Match"))
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// davDepth returns the Depth header value. Infinity is handled as 1, as
+// there is nothing deeper than calendar objects.
+func davDepth(c *gin.Context) int {
+	if c.GetHeader("Depth") == "0" {
+		return 0
+	}
+	return 1
+}
+
+func renderPropfind(c *gin.Context, resources []davResource) {
+	var req propfindRequest
+	if err := decodeDAVRequest(c.Request.Body, &req); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var names []xml.Name
+	if req.AllProp == nil && req.PropName == nil {
+		names = req.Prop
+	}
+
+	var ms multistatus
+	for _, resource := range resources {
+		ms.responses = append(ms.responses, resource.propfind(names, req.PropName != nil))
+	}
+	ms.render(c)
+}
+
+func newCalDAVErrorResponse(c *gin.Context, err error) {
+	switch err.(type) {
+	case *todo.ErrNoSuchList, *todo.ErrNoSuchCalendarObject:
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case *todo.ErrPreconditionFailed:
+		newErrorResponse(c, http.StatusPreconditionFailed, err.Error())
+	case *todo.ErrInvalidTransition, *todo.ErrItemBlocked:
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case *todo.ErrInvalidCalendarData:
+		newDAVError(c, http.StatusForbidden, conditionValidCalendarData, err.Error())
+	case *todo.ErrUnsupportedCalendarComponent:
+		newDAVError(c, http.StatusForbidden, conditionSupportedComponent, err.Error())
+	case *todo.ErrInvalidSyncToken:
+		newDAVError(c, http.StatusForbidden, conditionValidSyncToken, err.Error())
+	default:
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
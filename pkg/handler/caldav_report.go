@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/ical"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) reportCalendar(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusNotFound, "Invalid list id")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(body))
+	start, err := rootElement(d)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	switch start.Name {
+	case reportCalendarQuery:
+		var req calendarQueryRequest
+		if err := d.DecodeElement(&req, &start); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		h.calendarQuery(c, userId, listId, req)
+	case reportCalendarMultiget:
+		var req calendarMultigetRequest
+		if err := d.DecodeElement(&req, &start); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		h.calendarMultiget(c, userId, listId, req)
+	case reportSyncCollection:
+		var req syncCollectionRequest
+		if err := d.DecodeElement(&req, &start); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		h.syncCollection(c, userId, listId, req)
+	default:
+		newDAVError(c, http.StatusForbidden, conditionSupportedReport, "Unsupported report")
+	}
+}
+
+func rootElement(d *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+func (h *Handler) calendarQuery(c *gin.Context, userId, listId int, req calendarQueryRequest) {
+	objects, err := h.services.CalDAV.Objects(c.Request.Context(), userId, listId)
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	var names []xml.Name
+	if req.AllProp == nil {
+		names = req.Prop
+	}
+
+	var ms multistatus
+	for _, object := range objects {
+		if req.Filter.matchCalendar(object) {
+			ms.responses = append(ms.responses, objectResource(listId, object).propfind(names, false))
+		}
+	}
+	ms.render(c)
+}
+
+func (h *Handler) calendarMultiget(c *gin.Context, userId, listId int, req calendarMultigetRequest) {
+	var names []xml.Name
+	if req.AllProp == nil {
+		names = req.Prop
+	}
+
+	var ms multistatus
+	for _, href := range req.Hrefs {
+		name, ok := objectName(listId, href)
+		if !ok {
+			ms.responses = append(ms.responses, davResponse{href: href, status: http.StatusNotFound})
+			continue
+		}
+
+		object, err := h.services.CalDAV.Object(c.Request.Context(), userId, listId, name)
+		if _, ok := err.(*todo.ErrNoSuchCalendarObject); ok {
+			ms.responses = append(ms.responses, davResponse{href: href, status: http.StatusNotFound})
+			continue
+		}
+		if err != nil {
+			newCalDAVErrorResponse(c, err)
+			return
+		}
+
+		ms.responses = append(ms.responses, objectResource(listId, object).propfind(names, false))
+	}
+	ms.render(c)
+}
+
+// syncCollection implements RFC 6578. Without a token the whole collection is
+// returned, otherwise only what changed after it.
+func (h *Handler) syncCollection(c *gin.Context, userId, listId int, req syncCollectionRequest) {
+	var since int64
+	if req.SyncToken != "" {
+		var err error
+		if since, err = parseSyncToken(req.SyncToken); err != nil {
+			newCalDAVErrorResponse(c, err)
+			return
+		}
+	}
+
+	objects, deleted, token, err := h.services.CalDAV.Changes(c.Request.Context(), userId, listId, since)
+	if err != nil {
+		newCalDAVErrorResponse(c, err)
+		return
+	}
+
+	ms := multistatus{syncToken: formatSyncToken(token)}
+	for _, object := range objects {
+		ms.responses = append(ms.responses, objectResource(listId, object).propfind(req.Prop, false))
+	}
+	// the initial sync only lists existing members
+	if req.SyncToken != "" {
+		for _, name := range deleted {
+			ms.responses = append(ms.responses, davResponse{
+				href:   objectHref(listId, name),
+				status: http.StatusNotFound,
+			})
+		}
+	}
+	ms.render(c)
+}
+
+// objectName extracts the resource name from an href of an object of the
+// list.
+func objectName(listId int, href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	name, ok := strings.CutPrefix(u.Path, calendarHref(listId))
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// matchCalendar evaluates a calendar-query filter, which starts with the
+// VCALENDAR component, against the task of an object.
+func (f compFilter) matchCalendar(object todo.CalendarObject) bool {
+	if f.Name == "" {
+		return true
+	}
+	if f.Name != "VCALENDAR" {
+		return f.IsNotDefined != nil
+	}
+	if f.IsNotDefined != nil {
+		return false
+	}
+
+	calendar, err := ical.Decode(strings.NewReader(object.Data))
+	if err != nil || len(calendar.Todos) == 0 {
+		return false
+	}
+
+	for _, filter := range f.CompFilters {
+		if !filter.matchTodo(calendar.Todos[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f compFilter) matchTodo(task ical.Todo) bool {
+	if f.Name != "VTODO" {
+		return f.IsNotDefined != nil
+	}
+	if f.IsNotDefined != nil {
+		return false
+	}
+
+	// a task without a due date overlaps any time range, RFC 4791 9.9
+	if f.TimeRange != nil && task.Due != nil && !f.TimeRange.contains(*task.Due) {
+		return false
+	}
+
+	for _, filter := range f.PropFilters {
+		if !filter.match(task) {
+			return false
+		}
+	}
+	// tasks have no subcomponents
+	for _, filter := range f.CompFilters {
+		if filter.IsNotDefined == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (f propFilter) match(task ical.Todo) bool {
+	value, defined := todoProperty(task, f.Name)
+
+	switch {
+	case f.IsNotDefined != nil:
+		return !defined
+	case !defined:
+		return false
+	case f.TimeRange != nil:
+		t, err := ical.ParseDateTime(value, nil)
+		return err == nil && f.TimeRange.contains(t)
+	case f.TextMatch != nil:
+		matches := strings.Contains(strings.ToLower(value), strings.ToLower(f.TextMatch.Value))
+		return matches != (f.TextMatch.NegateCondition == "yes")
+	}
+	return true
+}
+
+func (r timeRange) contains(t time.Time) bool {
+	if start, err := ical.ParseDateTime(r.Start, nil); err == nil && t.Before(start) {
+		return false
+	}
+	if end, err := ical.ParseDateTime(r.End, nil); err == nil && !t.Before(end) {
+		return false
+	}
+	return true
+}
+
+// todoProperty returns the value of a task property as it is rendered.
+// COMPLETED is defined for done tasks, even though its value is unknown.
+func todoProperty(task ical.Todo, name string) (string, bool) {
+	switch strings.ToUpper(name) {
+	case "UID":
+		return task.UID, true
+	case "SUMMARY":
+		return task.Summary, true
+	case "DESCRIPTION":
+		return task.Description, task.Description != ""
+	case "STATUS":
+		if task.Completed {
+			return ical.StatusCompleted, true
+		}
+		return ical.StatusNeedsAction, true
+	case "COMPLETED":
+		return "", task.Completed
+	case "DUE":
+		if task.Due == nil {
+			return "", false
+		}
+		return ical.FormatDateTime(*task.Due), true
+	case "PRIORITY":
+		return strconv.Itoa(task.Priority), task.Priority != 0
+	}
+	return "", false
+}
@@ -0,0 +1,421 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	todoical "github.com/OrIX219/todo/pkg/ical"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/emersion/go-ical"
+	"github.com/emersion/go-webdav"
+	"github.com/emersion/go-webdav/caldav"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+const davNamespaces = ` xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/"` +
+	` xmlns:c="urn:ietf:params:xml:ns:caldav"`
+
+func testCalendarObject(id int, title string, done bool) todo.CalendarObject {
+	object := todo.CalendarObject{
+		TodoItem: todo.TodoItem{Id: id, Title: title, Done: done},
+		Name:     fmt.Sprintf("item-%d.ics", id),
+		UID:      fmt.Sprintf("item-%d@todo-app", id),
+		Version:  int64(id + 10),
+		Modified: time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	todoical.Calendar{
+		ProdId: "-//test//EN",
+		Todos: []todoical.Todo{{
+			UID:       object.UID,
+			Summary:   title,
+			Completed: done,
+			Stamp:     object.Modified,
+		}},
+	}.Encode(&buf)
+	object.Data = buf.String()
+
+	return object
+}
+
+func TestHandler_caldavClient(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	open := testCalendarObject(1, "Buy milk", false)
+	done := testCalendarObject(2, "Pay rent", true)
+	calendar := todo.CalendarCollection{
+		List:      todo.TodoList{Id: 1, Title: "Home", Description: "Chores"},
+		SyncToken: 12,
+	}
+
+	auth := mock_service.NewMockAuthorization(c)
+	auth.EXPECT().Authenticate(gomock.Any(), "test", "qwerty").Return(1, nil).AnyTimes()
+
+	caldavService := mock_service.NewMockCalDAV(c)
+	caldavService.EXPECT().Calendars(gomock.Any(), 1).Return([]todo.CalendarCollection{calendar}, nil)
+	caldavService.EXPECT().Objects(gomock.Any(), 1, 1).Return([]todo.CalendarObject{open, done}, nil)
+	caldavService.EXPECT().Object(gomock.Any(), 1, 1, open.Name).Return(open, nil).Times(2)
+	caldavService.EXPECT().Object(gomock.Any(), 1, 1, "missing.ics").Return(todo.CalendarObject{},
+		&todo.ErrNoSuchCalendarObject{})
+	caldavService.EXPECT().PutObject(gomock.Any(), 1, 1, "new.ics", gomock.Any(), "", "").DoAndReturn(
+		func(ctx context.Context, userId, listId int, name string, data io.Reader, ifMatch, ifNoneMatch string) (bool, error) {
+			calendar, err := todoical.Decode(data)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "New task", calendar.Todos[0].Summary)
+			return true, nil
+		})
+	caldavService.EXPECT().DeleteObject(gomock.Any(), 1, 1, open.Name, "").Return(nil)
+
+	rateLimit := mock_service.NewMockRateLimit(c)
+	rateLimit.EXPECT().Allow(gomock.Any(), todo.RateLimitDAV, "ip:127.0.0.1").Return(todo.RateLimitStatus{
+		Allowed: true,
+	}, nil).AnyTimes()
+
+	services := &service.Service{Authorization: auth, CalDAV: caldavService, RateLimit: rateLimit}
+	handler := NewHandler(services)
+
+	server := httptest.NewServer(handler.InitRoutes())
+	defer server.Close()
+
+	httpClient := webdav.HTTPClientWithBasicAuth(server.Client(), "test", "qwerty")
+	client, err := caldav.NewClient(httpClient, server.URL+"/dav/")
+	assert.Equal(t, nil, err)
+	ctx := context.Background()
+
+	principal, err := client.FindCurrentUserPrincipal(ctx)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/dav/principal/", principal)
+
+	homeSet, err := client.FindCalendarHomeSet(ctx, principal)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/dav/calendars/", homeSet)
+
+	calendars, err := client.FindCalendars(ctx, homeSet)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []caldav.Calendar{{
+		Path:                  "/dav/calendars/1/",
+		Name:                  "Home",
+		Description:           "Chores",
+		MaxResourceSize:       davMaxObjectSize,
+		SupportedComponentSet: []string{"VTODO"},
+	}}, calendars)
+
+	objects, err := client.QueryCalendar(ctx, calendars[0].Path, &caldav.CalendarQuery{
+		CompRequest: caldav.CalendarCompRequest{Name: "VCALENDAR", AllProps: true, AllComps: true},
+		CompFilter: caldav.CompFilter{
+			Name: "VCALENDAR",
+			Comps: []caldav.CompFilter{{
+				Name: "VTODO",
+				Props: []caldav.PropFilter{{
+					Name:      "STATUS",
+					TextMatch: &caldav.TextMatch{Text: "COMPLETED", NegateCondition: true},
+				}},
+			}},
+		},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(objects))
+	assert.Equal(t, "/dav/calendars/1/item-1.ics", objects[0].Path)
+	assert.Equal(t, "11", objects[0].ETag)
+	summary, err := objects[0].Data.Children[0].Props.Text(ical.PropSummary)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "Buy milk", summary)
+
+	object, err := client.GetCalendarObject(ctx, "/dav/calendars/1/item-1.ics")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "11", object.ETag)
+
+	objects, err = client.MultiGetCalendar(ctx, calendars[0].Path, &caldav.CalendarMultiGet{
+		Paths: []string{"/dav/calendars/1/item-1.ics"},
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(objects))
+
+	task := ical.NewComponent(ical.CompToDo)
+	task.Props.SetText(ical.PropUID, "new@client")
+	task.Props.SetText(ical.PropSummary, "New task")
+	task.Props.SetDateTime(ical.PropDateTimeStamp, time.Now())
+	newCalendar := ical.NewCalendar()
+	newCalendar.Props.SetText(ical.PropVersion, "2.0")
+	newCalendar.Props.SetText(ical.PropProductID, "-//client//EN")
+	newCalendar.Children = append(newCalendar.Children, task)
+
+	created, err := client.PutCalendarObject(ctx, "/dav/calendars/1/new.ics", newCalendar)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/dav/calendars/1/new.ics", created.Path)
+
+	assert.Equal(t, nil, client.RemoveAll(ctx, "/dav/calendars/1/item-1.ics"))
+
+	// multiget reports missing objects instead of failing
+	req, _ := http.NewRequest("REPORT", server.URL+"/dav/calendars/1/", strings.NewReader(
+		`<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`+
+			`<d:prop><d:getetag/></d:prop><d:href>/dav/calendars/1/missing.ics</d:href>`+
+			`</c:calendar-multiget>`))
+	req.SetBasicAuth("test", "qwerty")
+	resp, err := http.DefaultClient.Do(req)
+	assert.Equal(t, nil, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.Equal(t, true, strings.Contains(string(body),
+		"<d:href>/dav/calendars/1/missing.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>"))
+}
+
+func TestHandler_syncCollection(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCalDAV)
+
+	object := testCalendarObject(1, "Buy milk", false)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name: "Initial",
+			inputBody: `<d:sync-collection xmlns:d="DAV:"><d:sync-token/>` +
+				`<d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`,
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().Changes(gomock.Any(), 1, 1, int64(0)).Return([]todo.CalendarObject{object},
+					[]string{"old.ics"}, int64(11), nil)
+			},
+			expectedStatus: 207,
+			expectedResponse: xml.Header + `<d:multistatus` + davNamespaces + `>` +
+				`<d:response><d:href>/dav/calendars/1/item-1.ics</d:href><d:propstat><d:prop>` +
+				`<d:getetag>&#34;11&#34;</d:getetag></d:prop><d:status>HTTP/1.1 200 OK</d:status>` +
+				`</d:propstat></d:response><d:sync-token>urn:todo-app:sync:11</d:sync-token>` +
+				`</d:multistatus>`,
+		},
+		{
+			name: "Changes",
+			inputBody: `<d:sync-collection xmlns:d="DAV:"><d:sync-token>urn:todo-app:sync:10</d:sync-token>` +
+				`<d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`,
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().Changes(gomock.Any(), 1, 1, int64(10)).Return(nil, []string{"old.ics"}, int64(12), nil)
+			},
+			expectedStatus: 207,
+			expectedResponse: xml.Header + `<d:multistatus` + davNamespaces + `>` +
+				`<d:response><d:href>/dav/calendars/1/old.ics</d:href>` +
+				`<d:status>HTTP/1.1 404 Not Found</d:status></d:response>` +
+				`<d:sync-token>urn:todo-app:sync:12</d:sync-token></d:multistatus>`,
+		},
+		{
+			name: "Invalid token",
+			inputBody: `<d:sync-collection xmlns:d="DAV:"><d:sync-token>http://other/1</d:sync-token>` +
+				`<d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`,
+			mockBehavior:     func(s *mock_service.MockCalDAV) {},
+			expectedStatus:   403,
+			expectedResponse: xml.Header + `<d:error` + davNamespaces + `><d:valid-sync-token/></d:error>`,
+		},
+		{
+			name: "No list with such id",
+			inputBody: `<d:sync-collection xmlns:d="DAV:"><d:sync-token/>` +
+				`<d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`,
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().Changes(gomock.Any(), 1, 1, int64(0)).Return(nil, nil, int64(0), &todo.ErrNoSuchList{})
+			},
+			expectedStatus:   404,
+			expectedResponse: `{"message":"No list with such id"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			caldavService := mock_service.NewMockCalDAV(c)
+			testCase.mockBehavior(caldavService)
+
+			services := &service.Service{CalDAV: caldavService}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.Handle("REPORT", "/dav/calendars/:id/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.reportCalendar)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("REPORT", "/dav/calendars/1/",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_putObject(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCalDAV)
+
+	testTable := []struct {
+		name           string
+		headers        map[string]string
+		mockBehavior   mockBehavior
+		expectedStatus int
+	}{
+		{
+			name: "Created",
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().PutObject(gomock.Any(), 1, 1, "task.ics", gomock.Any(), "", "").Return(true, nil)
+			},
+			expectedStatus: 201,
+		},
+		{
+			name:    "Updated",
+			headers: map[string]string{"If-Match": `"11"`},
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().PutObject(gomock.Any(), 1, 1, "task.ics", gomock.Any(), `"11"`, "").Return(false, nil)
+			},
+			expectedStatus: 204,
+		},
+		{
+			name:    "Precondition failed",
+			headers: map[string]string{"If-None-Match": "*"},
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().PutObject(gomock.Any(), 1, 1, "task.ics", gomock.Any(), "", "*").Return(false,
+					&todo.ErrPreconditionFailed{})
+			},
+			expectedStatus: 412,
+		},
+		{
+			name: "Not a task",
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().PutObject(gomock.Any(), 1, 1, "task.ics", gomock.Any(), "", "").Return(false,
+					&todo.ErrUnsupportedCalendarComponent{})
+			},
+			expectedStatus: 403,
+		},
+		{
+			name: "Service failure",
+			mockBehavior: func(s *mock_service.MockCalDAV) {
+				s.EXPECT().PutObject(gomock.Any(), 1, 1, "task.ics", gomock.Any(), "", "").Return(false,
+					errors.New("Service failure"))
+			},
+			expectedStatus: 500,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			caldavService := mock_service.NewMockCalDAV(c)
+			testCase.mockBehavior(caldavService)
+
+			services := &service.Service{CalDAV: caldavService}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.PUT("/dav/calendars/:id/:name", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.putObject)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/dav/calendars/1/task.ics",
+				bytes.NewBufferString("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+			for name, value := range testCase.headers {
+				req.Header.Set(name, value)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_calendarQuery(t *testing.T) {
+	objects := []todo.CalendarObject{
+		testCalendarObject(1, "Buy milk", false),
+		testCalendarObject(2, "Pay rent", true),
+	}
+
+	testTable := []struct {
+		name          string
+		inputFilter   string
+		expectedHrefs []string
+	}{
+		{
+			name:          "All tasks",
+			inputFilter:   `<c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter>`,
+			expectedHrefs: []string{"/dav/calendars/1/item-1.ics", "/dav/calendars/1/item-2.ics"},
+		},
+		{
+			name: "Not completed",
+			inputFilter: `<c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">` +
+				`<c:prop-filter name="COMPLETED"><c:is-not-defined/></c:prop-filter>` +
+				`</c:comp-filter></c:comp-filter>`,
+			expectedHrefs: []string{"/dav/calendars/1/item-1.ics"},
+		},
+		{
+			name: "Text match",
+			inputFilter: `<c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">` +
+				`<c:prop-filter name="SUMMARY"><c:text-match>RENT</c:text-match></c:prop-filter>` +
+				`</c:comp-filter></c:comp-filter>`,
+			expectedHrefs: []string{"/dav/calendars/1/item-2.ics"},
+		},
+		{
+			name:        "Events",
+			inputFilter: `<c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT"/></c:comp-filter>`,
+		},
+		{
+			name: "Time range",
+			inputFilter: `<c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO">` +
+				`<c:prop-filter name="DUE"><c:time-range start="20231001T000000Z"/></c:prop-filter>` +
+				`</c:comp-filter></c:comp-filter>`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			caldavService := mock_service.NewMockCalDAV(c)
+			caldavService.EXPECT().Objects(gomock.Any(), 1, 1).Return(objects, nil)
+
+			services := &service.Service{CalDAV: caldavService}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.Handle("REPORT", "/dav/calendars/:id/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.reportCalendar)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("REPORT", "/dav/calendars/1/", bytes.NewBufferString(
+				`<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`+
+					`<d:prop><d:getetag/></d:prop><c:filter>`+testCase.inputFilter+`</c:filter>`+
+					`</c:calendar-query>`))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusMultiStatus, w.Code)
+			assert.Equal(t, len(testCase.expectedHrefs), strings.Count(w.Body.String(), "<d:response>"))
+			for _, href := range testCase.expectedHrefs {
+				assert.Equal(t, true, strings.Contains(w.Body.String(), "<d:href>"+href+"</d:href>"))
+			}
+		})
+	}
+}
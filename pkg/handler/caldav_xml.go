@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+
+	davContentType = "application/xml; charset=utf-8"
+)
+
+// davPrefixes are declared on the root of every response, properties in other
+// namespaces declare their own.
+var davPrefixes = map[string]string{
+	nsDAV:            "d",
+	nsCalDAV:         "c",
+	nsCalendarServer: "cs",
+}
+
+var (
+	propResourceType            = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName             = xml.Name{Space: nsDAV, Local: "displayname"}
+	propCurrentUserPrincipal    = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL            = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propCurrentUserPrivileges   = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet      = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propSyncToken               = xml.Name{Space: nsDAV, Local: "sync-token"}
+	propGetETag                 = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType          = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propGetContentLength        = xml.Name{Space: nsDAV, Local: "getcontentlength"}
+	propGetLastModified         = xml.Name{Space: nsDAV, Local: "getlastmodified"}
+	propCalendarHomeSet         = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propCalendarDescription     = xml.Name{Space: nsCalDAV, Local: "calendar-description"}
+	propSupportedComponentSet   = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propMaxResourceSize         = xml.Name{Space: nsCalDAV, Local: "max-resource-size"}
+	propCalendarData            = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag                 = xml.Name{Space: nsCalendarServer, Local: "getctag"}
+	reportCalendarQuery         = xml.Name{Space: nsCalDAV, Local: "calendar-query"}
+	reportCalendarMultiget      = xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}
+	reportSyncCollection        = xml.Name{Space: nsDAV, Local: "sync-collection"}
+	conditionValidSyncToken     = xml.Name{Space: nsDAV, Local: "valid-sync-token"}
+	conditionSupportedReport    = xml.Name{Space: nsDAV, Local: "supported-report"}
+	conditionValidCalendarData  = xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"}
+	conditionSupportedComponent = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component"}
+	conditionMaxResourceSize    = xml.Name{Space: nsCalDAV, Local: "max-resource-size"}
+)
+
+// propNames collects the names of the requested properties, ignoring their
+// content, e.g. the component selection of calendar-data.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+type calendarQueryRequest struct {
+	XMLName xml.Name   `xml:"urn:ietf:params:xml:ns:caldav calendar-query"`
+	AllProp *struct{}  `xml:"DAV: allprop"`
+	Prop    propNames  `xml:"DAV: prop"`
+	Filter  compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+type calendarMultigetRequest struct {
+	XMLName xml.Name  `xml:"urn:ietf:params:xml:ns:caldav calendar-multiget"`
+	AllProp *struct{} `xml:"DAV: allprop"`
+	Prop    propNames `xml:"DAV: prop"`
+	Hrefs   []string  `xml:"DAV: href"`
+}
+
+type syncCollectionRequest struct {
+	XMLName   xml.Name  `xml:"DAV: sync-collection"`
+	SyncToken string    `xml:"DAV: sync-token"`
+	SyncLevel string    `xml:"DAV: sync-level"`
+	Prop      propNames `xml:"DAV: prop"`
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type textMatch struct {
+	Value           string `xml:",chardata"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+// davProp is a property with its value already encoded as XML.
+type davProp struct {
+	name  xml.Name
+	value string
+}
+
+type davResponse struct {
+	href    string
+	status  int
+	found   []davProp
+	missing []xml.Name
+}
+
+type multistatus struct {
+	responses []davResponse
+	syncToken string
+}
+
+// davResource is something a PROPFIND can be made on.
+type davResource struct {
+	href  string
+	props map[xml.Name]string
+}
+
+// propfind selects the requested properties of the resource. With no names
+// all properties but calendar-data are returned.
+func (r davResource) propfind(names []xml.Name, namesOnly bool) davResponse {
+	resp := davResponse{href: r.href}
+
+	if len(names) == 0 {
+		for name, value := range r.props {
+			if name == propCalendarData {
+				continue
+			}
+			if namesOnly {
+				value = ""
+			}
+			resp.found = append(resp.found, davProp{name: name, value: value})
+		}
+		sort.Slice(resp.found, func(i, j int) bool {
+			return resp.found[i].name.Local < resp.found[j].name.Local
+		})
+		return resp
+	}
+
+	for _, name := range names {
+		if value, ok := r.props[name]; ok {
+			resp.found = append(resp.found, davProp{name: name, value: value})
+		} else {
+			resp.missing = append(resp.missing, name)
+		}
+	}
+	return resp
+}
+
+func (m multistatus) render(c *gin.Context) {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString("<d:multistatus")
+	writeNamespaces(&sb)
+	sb.WriteString(">")
+
+	for _, resp := range m.responses {
+		sb.WriteString("<d:response>")
+		writeElement(&sb, xml.Name{Space: nsDAV, Local: "href"}, escapeXML(resp.href))
+		if resp.status != 0 {
+			writeElement(&sb, xml.Name{Space: nsDAV, Local: "status"}, statusLine(resp.status))
+		}
+		if len(resp.found) > 0 {
+			sb.WriteString("<d:propstat><d:prop>")
+			for _, prop := range resp.found {
+				writeElement(&sb, prop.name, prop.value)
+			}
+			sb.WriteString("</d:prop>")
+			writeElement(&sb, xml.Name{Space: nsDAV, Local: "status"}, statusLine(http.StatusOK))
+			sb.WriteString("</d:propstat>")
+		}
+		if len(resp.missing) > 0 {
+			sb.WriteString("<d:propstat><d:prop>")
+			for _, name := range resp.missing {
+				writeElement(&sb, name, "")
+			}
+			sb.WriteString("</d:prop>")
+			writeElement(&sb, xml.Name{Space: nsDAV, Local: "status"}, statusLine(http.StatusNotFound))
+			sb.WriteString("</d:propstat>")
+		}
+		sb.WriteString("</d:response>")
+	}
+
+	if m.syncToken != "" {
+		writeElement(&sb, propSyncToken, escapeXML(m.syncToken))
+	}
+	sb.WriteString("</d:multistatus>")
+
+	c.Data(http.StatusMultiStatus, davContentType, []byte(sb.String()))
+}
+
+// newDAVError aborts the request with a failed precondition as described in
+// RFC 4918 section 16.
+func newDAVError(c *gin.Context, statusCode int, condition xml.Name, message string) {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString("<d:error")
+	writeNamespaces(&sb)
+	sb.WriteString(">")
+	writeElement(&sb, condition, "")
+	sb.WriteString("</d:error>")
+
+	logError(c, statusCode, message)
+	c.Data(statusCode, davContentType, []byte(sb.String()))
+	c.Abort()
+}
+
+func writeNamespaces(sb *strings.Builder) {
+	spaces := make([]string, 0, len(davPrefixes))
+	for space := range davPrefixes {
+		spaces = append(spaces, space)
+	}
+	sort.Strings(spaces)
+
+	for _, space := range spaces {
+		fmt.Fprintf(sb, ` xmlns:%s="%s"`, davPrefixes[space], space)
+	}
+}
+
+// writeElement writes an element with the given XML content. Elements from
+// namespaces without a prefix get a default namespace declaration.
+func writeElement(sb *strings.Builder, name xml.Name, content string) {
+	tag, attrs := name.Local, ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else {
+		attrs = fmt.Sprintf(` xmlns="%s"`, escapeXML(name.Space))
+	}
+
+	if content == "" {
+		fmt.Fprintf(sb, "<%s%s/>", tag, attrs)
+		return
+	}
+	fmt.Fprintf(sb, "<%s%s>%s</%s>", tag, attrs, content, tag)
+}
+
+func hrefElement(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+func escapeXML(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// decodeDAVRequest decodes the body into v. An empty body leaves v untouched.
+func decodeDAVRequest(body io.Reader, v any) error {
+	err := xml.NewDecoder(body).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
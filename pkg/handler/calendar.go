@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/ical"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	feedPath      = "/feeds/"
+	feedExtension = ".ics"
+)
+
+func renderCalendar(c *gin.Context, calendar ical.Calendar) {
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, ical.ContentType, buf.Bytes())
+}
+
+func (h *Handler) getListCalendar(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid list id")
+		return
+	}
+
+	calendar, err := h.services.Calendar.ListCalendar(c.Request.Context(), userId, listId)
+	if err != nil {
+		var status int
+		switch err.(type) {
+		case *todo.ErrNoSuchList:
+			status = http.StatusOK
+		default:
+			status = http.StatusInternalServerError
+		}
+		newErrorResponse(c, status, err.Error())
+		return
+	}
+
+	renderCalendar(c, calendar)
+}
+
+// getFeed serves the calendar behind the secret url without a bearer token,
+// so calendar apps can subscribe to it.
+func (h *Handler) getFeed(c *gin.Context) {
+	file := c.Param("file")
+	if !strings.HasSuffix(file, feedExtension) {
+		newErrorResponse(c, http.StatusNotFound, "No such calendar feed")
+		return
+	}
+
+	calendar, err := h.services.Calendar.FeedCalendar(c.Request.Context(), strings.TrimSuffix(file, feedExtension))
+	if err != nil {
+		var status int
+		switch err.(type) {
+		case *todo.ErrNoSuchFeed:
+			status = http.StatusNotFound
+		default:
+			status = http.StatusInternalServerError
+		}
+		newErrorResponse(c, status, err.Error())
+		return
+	}
+
+	renderCalendar(c, calendar)
+}
+
+type feedResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+func (h *Handler) getFeedToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	token, err := h.services.Calendar.FeedToken(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, feedResponse{
+		Token: token,
+		URL:   feedPath + token + feedExtension,
+	})
+}
+
+func (h *Handler) rotateFeedToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	token, err := h.services.Calendar.RotateFeedToken(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, feedResponse{
+		Token: token,
+		URL:   feedPath + token + feedExtension,
+	})
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/ical"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_getListCalendar(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCalendar, listId int)
+
+	stamp := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	testTable := []struct {
+		name             string
+		inputListId      any
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:        "OK",
+			inputListId: 1,
+			mockBehavior: func(s *mock_service.MockCalendar, listId int) {
+				s.EXPECT().ListCalendar(gomock.Any(), 1, listId).Return(ical.Calendar{
+					ProdId: "-//test//EN",
+					Name:   "Test",
+					Todos: []ical.Todo{
+						{UID: "item-1@todo-app", Summary: "Test", Stamp: stamp},
+					},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+				"CALSCALE:GREGORIAN\r\nX-WR-CALNAME:Test\r\nBEGIN:VTODO\r\n" +
+				"UID:item-1@todo-app\r\nDTSTAMP:20231001T120000Z\r\nSUMMARY:Test\r\n" +
+				"STATUS:NEEDS-ACTION\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+		},
+		{
+			name:             "Invalid id",
+			inputListId:      "asd",
+			mockBehavior:     func(s *mock_service.MockCalendar, listId int) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid list id"}`,
+		},
+		{
+			name:        "No list with such id",
+			inputListId: 10,
+			mockBehavior: func(s *mock_service.MockCalendar, listId int) {
+				s.EXPECT().ListCalendar(gomock.Any(), 1, listId).Return(ical.Calendar{},
+					&todo.ErrNoSuchList{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No list with such id"}`,
+		},
+		{
+			name:        "Service failure",
+			inputListId: 1,
+			mockBehavior: func(s *mock_service.MockCalendar, listId int) {
+				s.EXPECT().ListCalendar(gomock.Any(), 1, listId).Return(ical.Calendar{},
+					errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			calendar := mock_service.NewMockCalendar(c)
+			if listId, ok := testCase.inputListId.(int); ok {
+				testCase.mockBehavior(calendar, listId)
+			}
+
+			services := &service.Service{Calendar: calendar}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/lists/:id/calendar.ics", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getListCalendar)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET",
+				fmt.Sprintf("/api/lists/%v/calendar.ics", testCase.inputListId), nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getFeed(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCalendar, token string)
+
+	testTable := []struct {
+		name             string
+		inputFile        string
+		token            string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputFile: "token.ics",
+			token:     "token",
+			mockBehavior: func(s *mock_service.MockCalendar, token string) {
+				s.EXPECT().FeedCalendar(gomock.Any(), token).Return(ical.Calendar{
+					ProdId: "-//test//EN",
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n" +
+				"CALSCALE:GREGORIAN\r\nEND:VCALENDAR\r\n",
+		},
+		{
+			name:             "No extension",
+			inputFile:        "token",
+			mockBehavior:     func(s *mock_service.MockCalendar, token string) {},
+			expectedStatus:   404,
+			expectedResponse: `{"message":"No such calendar feed"}`,
+		},
+		{
+			name:      "Unknown token",
+			inputFile: "token.ics",
+			token:     "token",
+			mockBehavior: func(s *mock_service.MockCalendar, token string) {
+				s.EXPECT().FeedCalendar(gomock.Any(), token).Return(ical.Calendar{}, &todo.ErrNoSuchFeed{})
+			},
+			expectedStatus:   404,
+			expectedResponse: `{"message":"No such calendar feed"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			calendar := mock_service.NewMockCalendar(c)
+			testCase.mockBehavior(calendar, testCase.token)
+
+			services := &service.Service{Calendar: calendar}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/feeds/:file", handler.getFeed)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/feeds/"+testCase.inputFile, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
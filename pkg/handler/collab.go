@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	socketWriteWait      = 10 * time.Second
+	socketPongWait       = 60 * time.Second
+	socketPingPeriod     = socketPongWait * 9 / 10
+	socketMaxMessageSize = 4096
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	Subprotocols:    []string{socketTokenParam},
+}
+
+type socketInput struct {
+	Type    string `json:"type"`
+	ItemId  int    `json:"item_id"`
+	Editing bool   `json:"editing"`
+}
+
+func (h *Handler) listSocket(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid list id")
+		return
+	}
+
+	client, err := h.services.Collab.Join(c.Request.Context(), userId, listId)
+	if err != nil {
+		var status int
+		switch err.(type) {
+		case *todo.ErrNoSuchList:
+			status = http.StatusOK
+		default:
+			status = http.StatusInternalServerError
+		}
+		newErrorResponse(c, status, err.Error())
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.services.Collab.Leave(client)
+		return
+	}
+
+	go writeSocket(conn, client)
+	h.readSocket(conn, client)
+}
+
+func (h *Handler) readSocket(conn *websocket.Conn, client *todo.CollabClient) {
+	defer func() {
+		conn.Close()
+		h.services.Collab.Leave(client)
+	}()
+
+	conn.SetReadLimit(socketMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var input socketInput
+		if err := json.Unmarshal(data, &input); err != nil {
+			continue
+		}
+
+		if input.Type == todo.CollabEditing && input.ItemId > 0 {
+			h.services.Collab.SetEditing(client, input.ItemId, input.Editing)
+		}
+	}
+}
+
+func writeSocket(conn *websocket.Conn, client *todo.CollabClient) {
+	ping := time.NewTicker(socketPingPeriod)
+	defer func() {
+		ping.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if !ok {
+				// the hub dropped the client, most likely for being too slow
+				closeMessage := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Too slow")
+				if client.GoingAway {
+					closeMessage = websocket.FormatCloseMessage(websocket.CloseGoingAway, "Shutting down")
+				}
+				conn.WriteMessage(websocket.CloseMessage, closeMessage)
+				return
+			}
+			if err := conn.WriteJSON(message); err != nil {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
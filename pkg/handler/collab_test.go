@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_listSocket(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockCollab, listId int)
+
+	testTable := []struct {
+		name             string
+		inputListId      any
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:        "Not a handshake",
+			inputListId: 1,
+			mockBehavior: func(s *mock_service.MockCollab, listId int) {
+				client := &todo.CollabClient{ListId: listId, UserId: 1}
+				s.EXPECT().Join(gomock.Any(), 1, listId).Return(client, nil)
+				s.EXPECT().Leave(client)
+			},
+			expectedStatus:   400,
+			expectedResponse: "Bad Request\n",
+		},
+		{
+			name:             "Invalid id",
+			inputListId:      "asd",
+			mockBehavior:     func(s *mock_service.MockCollab, listId int) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid list id"}`,
+		},
+		{
+			name:        "No list with such id",
+			inputListId: 10,
+			mockBehavior: func(s *mock_service.MockCollab, listId int) {
+				s.EXPECT().Join(gomock.Any(), 1, listId).Return(nil, &todo.ErrNoSuchList{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No list with such id"}`,
+		},
+		{
+			name:        "Service failure",
+			inputListId: 1,
+			mockBehavior: func(s *mock_service.MockCollab, listId int) {
+				s.EXPECT().Join(gomock.Any(), 1, listId).Return(nil, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			collab := mock_service.NewMockCollab(c)
+			if listId, ok := testCase.inputListId.(int); ok {
+				testCase.mockBehavior(collab, listId)
+			}
+
+			services := &service.Service{Collab: collab}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/lists/:id/ws", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.listSocket)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET",
+				fmt.Sprintf("/api/lists/%v/ws", testCase.inputListId), nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
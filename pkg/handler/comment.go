@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func commentErrorStatus(err error) int {
+	switch err.(type) {
+	case *todo.ErrNoSuchItem, *todo.ErrNoSuchComment:
+		return http.StatusOK
+	case *todo.ErrInvalidCommentInput:
+		return http.StatusBadRequest
+	case *todo.ErrCommentForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) createComment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	var input todo.CommentInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.services.Comment.Create(c.Request.Context(), userId, itemId, input)
+	if err != nil {
+		newErrorResponse(c, commentErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// getAllComments returns the comments on the item oldest first, a page of
+// limit comments at a time. The next page starts after the id in next.
+func (h *Handler) getAllComments(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	after, err := strconv.Atoi(c.DefaultQuery("after", "0"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid after")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.services.Comment.GetAll(c.Request.Context(), userId, itemId, after, limit)
+	if err != nil {
+		newErrorResponse(c, commentErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) updateComment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	commentId, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid comment id")
+		return
+	}
+
+	var input todo.CommentInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.services.Comment.Update(c.Request.Context(), userId, itemId, commentId, input)
+	if err != nil {
+		newErrorResponse(c, commentErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *Handler) deleteComment(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	commentId, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid comment id")
+		return
+	}
+
+	if err := h.services.Comment.Delete(c.Request.Context(), userId, itemId, commentId); err != nil {
+		newErrorResponse(c, commentErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+var testComment = todo.Comment{
+	Id:        3,
+	ItemId:    1,
+	Author:    todo.CommentAuthor{Id: 1, Name: "Alice", Username: "alice"},
+	Body:      "Ask @bob",
+	Mentions:  todo.Usernames{"bob"},
+	CreatedAt: time.Date(2023, 12, 4, 10, 0, 0, 0, time.UTC),
+}
+
+const testCommentJSON = `{"id":3,"item_id":1,"author":{"id":1,"name":"Alice","username":"alice"},` +
+	`"body":"Ask @bob","mentions":["bob"],"created_at":"2023-12-04T10:00:00Z"}`
+
+func TestHandler_createComment(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockComment, input todo.CommentInput)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		inputComment     todo.CommentInput
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:         "OK",
+			inputBody:    `{"body":"Ask @bob"}`,
+			inputComment: todo.CommentInput{Body: "Ask @bob"},
+			mockBehavior: func(s *mock_service.MockComment, input todo.CommentInput) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, input).Return(testComment, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: testCommentJSON,
+		},
+		{
+			name:             "No body",
+			inputBody:        `{}`,
+			mockBehavior:     func(s *mock_service.MockComment, input todo.CommentInput) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:         "No such item",
+			inputBody:    `{"body":"Ask @bob"}`,
+			inputComment: todo.CommentInput{Body: "Ask @bob"},
+			mockBehavior: func(s *mock_service.MockComment, input todo.CommentInput) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, input).Return(todo.Comment{}, &todo.ErrNoSuchItem{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No item with such id"}`,
+		},
+		{
+			name:         "Service failure",
+			inputBody:    `{"body":"Ask @bob"}`,
+			inputComment: todo.CommentInput{Body: "Ask @bob"},
+			mockBehavior: func(s *mock_service.MockComment, input todo.CommentInput) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, input).Return(todo.Comment{}, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			comment := mock_service.NewMockComment(c)
+			testCase.mockBehavior(comment, testCase.inputComment)
+
+			services := &service.Service{Comment: comment}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/items/:id/comments", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.createComment)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/items/1/comments",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
+
+func TestHandler_getAllComments(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockComment)
+
+	testTable := []struct {
+		name             string
+		query            string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:  "OK",
+			query: "?limit=1",
+			mockBehavior: func(s *mock_service.MockComment) {
+				s.EXPECT().GetAll(gomock.Any(), 1, 1, 0, 1).Return(todo.CommentsPage{
+					Data: []todo.Comment{testComment},
+					Next: 3,
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"data":[` + testCommentJSON + `],"next":3}`,
+		},
+		{
+			name:  "Last page",
+			query: "?after=3",
+			mockBehavior: func(s *mock_service.MockComment) {
+				s.EXPECT().GetAll(gomock.Any(), 1, 1, 3, 0).Return(todo.CommentsPage{Data: []todo.Comment{}}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"data":[]}`,
+		},
+		{
+			name:             "Invalid limit",
+			query:            "?limit=all",
+			mockBehavior:     func(s *mock_service.MockComment) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid limit"}`,
+		},
+		{
+			name:  "Invalid page",
+			query: "?limit=1000",
+			mockBehavior: func(s *mock_service.MockComment) {
+				s.EXPECT().GetAll(gomock.Any(), 1, 1, 0, 1000).Return(todo.CommentsPage{},
+					&todo.ErrInvalidCommentInput{Reason: "invalid page"})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid comment input: invalid page"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			comment := mock_service.NewMockComment(c)
+			testCase.mockBehavior(comment)
+
+			services := &service.Service{Comment: comment}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/items/:id/comments", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getAllComments)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/items/1/comments"+testCase.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
+
+func TestHandler_deleteComment(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockComment)
+
+	testTable := []struct {
+		name             string
+		commentId        string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			commentId: "3",
+			mockBehavior: func(s *mock_service.MockComment) {
+				s.EXPECT().Delete(gomock.Any(), 1, 1, 3).Return(nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"status":"ok"}`,
+		},
+		{
+			name:      "Someone else's comment",
+			commentId: "3",
+			mockBehavior: func(s *mock_service.MockComment) {
+				s.EXPECT().Delete(gomock.Any(), 1, 1, 3).Return(&todo.ErrCommentForbidden{
+					Reason: "Only the author or the owner of the list can delete the comment",
+				})
+			},
+			expectedStatus:   403,
+			expectedResponse: `{"message":"Only the author or the owner of the list can delete the comment"}`,
+		},
+		{
+			name:      "No such comment",
+			commentId: "4",
+			mockBehavior: func(s *mock_service.MockComment) {
+				s.EXPECT().Delete(gomock.Any(), 1, 1, 4).Return(&todo.ErrNoSuchComment{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No comment with such id"}`,
+		},
+		{
+			name:             "Invalid comment id",
+			commentId:        "a",
+			mockBehavior:     func(s *mock_service.MockComment) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid comment id"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			comment := mock_service.NewMockComment(c)
+			testCase.mockBehavior(comment)
+
+			services := &service.Service{Comment: comment}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.DELETE("/api/items/:id/comments/:comment_id", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.deleteComment)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/items/1/comments/"+testCase.commentId, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
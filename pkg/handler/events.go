@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	lastEventIdHeader       = "Last-Event-ID"
+	eventsHeartbeatInterval = 15 * time.Second
+)
+
+func (h *Handler) streamEvents(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var lastEventId int64
+	if header := c.GetHeader(lastEventIdHeader); header != "" {
+		lastEventId, err = strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid last event id")
+			return
+		}
+	}
+
+	sub, backlog, ok := h.services.Events.Subscribe(userId, lastEventId)
+	defer h.services.Events.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// the server write timeout would otherwise cut the stream off
+	controller := http.NewResponseController(c.Writer)
+	extendDeadline := func() {
+		controller.SetWriteDeadline(time.Now().Add(2 * eventsHeartbeatInterval))
+	}
+	extendDeadline()
+
+	if !ok {
+		// some events are lost, the client has to refetch its state
+		c.Render(-1, sse.Event{Event: "reset", Data: "{}"})
+	}
+	for _, event := range backlog {
+		renderEvent(c, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			extendDeadline()
+			renderEvent(c, event)
+		case <-heartbeat.C:
+			extendDeadline()
+			c.Writer.WriteString(": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func renderEvent(c *gin.Context, event todo.Event) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.Id, 10),
+		Event: event.Type,
+		Data:  event,
+	})
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_streamEvents(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockEvents, lastEventId int64)
+
+	testTable := []struct {
+		name             string
+		headerValue      string
+		lastEventId      int64
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockEvents, lastEventId int64) {
+				sub := &todo.EventSubscription{UserId: 1, Events: make(chan todo.Event, 1)}
+				sub.Events <- todo.Event{Id: 2, Type: "item.deleted", ListId: 1, ItemId: 3, ActorId: 1}
+				close(sub.Events)
+				s.EXPECT().Subscribe(1, lastEventId).Return(sub, nil, true)
+				s.EXPECT().Unsubscribe(sub)
+			},
+			expectedStatus:   200,
+			expectedResponse: "id:2\nevent:item.deleted\ndata:{\"id\":2,\"type\":\"item.deleted\",\"list_id\":1,\"item_id\":3,\"actor_id\":1}\n\n",
+		},
+		{
+			name:        "Resume",
+			headerValue: "1",
+			lastEventId: 1,
+			mockBehavior: func(s *mock_service.MockEvents, lastEventId int64) {
+				sub := &todo.EventSubscription{UserId: 1, Events: make(chan todo.Event)}
+				close(sub.Events)
+				s.EXPECT().Subscribe(1, lastEventId).Return(sub, []todo.Event{
+					{Id: 2, Type: "list.updated", ListId: 1, ActorId: 2},
+				}, true)
+				s.EXPECT().Unsubscribe(sub)
+			},
+			expectedStatus:   200,
+			expectedResponse: "id:2\nevent:list.updated\ndata:{\"id\":2,\"type\":\"list.updated\",\"list_id\":1,\"actor_id\":2}\n\n",
+		},
+		{
+			name:        "Resume from evicted event",
+			headerValue: "1",
+			lastEventId: 1,
+			mockBehavior: func(s *mock_service.MockEvents, lastEventId int64) {
+				sub := &todo.EventSubscription{UserId: 1, Events: make(chan todo.Event)}
+				close(sub.Events)
+				s.EXPECT().Subscribe(1, lastEventId).Return(sub, []todo.Event{}, false)
+				s.EXPECT().Unsubscribe(sub)
+			},
+			expectedStatus:   200,
+			expectedResponse: "event:reset\ndata:{}\n\n",
+		},
+		{
+			name:             "Invalid last event id",
+			headerValue:      "asd",
+			mockBehavior:     func(s *mock_service.MockEvents, lastEventId int64) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid last event id"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			events := mock_service.NewMockEvents(c)
+			testCase.mockBehavior(events, testCase.lastEventId)
+
+			services := &service.Service{Events: events}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/events", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.streamEvents)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/events", nil)
+			if testCase.headerValue != "" {
+				req.Header.Set("Last-Event-ID", testCase.headerValue)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func filterErrorStatus(err error) int {
+	switch err.(type) {
+	case *todo.ErrNoSuchFilter:
+		return http.StatusOK
+	case *todo.ErrInvalidFilterInput:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) createFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input todo.FilterInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	filter, err := h.services.Filter.Create(c.Request.Context(), userId, input)
+	if err != nil {
+		newErrorResponse(c, filterErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+type getAllFiltersResponse struct {
+	Data []todo.Filter `json:"data"`
+}
+
+func (h *Handler) getAllFilters(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	filters, err := h.services.Filter.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllFiltersResponse{
+		Data: filters,
+	})
+}
+
+func (h *Handler) getFilterById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid filter id")
+		return
+	}
+
+	filter, err := h.services.Filter.GetById(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, filterErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, filter)
+}
+
+func (h *Handler) updateFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid filter id")
+		return
+	}
+
+	var input todo.UpdateFilterInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err = h.services.Filter.Update(c.Request.Context(), userId, id, input)
+	if err != nil {
+		newErrorResponse(c, filterErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) deleteFilter(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid filter id")
+		return
+	}
+
+	err = h.services.Filter.Delete(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) getFilterItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid filter id")
+		return
+	}
+
+	items, err := h.services.Filter.GetItems(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, filterErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllItemsResponse{
+		Data: items,
+	})
+}
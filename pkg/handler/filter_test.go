@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_createFilter(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockFilter, input todo.FilterInput)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		inputFilter      todo.FilterInput
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"name":"Work","query":"done:false AND tag:work"}`,
+			inputFilter: todo.FilterInput{
+				Name:  "Work",
+				Query: "done:false AND tag:work",
+			},
+			mockBehavior: func(s *mock_service.MockFilter, input todo.FilterInput) {
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(todo.Filter{
+					Id:    1,
+					Name:  input.Name,
+					Query: input.Query,
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1,"name":"Work","query":"done:false AND tag:work","created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:             "No query",
+			inputBody:        `{"name":"Work"}`,
+			mockBehavior:     func(s *mock_service.MockFilter, input todo.FilterInput) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Syntax error",
+			inputBody: `{"name":"Work","query":"tag:work priority:high"}`,
+			inputFilter: todo.FilterInput{
+				Name:  "Work",
+				Query: "tag:work priority:high",
+			},
+			mockBehavior: func(s *mock_service.MockFilter, input todo.FilterInput) {
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(todo.Filter{}, &todo.ErrInvalidFilterInput{
+					Reason: `query at column 10: expected AND or OR before "priority"`,
+				})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid filter input: query at column 10: expected AND or OR before \"priority\""}`,
+		},
+		{
+			name:      "Service failure",
+			inputBody: `{"name":"Work","query":"tag:work"}`,
+			inputFilter: todo.FilterInput{
+				Name:  "Work",
+				Query: "tag:work",
+			},
+			mockBehavior: func(s *mock_service.MockFilter, input todo.FilterInput) {
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(todo.Filter{},
+					errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			filter := mock_service.NewMockFilter(c)
+			testCase.mockBehavior(filter, testCase.inputFilter)
+
+			services := &service.Service{Filter: filter}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/filters/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.createFilter)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/filters/",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
+
+func TestHandler_getFilterItems(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockFilter, filterId int)
+
+	testTable := []struct {
+		name             string
+		inputFilterId    any
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:          "OK",
+			inputFilterId: 1,
+			mockBehavior: func(s *mock_service.MockFilter, filterId int) {
+				s.EXPECT().GetItems(gomock.Any(), 1, filterId).Return([]todo.TodoItem{
+					{Id: 2, Title: "Report", Tags: todo.Tags{"work"}},
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"data":[{"id":2,"title":"Report","description":"","done":false,"tags":["work"]}]}`,
+		},
+		{
+			name:          "No items",
+			inputFilterId: 1,
+			mockBehavior: func(s *mock_service.MockFilter, filterId int) {
+				s.EXPECT().GetItems(gomock.Any(), 1, filterId).Return([]todo.TodoItem{}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"data":[]}`,
+		},
+		{
+			name:             "Invalid id",
+			inputFilterId:    "asd",
+			mockBehavior:     func(s *mock_service.MockFilter, filterId int) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid filter id"}`,
+		},
+		{
+			name:          "No filter with such id",
+			inputFilterId: 10,
+			mockBehavior: func(s *mock_service.MockFilter, filterId int) {
+				s.EXPECT().GetItems(gomock.Any(), 1, filterId).Return(nil, &todo.ErrNoSuchFilter{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No filter with such id"}`,
+		},
+		{
+			name:          "Service failure",
+			inputFilterId: 1,
+			mockBehavior: func(s *mock_service.MockFilter, filterId int) {
+				s.EXPECT().GetItems(gomock.Any(), 1, filterId).Return(nil, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			filter := mock_service.NewMockFilter(c)
+			if filterId, ok := testCase.inputFilterId.(int); ok {
+				testCase.mockBehavior(filter, filterId)
+			}
+
+			services := &service.Service{Filter: filter}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/filters/:id/items", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getFilterItems)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET",
+				fmt.Sprintf("/api/filters/%v/items", testCase.inputFilterId), nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"log/slog"
+
+	"github.com/OrIX219/todo/pkg"
 	"github.com/OrIX219/todo/pkg/service"
 	"github.com/gin-gonic/gin"
 )
@@ -15,15 +18,53 @@ func NewHandler(services *service.Service) *Handler {
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		slog.Warn("Invalid TRUSTED_PROXIES, trusting none", "error", err)
+		router.SetTrustedProxies(nil)
+	}
+	router.TrustedPlatform = clientIPHeader()
+	router.Use(requestTracing, requestLogger, requestMetrics)
+
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+	router.GET("/version", h.version)
 
-	auth := router.Group("/auth")
+	auth := router.Group("/auth", h.rateLimitByIP(todo.RateLimitAuth))
 	{
-		auth.POST("/sign-up", h.signUp)
+		auth.POST("/sign-up", h.idempotency, h.signUp)
 		auth.POST("sign-in", h.signIn)
+		auth.POST("/2fa", h.verifyTwoFactor)
 	}
 
-	api := router.Group("/api", h.userIdentity)
+	router.GET("/api/lists/:id/ws", h.rateLimitByIP(todo.RateLimitAPI), h.socketIdentity, h.listSocket)
+	router.GET("/feeds/:file", h.getFeed)
+
+	router.GET("/.well-known/caldav", h.davWellKnown)
+	router.Handle("PROPFIND", "/.well-known/caldav", h.davWellKnown)
+	router.OPTIONS("/dav/*path", h.davOptions)
+
+	dav := router.Group("/dav", h.rateLimitByIP(todo.RateLimitDAV), h.basicIdentity)
 	{
+		dav.Handle("PROPFIND", "/", h.propfindRoot)
+		dav.Handle("PROPFIND", "/principal/", h.propfindPrincipal)
+		dav.Handle("PROPFIND", "/calendars/", h.propfindCalendars)
+		dav.Handle("PROPFIND", "/calendars/:id/", h.propfindCalendar)
+		dav.Handle("REPORT", "/calendars/:id/", h.reportCalendar)
+		dav.Handle("PROPFIND", "/calendars/:id/:name", h.propfindObject)
+		dav.GET("/calendars/:id/:name", h.getObject)
+		dav.HEAD("/calendars/:id/:name", h.getObject)
+		dav.PUT("/calendars/:id/:name", h.putObject)
+		dav.DELETE("/calendars/:id/:name", h.deleteObject)
+	}
+
+	api := router.Group("/api", h.userIdentity, h.rateLimitByUser(todo.RateLimitAPI), h.idempotency)
+	{
+		api.GET("/events", h.streamEvents)
+		api.GET("/export", h.exportLists)
+		api.POST("/import", h.importLists)
+		api.POST("/import/:source", h.importLists)
+		api.POST("/quick-add", h.quickAdd)
+
 		lists := api.Group("/lists")
 		{
 			lists.POST("/", h.createList)
@@ -31,6 +72,10 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			lists.GET("/:id", h.getListById)
 			lists.PUT("/:id", h.updateList)
 			lists.DELETE("/:id", h.deleteList)
+			lists.GET("/:id/calendar.ics", h.getListCalendar)
+			lists.GET("/:id/statuses", h.getListStatuses)
+			lists.PUT("/:id/statuses", h.setListStatuses)
+			lists.GET("/:id/board", h.getListBoard)
 
 			items := lists.Group(":id/items")
 			{
@@ -41,10 +86,55 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 		items := api.Group("/items")
 		{
+			items.POST("/bulk", h.bulkItems)
 			items.GET("/:id", h.getItemById)
+			items.GET("/:id/dependencies", h.getItemDependencies)
+			items.POST("/:id/comments", h.createComment)
+			items.GET("/:id/comments", h.getAllComments)
+			items.PUT("/:id/comments/:comment_id", h.updateComment)
+			items.DELETE("/:id/comments/:comment_id", h.deleteComment)
+			items.POST("/:id/attachments", h.createAttachment)
+			items.GET("/:id/attachments", h.getAllAttachments)
+			items.GET("/:id/attachments/:attachment_id", h.getAttachment)
+			items.DELETE("/:id/attachments/:attachment_id", h.deleteAttachment)
 			items.PUT("/:id", h.updateItem)
 			items.DELETE("/:id", h.deleteItem)
 		}
+
+		calendar := api.Group("/calendar")
+		{
+			calendar.GET("/feed", h.getFeedToken)
+			calendar.POST("/feed", h.rotateFeedToken)
+		}
+
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("/", h.createWebhook)
+			webhooks.GET("/", h.getAllWebhooks)
+			webhooks.GET("/:id", h.getWebhookById)
+			webhooks.PUT("/:id", h.updateWebhook)
+			webhooks.DELETE("/:id", h.deleteWebhook)
+			webhooks.GET("/:id/deliveries", h.getWebhookDeliveries)
+			webhooks.POST("/:id/test", h.testWebhook)
+		}
+
+		account := api.Group("/account")
+		{
+			account.GET("/sessions", h.getSessions)
+			account.POST("/2fa", h.enrollTwoFactor)
+			account.POST("/2fa/confirm", h.confirmTwoFactor)
+			account.DELETE("/2fa", h.disableTwoFactor)
+		}
+
+		filters := api.Group("/filters")
+		{
+			filters.POST("/", h.createFilter)
+			filters.GET("/", h.getAllFilters)
+			filters.GET("/:id", h.getFilterById)
+			filters.PUT("/:id", h.updateFilter)
+			filters.DELETE("/:id", h.deleteFilter)
+			filters.GET("/:id/items", h.getFilterItems)
+		}
 	}
 
 	return router
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func healthErrorStatus(err error) int {
+	switch err.(type) {
+	case *todo.ErrNotReady:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// healthz answers as long as the process can serve requests at all.
+func (h *Handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// readyz tells load balancers whether to send requests here.
+func (h *Handler) readyz(c *gin.Context) {
+	if err := h.services.Health.Ready(c.Request.Context()); err != nil {
+		newErrorResponse(c, healthErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) version(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Health.Version())
+}
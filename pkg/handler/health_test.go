@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_readyz(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockHealth)
+
+	testTable := []struct {
+		name             string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockHealth) {
+				s.EXPECT().Ready(gomock.Any()).Return(nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"status":"ok"}`,
+		},
+		{
+			name: "Not ready",
+			mockBehavior: func(s *mock_service.MockHealth) {
+				s.EXPECT().Ready(gomock.Any()).Return(&todo.ErrNotReady{Reason: "shutting down"})
+			},
+			expectedStatus:   503,
+			expectedResponse: `{"message":"Not ready: shutting down"}`,
+		},
+		{
+			name: "Service failure",
+			mockBehavior: func(s *mock_service.MockHealth) {
+				s.EXPECT().Ready(gomock.Any()).Return(errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			health := mock_service.NewMockHealth(c)
+			testCase.mockBehavior(health)
+
+			services := &service.Service{Health: health}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/readyz", handler.readyz)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/readyz", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
+
+func TestHandler_version(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	health := mock_service.NewMockHealth(c)
+	health.EXPECT().Version().Return(todo.BuildInfo{Version: "v1.2.0", Revision: "abc123", GoVersion: "go1.21.0"})
+
+	handler := NewHandler(&service.Service{Health: health})
+
+	r := gin.New()
+	r.GET("/version", handler.version)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, w.Body.String(), `{"version":"v1.2.0","revision":"abc123","modified":false,"go_version":"go1.21.0"}`)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// idempotency replays the stored response for POST requests retried with the
+// same Idempotency-Key. It must run after userIdentity for per user scoping.
+// Anonymous requests share one namespace, so their key is combined with the
+// request fingerprint and a key reused by another client with another body
+// doesn't clash; it's meant for sign-up only, never for responses holding
+// tokens.
+func (h *Handler) idempotency(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if c.Request.Method != http.MethodPost || key == "" {
+		return
+	}
+	if len(key) > idempotencyKeyMaxLength {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid idempotency key")
+		return
+	}
+
+	// uploads are streamed, they'd have to be read whole to tell a retry
+	// from a different file
+	if c.ContentType() == "multipart/form-data" {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	fingerprint := requestFingerprint(c, body)
+	userId := c.GetInt(userCtx)
+	if _, ok := c.Get(userCtx); !ok {
+		sum := sha256.Sum256([]byte(key + "\x00" + fingerprint))
+		key = hex.EncodeToString(sum[:])
+	}
+
+	record, reserved, err := h.services.Idempotency.Begin(c.Request.Context(), userId, key, fingerprint)
+	if err != nil {
+		var status int
+		switch err.(type) {
+		case *todo.ErrIdempotencyKeyReused:
+			status = http.StatusUnprocessableEntity
+		case *todo.ErrIdempotencyRequestInProgress:
+			status = http.StatusConflict
+		default:
+			status = http.StatusInternalServerError
+		}
+		newErrorResponse(c, status, err.Error())
+		return
+	}
+
+	if !reserved {
+		c.Header(idempotencyReplayedHeader, "true")
+		c.Data(record.StatusCode, record.ContentType, record.Response)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+	c.Writer = recorder
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		if err := h.services.Idempotency.Abort(c.Request.Context(), userId, key); err != nil {
+			logging.FromContext(c.Request.Context()).Error("Failed to abort idempotent request", "error", err)
+		}
+		return
+	}
+
+	record.StatusCode = recorder.Status()
+	record.ContentType = recorder.Header().Get("Content-Type")
+	record.Response = recorder.body.Bytes()
+	if err := h.services.Idempotency.Complete(c.Request.Context(), record); err != nil {
+		logging.FromContext(c.Request.Context()).Error("Failed to save idempotent response", "error", err)
+	}
+}
+
+func requestFingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Request.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
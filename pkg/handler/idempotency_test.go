@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_idempotency(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockIdempotency, key string)
+
+	testTable := []struct {
+		name             string
+		key              string
+		anonymous        bool
+		contentType      string
+		handlerStatus    int
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+		expectedReplayed string
+	}{
+		{
+			name:             "No key",
+			key:              "",
+			handlerStatus:    200,
+			mockBehavior:     func(s *mock_service.MockIdempotency, key string) {},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1}`,
+		},
+		{
+			name:          "Anonymous",
+			key:           "key",
+			anonymous:     true,
+			handlerStatus: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				// keys of anonymous requests are scoped by the request
+				s.EXPECT().Begin(gomock.Any(), 0, gomock.Not(key), gomock.Any()).Return(todo.IdempotencyRecord{
+					StatusCode:  200,
+					ContentType: "application/json; charset=utf-8",
+					Response:    []byte(`{"id":1}`),
+				}, false, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1}`,
+			expectedReplayed: "true",
+		},
+		{
+			name:             "Upload",
+			key:              "key",
+			contentType:      "multipart/form-data; boundary=x",
+			handlerStatus:    200,
+			mockBehavior:     func(s *mock_service.MockIdempotency, key string) {},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1}`,
+		},
+		{
+			name:          "First request",
+			key:           "key",
+			handlerStatus: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				record := todo.IdempotencyRecord{Id: 1, UserId: 1, Key: key}
+				s.EXPECT().Begin(gomock.Any(), 1, key, gomock.Any()).Return(record, true, nil)
+				record.StatusCode = 200
+				record.ContentType = "application/json; charset=utf-8"
+				record.Response = []byte(`{"id":1}`)
+				s.EXPECT().Complete(gomock.Any(), record).Return(nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1}`,
+		},
+		{
+			name:          "Replay",
+			key:           "key",
+			handlerStatus: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				s.EXPECT().Begin(gomock.Any(), 1, key, gomock.Any()).Return(todo.IdempotencyRecord{
+					Id:          1,
+					UserId:      1,
+					Key:         key,
+					StatusCode:  200,
+					ContentType: "application/json; charset=utf-8",
+					Response:    []byte(`{"id":1}`),
+				}, false, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1}`,
+			expectedReplayed: "true",
+		},
+		{
+			name:          "Key reused",
+			key:           "key",
+			handlerStatus: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				s.EXPECT().Begin(gomock.Any(), 1, key, gomock.Any()).Return(todo.IdempotencyRecord{},
+					false, &todo.ErrIdempotencyKeyReused{})
+			},
+			expectedStatus:   422,
+			expectedResponse: `{"message":"Idempotency key was already used with a different request"}`,
+		},
+		{
+			name:          "In progress",
+			key:           "key",
+			handlerStatus: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				s.EXPECT().Begin(gomock.Any(), 1, key, gomock.Any()).Return(todo.IdempotencyRecord{},
+					false, &todo.ErrIdempotencyRequestInProgress{})
+			},
+			expectedStatus:   409,
+			expectedResponse: `{"message":"Request with this idempotency key is still in progress"}`,
+		},
+		{
+			name:          "Handler failure",
+			key:           "key",
+			handlerStatus: 500,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				s.EXPECT().Begin(gomock.Any(), 1, key, gomock.Any()).Return(
+					todo.IdempotencyRecord{Id: 1, UserId: 1, Key: key}, true, nil)
+				s.EXPECT().Abort(gomock.Any(), 1, key).Return(nil)
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"id":1}`,
+		},
+		{
+			name:          "Service failure",
+			key:           "key",
+			handlerStatus: 200,
+			mockBehavior: func(s *mock_service.MockIdempotency, key string) {
+				s.EXPECT().Begin(gomock.Any(), 1, key, gomock.Any()).Return(todo.IdempotencyRecord{},
+					false, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			idempotency := mock_service.NewMockIdempotency(c)
+			testCase.mockBehavior(idempotency, testCase.key)
+
+			services := &service.Service{Idempotency: idempotency}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/lists/", func(c *gin.Context) {
+				if !testCase.anonymous {
+					c.Set(userCtx, 1)
+				}
+			}, handler.idempotency, func(c *gin.Context) {
+				c.JSON(testCase.handlerStatus, map[string]any{
+					"id": 1,
+				})
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/lists/",
+				bytes.NewBufferString(`{"title":"Test"}`))
+			if testCase.key != "" {
+				req.Header.Set("Idempotency-Key", testCase.key)
+			}
+			if testCase.contentType != "" {
+				req.Header.Set("Content-Type", testCase.contentType)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+			assert.Equal(t, testCase.expectedReplayed, w.Header().Get("Idempotent-Replayed"))
+		})
+	}
+}
@@ -26,12 +26,16 @@ func (h *Handler) createItem(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.TodoItem.Create(userId, listId, input)
+	id, err := h.services.TodoItem.Create(c.Request.Context(), userId, listId, input)
 	if err != nil {
 		var status int
 		switch err.(type) {
 		case *todo.ErrNoSuchList:
 			status = http.StatusOK
+		case *todo.ErrNoSuchStatus, *todo.ErrInvalidDependency:
+			status = http.StatusBadRequest
+		case *todo.ErrItemBlocked:
+			status = http.StatusConflict
 		default:
 			status = http.StatusInternalServerError
 		}
@@ -60,7 +64,17 @@ func (h *Handler) getAllItems(c *gin.Context) {
 		return
 	}
 
-	items, err := h.services.TodoItem.GetAll(userId, listId)
+	var blocked *bool
+	if value, ok := c.GetQuery("blocked"); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "Invalid blocked")
+			return
+		}
+		blocked = &b
+	}
+
+	items, err := h.services.TodoItem.GetAll(c.Request.Context(), userId, listId, blocked)
 	if err != nil {
 		var status int
 		switch err.(type) {
@@ -90,7 +104,7 @@ func (h *Handler) getItemById(c *gin.Context) {
 		return
 	}
 
-	item, err := h.services.TodoItem.GetById(userId, itemId)
+	item, err := h.services.TodoItem.GetById(c.Request.Context(), userId, itemId)
 	if err != nil {
 		var status int
 		switch err.(type) {
@@ -124,14 +138,16 @@ func (h *Handler) updateItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoItem.Update(userId, id, input)
+	warnings, err := h.services.TodoItem.Update(c.Request.Context(), userId, id, input)
 	if err != nil {
 		var status int
 		switch err.(type) {
 		case *todo.ErrNoSuchItem:
 			status = http.StatusOK
-		case *todo.ErrInvalidUpdateItemInput:
+		case *todo.ErrInvalidUpdateItemInput, *todo.ErrNoSuchStatus, *todo.ErrInvalidDependency:
 			status = http.StatusBadRequest
+		case *todo.ErrInvalidTransition, *todo.ErrDependencyCycle, *todo.ErrItemBlocked:
+			status = http.StatusConflict
 		default:
 			status = http.StatusInternalServerError
 		}
@@ -139,10 +155,47 @@ func (h *Handler) updateItem(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, statusResponse{
-		Status: "ok",
+	c.JSON(http.StatusOK, updateItemResponse{
+		Status:   "ok",
+		Warnings: warnings,
 	})
 }
+
+type updateItemResponse struct {
+	Status   string         `json:"status"`
+	Warnings []todo.Warning `json:"warnings,omitempty"`
+}
+
+// getItemDependencies returns the items the item waits for and the ones
+// waiting for it.
+func (h *Handler) getItemDependencies(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid item id")
+		return
+	}
+
+	dependencies, err := h.services.TodoItem.Dependencies(c.Request.Context(), userId, itemId)
+	if err != nil {
+		var status int
+		switch err.(type) {
+		case *todo.ErrNoSuchItem:
+			status = http.StatusOK
+		default:
+			status = http.StatusInternalServerError
+		}
+		newErrorResponse(c, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dependencies)
+}
+
 func (h *Handler) deleteItem(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
@@ -155,7 +208,7 @@ func (h *Handler) deleteItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoItem.Delete(userId, itemId)
+	err = h.services.TodoItem.Delete(c.Request.Context(), userId, itemId)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -165,3 +218,24 @@ func (h *Handler) deleteItem(c *gin.Context) {
 		Status: "ok",
 	})
 }
+
+func (h *Handler) bulkItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input todo.BulkItemsInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	output, err := h.services.TodoItem.Bulk(c.Request.Context(), userId, input)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
@@ -38,7 +38,7 @@ func TestHandler_createItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, listId int,
 				input todo.TodoItem) {
-				s.EXPECT().Create(1, listId, input).Return(1, nil)
+				s.EXPECT().Create(gomock.Any(), 1, listId, input).Return(1, nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"id":1}`,
@@ -93,7 +93,7 @@ func TestHandler_createItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, listId int,
 				input todo.TodoItem) {
-				s.EXPECT().Create(1, listId, input).Return(0,
+				s.EXPECT().Create(gomock.Any(), 1, listId, input).Return(0,
 					&todo.ErrNoSuchList{})
 			},
 			expectedStatus:   200,
@@ -109,7 +109,7 @@ func TestHandler_createItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, listId int,
 				input todo.TodoItem) {
-				s.EXPECT().Create(1, listId, input).Return(0,
+				s.EXPECT().Create(gomock.Any(), 1, listId, input).Return(0,
 					errors.New("Service failure"))
 			},
 			expectedStatus:   500,
@@ -162,7 +162,7 @@ func TestHandler_getItemById(t *testing.T) {
 			name:    "OK",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoItem, itemId int) {
-				s.EXPECT().GetById(1, itemId).Return(todo.TodoItem{
+				s.EXPECT().GetById(gomock.Any(), 1, itemId).Return(todo.TodoItem{
 					Id:          1,
 					Title:       "Test",
 					Description: "Description",
@@ -183,7 +183,7 @@ func TestHandler_getItemById(t *testing.T) {
 			name:    "No item with such id",
 			inputId: 10,
 			mockBehavior: func(s *mock_service.MockTodoItem, itemId int) {
-				s.EXPECT().GetById(1, itemId).Return(todo.TodoItem{},
+				s.EXPECT().GetById(gomock.Any(), 1, itemId).Return(todo.TodoItem{},
 					&todo.ErrNoSuchItem{})
 			},
 			expectedStatus:   200,
@@ -193,7 +193,7 @@ func TestHandler_getItemById(t *testing.T) {
 			name:    "Service failure",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoItem, itemId int) {
-				s.EXPECT().GetById(1, itemId).Return(todo.TodoItem{},
+				s.EXPECT().GetById(gomock.Any(), 1, itemId).Return(todo.TodoItem{},
 					errors.New("Service failure"))
 			},
 			expectedStatus:   500,
@@ -258,7 +258,7 @@ func TestHandler_updateItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, id int,
 				input todo.UpdateItemInput) {
-				s.EXPECT().Update(1, id, input).Return(nil)
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil, nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"status":"ok"}`,
@@ -289,7 +289,7 @@ func TestHandler_updateItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, id int,
 				input todo.UpdateItemInput) {
-				s.EXPECT().Update(1, id, input).Return(&todo.ErrNoSuchItem{})
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil, &todo.ErrNoSuchItem{})
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"message":"No item with such id"}`,
@@ -305,12 +305,60 @@ func TestHandler_updateItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, id int,
 				input todo.UpdateItemInput) {
-				s.EXPECT().Update(1, id, input).Return(
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil,
 					&todo.ErrInvalidUpdateItemInput{})
 			},
 			expectedStatus:   400,
 			expectedResponse: `{"message":"Invalid item update input"}`,
 		},
+		{
+			name:      "Blocked",
+			inputId:   1,
+			inputBody: `{"done":true}`,
+			inputUpdate: todo.UpdateItemInput{
+				Done: &doneBool,
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, id int,
+				input todo.UpdateItemInput) {
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil,
+					&todo.ErrItemBlocked{Blockers: []int{2, 3}})
+			},
+			expectedStatus:   409,
+			expectedResponse: `{"message":"Item is blocked by open items 2, 3"}`,
+		},
+		{
+			name:      "Blocked with warning",
+			inputId:   1,
+			inputBody: `{"done":true}`,
+			inputUpdate: todo.UpdateItemInput{
+				Done: &doneBool,
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, id int,
+				input todo.UpdateItemInput) {
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return([]todo.Warning{{
+					Code:    "blocked",
+					Message: "Item is blocked by open items 2",
+				}}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"status":"ok","warnings":[` +
+				`{"code":"blocked","message":"Item is blocked by open items 2"}]}`,
+		},
+		{
+			name:      "Dependency cycle",
+			inputId:   1,
+			inputBody: `{"blocked_by":[2]}`,
+			inputUpdate: todo.UpdateItemInput{
+				BlockedBy: &todo.ItemIds{2},
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, id int,
+				input todo.UpdateItemInput) {
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil,
+					&todo.ErrDependencyCycle{Cycle: []int{1, 2, 1}})
+			},
+			expectedStatus:   409,
+			expectedResponse: `{"message":"Dependencies can't form a cycle: 1 blocked by 2 blocked by 1"}`,
+		},
 		{
 			name:      "Service failure",
 			inputId:   1,
@@ -322,7 +370,7 @@ func TestHandler_updateItem(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoItem, id int,
 				input todo.UpdateItemInput) {
-				s.EXPECT().Update(1, id, input).Return(
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil,
 					errors.New("Service failure"))
 			},
 			expectedStatus:   500,
@@ -375,7 +423,7 @@ func TestHandler_deleteItem(t *testing.T) {
 			name:    "OK",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoItem, id int) {
-				s.EXPECT().Delete(1, id).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), 1, id).Return(nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"status":"ok"}`,
@@ -392,7 +440,7 @@ func TestHandler_deleteItem(t *testing.T) {
 			name:    "No item with such id",
 			inputId: 10,
 			mockBehavior: func(s *mock_service.MockTodoItem, id int) {
-				s.EXPECT().Delete(1, id).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), 1, id).Return(nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"status":"ok"}`,
@@ -401,7 +449,7 @@ func TestHandler_deleteItem(t *testing.T) {
 			name:    "Service failure",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoItem, id int) {
-				s.EXPECT().Delete(1, id).Return(errors.New("Service failure"))
+				s.EXPECT().Delete(gomock.Any(), 1, id).Return(errors.New("Service failure"))
 			},
 			expectedStatus:   500,
 			expectedResponse: `{"message":"Service failure"}`,
@@ -437,3 +485,261 @@ func TestHandler_deleteItem(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_bulkItems(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoItem, input todo.BulkItemsInput)
+
+	doneBool := true
+	testTable := []struct {
+		name             string
+		inputBody        string
+		inputBulk        todo.BulkItemsInput
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"atomic":true,"operations":[{"op":"update","item_id":1,"update":{"done":true}},{"op":"delete","item_id":2}]}`,
+			inputBulk: todo.BulkItemsInput{
+				Atomic: true,
+				Operations: []todo.BulkItemOperation{
+					{Op: "update", ItemId: 1, Update: &todo.UpdateItemInput{Done: &doneBool}},
+					{Op: "delete", ItemId: 2},
+				},
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, input todo.BulkItemsInput) {
+				s.EXPECT().Bulk(gomock.Any(), 1, input).Return(todo.BulkItemsOutput{
+					Applied: true,
+					Results: []todo.BulkItemResult{
+						{Index: 0, Op: "update", Id: 1, Status: "ok"},
+						{Index: 1, Op: "delete", Id: 2, Status: "ok"},
+					},
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"applied":true,"results":[{"index":0,"op":"update","id":1,"status":"ok"},{"index":1,"op":"delete","id":2,"status":"ok"}]}`,
+		},
+		{
+			name:      "Partial failure",
+			inputBody: `{"operations":[{"op":"move","item_id":1,"list_id":5}]}`,
+			inputBulk: todo.BulkItemsInput{
+				Operations: []todo.BulkItemOperation{
+					{Op: "move", ItemId: 1, ListId: 5},
+				},
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, input todo.BulkItemsInput) {
+				s.EXPECT().Bulk(gomock.Any(), 1, input).Return(todo.BulkItemsOutput{
+					Applied: true,
+					Results: []todo.BulkItemResult{
+						{Index: 0, Op: "move", Status: "error", Code: "no_such_list",
+							Message: "No list with such id"},
+					},
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"applied":true,"results":[{"index":0,"op":"move","status":"error","code":"no_such_list","message":"No list with such id"}]}`,
+		},
+		{
+			name:             "No operations",
+			inputBody:        `{"operations":[]}`,
+			mockBehavior:     func(s *mock_service.MockTodoItem, input todo.BulkItemsInput) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Service failure",
+			inputBody: `{"operations":[{"op":"delete","item_id":2}]}`,
+			inputBulk: todo.BulkItemsInput{
+				Operations: []todo.BulkItemOperation{
+					{Op: "delete", ItemId: 2},
+				},
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, input todo.BulkItemsInput) {
+				s.EXPECT().Bulk(gomock.Any(), 1, input).Return(todo.BulkItemsOutput{},
+					errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			item := mock_service.NewMockTodoItem(c)
+			testCase.mockBehavior(item, testCase.inputBulk)
+
+			services := &service.Service{TodoItem: item}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/items/bulk", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.bulkItems)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/items/bulk",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getAllItems(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoItem, blocked *bool)
+
+	blockedBool := true
+	testTable := []struct {
+		name             string
+		query            string
+		inputBlocked     *bool
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:  "OK",
+			query: "",
+			mockBehavior: func(s *mock_service.MockTodoItem, blocked *bool) {
+				s.EXPECT().GetAll(gomock.Any(), 1, 1, blocked).Return([]todo.TodoItem{
+					{Id: 1, Title: "Deploy", BlockedBy: todo.ItemIds{2}, Blocked: true},
+					{Id: 2, Title: "Review"},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"data":[` +
+				`{"id":1,"title":"Deploy","description":"","done":false,"blocked_by":[2],"blocked":true},` +
+				`{"id":2,"title":"Review","description":"","done":false}]}`,
+		},
+		{
+			name:         "Blocked",
+			query:        "?blocked=true",
+			inputBlocked: &blockedBool,
+			mockBehavior: func(s *mock_service.MockTodoItem, blocked *bool) {
+				s.EXPECT().GetAll(gomock.Any(), 1, 1, blocked).Return([]todo.TodoItem{}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"data":[]}`,
+		},
+		{
+			name:             "Invalid blocked",
+			query:            "?blocked=maybe",
+			mockBehavior:     func(s *mock_service.MockTodoItem, blocked *bool) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid blocked"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			item := mock_service.NewMockTodoItem(c)
+			testCase.mockBehavior(item, testCase.inputBlocked)
+
+			services := &service.Service{TodoItem: item}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/lists/:id/items/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getAllItems)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/lists/1/items/"+testCase.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getItemDependencies(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoItem, id int)
+
+	testTable := []struct {
+		name             string
+		inputId          any
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:    "OK",
+			inputId: 1,
+			mockBehavior: func(s *mock_service.MockTodoItem, id int) {
+				s.EXPECT().Dependencies(gomock.Any(), 1, id).Return(todo.ItemDependencies{
+					BlockedBy: []todo.TodoItem{{Id: 2, Title: "Review", Done: true}},
+					Blocking:  []todo.TodoItem{},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"blocked_by":[{"id":2,"title":"Review","description":"","done":true}],` +
+				`"blocking":[]}`,
+		},
+		{
+			name:             "Invalid id",
+			inputId:          "a",
+			mockBehavior:     func(s *mock_service.MockTodoItem, id int) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid item id"}`,
+		},
+		{
+			name:    "No item with such id",
+			inputId: 10,
+			mockBehavior: func(s *mock_service.MockTodoItem, id int) {
+				s.EXPECT().Dependencies(gomock.Any(), 1, id).Return(todo.ItemDependencies{}, &todo.ErrNoSuchItem{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No item with such id"}`,
+		},
+		{
+			name:    "Service failure",
+			inputId: 1,
+			mockBehavior: func(s *mock_service.MockTodoItem, id int) {
+				s.EXPECT().Dependencies(gomock.Any(), 1, id).Return(todo.ItemDependencies{}, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			item := mock_service.NewMockTodoItem(c)
+			if itemId, ok := testCase.inputId.(int); ok {
+				testCase.mockBehavior(item, itemId)
+			}
+
+			services := &service.Service{TodoItem: item}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/items/:id/dependencies", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getItemDependencies)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET",
+				fmt.Sprintf("/api/items/%v/dependencies", testCase.inputId), nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
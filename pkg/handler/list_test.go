@@ -34,7 +34,7 @@ func TestHandler_createList(t *testing.T) {
 				Description: "Description",
 			},
 			mockBehavior: func(s *mock_service.MockTodoList, input todo.TodoList) {
-				s.EXPECT().Create(1, input).Return(1, nil)
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(1, nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"id":1}`,
@@ -67,7 +67,7 @@ func TestHandler_createList(t *testing.T) {
 				Description: "Description",
 			},
 			mockBehavior: func(s *mock_service.MockTodoList, input todo.TodoList) {
-				s.EXPECT().Create(1, input).Return(0, errors.New("Service failure"))
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(0, errors.New("Service failure"))
 			},
 			expectedStatus:   500,
 			expectedResponse: `{"message":"Service failure"}`,
@@ -114,7 +114,7 @@ func TestHandler_getAllLists(t *testing.T) {
 		{
 			name: "OK",
 			mockBehavior: func(s *mock_service.MockTodoList) {
-				s.EXPECT().GetAll(1).Return([]todo.TodoList{
+				s.EXPECT().GetAll(gomock.Any(), 1).Return([]todo.TodoList{
 					{
 						Id:          1,
 						Title:       "Test",
@@ -135,7 +135,7 @@ func TestHandler_getAllLists(t *testing.T) {
 		{
 			name: "No lists",
 			mockBehavior: func(s *mock_service.MockTodoList) {
-				s.EXPECT().GetAll(1).Return([]todo.TodoList{}, nil)
+				s.EXPECT().GetAll(gomock.Any(), 1).Return([]todo.TodoList{}, nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"data":[]}`,
@@ -143,7 +143,7 @@ func TestHandler_getAllLists(t *testing.T) {
 		{
 			name: "Service failure",
 			mockBehavior: func(s *mock_service.MockTodoList) {
-				s.EXPECT().GetAll(1).Return([]todo.TodoList{},
+				s.EXPECT().GetAll(gomock.Any(), 1).Return([]todo.TodoList{},
 					errors.New("Service failure"))
 			},
 			expectedStatus:   500,
@@ -192,7 +192,7 @@ func TestHandler_getListById(t *testing.T) {
 			name:    "OK",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoList, id int) {
-				s.EXPECT().GetById(1, id).Return(todo.TodoList{
+				s.EXPECT().GetById(gomock.Any(), 1, id).Return(todo.TodoList{
 					Id:          id,
 					Title:       "Test",
 					Description: "Description",
@@ -212,7 +212,7 @@ func TestHandler_getListById(t *testing.T) {
 			name:    "No list with such id",
 			inputId: 10,
 			mockBehavior: func(s *mock_service.MockTodoList, id int) {
-				s.EXPECT().GetById(1, id).Return(todo.TodoList{},
+				s.EXPECT().GetById(gomock.Any(), 1, id).Return(todo.TodoList{},
 					&todo.ErrNoSuchList{})
 			},
 			expectedStatus:   200,
@@ -222,7 +222,7 @@ func TestHandler_getListById(t *testing.T) {
 			name:    "Service failure",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoList, id int) {
-				s.EXPECT().GetById(1, id).Return(todo.TodoList{},
+				s.EXPECT().GetById(gomock.Any(), 1, id).Return(todo.TodoList{},
 					errors.New("Service failure"))
 			},
 			expectedStatus:   500,
@@ -285,7 +285,7 @@ func TestHandler_updateList(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoList, id int,
 				input todo.UpdateListInput) {
-				s.EXPECT().Update(1, id, input).Return(nil)
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"status":"ok"}`,
@@ -314,7 +314,7 @@ func TestHandler_updateList(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoList, id int,
 				input todo.UpdateListInput) {
-				s.EXPECT().Update(1, id, input).Return(&todo.ErrNoSuchList{})
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(&todo.ErrNoSuchList{})
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"message":"No list with such id"}`,
@@ -329,7 +329,7 @@ func TestHandler_updateList(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoList, id int,
 				input todo.UpdateListInput) {
-				s.EXPECT().Update(1, id, input).Return(
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(
 					&todo.ErrInvalidUpdateListInput{})
 			},
 			expectedStatus:   400,
@@ -345,7 +345,7 @@ func TestHandler_updateList(t *testing.T) {
 			},
 			mockBehavior: func(s *mock_service.MockTodoList, id int,
 				input todo.UpdateListInput) {
-				s.EXPECT().Update(1, id, input).Return(
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(
 					errors.New("Service failure"))
 			},
 			expectedStatus:   500,
@@ -397,7 +397,7 @@ func TestHandler_deleteList(t *testing.T) {
 			name:    "OK",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoList, id int) {
-				s.EXPECT().Delete(1, id).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), 1, id).Return(nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"status":"ok"}`,
@@ -413,7 +413,7 @@ func TestHandler_deleteList(t *testing.T) {
 			name:    "No list with such id",
 			inputId: 10,
 			mockBehavior: func(s *mock_service.MockTodoList, id int) {
-				s.EXPECT().Delete(1, id).Return(nil)
+				s.EXPECT().Delete(gomock.Any(), 1, id).Return(nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: `{"status":"ok"}`,
@@ -422,7 +422,7 @@ func TestHandler_deleteList(t *testing.T) {
 			name:    "Service failure",
 			inputId: 1,
 			mockBehavior: func(s *mock_service.MockTodoList, id int) {
-				s.EXPECT().Delete(1, id).Return(errors.New("Service failure"))
+				s.EXPECT().Delete(gomock.Any(), 1, id).Return(errors.New("Service failure"))
 			},
 			expectedStatus:   500,
 			expectedResponse: `{"message":"Service failure"}`,
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	requestIdHeader = "X-Request-ID"
+
+	// maxRequestIdLength bounds the ids taken from clients, which end up
+	// in every log line of their requests
+	maxRequestIdLength = 128
+)
+
+// requestLogger tags the request with an id, taken from the X-Request-ID
+// header when the client or a proxy sent one, and puts a logger with it in
+// the context of the request. Once the request is done, it's logged with
+// its route, status and latency.
+func requestLogger(c *gin.Context) {
+	start := time.Now()
+
+	requestId := c.GetHeader(requestIdHeader)
+	if !validRequestId(requestId) {
+		requestId = newRequestId()
+	}
+	c.Header(requestIdHeader, requestId)
+
+	logger := logging.FromContext(c.Request.Context()).With("request_id", requestId)
+	if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+		logger = logger.With("trace_id", span.TraceID().String())
+	}
+	c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
+
+	c.Next()
+
+	attrs := []any{
+		"method", c.Request.Method,
+		"route", routeTemplate(c),
+		"status", c.Writer.Status(),
+		"latency", time.Since(start),
+	}
+	if userId, ok := c.Get(userCtx); ok {
+		attrs = append(attrs, "user_id", userId)
+	}
+
+	level := slog.LevelInfo
+	if c.Writer.Status() >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	logger.Log(c.Request.Context(), level, "Request", attrs...)
+}
+
+// routeTemplate returns the route the request matched, like /api/lists/:id,
+// keeping ids out of logs and metric labels.
+func routeTemplate(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
+
+// setUserId records the authenticated user, also tagging the span of the
+// request and everything logged for the rest of it with it.
+func setUserId(c *gin.Context, userId int) {
+	c.Set(userCtx, userId)
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(tracing.UserId(userId))
+
+	logger := logging.FromContext(c.Request.Context()).With("user_id", userId)
+	c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
+}
+
+// validRequestId accepts printable ASCII ids, so they can't forge log lines.
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
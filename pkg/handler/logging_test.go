@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRequestLogger(t *testing.T) {
+	testTable := []struct {
+		name              string
+		requestId         string
+		path              string
+		expectedRequestId string
+		expectedRoute     string
+		expectedStatus    float64
+		expectedUserId    any
+	}{
+		{
+			name:              "Propagated",
+			requestId:         "req-1",
+			path:              "/api/items/5",
+			expectedRequestId: "req-1",
+			expectedRoute:     "/api/items/:id",
+			expectedStatus:    200,
+			expectedUserId:    float64(1),
+		},
+		{
+			name:           "Generated",
+			path:           "/api/items/5",
+			expectedRoute:  "/api/items/:id",
+			expectedStatus: 200,
+			expectedUserId: float64(1),
+		},
+		{
+			name:           "Invalid",
+			requestId:      "req\n1",
+			path:           "/api/items/5",
+			expectedRoute:  "/api/items/:id",
+			expectedStatus: 200,
+			expectedUserId: float64(1),
+		},
+		{
+			name:              "Unmatched",
+			requestId:         "req-2",
+			path:              "/nowhere",
+			expectedRequestId: "req-2",
+			expectedRoute:     "unmatched",
+			expectedStatus:    404,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			r := gin.New()
+			r.Use(requestLogger)
+			r.GET("/api/items/:id", func(c *gin.Context) {
+				setUserId(c, 1)
+				logging.FromContext(c.Request.Context()).Info("Handled")
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", testCase.path, nil)
+			req = req.WithContext(logging.NewContext(req.Context(), logger))
+			if testCase.requestId != "" {
+				req.Header.Set(requestIdHeader, testCase.requestId)
+			}
+
+			r.ServeHTTP(w, req)
+
+			requestId := w.Header().Get(requestIdHeader)
+			if testCase.expectedRequestId != "" {
+				assert.Equal(t, requestId, testCase.expectedRequestId)
+			} else {
+				assert.Equal(t, len(requestId), 32)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			records := make([]map[string]any, len(lines))
+			for i, line := range lines {
+				assert.Equal(t, json.Unmarshal([]byte(line), &records[i]), nil)
+				assert.Equal(t, records[i]["request_id"], requestId)
+			}
+
+			access := records[len(records)-1]
+			assert.Equal(t, access["method"], "GET")
+			assert.Equal(t, access["route"], testCase.expectedRoute)
+			assert.Equal(t, access["status"], testCase.expectedStatus)
+			assert.Equal(t, access["user_id"], testCase.expectedUserId)
+			if testCase.expectedUserId != nil {
+				assert.Equal(t, records[0]["msg"], "Handled")
+				assert.Equal(t, records[0]["user_id"], testCase.expectedUserId)
+			}
+		})
+	}
+}
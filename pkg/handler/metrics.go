@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OrIX219/todo/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// requestMetrics counts the request and how long it took by its route and
+// status.
+func requestMetrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := routeTemplate(c)
+	status := strconv.Itoa(c.Writer.Status())
+	metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+	metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OrIX219/todo/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	r := gin.New()
+	r.Use(requestMetrics)
+	r.GET("/test/metrics/:id", func(c *gin.Context) {
+		c.Status(201)
+	})
+
+	for _, path := range []string{"/test/metrics/1", "/test/metrics/2", "/test/metrics/2/x"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Equal(t, strings.Contains(body,
+		`http_requests_total{method="GET",route="/test/metrics/:id",status="201"} 2`+"\n"), true)
+	assert.Equal(t, strings.Contains(body,
+		`http_request_duration_seconds_count{method="GET",route="/test/metrics/:id",status="201"} 2`+"\n"), true)
+	assert.Equal(t, strings.Contains(body, `route="unmatched",status="404"`), true)
+	assert.Equal(t, strings.Contains(body, "/test/metrics/1"), false)
+}
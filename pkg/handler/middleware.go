@@ -5,12 +5,17 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/metrics"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 )
 
 const (
-	authHeader = "Authorization"
-	userCtx    = "userId"
+	authHeader       = "Authorization"
+	userCtx          = "userId"
+	socketTokenParam = "access_token"
+	basicAuthRealm   = `Basic realm="todo", charset="UTF-8"`
 )
 
 func (h *Handler) userIdentity(c *gin.Context) {
@@ -36,13 +41,70 @@ func (h *Handler) userIdentity(c *gin.Context) {
 		return
 	}
 
-	userId, err := h.services.Authorization.ParseToken(headerParts[1])
+	userId, err := h.services.Authorization.ParseToken(c.Request.Context(), headerParts[1])
 	if err != nil {
+		metrics.TokenFailures.Inc()
 		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	c.Set(userCtx, userId)
+	setUserId(c, userId)
+}
+
+// socketIdentity authenticates WebSocket handshakes, where browsers can't set
+// headers. The token comes either in the query or as the second subprotocol
+// after "access_token".
+func (h *Handler) socketIdentity(c *gin.Context) {
+	token := c.Query(socketTokenParam)
+	if token == "" {
+		protocols := websocket.Subprotocols(c.Request)
+		if len(protocols) == 2 && protocols[0] == socketTokenParam {
+			token = protocols[1]
+		}
+	}
+
+	if token == "" {
+		newErrorResponse(c, http.StatusUnauthorized, "Empty token")
+		return
+	}
+
+	userId, err := h.services.Authorization.ParseToken(c.Request.Context(), token)
+	if err != nil {
+		metrics.TokenFailures.Inc()
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	setUserId(c, userId)
+}
+
+// basicIdentity authenticates CalDAV clients, which only support HTTP Basic
+// auth with the account credentials.
+func (h *Handler) basicIdentity(c *gin.Context) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		c.Header("WWW-Authenticate", basicAuthRealm)
+		newErrorResponse(c, http.StatusUnauthorized, "Empty auth header")
+		return
+	}
+
+	userId, err := h.services.Authorization.Authenticate(c.Request.Context(), username, password)
+	if err != nil {
+		switch err := err.(type) {
+		case *todo.ErrInvalidCredentials, *todo.ErrTwoFactorRequired:
+			// a password alone can't answer a two-factor challenge
+			c.Header("WWW-Authenticate", basicAuthRealm)
+			newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		case *todo.ErrAccountLocked:
+			c.Header("Retry-After", seconds(err.RetryAfter))
+			newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	setUserId(c, userId)
 }
 
 func getUserId(c *gin.Context) (int, error) {
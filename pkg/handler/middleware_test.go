@@ -4,7 +4,9 @@ import (
 	"errors"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	todo "github.com/OrIX219/todo/pkg"
 	"github.com/OrIX219/todo/pkg/service"
 	mock_service "github.com/OrIX219/todo/pkg/service/mock"
 	_ "github.com/OrIX219/todo/testing"
@@ -31,7 +33,7 @@ func TestHandler_userIdentity(t *testing.T) {
 			headerValue: "Bearer token",
 			token:       "token",
 			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
-				s.EXPECT().ParseToken(token).Return(1, nil)
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(1, nil)
 			},
 			expectedStatus:   200,
 			expectedResponse: "1",
@@ -65,7 +67,7 @@ func TestHandler_userIdentity(t *testing.T) {
 			headerValue: "Bearer token",
 			token:       "token",
 			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
-				s.EXPECT().ParseToken(token).Return(0, errors.New("Invalid token"))
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(0, errors.New("Invalid token"))
 			},
 			expectedStatus:   401,
 			expectedResponse: `{"message":"Invalid token"}`,
@@ -100,3 +102,188 @@ func TestHandler_userIdentity(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_socketIdentity(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthorization, token string)
+
+	testTable := []struct {
+		name             string
+		query            string
+		protocols        string
+		token            string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:  "Query token",
+			query: "?access_token=token",
+			token: "token",
+			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(1, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: "1",
+		},
+		{
+			name:      "Subprotocol token",
+			protocols: "access_token, token",
+			token:     "token",
+			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(1, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: "1",
+		},
+		{
+			name:             "No token",
+			mockBehavior:     func(s *mock_service.MockAuthorization, token string) {},
+			expectedStatus:   401,
+			expectedResponse: `{"message":"Empty token"}`,
+		},
+		{
+			name:             "Unknown subprotocol",
+			protocols:        "chat, token",
+			mockBehavior:     func(s *mock_service.MockAuthorization, token string) {},
+			expectedStatus:   401,
+			expectedResponse: `{"message":"Empty token"}`,
+		},
+		{
+			name:  "Service failure",
+			query: "?access_token=token",
+			token: "token",
+			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
+				s.EXPECT().ParseToken(gomock.Any(), token).Return(0, errors.New("Invalid token"))
+			},
+			expectedStatus:   401,
+			expectedResponse: `{"message":"Invalid token"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			testCase.mockBehavior(auth, testCase.token)
+
+			services := &service.Service{Authorization: auth}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/ws", handler.socketIdentity, func(c *gin.Context) {
+				id, _ := c.Get(userCtx)
+				c.String(200, "%d", id.(int))
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/ws"+testCase.query, nil)
+			if testCase.protocols != "" {
+				req.Header.Set("Sec-WebSocket-Protocol", testCase.protocols)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_basicIdentity(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthorization)
+
+	testTable := []struct {
+		name             string
+		username         string
+		password         string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:     "OK",
+			username: "test",
+			password: "qwerty",
+			mockBehavior: func(s *mock_service.MockAuthorization) {
+				s.EXPECT().Authenticate(gomock.Any(), "test", "qwerty").Return(1, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: "1",
+		},
+		{
+			name:             "No header",
+			mockBehavior:     func(s *mock_service.MockAuthorization) {},
+			expectedStatus:   401,
+			expectedResponse: `{"message":"Empty auth header"}`,
+		},
+		{
+			name:     "Invalid credentials",
+			username: "test",
+			password: "ytrewq",
+			mockBehavior: func(s *mock_service.MockAuthorization) {
+				s.EXPECT().Authenticate(gomock.Any(), "test", "ytrewq").Return(0, &todo.ErrInvalidCredentials{})
+			},
+			expectedStatus:   401,
+			expectedResponse: `{"message":"Invalid credentials"}`,
+		},
+		{
+			name:     "Two-factor enabled",
+			username: "test",
+			password: "qwerty",
+			mockBehavior: func(s *mock_service.MockAuthorization) {
+				s.EXPECT().Authenticate(gomock.Any(), "test", "qwerty").Return(0, &todo.ErrTwoFactorRequired{})
+			},
+			expectedStatus:   401,
+			expectedResponse: `{"message":"Two-factor authentication required"}`,
+		},
+		{
+			name:     "Locked",
+			username: "test",
+			password: "ytrewq",
+			mockBehavior: func(s *mock_service.MockAuthorization) {
+				s.EXPECT().Authenticate(gomock.Any(), "test", "ytrewq").Return(0,
+					&todo.ErrAccountLocked{RetryAfter: 1500 * time.Millisecond})
+			},
+			expectedStatus:   429,
+			expectedResponse: `{"message":"Too many failed sign-ins, try again later"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			testCase.mockBehavior(auth)
+
+			services := &service.Service{Authorization: auth}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.Handle("PROPFIND", "/dav/", handler.basicIdentity, func(c *gin.Context) {
+				id, _ := c.Get(userCtx)
+				c.String(200, "%d", id.(int))
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PROPFIND", "/dav/", nil)
+			if testCase.username != "" {
+				req.SetBasicAuth(testCase.username, testCase.password)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+			if testCase.expectedStatus == 401 {
+				assert.Equal(t, basicAuthRealm, w.Header().Get("WWW-Authenticate"))
+			}
+			if testCase.expectedStatus == 429 {
+				assert.Equal(t, "2", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// quickAdd creates an item from a single line of text, e.g.
+// "Pay rent tomorrow 9am #finance !high @Household", and returns how the text
+// was interpreted. With dry_run nothing is created.
+func (h *Handler) quickAdd(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid dry_run")
+		return
+	}
+
+	var input todo.QuickAddInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	output, err := h.services.QuickAdd.Add(c.Request.Context(), userId, input, dryRun)
+	if err != nil {
+		var status int
+		switch err.(type) {
+		case *todo.ErrNoSuchList:
+			status = http.StatusOK
+		case *todo.ErrInvalidQuickAdd:
+			status = http.StatusBadRequest
+		default:
+			status = http.StatusInternalServerError
+		}
+		newErrorResponse(c, status, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
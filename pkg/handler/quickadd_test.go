@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_quickAdd(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockQuickAdd)
+
+	due := time.Date(2023, time.October, 19, 9, 0, 0, 0, time.UTC)
+	output := todo.QuickAddOutput{
+		Id:     3,
+		ListId: 2,
+		List:   "Household",
+		Item: todo.TodoItem{
+			Id:       3,
+			Title:    "Pay rent",
+			Due:      &due,
+			Priority: todo.PriorityHigh,
+			Tags:     todo.Tags{"finance"},
+		},
+		Parts: []todo.QuickAddPart{
+			{Kind: "list", Text: "@Household"},
+			{Kind: "tag", Text: "#finance"},
+		},
+	}
+
+	testTable := []struct {
+		name             string
+		query            string
+		inputBody        string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"text":"Pay rent tomorrow 9am #finance !high @Household"}`,
+			mockBehavior: func(s *mock_service.MockQuickAdd) {
+				s.EXPECT().Add(gomock.Any(), 1, todo.QuickAddInput{
+					Text: "Pay rent tomorrow 9am #finance !high @Household",
+				}, false).Return(output, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"id":3,"dry_run":false,"list_id":2,"list":"Household",` +
+				`"item":{"id":3,"title":"Pay rent","description":"","done":false,` +
+				`"due":"2023-10-19T09:00:00Z","priority":3,"tags":["finance"]},` +
+				`"parts":[{"kind":"list","text":"@Household"},{"kind":"tag","text":"#finance"}]}`,
+		},
+		{
+			name:      "Dry run",
+			query:     "?dry_run=true",
+			inputBody: `{"text":"Pay rent","list_id":2,"timezone":"Europe/Berlin"}`,
+			mockBehavior: func(s *mock_service.MockQuickAdd) {
+				s.EXPECT().Add(gomock.Any(), 1, todo.QuickAddInput{
+					Text:     "Pay rent",
+					ListId:   2,
+					Timezone: "Europe/Berlin",
+				}, true).Return(todo.QuickAddOutput{
+					DryRun: true,
+					ListId: 2,
+					List:   "Household",
+					Item:   todo.TodoItem{Title: "Pay rent"},
+					Parts:  []todo.QuickAddPart{},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"dry_run":true,"list_id":2,"list":"Household",` +
+				`"item":{"id":0,"title":"Pay rent","description":"","done":false},` +
+				`"parts":[]}`,
+		},
+		{
+			name:             "Invalid dry run",
+			query:            "?dry_run=maybe",
+			inputBody:        `{"text":"Pay rent"}`,
+			mockBehavior:     func(s *mock_service.MockQuickAdd) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid dry_run"}`,
+		},
+		{
+			name:             "No text",
+			inputBody:        `{"list_id":2}`,
+			mockBehavior:     func(s *mock_service.MockQuickAdd) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Invalid text",
+			inputBody: `{"text":"#finance"}`,
+			mockBehavior: func(s *mock_service.MockQuickAdd) {
+				s.EXPECT().Add(gomock.Any(), 1, todo.QuickAddInput{Text: "#finance"}, false).
+					Return(todo.QuickAddOutput{}, &todo.ErrInvalidQuickAdd{Reason: "no title"})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid quick add: no title"}`,
+		},
+		{
+			name:      "No list with such title",
+			inputBody: `{"text":"Pay rent @Nowhere"}`,
+			mockBehavior: func(s *mock_service.MockQuickAdd) {
+				s.EXPECT().Add(gomock.Any(), 1, todo.QuickAddInput{Text: "Pay rent @Nowhere"}, false).
+					Return(todo.QuickAddOutput{}, &todo.ErrNoSuchList{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No list with such id"}`,
+		},
+		{
+			name:      "Service failure",
+			inputBody: `{"text":"Pay rent @Household"}`,
+			mockBehavior: func(s *mock_service.MockQuickAdd) {
+				s.EXPECT().Add(gomock.Any(), 1, todo.QuickAddInput{Text: "Pay rent @Household"}, false).
+					Return(todo.QuickAddOutput{}, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			quickAdd := mock_service.NewMockQuickAdd(c)
+			testCase.mockBehavior(quickAdd)
+
+			services := &service.Service{QuickAdd: quickAdd}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/quick-add", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.quickAdd)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/quick-add"+testCase.query,
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitByIP limits the clients of a route group by their address,
+// for routes used before signing in. Those check credentials, so requests
+// are refused when the store fails rather than let through unlimited.
+func (h *Handler) rateLimitByIP(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.rateLimit(c, group, "ip:"+c.ClientIP(), false)
+	}
+}
+
+// rateLimitByUser limits the users of a route group. It must run after
+// userIdentity. Requests are let through when the store fails, an outage
+// of it shouldn't take the API down.
+func (h *Handler) rateLimitByUser(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.rateLimit(c, group, "user:"+strconv.Itoa(c.GetInt(userCtx)), true)
+	}
+}
+
+// rateLimit takes a token for the request and tells the client about its
+// limit in the RateLimit headers.
+func (h *Handler) rateLimit(c *gin.Context, group, key string, failOpen bool) {
+	status, err := h.services.RateLimit.Allow(c.Request.Context(), group, key)
+	if err != nil {
+		if failOpen {
+			logging.FromContext(c.Request.Context()).Error("Rate limiting failed", "error", err)
+			return
+		}
+		newErrorResponse(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if status.Limit.Unlimited() {
+		return
+	}
+
+	c.Header("RateLimit-Policy", strconv.Itoa(status.Limit.Requests)+";w="+seconds(status.Limit.Period))
+	c.Header("RateLimit-Limit", strconv.Itoa(status.Limit.Requests))
+	c.Header("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	c.Header("RateLimit-Reset", seconds(status.Reset))
+
+	if !status.Allowed {
+		c.Header("Retry-After", seconds(status.RetryAfter))
+		newErrorResponse(c, http.StatusTooManyRequests, "Too many requests")
+	}
+}
+
+// seconds rounds up, so clients waiting for it don't come back too early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// trustedProxies returns the proxies whose X-Forwarded-For is believed for
+// client addresses, set by TRUSTED_PROXIES. Without them the address of the
+// connection is used, forwarded ones being easy to forge.
+func trustedProxies() []string {
+	value := config.Get("TRUSTED_PROXIES", "")
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// clientIPHeader returns the header a proxy in front sets to the client
+// address, set by CLIENT_IP_HEADER. It's believed from every peer, so the
+// server refuses to start with it unless it listens on a unix socket, where
+// the proxy is the only peer and the connection has no address.
+func clientIPHeader() string {
+	return config.Get("CLIENT_IP_HEADER", "")
+}
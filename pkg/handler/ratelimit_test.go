@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+var testRateLimit = todo.RateLimit{Requests: 10, Period: time.Minute}
+
+func TestHandler_rateLimit(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockRateLimit)
+
+	testTable := []struct {
+		name            string
+		mockBehavior    mockBehavior
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "Allowed",
+			mockBehavior: func(s *mock_service.MockRateLimit) {
+				s.EXPECT().Allow(gomock.Any(), todo.RateLimitAuth, "ip:192.0.2.1").Return(todo.RateLimitStatus{
+					Limit: testRateLimit, Allowed: true, Remaining: 9, Reset: 6 * time.Second,
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedHeaders: map[string]string{
+				"RateLimit-Policy":    "10;w=60",
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "9",
+				"RateLimit-Reset":     "6",
+				"Retry-After":         "",
+			},
+		},
+		{
+			name: "Limited",
+			mockBehavior: func(s *mock_service.MockRateLimit) {
+				s.EXPECT().Allow(gomock.Any(), todo.RateLimitAuth, "ip:192.0.2.1").Return(todo.RateLimitStatus{
+					Limit: testRateLimit, Reset: time.Minute, RetryAfter: 5500 * time.Millisecond,
+				}, nil)
+			},
+			expectedStatus: 429,
+			expectedHeaders: map[string]string{
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"Retry-After":         "6",
+			},
+		},
+		{
+			name: "Unlimited",
+			mockBehavior: func(s *mock_service.MockRateLimit) {
+				s.EXPECT().Allow(gomock.Any(), todo.RateLimitAuth, "ip:192.0.2.1").Return(todo.RateLimitStatus{
+					Allowed: true,
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit": "",
+			},
+		},
+		{
+			name: "Store failure",
+			mockBehavior: func(s *mock_service.MockRateLimit) {
+				s.EXPECT().Allow(gomock.Any(), todo.RateLimitAuth, "ip:192.0.2.1").Return(todo.RateLimitStatus{},
+					errors.New("Service failure"))
+			},
+			expectedStatus: 503,
+			expectedHeaders: map[string]string{
+				"RateLimit-Limit": "",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			rateLimit := mock_service.NewMockRateLimit(c)
+			testCase.mockBehavior(rateLimit)
+
+			services := &service.Service{RateLimit: rateLimit}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/auth/sign-in", handler.rateLimitByIP(todo.RateLimitAuth), func(c *gin.Context) {
+				c.Status(200)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/auth/sign-in", nil)
+			req.RemoteAddr = "192.0.2.1:51234"
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			for header, value := range testCase.expectedHeaders {
+				assert.Equal(t, w.Header().Get(header), value)
+			}
+		})
+	}
+}
+
+func TestHandler_rateLimitByUser(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	rateLimit := mock_service.NewMockRateLimit(c)
+	rateLimit.EXPECT().Allow(gomock.Any(), todo.RateLimitAPI, "user:1").Return(todo.RateLimitStatus{
+		Limit: testRateLimit, Allowed: true, Remaining: 9,
+	}, nil)
+
+	handler := NewHandler(&service.Service{RateLimit: rateLimit})
+
+	r := gin.New()
+	r.GET("/api/lists", func(c *gin.Context) {
+		c.Set(userCtx, 1)
+	}, handler.rateLimitByUser(todo.RateLimitAPI), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/lists", nil))
+
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, w.Header().Get("RateLimit-Remaining"), "9")
+}
+
+func TestHandler_rateLimitByUser_StoreFailure(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	rateLimit := mock_service.NewMockRateLimit(c)
+	rateLimit.EXPECT().Allow(gomock.Any(), todo.RateLimitAPI, "user:1").Return(todo.RateLimitStatus{},
+		errors.New("Service failure"))
+
+	handler := NewHandler(&service.Service{RateLimit: rateLimit})
+
+	r := gin.New()
+	r.GET("/api/lists", func(c *gin.Context) {
+		c.Set(userCtx, 1)
+	}, handler.rateLimitByUser(todo.RateLimitAPI), func(c *gin.Context) {
+		c.Status(200)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/lists", nil))
+
+	assert.Equal(t, w.Code, 200)
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
-	"log"
+	"errors"
+	"log/slog"
+	"net/http"
 
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type errorResponse struct {
@@ -15,6 +20,17 @@ type statusResponse struct {
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
-	log.Printf("Error: %s", message)
+	logError(c, statusCode, message)
 	c.AbortWithStatusJSON(statusCode, errorResponse{message})
 }
+
+// logError logs why the request failed, as an error only when it's the
+// server's fault, which is also recorded on the span of the request.
+func logError(c *gin.Context, statusCode int, message string) {
+	level := slog.LevelWarn
+	if statusCode >= http.StatusInternalServerError {
+		level = slog.LevelError
+		tracing.Fail(trace.SpanFromContext(c.Request.Context()), errors.New(message))
+	}
+	logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, message, "status", statusCode)
+}
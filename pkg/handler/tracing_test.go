@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OrIX219/todo/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestTracing(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	testTable := []struct {
+		name            string
+		traceparent     string
+		status          int
+		message         string
+		expectedTraceId string
+		expectedParent  string
+		expectedCode    codes.Code
+		expectedEvents  int
+	}{
+		{
+			name:            "Propagated",
+			traceparent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			status:          200,
+			expectedTraceId: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedParent:  "00f067aa0ba902b7",
+			expectedCode:    codes.Unset,
+		},
+		{
+			name:           "New trace",
+			status:         500,
+			expectedParent: "0000000000000000",
+			expectedCode:   codes.Error,
+		},
+		{
+			name:           "Failed",
+			status:         500,
+			message:        "db is down",
+			expectedParent: "0000000000000000",
+			expectedCode:   codes.Error,
+			expectedEvents: 1,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			exporter.Reset()
+
+			r := gin.New()
+			r.Use(requestTracing)
+			r.GET("/api/items/:id", func(c *gin.Context) {
+				setUserId(c, 1)
+				var err error
+				if testCase.message != "" {
+					err = errors.New(testCase.message)
+				}
+				_, span := tracing.Start(c.Request.Context(), "TodoItemService.GetById")
+				tracing.End(span, &err)
+				if err != nil {
+					newErrorResponse(c, testCase.status, err.Error())
+					return
+				}
+				c.Status(testCase.status)
+			})
+
+			req := httptest.NewRequest("GET", "/api/items/5", nil)
+			if testCase.traceparent != "" {
+				req.Header.Set("traceparent", testCase.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			assert.Equal(t, len(spans), 2)
+			child, server := spans[0], spans[1]
+
+			assert.Equal(t, server.Name, "GET /api/items/:id")
+			assert.Equal(t, server.SpanKind, trace.SpanKindServer)
+			assert.Equal(t, server.Parent.SpanID().String(), testCase.expectedParent)
+			assert.Equal(t, server.Status.Code, testCase.expectedCode)
+			if testCase.expectedTraceId != "" {
+				assert.Equal(t, server.SpanContext.TraceID().String(), testCase.expectedTraceId)
+			}
+			assert.Equal(t, child.Parent.SpanID(), server.SpanContext.SpanID())
+			assert.Equal(t, len(server.Events), testCase.expectedEvents)
+			assert.Equal(t, len(child.Events), testCase.expectedEvents)
+			if testCase.message != "" {
+				assert.Equal(t, child.Status.Code, codes.Error)
+				assert.Equal(t, child.Status.Description, testCase.message)
+			}
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, attr := range server.Attributes {
+				attrs[attr.Key] = attr.Value
+			}
+			assert.Equal(t, attrs["http.route"].AsString(), "/api/items/:id")
+			assert.Equal(t, attrs["http.response.status_code"].AsInt64(), int64(testCase.status))
+			assert.Equal(t, attrs["user.id"].AsInt64(), int64(1))
+		})
+	}
+}
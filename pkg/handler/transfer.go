@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/transfer"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportFileName = "todo-export"
+	importMaxSize  = 10 << 20
+	importFileForm = "file"
+)
+
+// exportLists streams the lists of the user in the requested format. Once the
+// response has started, errors can only be logged.
+func (h *Handler) exportLists(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	format := c.DefaultQuery("format", todo.FormatJSON)
+	contentType := transfer.ContentType(format)
+	if contentType == "" {
+		newErrorResponse(c, http.StatusBadRequest, "Unsupported format: "+format)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s%s"`, exportFileName, transfer.Extension(format)))
+
+	if err := h.services.Transfer.Export(c.Request.Context(), userId, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			logging.FromContext(c.Request.Context()).Error("Export failed after it started", "error", err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// importLists accepts a file either as the raw body or as the file field of a
+// multipart form. The format is taken from the format parameter, the file
+// name or the content type, in that order. With a source in the path the
+// file is an export of another tool instead.
+func (h *Handler) importLists(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid dry_run")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, importMaxSize)
+	options := todo.ImportOptions{
+		Format:      c.Query("format"),
+		Source:      c.Param("source"),
+		DryRun:      dryRun,
+		OnDuplicate: c.Query("on_duplicate"),
+	}
+
+	var r io.Reader = c.Request.Body
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		file, header, err := c.Request.FormFile(importFileForm)
+		if err != nil {
+			newImportReadErrorResponse(c, err)
+			return
+		}
+		defer file.Close()
+
+		r = file
+		options.FileName = header.Filename
+		if options.Format == "" {
+			options.Format, _ = transfer.FormatOf(header.Filename)
+		}
+	}
+	if options.Format == "" {
+		options.Format, _ = transfer.FormatOf(c.ContentType())
+	}
+	if options.Format == "" && options.Source == "" {
+		newErrorResponse(c, http.StatusBadRequest, "Unknown import format")
+		return
+	}
+
+	// read the file first, so that a too large one isn't reported as invalid
+	data, err := io.ReadAll(r)
+	if err != nil {
+		newImportReadErrorResponse(c, err)
+		return
+	}
+
+	report, err := h.services.Transfer.Import(c.Request.Context(), userId, bytes.NewReader(data), options)
+	if err != nil {
+		newImportErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func newImportReadErrorResponse(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		newErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+}
+
+func newImportErrorResponse(c *gin.Context, err error) {
+	var status int
+	switch err.(type) {
+	case *todo.ErrUnsupportedFormat, *todo.ErrUnsupportedSource, *todo.ErrInvalidImport:
+		status = http.StatusBadRequest
+	default:
+		status = http.StatusInternalServerError
+	}
+	newErrorResponse(c, status, err.Error())
+}
@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_exportLists(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTransfer)
+
+	testTable := []struct {
+		name                string
+		query               string
+		mockBehavior        mockBehavior
+		expectedStatus      int
+		expectedContentType string
+		expectedResponse    string
+	}{
+		{
+			name:  "OK",
+			query: "?format=markdown",
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Export(gomock.Any(), 1, todo.FormatMarkdown, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, userId int, format string, w io.Writer) error {
+						_, err := io.WriteString(w, "# Home\n\n- [ ] Task\n")
+						return err
+					})
+			},
+			expectedStatus:      200,
+			expectedContentType: "text/markdown; charset=utf-8",
+			expectedResponse:    "# Home\n\n- [ ] Task\n",
+		},
+		{
+			name: "Default format",
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Export(gomock.Any(), 1, todo.FormatJSON, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, userId int, format string, w io.Writer) error {
+						_, err := io.WriteString(w, "[]\n")
+						return err
+					})
+			},
+			expectedStatus:      200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedResponse:    "[]\n",
+		},
+		{
+			name:                "Unsupported format",
+			query:               "?format=xml",
+			mockBehavior:        func(s *mock_service.MockTransfer) {},
+			expectedStatus:      400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedResponse:    `{"message":"Unsupported format: xml"}`,
+		},
+		{
+			name:  "Service failure",
+			query: "?format=csv",
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Export(gomock.Any(), 1, todo.FormatCSV, gomock.Any()).
+					Return(errors.New("Service failure"))
+			},
+			expectedStatus:      500,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedResponse:    `{"message":"Service failure"}`,
+		},
+		{
+			name:  "Failure after start",
+			query: "?format=csv",
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Export(gomock.Any(), 1, todo.FormatCSV, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, userId int, format string, w io.Writer) error {
+						io.WriteString(w, "list,title\n")
+						return errors.New("Service failure")
+					})
+			},
+			expectedStatus:      200,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedResponse:    "list,title\n",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			transfer := mock_service.NewMockTransfer(c)
+			testCase.mockBehavior(transfer)
+
+			services := &service.Service{Transfer: transfer}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/export", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.exportLists)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/export"+testCase.query, nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func multipartImport(fileName, content string) (string, *bytes.Buffer) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile(importFileForm, fileName)
+	io.WriteString(fw, content)
+	mw.Close()
+	return mw.FormDataContentType(), &body
+}
+
+func TestHandler_importLists(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTransfer)
+
+	multipartType, multipartBody := multipartImport("lists.md", "# Home\n")
+
+	testTable := []struct {
+		name             string
+		source           string
+		query            string
+		contentType      string
+		body             io.Reader
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:        "OK",
+			query:       "?dry_run=true&on_duplicate=merge",
+			contentType: "text/csv",
+			body:        strings.NewReader("list,title\nHome,Task\n"),
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Import(gomock.Any(), 1, gomock.Any(), todo.ImportOptions{
+					Format:      todo.FormatCSV,
+					DryRun:      true,
+					OnDuplicate: todo.OnDuplicateMerge,
+				}).Return(todo.ImportReport{
+					DryRun:       true,
+					CreatedItems: 1,
+					Lists: []todo.ImportListReport{
+						{Title: "Home", Id: 1, Action: todo.ImportActionMerge, Items: 1},
+					},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"dry_run":true,"created_lists":0,"created_items":1,"skipped_lists":0,` +
+				`"skipped_items":0,"lists":[{"title":"Home","id":1,"action":"merge","items":1,"skipped_items":0}]}`,
+		},
+		{
+			name:        "Multipart",
+			contentType: multipartType,
+			body:        multipartBody,
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Import(gomock.Any(), 1, gomock.Any(), todo.ImportOptions{Format: todo.FormatMarkdown, FileName: "lists.md"}).
+					DoAndReturn(func(ctx context.Context, userId int, r io.Reader, options todo.ImportOptions) (todo.ImportReport, error) {
+						data, _ := io.ReadAll(r)
+						assert.Equal(t, "# Home\n", string(data))
+						return todo.ImportReport{Lists: []todo.ImportListReport{}}, nil
+					})
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"dry_run":false,"created_lists":0,"created_items":0,"skipped_lists":0,` +
+				`"skipped_items":0,"lists":[]}`,
+		},
+		{
+			name:        "Source",
+			source:      "/trello",
+			contentType: "application/json",
+			body:        strings.NewReader(`{"name":"Board"}`),
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Import(gomock.Any(), 1, gomock.Any(), todo.ImportOptions{
+					Format: todo.FormatJSON,
+					Source: todo.SourceTrello,
+				}).Return(todo.ImportReport{
+					Source:       todo.SourceTrello,
+					Mapping:      []todo.ImportMapping{{From: "board", To: todo.MappingList, Count: 1}},
+					CreatedLists: 1,
+					Lists: []todo.ImportListReport{
+						{Title: "Board", Id: 2, Action: todo.ImportActionCreate},
+					},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"source":"trello","mapping":[{"from":"board","to":"list","count":1}],` +
+				`"dry_run":false,"created_lists":1,"created_items":0,"skipped_lists":0,"skipped_items":0,` +
+				`"lists":[{"title":"Board","id":2,"action":"create","items":0,"skipped_items":0}]}`,
+		},
+		{
+			name:        "Unknown source",
+			source:      "/asana",
+			contentType: "application/json",
+			body:        strings.NewReader(`{}`),
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Import(gomock.Any(), 1, gomock.Any(), todo.ImportOptions{Format: todo.FormatJSON, Source: "asana"}).
+					Return(todo.ImportReport{}, &todo.ErrUnsupportedSource{Source: "asana"})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Unsupported import source: asana"}`,
+		},
+		{
+			name:             "Unknown format",
+			contentType:      "text/plain",
+			body:             strings.NewReader("Task"),
+			mockBehavior:     func(s *mock_service.MockTransfer) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Unknown import format"}`,
+		},
+		{
+			name:             "Invalid dry_run",
+			query:            "?dry_run=maybe",
+			contentType:      "application/json",
+			body:             strings.NewReader("[]"),
+			mockBehavior:     func(s *mock_service.MockTransfer) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid dry_run"}`,
+		},
+		{
+			name:             "Too large",
+			contentType:      "application/json",
+			body:             strings.NewReader(strings.Repeat(" ", importMaxSize+1)),
+			mockBehavior:     func(s *mock_service.MockTransfer) {},
+			expectedStatus:   413,
+			expectedResponse: `{"message":"http: request body too large"}`,
+		},
+		{
+			name:        "Invalid import",
+			query:       "?format=json",
+			body:        strings.NewReader(`[{}]`),
+			contentType: "application/octet-stream",
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Import(gomock.Any(), 1, gomock.Any(), todo.ImportOptions{Format: todo.FormatJSON}).
+					Return(todo.ImportReport{}, &todo.ErrInvalidImport{Reason: "list 1: title is required"})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid import: list 1: title is required"}`,
+		},
+		{
+			name:        "Service failure",
+			contentType: "application/json",
+			body:        strings.NewReader("[]"),
+			mockBehavior: func(s *mock_service.MockTransfer) {
+				s.EXPECT().Import(gomock.Any(), 1, gomock.Any(), todo.ImportOptions{Format: todo.FormatJSON}).
+					Return(todo.ImportReport{}, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			transfer := mock_service.NewMockTransfer(c)
+			testCase.mockBehavior(transfer)
+
+			services := &service.Service{Transfer: transfer}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			setUser := func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}
+			r.POST("/api/import", setUser, handler.importLists)
+			r.POST("/api/import/:source", setUser, handler.importLists)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/import"+testCase.source+testCase.query, testCase.body)
+			req.Header.Set("Content-Type", testCase.contentType)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
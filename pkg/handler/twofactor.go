@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) enrollTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input todo.TwoFactorEnrollInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	enrollment, err := h.services.TwoFactor.Enroll(c.Request.Context(), userId, input.Password)
+	if err != nil {
+		switch err := err.(type) {
+		case *todo.ErrInvalidCredentials:
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case *todo.ErrTwoFactorEnabled:
+			newErrorResponse(c, http.StatusConflict, err.Error())
+		case *todo.ErrAccountLocked:
+			c.Header("Retry-After", seconds(err.RetryAfter))
+			newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+type confirmTwoFactorResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (h *Handler) confirmTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input todo.TwoFactorConfirmInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := h.services.TwoFactor.Confirm(c.Request.Context(), userId, input.Password, input.Code)
+	if err != nil {
+		switch err := err.(type) {
+		case *todo.ErrInvalidCredentials, *todo.ErrInvalidTwoFactorCode, *todo.ErrTwoFactorNotEnrolled:
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case *todo.ErrTwoFactorEnabled:
+			newErrorResponse(c, http.StatusConflict, err.Error())
+		case *todo.ErrAccountLocked:
+			c.Header("Retry-After", seconds(err.RetryAfter))
+			newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, confirmTwoFactorResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+func (h *Handler) disableTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input todo.TwoFactorDisableInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.services.TwoFactor.Disable(c.Request.Context(), userId, input.Password, input.Code); err != nil {
+		switch err := err.(type) {
+		case *todo.ErrInvalidCredentials, *todo.ErrInvalidTwoFactorCode, *todo.ErrTwoFactorNotEnrolled:
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+		case *todo.ErrAccountLocked:
+			c.Header("Retry-After", seconds(err.RetryAfter))
+			newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		default:
+			newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_enrollTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTwoFactor)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"password":"qwerty"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Enroll(gomock.Any(), 1, "qwerty").Return(todo.TwoFactorEnrollment{
+					Secret: "JBSWY3DPEHPK3PXP",
+					URI:    "otpauth://totp/todo-app:test?secret=JBSWY3DPEHPK3PXP",
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"secret":"JBSWY3DPEHPK3PXP",` +
+				`"uri":"otpauth://totp/todo-app:test?secret=JBSWY3DPEHPK3PXP"}`,
+		},
+		{
+			name:             "Empty Fields",
+			inputBody:        `{}`,
+			mockBehavior:     func(s *mock_service.MockTwoFactor) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Wrong password",
+			inputBody: `{"password":"ytrewq"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Enroll(gomock.Any(), 1, "ytrewq").Return(todo.TwoFactorEnrollment{}, &todo.ErrInvalidCredentials{})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid credentials"}`,
+		},
+		{
+			name:      "Locked",
+			inputBody: `{"password":"ytrewq"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Enroll(gomock.Any(), 1, "ytrewq").Return(todo.TwoFactorEnrollment{},
+					&todo.ErrAccountLocked{RetryAfter: time.Minute})
+			},
+			expectedStatus:   429,
+			expectedResponse: `{"message":"` + (&todo.ErrAccountLocked{}).Error() + `"}`,
+		},
+		{
+			name:      "Already enabled",
+			inputBody: `{"password":"qwerty"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Enroll(gomock.Any(), 1, "qwerty").Return(todo.TwoFactorEnrollment{}, &todo.ErrTwoFactorEnabled{})
+			},
+			expectedStatus:   409,
+			expectedResponse: `{"message":"Two-factor authentication is already enabled"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"password":"qwerty"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Enroll(gomock.Any(), 1, "qwerty").Return(todo.TwoFactorEnrollment{}, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			twoFactor := mock_service.NewMockTwoFactor(c)
+			testCase.mockBehavior(twoFactor)
+
+			services := &service.Service{TwoFactor: twoFactor}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/account/2fa", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.enrollTwoFactor)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/account/2fa",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_confirmTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTwoFactor)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"password":"qwerty","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Confirm(gomock.Any(), 1, "qwerty", "123456").Return(
+					[]string{"abcdefgh-ijklmnop", "qrstuvwx-yz234567"}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"recovery_codes":["abcdefgh-ijklmnop","qrstuvwx-yz234567"]}`,
+		},
+		{
+			name:             "Empty Fields",
+			inputBody:        `{}`,
+			mockBehavior:     func(s *mock_service.MockTwoFactor) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Wrong password",
+			inputBody: `{"password":"ytrewq","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Confirm(gomock.Any(), 1, "ytrewq", "123456").Return(nil, &todo.ErrInvalidCredentials{})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid credentials"}`,
+		},
+		{
+			name:      "Invalid code",
+			inputBody: `{"password":"qwerty","code":"000000"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Confirm(gomock.Any(), 1, "qwerty", "000000").Return(nil, &todo.ErrInvalidTwoFactorCode{})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid two-factor code"}`,
+		},
+		{
+			name:      "Not enrolled",
+			inputBody: `{"password":"qwerty","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Confirm(gomock.Any(), 1, "qwerty", "123456").Return(nil, &todo.ErrTwoFactorNotEnrolled{})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Two-factor authentication isn't enrolled"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"password":"qwerty","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Confirm(gomock.Any(), 1, "qwerty", "123456").Return(nil, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			twoFactor := mock_service.NewMockTwoFactor(c)
+			testCase.mockBehavior(twoFactor)
+
+			services := &service.Service{TwoFactor: twoFactor}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/account/2fa/confirm", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.confirmTwoFactor)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/account/2fa/confirm",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_disableTwoFactor(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTwoFactor)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"password":"qwerty","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Disable(gomock.Any(), 1, "qwerty", "123456").Return(nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"status":"ok"}`,
+		},
+		{
+			name:      "Invalid code",
+			inputBody: `{"password":"qwerty","code":"000000"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Disable(gomock.Any(), 1, "qwerty", "000000").Return(&todo.ErrInvalidTwoFactorCode{})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid two-factor code"}`,
+		},
+		{
+			name:      "Invalid password",
+			inputBody: `{"password":"wrong","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Disable(gomock.Any(), 1, "wrong", "123456").Return(&todo.ErrInvalidCredentials{})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid credentials"}`,
+		},
+		{
+			name:      "Locked",
+			inputBody: `{"password":"qwerty","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Disable(gomock.Any(), 1, "qwerty", "123456").Return(&todo.ErrAccountLocked{RetryAfter: time.Minute})
+			},
+			expectedStatus:   429,
+			expectedResponse: `{"message":"` + (&todo.ErrAccountLocked{}).Error() + `"}`,
+		},
+		{
+			name:             "No password",
+			inputBody:        `{"code":"123456"}`,
+			mockBehavior:     func(s *mock_service.MockTwoFactor) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Service Failure",
+			inputBody: `{"password":"qwerty","code":"123456"}`,
+			mockBehavior: func(s *mock_service.MockTwoFactor) {
+				s.EXPECT().Disable(gomock.Any(), 1, "qwerty", "123456").Return(errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			twoFactor := mock_service.NewMockTwoFactor(c)
+			testCase.mockBehavior(twoFactor)
+
+			services := &service.Service{TwoFactor: twoFactor}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.DELETE("/api/account/2fa", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.disableTwoFactor)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/api/account/2fa",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
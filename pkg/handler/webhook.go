@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func webhookErrorStatus(err error) int {
+	switch err.(type) {
+	case *todo.ErrNoSuchWebhook:
+		return http.StatusOK
+	case *todo.ErrInvalidWebhookInput:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) createWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input todo.WebhookInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook, err := h.services.Webhook.Create(c.Request.Context(), userId, input)
+	if err != nil {
+		newErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+type getAllWebhooksResponse struct {
+	Data []todo.Webhook `json:"data"`
+}
+
+func (h *Handler) getAllWebhooks(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	webhooks, err := h.services.Webhook.GetAll(c.Request.Context(), userId)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllWebhooksResponse{
+		Data: webhooks,
+	})
+}
+
+func (h *Handler) getWebhookById(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+
+	webhook, err := h.services.Webhook.GetById(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *Handler) updateWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+
+	var input todo.UpdateWebhookInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err = h.services.Webhook.Update(c.Request.Context(), userId, id, input)
+	if err != nil {
+		newErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+
+	err = h.services.Webhook.Delete(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+type getWebhookDeliveriesResponse struct {
+	Data []todo.WebhookDelivery `json:"data"`
+}
+
+func (h *Handler) getWebhookDeliveries(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+
+	deliveries, err := h.services.Webhook.GetDeliveries(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getWebhookDeliveriesResponse{
+		Data: deliveries,
+	})
+}
+
+func (h *Handler) testWebhook(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid webhook id")
+		return
+	}
+
+	delivery, err := h.services.Webhook.SendTest(c.Request.Context(), userId, id)
+	if err != nil {
+		newErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
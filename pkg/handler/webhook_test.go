@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_createWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockWebhook, input todo.WebhookInput)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		inputWebhook     todo.WebhookInput
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"url":"https://example.com/hook","events":["item.*"]}`,
+			inputWebhook: todo.WebhookInput{
+				URL:    "https://example.com/hook",
+				Events: []string{"item.*"},
+			},
+			mockBehavior: func(s *mock_service.MockWebhook, input todo.WebhookInput) {
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(todo.Webhook{
+					Id:     1,
+					URL:    input.URL,
+					Events: input.Events,
+					Secret: "secret",
+					Active: true,
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"id":1,"url":"https://example.com/hook","events":["item.*"],"secret":"secret","active":true,"failure_count":0,"created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:             "No events",
+			inputBody:        `{"url":"https://example.com/hook"}`,
+			mockBehavior:     func(s *mock_service.MockWebhook, input todo.WebhookInput) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Invalid input",
+			inputBody: `{"url":"ftp://example.com","events":["*"]}`,
+			inputWebhook: todo.WebhookInput{
+				URL:    "ftp://example.com",
+				Events: []string{"*"},
+			},
+			mockBehavior: func(s *mock_service.MockWebhook, input todo.WebhookInput) {
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(todo.Webhook{},
+					&todo.ErrInvalidWebhookInput{Reason: "url must be an absolute http(s) url"})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid webhook input: url must be an absolute http(s) url"}`,
+		},
+		{
+			name:      "Service failure",
+			inputBody: `{"url":"https://example.com/hook","events":["*"]}`,
+			inputWebhook: todo.WebhookInput{
+				URL:    "https://example.com/hook",
+				Events: []string{"*"},
+			},
+			mockBehavior: func(s *mock_service.MockWebhook, input todo.WebhookInput) {
+				s.EXPECT().Create(gomock.Any(), 1, input).Return(todo.Webhook{},
+					errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhook := mock_service.NewMockWebhook(c)
+			testCase.mockBehavior(webhook, testCase.inputWebhook)
+
+			services := &service.Service{Webhook: webhook}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/webhooks/", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.createWebhook)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/webhooks/",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_updateWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockWebhook, id int,
+		input todo.UpdateWebhookInput)
+
+	activeBool := true
+	testTable := []struct {
+		name             string
+		inputId          any
+		inputBody        string
+		inputUpdate      todo.UpdateWebhookInput
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:        "OK",
+			inputId:     1,
+			inputBody:   `{"active":true}`,
+			inputUpdate: todo.UpdateWebhookInput{Active: &activeBool},
+			mockBehavior: func(s *mock_service.MockWebhook, id int,
+				input todo.UpdateWebhookInput) {
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"status":"ok"}`,
+		},
+		{
+			name:        "Invalid id",
+			inputId:     "asd",
+			inputBody:   `{"active":true}`,
+			inputUpdate: todo.UpdateWebhookInput{Active: &activeBool},
+			mockBehavior: func(s *mock_service.MockWebhook, id int,
+				input todo.UpdateWebhookInput) {
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid webhook id"}`,
+		},
+		{
+			name:        "No webhook with such id",
+			inputId:     10,
+			inputBody:   `{"active":true}`,
+			inputUpdate: todo.UpdateWebhookInput{Active: &activeBool},
+			mockBehavior: func(s *mock_service.MockWebhook, id int,
+				input todo.UpdateWebhookInput) {
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(&todo.ErrNoSuchWebhook{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No webhook with such id"}`,
+		},
+		{
+			name:        "Empty update",
+			inputId:     1,
+			inputBody:   `{}`,
+			inputUpdate: todo.UpdateWebhookInput{},
+			mockBehavior: func(s *mock_service.MockWebhook, id int,
+				input todo.UpdateWebhookInput) {
+				s.EXPECT().Update(gomock.Any(), 1, id, input).Return(
+					&todo.ErrInvalidWebhookInput{Reason: "nothing to update"})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid webhook input: nothing to update"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhook := mock_service.NewMockWebhook(c)
+			if id, ok := testCase.inputId.(int); ok {
+				testCase.mockBehavior(webhook, id, testCase.inputUpdate)
+			}
+
+			services := &service.Service{Webhook: webhook}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.PUT("/api/webhooks/:id", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.updateWebhook)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT",
+				fmt.Sprintf("/api/webhooks/%v", testCase.inputId),
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_testWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockWebhook, id int)
+
+	testTable := []struct {
+		name             string
+		inputId          any
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:    "OK",
+			inputId: 1,
+			mockBehavior: func(s *mock_service.MockWebhook, id int) {
+				s.EXPECT().SendTest(gomock.Any(), 1, id).Return(todo.WebhookDelivery{
+					Id:             3,
+					WebhookId:      id,
+					EventType:      "ping",
+					Payload:        "{}",
+					Status:         "succeeded",
+					Attempts:       1,
+					ResponseStatus: 204,
+				}, nil)
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"id":3,"webhook_id":1,"event_type":"ping","payload":"{}","status":"succeeded","attempts":1,"next_attempt_at":"0001-01-01T00:00:00Z","response_status":204,"error":"","created_at":"0001-01-01T00:00:00Z","delivered_at":null}`,
+		},
+		{
+			name:    "No webhook with such id",
+			inputId: 10,
+			mockBehavior: func(s *mock_service.MockWebhook, id int) {
+				s.EXPECT().SendTest(gomock.Any(), 1, id).Return(todo.WebhookDelivery{},
+					&todo.ErrNoSuchWebhook{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No webhook with such id"}`,
+		},
+		{
+			name:    "Service failure",
+			inputId: 1,
+			mockBehavior: func(s *mock_service.MockWebhook, id int) {
+				s.EXPECT().SendTest(gomock.Any(), 1, id).Return(todo.WebhookDelivery{},
+					errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhook := mock_service.NewMockWebhook(c)
+			if id, ok := testCase.inputId.(int); ok {
+				testCase.mockBehavior(webhook, id)
+			}
+
+			services := &service.Service{Webhook: webhook}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.POST("/api/webhooks/:id/test", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.testWebhook)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST",
+				fmt.Sprintf("/api/webhooks/%v/test", testCase.inputId), nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.expectedStatus, w.Code)
+			assert.Equal(t, testCase.expectedResponse, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func workflowErrorStatus(err error) int {
+	switch err.(type) {
+	case *todo.ErrNoSuchList:
+		return http.StatusOK
+	case *todo.ErrInvalidWorkflowInput:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+type getListStatusesResponse struct {
+	Data todo.Workflow `json:"data"`
+}
+
+func (h *Handler) getListStatuses(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid list id")
+		return
+	}
+
+	statuses, err := h.services.Workflow.GetStatuses(c.Request.Context(), userId, listId)
+	if err != nil {
+		newErrorResponse(c, workflowErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getListStatusesResponse{
+		Data: statuses,
+	})
+}
+
+// setListStatuses replaces the workflow of the list, an empty one turning
+// the list back into a plain todo list.
+func (h *Handler) setListStatuses(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid list id")
+		return
+	}
+
+	var input todo.WorkflowInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	statuses, err := h.services.Workflow.SetStatuses(c.Request.Context(), userId, listId, input)
+	if err != nil {
+		newErrorResponse(c, workflowErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, getListStatusesResponse{
+		Data: statuses,
+	})
+}
+
+func (h *Handler) getListBoard(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "Invalid list id")
+		return
+	}
+
+	board, err := h.services.Workflow.Board(c.Request.Context(), userId, listId)
+	if err != nil {
+		newErrorResponse(c, workflowErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/service"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+func TestHandler_setListStatuses(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockWorkflow, input todo.WorkflowInput)
+
+	testTable := []struct {
+		name             string
+		inputBody        string
+		inputWorkflow    todo.WorkflowInput
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"statuses":[{"name":"Todo","transitions":["Done"]},{"name":"Done","terminal":true}]}`,
+			inputWorkflow: todo.WorkflowInput{Statuses: []todo.StatusInput{
+				{Name: "Todo", Transitions: []string{"Done"}},
+				{Name: "Done", Terminal: true},
+			}},
+			mockBehavior: func(s *mock_service.MockWorkflow, input todo.WorkflowInput) {
+				s.EXPECT().SetStatuses(gomock.Any(), 1, 1, input).Return(todo.Workflow{
+					{Id: 1, ListId: 1, Name: "Todo", Transitions: todo.StatusNames{"Done"}},
+					{Id: 2, ListId: 1, Name: "Done", Position: 1, Terminal: true},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"data":[` +
+				`{"id":1,"list_id":1,"name":"Todo","position":0,"terminal":false,"transitions":["Done"]},` +
+				`{"id":2,"list_id":1,"name":"Done","position":1,"terminal":true,"transitions":null}]}`,
+		},
+		{
+			name:             "No status name",
+			inputBody:        `{"statuses":[{"terminal":true}]}`,
+			mockBehavior:     func(s *mock_service.MockWorkflow, input todo.WorkflowInput) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid request body"}`,
+		},
+		{
+			name:      "Invalid workflow",
+			inputBody: `{"statuses":[{"name":"Todo"},{"name":"Doing"}]}`,
+			inputWorkflow: todo.WorkflowInput{Statuses: []todo.StatusInput{
+				{Name: "Todo"},
+				{Name: "Doing"},
+			}},
+			mockBehavior: func(s *mock_service.MockWorkflow, input todo.WorkflowInput) {
+				s.EXPECT().SetStatuses(gomock.Any(), 1, 1, input).Return(nil, &todo.ErrInvalidWorkflowInput{
+					Reason: "exactly one status must be terminal",
+				})
+			},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid workflow input: exactly one status must be terminal"}`,
+		},
+		{
+			name:          "No such list",
+			inputBody:     `{"statuses":[]}`,
+			inputWorkflow: todo.WorkflowInput{Statuses: []todo.StatusInput{}},
+			mockBehavior: func(s *mock_service.MockWorkflow, input todo.WorkflowInput) {
+				s.EXPECT().SetStatuses(gomock.Any(), 1, 1, input).Return(nil, &todo.ErrNoSuchList{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No list with such id"}`,
+		},
+		{
+			name:          "Service failure",
+			inputBody:     `{"statuses":[]}`,
+			inputWorkflow: todo.WorkflowInput{Statuses: []todo.StatusInput{}},
+			mockBehavior: func(s *mock_service.MockWorkflow, input todo.WorkflowInput) {
+				s.EXPECT().SetStatuses(gomock.Any(), 1, 1, input).Return(nil, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			workflow := mock_service.NewMockWorkflow(c)
+			testCase.mockBehavior(workflow, testCase.inputWorkflow)
+
+			services := &service.Service{Workflow: workflow}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.PUT("/api/lists/:id/statuses", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.setListStatuses)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/lists/1/statuses",
+				bytes.NewBufferString(testCase.inputBody))
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
+
+func TestHandler_getListBoard(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockWorkflow, listId int)
+
+	testTable := []struct {
+		name             string
+		inputListId      any
+		mockBehavior     mockBehavior
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:        "OK",
+			inputListId: 1,
+			mockBehavior: func(s *mock_service.MockWorkflow, listId int) {
+				s.EXPECT().Board(gomock.Any(), 1, listId).Return(todo.Board{
+					ListId: listId,
+					Columns: []todo.BoardColumn{
+						{
+							Status: todo.ListStatus{Name: "Open"},
+							Items:  []todo.TodoItem{{Id: 2, Title: "Plan", Status: "Open"}},
+						},
+						{
+							Status: todo.ListStatus{Name: "Done", Terminal: true},
+							Items:  []todo.TodoItem{},
+						},
+					},
+				}, nil)
+			},
+			expectedStatus: 200,
+			expectedResponse: `{"list_id":1,"columns":[` +
+				`{"status":{"id":0,"list_id":0,"name":"Open","position":0,"terminal":false,"transitions":null},` +
+				`"items":[{"id":2,"title":"Plan","description":"","done":false,"status":"Open"}]},` +
+				`{"status":{"id":0,"list_id":0,"name":"Done","position":0,"terminal":true,"transitions":null},` +
+				`"items":[]}]}`,
+		},
+		{
+			name:             "Invalid list id",
+			inputListId:      "a",
+			mockBehavior:     func(s *mock_service.MockWorkflow, listId int) {},
+			expectedStatus:   400,
+			expectedResponse: `{"message":"Invalid list id"}`,
+		},
+		{
+			name:        "No such list",
+			inputListId: 2,
+			mockBehavior: func(s *mock_service.MockWorkflow, listId int) {
+				s.EXPECT().Board(gomock.Any(), 1, listId).Return(todo.Board{}, &todo.ErrNoSuchList{})
+			},
+			expectedStatus:   200,
+			expectedResponse: `{"message":"No list with such id"}`,
+		},
+		{
+			name:        "Service failure",
+			inputListId: 1,
+			mockBehavior: func(s *mock_service.MockWorkflow, listId int) {
+				s.EXPECT().Board(gomock.Any(), 1, listId).Return(todo.Board{}, errors.New("Service failure"))
+			},
+			expectedStatus:   500,
+			expectedResponse: `{"message":"Service failure"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			workflow := mock_service.NewMockWorkflow(c)
+			if listId, ok := testCase.inputListId.(int); ok {
+				testCase.mockBehavior(workflow, listId)
+			}
+
+			services := &service.Service{Workflow: workflow}
+			handler := NewHandler(services)
+
+			r := gin.New()
+			r.GET("/api/lists/:id/board", func(c *gin.Context) {
+				c.Set(userCtx, 1)
+			}, handler.getListBoard)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET",
+				fmt.Sprintf("/api/lists/%v/board", testCase.inputListId), nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, testCase.expectedStatus)
+			assert.Equal(t, w.Body.String(), testCase.expectedResponse)
+		})
+	}
+}
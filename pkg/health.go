@@ -0,0 +1,18 @@
+package todo
+
+// BuildInfo describes the build of the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version"`
+}
+
+type ErrNotReady struct {
+	Reason string
+}
+
+func (e *ErrNotReady) Error() string {
+	return "Not ready: " + e.Reason
+}
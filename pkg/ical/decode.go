@@ -0,0 +1,271 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateFormat = "20060102"
+
+var ErrNoCalendar = errors.New("ical: no VCALENDAR component")
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode parses the VTODO components of a calendar. Other components,
+// including the ones nested into VTODO such as VALARM, and unknown properties
+// are skipped.
+func Decode(r io.Reader) (Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var (
+		calendar Calendar
+		todo     *Todo
+		stack    []string
+		found    bool
+	)
+	for i, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			return Calendar{}, fmt.Errorf("ical: line %d: %w", i+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 && component != "VCALENDAR" {
+				return Calendar{}, ErrNoCalendar
+			}
+			stack = append(stack, component)
+			if len(stack) == 2 && component == "VTODO" {
+				todo = &Todo{}
+			}
+			found = true
+			continue
+		case "END":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 || stack[len(stack)-1] != component {
+				return Calendar{}, fmt.Errorf("ical: line %d: unexpected END:%s", i+1, prop.value)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 1 && component == "VTODO" {
+				calendar.Todos = append(calendar.Todos, *todo)
+				todo = nil
+			}
+			continue
+		}
+
+		switch {
+		case len(stack) == 1:
+			err = calendar.setProperty(prop)
+		case len(stack) == 2 && todo != nil:
+			err = todo.setProperty(prop)
+		}
+		if err != nil {
+			return Calendar{}, fmt.Errorf("ical: line %d: %w", i+1, err)
+		}
+	}
+
+	if !found {
+		return Calendar{}, ErrNoCalendar
+	}
+	if len(stack) != 0 {
+		return Calendar{}, fmt.Errorf("ical: unterminated %s", stack[len(stack)-1])
+	}
+
+	return calendar, nil
+}
+
+func (c *Calendar) setProperty(prop property) error {
+	switch prop.name {
+	case "PRODID":
+		c.ProdId = UnescapeText(prop.value)
+	case "X-WR-CALNAME":
+		c.Name = UnescapeText(prop.value)
+	}
+	return nil
+}
+
+func (t *Todo) setProperty(prop property) error {
+	switch prop.name {
+	case "UID":
+		t.UID = UnescapeText(prop.value)
+	case "SUMMARY":
+		t.Summary = UnescapeText(prop.value)
+	case "DESCRIPTION":
+		t.Description = UnescapeText(prop.value)
+	case "STATUS":
+		t.Completed = strings.EqualFold(prop.value, StatusCompleted)
+	case "COMPLETED":
+		t.Completed = true
+	case "PRIORITY":
+		priority, err := strconv.Atoi(prop.value)
+		if err != nil || priority < 0 || priority > 9 {
+			return fmt.Errorf("invalid PRIORITY %q", prop.value)
+		}
+		t.Priority = priority
+	case "DUE":
+		due, err := ParseDateTime(prop.value, prop.params)
+		if err != nil {
+			return err
+		}
+		t.Due = &due
+	case "DTSTAMP":
+		stamp, err := ParseDateTime(prop.value, prop.params)
+		if err != nil {
+			return err
+		}
+		t.Stamp = stamp
+	case "LAST-MODIFIED":
+		modified, err := ParseDateTime(prop.value, prop.params)
+		if err != nil {
+			return err
+		}
+		t.LastModified = &modified
+	}
+	return nil
+}
+
+// unfold reads content lines, joining the folded ones. Bare LF line endings
+// are accepted as well.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+				lines[len(lines)-1] += line[1:]
+			} else {
+				lines = append(lines, line)
+			}
+		}
+
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func parseProperty(line string) (property, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return property{}, errors.New("malformed content line")
+	}
+
+	prop := property{name: strings.ToUpper(line[:end])}
+	line = line[end:]
+
+	for line[0] == ';' {
+		line = line[1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return property{}, errors.New("malformed parameter")
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			quote := strings.IndexByte(line[1:], '"')
+			if quote < 0 {
+				return property{}, errors.New("unterminated parameter value")
+			}
+			value = line[1 : quote+1]
+			line = line[quote+2:]
+		} else {
+			end := strings.IndexAny(line, ";:")
+			if end < 0 {
+				return property{}, errors.New("malformed parameter")
+			}
+			value = line[:end]
+			line = line[end:]
+		}
+
+		if prop.params == nil {
+			prop.params = make(map[string]string)
+		}
+		prop.params[name] = value
+
+		if line == "" {
+			return property{}, errors.New("malformed content line")
+		}
+	}
+
+	if line[0] != ':' {
+		return property{}, errors.New("malformed content line")
+	}
+	prop.value = line[1:]
+
+	return prop, nil
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// ParseDateTime parses a DATE or DATE-TIME value. Local times are resolved
+// with the TZID parameter when it names a known location and are treated as
+// UTC otherwise.
+func ParseDateTime(value string, params map[string]string) (time.Time, error) {
+	loc := time.UTC
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len(dateFormat):
+		t, err = time.ParseInLocation(dateFormat, value, loc)
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse(dateTimeFormat, value)
+	default:
+		t, err = time.ParseInLocation(strings.TrimSuffix(dateTimeFormat, "Z"), value, loc)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	return t.UTC(), nil
+}
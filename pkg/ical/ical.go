@@ -0,0 +1,149 @@
+// Package ical renders and parses todo items as RFC 5545 VTODO components.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	StatusNeedsAction = "NEEDS-ACTION"
+	StatusCompleted   = "COMPLETED"
+
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+type Todo struct {
+	UID          string
+	Summary      string
+	Description  string
+	Completed    bool
+	Due          *time.Time
+	Priority     int
+	Stamp        time.Time
+	LastModified *time.Time
+}
+
+type Calendar struct {
+	ProdId string
+	Name   string
+	Todos  []Todo
+}
+
+// Encode writes the calendar with escaped values and folded lines.
+func (c Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", EscapeText(c.ProdId))
+	e.line("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", EscapeText(c.Name))
+	}
+	for _, todo := range c.Todos {
+		todo.encode(e)
+	}
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return bw.Flush()
+}
+
+func (t Todo) encode(e *encoder) {
+	e.line("BEGIN", "VTODO")
+	e.line("UID", EscapeText(t.UID))
+	e.line("DTSTAMP", FormatDateTime(t.Stamp))
+	if t.LastModified != nil {
+		e.line("LAST-MODIFIED", FormatDateTime(*t.LastModified))
+	}
+	e.line("SUMMARY", EscapeText(t.Summary))
+	if t.Description != "" {
+		e.line("DESCRIPTION", EscapeText(t.Description))
+	}
+	if t.Completed {
+		e.line("STATUS", StatusCompleted)
+	} else {
+		e.line("STATUS", StatusNeedsAction)
+	}
+	if t.Due != nil {
+		e.line("DUE", FormatDateTime(*t.Due))
+	}
+	if t.Priority > 0 {
+		e.line("PRIORITY", strconv.Itoa(t.Priority))
+	}
+	e.line("END", "VTODO")
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.WriteString(FoldLine(name+":"+value) + "\r\n")
+}
+
+// EscapeText escapes a TEXT value as described in RFC 5545 section 3.3.11.
+func EscapeText(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', ';', ',':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			// CRLF is written as a single \n
+			if i+1 < len(s) && s[i+1] == '\n' {
+				continue
+			}
+			sb.WriteString(`\n`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// FoldLine splits a content line into lines of at most 75 octets, without
+// breaking multi-byte characters. Continuation lines start with a space.
+func FoldLine(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+
+	var sb strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	sb.WriteString(line)
+	return sb.String()
+}
+
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
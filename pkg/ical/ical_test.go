@@ -0,0 +1,250 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestEscapeText(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Plain", "Buy milk", "Buy milk"},
+		{"Comma and semicolon", "a,b;c", `a\,b\;c`},
+		{"Backslash", `C:\temp`, `C:\\temp`},
+		{"Newline", "line1\nline2", `line1\nline2`},
+		{"CRLF", "line1\r\nline2", `line1\nline2`},
+		{"Colon is kept", "Note: x", "Note: x"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, EscapeText(testCase.input))
+		})
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Short",
+			input:    "SUMMARY:Test",
+			expected: "SUMMARY:Test",
+		},
+		{
+			name:     "Exactly 75",
+			input:    strings.Repeat("a", 75),
+			expected: strings.Repeat("a", 75),
+		},
+		{
+			name:     "Long",
+			input:    strings.Repeat("a", 160),
+			expected: strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n " + strings.Repeat("a", 11),
+		},
+		{
+			name:     "Multi-byte",
+			input:    strings.Repeat("a", 74) + "ёж",
+			expected: strings.Repeat("a", 74) + "\r\n ёж",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			folded := FoldLine(testCase.input)
+			assert.Equal(t, testCase.expected, folded)
+			for _, line := range strings.Split(folded, "\r\n") {
+				assert.Equal(t, true, len(line) <= 75)
+			}
+		})
+	}
+}
+
+func TestCalendar_Encode(t *testing.T) {
+	stamp := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2023, 10, 2, 9, 30, 0, 0, time.FixedZone("UTC+3", 3*60*60))
+
+	calendar := Calendar{
+		ProdId: "-//OrIX219//todo//EN",
+		Name:   "Home, sweet home",
+		Todos: []Todo{
+			{
+				UID:         "item-1@todo",
+				Summary:     "Pay rent",
+				Description: "Landlord; card",
+				Due:         &due,
+				Priority:    1,
+				Stamp:       stamp,
+			},
+			{
+				UID:       "item-2@todo",
+				Summary:   "Done thing",
+				Completed: true,
+				Stamp:     stamp,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, calendar.Encode(&buf))
+	assert.Equal(t, "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:-//OrIX219//todo//EN\r\n"+
+		"CALSCALE:GREGORIAN\r\n"+
+		"X-WR-CALNAME:Home\\, sweet home\r\n"+
+		"BEGIN:VTODO\r\n"+
+		"UID:item-1@todo\r\n"+
+		"DTSTAMP:20231001T120000Z\r\n"+
+		"SUMMARY:Pay rent\r\n"+
+		"DESCRIPTION:Landlord\\; card\r\n"+
+		"STATUS:NEEDS-ACTION\r\n"+
+		"DUE:20231002T063000Z\r\n"+
+		"PRIORITY:1\r\n"+
+		"END:VTODO\r\n"+
+		"BEGIN:VTODO\r\n"+
+		"UID:item-2@todo\r\n"+
+		"DTSTAMP:20231001T120000Z\r\n"+
+		"SUMMARY:Done thing\r\n"+
+		"STATUS:COMPLETED\r\n"+
+		"END:VTODO\r\n"+
+		"END:VCALENDAR\r\n", buf.String())
+}
+
+func TestDecode(t *testing.T) {
+	due := time.Date(2023, 10, 2, 6, 30, 0, 0, time.UTC)
+	dueDate := time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC)
+	stamp := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		input         string
+		expected      Calendar
+		expectedError bool
+	}{
+		{
+			name: "OK",
+			input: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\n" +
+				"BEGIN:VTODO\r\nUID:abc@test\r\nDTSTAMP:20231001T120000Z\r\n" +
+				"SUMMARY:Pay rent\r\nDESCRIPTION:Landlord\\; card\\nsecond line\r\n" +
+				"STATUS:COMPLETED\r\nDUE:20231002T063000Z\r\nPRIORITY:1\r\n" +
+				"END:VTODO\r\nEND:VCALENDAR\r\n",
+			expected: Calendar{
+				ProdId: "-//Test//EN",
+				Todos: []Todo{
+					{
+						UID:         "abc@test",
+						Summary:     "Pay rent",
+						Description: "Landlord; card\nsecond line",
+						Completed:   true,
+						Due:         &due,
+						Priority:    1,
+						Stamp:       stamp,
+					},
+				},
+			},
+		},
+		{
+			name: "Folded lines and LF endings",
+			input: "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:abc@test\nSUMMARY:Very\n  long\n\t summary\n" +
+				"END:VTODO\nEND:VCALENDAR\n",
+			expected: Calendar{
+				Todos: []Todo{{UID: "abc@test", Summary: "Very long summary"}},
+			},
+		},
+		{
+			name: "TZID and DATE values",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VTIMEZONE\r\nTZID:Europe/Moscow\r\nEND:VTIMEZONE\r\n" +
+				"BEGIN:VTODO\r\nUID:a\r\nDUE;TZID=Europe/Moscow:20231002T093000\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:b\r\nDUE;VALUE=DATE:20231002\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+			expected: Calendar{
+				Todos: []Todo{{UID: "a", Due: &due}, {UID: "b", Due: &dueDate}},
+			},
+		},
+		{
+			name: "Nested components and quoted params",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:a\r\n" +
+				"ATTENDEE;CN=\"Doe; John\":mailto:john@example.com\r\n" +
+				"BEGIN:VALARM\r\nDESCRIPTION:Alarm\r\nEND:VALARM\r\nSUMMARY:Task\r\n" +
+				"COMPLETED:20231001T120000Z\r\nEND:VTODO\r\n" +
+				"BEGIN:VEVENT\r\nUID:event\r\nSUMMARY:Event\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			expected: Calendar{
+				Todos: []Todo{{UID: "a", Summary: "Task", Completed: true}},
+			},
+		},
+		{
+			name:          "No calendar",
+			input:         "BEGIN:VTODO\r\nEND:VTODO\r\n",
+			expectedError: true,
+		},
+		{
+			name:          "Empty",
+			input:         "",
+			expectedError: true,
+		},
+		{
+			name:          "Unterminated",
+			input:         "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:a\r\n",
+			expectedError: true,
+		},
+		{
+			name:          "Mismatched END",
+			input:         "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			expectedError: true,
+		},
+		{
+			name:          "Invalid DUE",
+			input:         "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nDUE:tomorrow\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			expectedError: true,
+		},
+		{
+			name:          "Malformed line",
+			input:         "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR\r\n",
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			calendar, err := Decode(strings.NewReader(testCase.input))
+			assert.Equal(t, testCase.expectedError, err != nil)
+			if !testCase.expectedError {
+				assert.Equal(t, testCase.expected, calendar)
+			}
+		})
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	due := time.Date(2023, 10, 2, 6, 30, 0, 0, time.UTC)
+	calendar := Calendar{
+		ProdId: "-//OrIX219//todo//EN",
+		Name:   "Home",
+		Todos: []Todo{
+			{
+				UID:         "item-1@todo",
+				Summary:     strings.Repeat("Long, summary; ", 10),
+				Description: "C:\\temp\nsecond line",
+				Due:         &due,
+				Priority:    5,
+				Stamp:       time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, calendar.Encode(&buf))
+
+	decoded, err := Decode(&buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, calendar, decoded)
+}
@@ -0,0 +1,30 @@
+package todo
+
+import "time"
+
+type IdempotencyRecord struct {
+	Id          int       `db:"id"`
+	UserId      int       `db:"user_id"`
+	Key         string    `db:"key"`
+	Fingerprint string    `db:"fingerprint"`
+	StatusCode  int       `db:"status_code"`
+	ContentType string    `db:"content_type"`
+	Response    []byte    `db:"response"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+type ErrIdempotencyKeyReused struct{}
+
+func (e *ErrIdempotencyKeyReused) Error() string {
+	return "Idempotency key was already used with a different request"
+}
+
+type ErrIdempotencyRequestInProgress struct{}
+
+func (e *ErrIdempotencyRequestInProgress) Error() string {
+	return "Request with this idempotency key is still in progress"
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", "warn")
+	assert.Equal(t, err, nil)
+
+	logger.Info("Dropped")
+	logger.Warn("Kept", "request_id", "abc")
+
+	var record map[string]any
+	assert.Equal(t, json.Unmarshal(buf.Bytes(), &record), nil)
+	assert.Equal(t, record["msg"], "Kept")
+	assert.Equal(t, record["request_id"], "abc")
+
+	buf.Reset()
+	logger, err = New(&buf, "TEXT", "debug")
+	assert.Equal(t, err, nil)
+	logger.Debug("Kept")
+	assert.Equal(t, strings.Contains(buf.String(), "msg=Kept"), true)
+
+	_, err = New(&buf, "xml", "info")
+	assert.NotEqual(t, err, nil)
+	_, err = New(&buf, "text", "loud")
+	assert.NotEqual(t, err, nil)
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, FromContext(context.Background()), slog.Default())
+
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	ctx := NewContext(context.Background(), logger)
+	assert.Equal(t, FromContext(ctx), logger)
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Default holds the metrics of the app.
+var Default = prometheus.NewRegistry()
+
+var factory = promauto.With(Default)
+
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by route template and status.",
+	}, []string{"method", "route", "status"})
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to handle HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	SignIns = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_sign_ins_total",
+		Help: "Sign-in attempts, by outcome: success, failure or error.",
+	}, []string{"outcome"})
+	TokenFailures = factory.NewCounter(prometheus.CounterOpts{
+		Name: "auth_token_failures_total",
+		Help: "Requests rejected for an invalid or expired token.",
+	})
+
+	ListsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "todo_lists_created_total",
+		Help: "Lists created.",
+	})
+	ItemsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "todo_items_created_total",
+		Help: "Items created.",
+	})
+	ItemsCompleted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "todo_items_completed_total",
+		Help: "Items marked as done.",
+	})
+)
+
+// Handler serves the metrics of the app.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
+
+// RegisterDBStats adds the connection pool stats of db to the metrics of
+// the app.
+func RegisterDBStats(db *sql.DB) {
+	stat := func(fn func(s sql.DBStats) float64) func() float64 {
+		return func() float64 {
+			return fn(db.Stats())
+		}
+	}
+	gauge := func(name, help string, fn func(s sql.DBStats) float64) {
+		factory.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, stat(fn))
+	}
+	counter := func(name, help string, fn func(s sql.DBStats) float64) {
+		factory.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, stat(fn))
+	}
+
+	gauge("db_max_open_connections", "Maximum number of open DB connections.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("db_open_connections", "Open DB connections, in use or idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("db_in_use_connections", "DB connections in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("db_idle_connections", "Idle DB connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("db_wait_count_total", "Times a DB connection had to be waited for.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("db_wait_duration_seconds_total", "Time spent waiting for DB connections.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("db_max_idle_closed_total", "DB connections closed for exceeding the idle limit.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("db_max_lifetime_closed_total", "DB connections closed for exceeding their lifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+type noConnector struct{}
+
+func (noConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("no connections")
+}
+
+func (noConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	db := sql.OpenDB(noConnector{})
+	db.SetMaxOpenConns(7)
+	RegisterDBStats(db)
+	SignIns.WithLabelValues("success").Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, strings.Contains(body, "db_max_open_connections 7\n"), true)
+	assert.Equal(t, strings.Contains(body, "db_open_connections 0\n"), true)
+	assert.Equal(t, strings.Contains(body, `auth_sign_ins_total{outcome="success"} 1`+"\n"), true)
+	assert.Equal(t, strings.Contains(body, "# TYPE db_wait_count_total counter\n"), true)
+}
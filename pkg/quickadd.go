@@ -0,0 +1,36 @@
+package todo
+
+type QuickAddInput struct {
+	Text string `json:"text" binding:"required,max=1000"`
+	// ListId is the list used when the text doesn't name one
+	ListId int `json:"list_id"`
+	// Timezone is the IANA name relative dates are resolved in, UTC by
+	// default
+	Timezone string `json:"timezone"`
+}
+
+type QuickAddPart struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// QuickAddOutput is the item created from the text and how the text was
+// understood. Recurrences aren't supported by items yet, so they are only
+// reported on a dry run, with the first occurrence as the due date.
+type QuickAddOutput struct {
+	Id         int            `json:"id,omitempty"`
+	DryRun     bool           `json:"dry_run"`
+	ListId     int            `json:"list_id"`
+	List       string         `json:"list"`
+	Item       TodoItem       `json:"item"`
+	Recurrence string         `json:"recurrence,omitempty"`
+	Parts      []QuickAddPart `json:"parts"`
+}
+
+type ErrInvalidQuickAdd struct {
+	Reason string
+}
+
+func (e *ErrInvalidQuickAdd) Error() string {
+	return "Invalid quick add: " + e.Reason
+}
@@ -0,0 +1,383 @@
+package quickadd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var numbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+var (
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a|p)?$`)
+	dayPattern      = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+	isoDatePattern  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	yearPattern     = regexp.MustCompile(`^\d{4}$`)
+	recurrenceUnits = map[string]string{
+		"day": "day", "days": "day",
+		"week": "week", "weeks": "week",
+		"month": "month", "months": "month",
+		"year": "year", "years": "year",
+	}
+)
+
+// matchRecurrence recognizes "every ..." phrases, which also set the first
+// occurrence as the date.
+func (p *parser) matchRecurrence(i int) int {
+	today := dateOf(p.now)
+
+	switch p.word(i) {
+	case "daily":
+		p.recur("every day", today, everyDays(1))
+		return 1
+	case "weekly":
+		p.recur("every week", today, everyDays(7))
+		return 1
+	case "monthly":
+		p.recur("every month", today, everyMonths(1))
+		return 1
+	case "yearly", "annually":
+		p.recur("every year", today, everyMonths(12))
+		return 1
+	case "every", "each":
+	default:
+		return 0
+	}
+
+	next := p.word(i + 1)
+	if weekday, ok := weekdays[next]; ok {
+		p.recur("every "+strings.ToLower(weekday.String()), upcoming(today, weekday), everyDays(7))
+		return 2
+	}
+	if next == "weekday" {
+		p.recur("every weekday", nextWeekday(today.addDays(-1)), nextWeekday)
+		return 2
+	}
+	if unit, ok := recurrenceUnits[next]; ok {
+		p.recur("every "+unit, today, every(1, unit))
+		return 2
+	}
+
+	if n, ok := count(next); ok && n > 1 {
+		if unit, ok := recurrenceUnits[p.word(i+2)]; ok {
+			p.recur("every "+strconv.Itoa(n)+" "+unit+"s", today, every(n, unit))
+			return 3
+		}
+	}
+	return 0
+}
+
+func every(n int, unit string) func(civilDate) civilDate {
+	switch unit {
+	case "week":
+		return everyDays(7 * n)
+	case "month":
+		return everyMonths(n)
+	case "year":
+		return everyMonths(12 * n)
+	}
+	return everyDays(n)
+}
+
+func everyDays(n int) func(civilDate) civilDate {
+	return func(d civilDate) civilDate { return d.addDays(n) }
+}
+
+func everyMonths(n int) func(civilDate) civilDate {
+	return func(d civilDate) civilDate { return d.addMonths(n) }
+}
+
+func nextWeekday(d civilDate) civilDate {
+	d = d.addDays(1)
+	for d.weekday() == time.Saturday || d.weekday() == time.Sunday {
+		d = d.addDays(1)
+	}
+	return d
+}
+
+// recur sets the recurrence and its first occurrence, next returns the one
+// after a given occurrence.
+func (p *parser) recur(recurrence string, first civilDate, next func(civilDate) civilDate) {
+	p.recurrence = recurrence
+	if p.date == nil && p.instant == nil {
+		p.date, p.next = &first, next
+	}
+}
+
+func (p *parser) matchDate(i int) int {
+	today := dateOf(p.now)
+	word := p.word(i)
+
+	set := func(d civilDate, n int) int {
+		p.date = &d
+		return n
+	}
+
+	switch word {
+	case "today":
+		return set(today, 1)
+	case "tonight":
+		p.tonight = true
+		return set(today, 1)
+	case "tomorrow", "tmrw", "tmr":
+		return set(today.addDays(1), 1)
+	case "on", "this":
+		if weekday, ok := weekdays[p.word(i+1)]; ok {
+			return set(upcoming(today, weekday), 2)
+		}
+		if word == "on" {
+			if d, n := p.matchCalendarDate(i+1, today); n > 0 {
+				return set(d, n+1)
+			}
+		}
+		return 0
+	case "next":
+		next := p.word(i + 1)
+		if weekday, ok := weekdays[next]; ok {
+			// the weekday of the next week, which starts on Monday
+			monday := upcoming(today.addDays(1), time.Monday)
+			return set(upcoming(monday, weekday), 2)
+		}
+		switch next {
+		case "week":
+			return set(upcoming(today.addDays(1), time.Monday), 2)
+		case "month":
+			return set(civilDate{year: today.year, month: today.month + 1, day: 1}.addDays(0), 2)
+		case "year":
+			return set(civilDate{year: today.year + 1, month: time.January, day: 1}, 2)
+		}
+		return 0
+	case "in":
+		return p.matchDuration(i, today)
+	}
+
+	if weekday, ok := weekdays[word]; ok {
+		return set(upcoming(today, weekday), 1)
+	}
+	if d, n := p.matchCalendarDate(i, today); n > 0 {
+		return set(d, n)
+	}
+	return 0
+}
+
+// matchDuration recognizes "in 3 days" or "in an hour". Minutes and hours
+// give the exact time.
+func (p *parser) matchDuration(i int, today civilDate) int {
+	n, ok := count(p.word(i + 1))
+	if !ok {
+		return 0
+	}
+
+	unit := p.word(i + 2)
+	switch strings.TrimSuffix(unit, "s") {
+	case "minute", "min":
+		instant := p.now.Add(time.Duration(n) * time.Minute)
+		p.instant = &instant
+	case "hour", "hr":
+		instant := p.now.Add(time.Duration(n) * time.Hour)
+		p.instant = &instant
+	case "day":
+		d := today.addDays(n)
+		p.date = &d
+	case "week":
+		d := today.addDays(7 * n)
+		p.date = &d
+	case "month":
+		d := today.addMonths(n)
+		p.date = &d
+	case "year":
+		d := today.addMonths(12 * n)
+		p.date = &d
+	default:
+		return 0
+	}
+	return 3
+}
+
+// matchCalendarDate recognizes ISO dates and month days in either order with
+// an optional year. Month days without a year are the next ones to come.
+func (p *parser) matchCalendarDate(i int, today civilDate) (civilDate, int) {
+	word := p.word(i)
+	if isoDatePattern.MatchString(word) {
+		t, err := time.Parse("2006-01-02", word)
+		if err != nil {
+			return civilDate{}, 0
+		}
+		return dateOf(t), 1
+	}
+
+	var month time.Month
+	var day int
+	if m, ok := months[word]; ok {
+		d, ok := parseDay(p.word(i + 1))
+		if !ok {
+			return civilDate{}, 0
+		}
+		month, day = m, d
+	} else if d, ok := parseDay(word); ok {
+		m, ok := months[p.word(i+1)]
+		if !ok {
+			return civilDate{}, 0
+		}
+		month, day = m, d
+	} else {
+		return civilDate{}, 0
+	}
+
+	if yearPattern.MatchString(p.word(i + 2)) {
+		year, _ := strconv.Atoi(p.word(i + 2))
+		return validDate(year, month, day), 3
+	}
+
+	d := validDate(today.year, month, day)
+	if d.before(today) {
+		d = validDate(today.year+1, month, day)
+	}
+	return d, 2
+}
+
+func (p *parser) matchClock(i int) int {
+	word := p.word(i)
+	set := func(hour, minute, n int) int {
+		p.clock = &clockTime{hour: hour, minute: minute}
+		return n
+	}
+
+	switch word {
+	case "noon", "midday":
+		return set(12, 0, 1)
+	case "midnight":
+		return set(0, 0, 1)
+	case "at":
+		if word := p.word(i + 1); word == "noon" || word == "midnight" {
+			return p.matchClock(i+1) + 1
+		}
+		if hour, minute, n, ok := p.parseClock(i+1, true); ok {
+			return set(hour, minute, n+1)
+		}
+		return 0
+	}
+
+	if hour, minute, n, ok := p.parseClock(i, false); ok {
+		return set(hour, minute, n)
+	}
+	return 0
+}
+
+// parseClock parses 9am, 9:30 pm or 21:00. A bare hour is only a time after
+// "at".
+func (p *parser) parseClock(i int, bare bool) (hour, minute, n int, ok bool) {
+	match := clockPattern.FindStringSubmatch(p.word(i))
+	if match == nil {
+		return 0, 0, 0, false
+	}
+
+	n = 1
+	meridiem := match[3]
+	if meridiem == "" {
+		if next := p.word(i + 1); next == "am" || next == "pm" {
+			meridiem, n = next, 2
+		}
+	}
+	if meridiem == "" && match[2] == "" && !bare {
+		return 0, 0, 0, false
+	}
+
+	hour, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	switch {
+	case minute > 59:
+		return 0, 0, 0, false
+	case meridiem == "":
+		if hour > 23 {
+			return 0, 0, 0, false
+		}
+	case hour < 1 || hour > 12:
+		return 0, 0, 0, false
+	case strings.HasPrefix(meridiem, "p") && hour != 12:
+		hour += 12
+	case strings.HasPrefix(meridiem, "a") && hour == 12:
+		hour = 0
+	}
+	return hour, minute, n, true
+}
+
+func count(word string) (int, bool) {
+	if n, ok := numbers[word]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(word)
+	return n, err == nil && n > 0 && n < 1000
+}
+
+func parseDay(word string) (int, bool) {
+	match := dayPattern.FindStringSubmatch(word)
+	if match == nil {
+		return 0, false
+	}
+	day, _ := strconv.Atoi(match[1])
+	return day, day >= 1 && day <= 31
+}
+
+// validDate moves days past the end of the month to its last day, so that
+// "feb 30" is still in February.
+func validDate(year int, month time.Month, day int) civilDate {
+	last := civilDate{year: year, month: month + 1, day: 0}.addDays(0)
+	if day > last.day {
+		day = last.day
+	}
+	return civilDate{year: year, month: month, day: day}
+}
+
+// addMonths keeps the day, unless the month is shorter.
+func (d civilDate) addMonths(n int) civilDate {
+	first := civilDate{year: d.year, month: d.month + time.Month(n), day: 1}.addDays(0)
+	return validDate(first.year, first.month, d.day)
+}
+
+// upcoming is the first day from d on that falls on the weekday.
+func upcoming(d civilDate, weekday time.Weekday) civilDate {
+	return d.addDays((int(weekday) - int(d.weekday()) + 7) % 7)
+}
+
+func (d civilDate) before(other civilDate) bool {
+	if d.year != other.year {
+		return d.year < other.year
+	}
+	if d.month != other.month {
+		return d.month < other.month
+	}
+	return d.day < other.day
+}
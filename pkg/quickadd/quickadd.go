@@ -0,0 +1,274 @@
+// Package quickadd parses one line descriptions of tasks, like
+// "Pay rent tomorrow 9am #finance !high @Household".
+//
+// Words starting with @ name the list, # marks tags and ! the priority
+// (!high, !medium, !low, !3 to !1, or !!! and !!). Due dates are relative English
+// phrases: today, tonight, tomorrow, weekdays with optional this, next or on,
+// next week or month, "in 3 days", month days like "oct 2" or "2nd october",
+// ISO dates and times like 9am, 9:30 pm, 21:00, noon or "at 17". Recurring
+// phrases like "every monday" or "daily" set the recurrence and the first
+// occurrence as the due date. Everything else is the title.
+package quickadd
+
+import (
+	"strings"
+	"time"
+)
+
+// Priorities match the ones of items.
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+const (
+	PartList       = "list"
+	PartTag        = "tag"
+	PartPriority   = "priority"
+	PartDue        = "due"
+	PartRecurrence = "recurrence"
+)
+
+// Part is a phrase of the text that was recognized as something else than
+// the title.
+type Part struct {
+	Kind string
+	Text string
+}
+
+type Result struct {
+	Title      string
+	List       string
+	Tags       []string
+	Priority   int
+	Due        *time.Time
+	Recurrence string
+	Parts      []Part
+}
+
+// Parse interprets text, resolving relative dates against now in its
+// location. Only the first list, priority, date and time are used, repeated
+// ones stay in the title.
+func Parse(text string, now time.Time) Result {
+	p := parser{words: split(text), now: now}
+	p.parse()
+	return p.result()
+}
+
+type parser struct {
+	words []string
+	now   time.Time
+
+	title      []string
+	list       string
+	tags       []string
+	priority   int
+	recurrence string
+	parts      []Part
+
+	date    *civilDate
+	clock   *clockTime
+	instant *time.Time
+	next    func(civilDate) civilDate
+	hasList bool
+	hasPrio bool
+	tonight bool
+}
+
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+type clockTime struct {
+	hour, minute int
+}
+
+func (p *parser) parse() {
+	for i := 0; i < len(p.words); {
+		if n := p.match(i); n > 0 {
+			i += n
+			continue
+		}
+		p.title = append(p.title, p.words[i])
+		i++
+	}
+}
+
+// match tries to recognize a part at the i-th word and returns the number of
+// words it takes.
+func (p *parser) match(i int) int {
+	word := p.words[i]
+
+	switch {
+	case len(word) > 1 && word[0] == '@' && !p.hasList:
+		p.list, p.hasList = unquote(word[1:]), true
+		p.add(PartList, word)
+		return 1
+	case len(word) > 1 && word[0] == '#':
+		tag := word[1:]
+		for _, t := range p.tags {
+			if strings.EqualFold(t, tag) {
+				p.add(PartTag, word)
+				return 1
+			}
+		}
+		p.tags = append(p.tags, tag)
+		p.add(PartTag, word)
+		return 1
+	case len(word) > 1 && word[0] == '!' && !p.hasPrio:
+		if priority, ok := parsePriority(word); ok {
+			p.priority, p.hasPrio = priority, true
+			p.add(PartPriority, word)
+			return 1
+		}
+		return 0
+	}
+
+	if p.recurrence == "" {
+		if n := p.matchRecurrence(i); n > 0 {
+			p.add(PartRecurrence, p.phrase(i, n))
+			return n
+		}
+	}
+	if p.date == nil && p.instant == nil {
+		if n := p.matchDate(i); n > 0 {
+			p.add(PartDue, p.phrase(i, n))
+			return n
+		}
+	}
+	if p.clock == nil && p.instant == nil {
+		if n := p.matchClock(i); n > 0 {
+			p.add(PartDue, p.phrase(i, n))
+			return n
+		}
+	}
+	return 0
+}
+
+func (p *parser) add(kind, text string) {
+	p.parts = append(p.parts, Part{Kind: kind, Text: text})
+}
+
+func (p *parser) phrase(i, n int) string {
+	return strings.Join(p.words[i:i+n], " ")
+}
+
+// word returns the i-th word lower cased and without trailing punctuation,
+// or "" past the end.
+func (p *parser) word(i int) string {
+	if i >= len(p.words) {
+		return ""
+	}
+	return strings.TrimRight(strings.ToLower(p.words[i]), ".,;")
+}
+
+func (p *parser) result() Result {
+	r := Result{
+		Title:      strings.Join(p.title, " "),
+		List:       p.list,
+		Tags:       p.tags,
+		Priority:   p.priority,
+		Recurrence: p.recurrence,
+		Parts:      p.parts,
+	}
+
+	switch {
+	case p.instant != nil:
+		r.Due = p.instant
+	case p.date != nil:
+		clock := p.clock
+		if clock == nil && p.tonight {
+			clock = &clockTime{hour: 20}
+		}
+		due := p.date.at(clock, p.now.Location())
+		// a recurrence whose time passed today starts with its next occurrence
+		if p.next != nil && clock != nil && due.Before(p.now) {
+			due = p.next(*p.date).at(clock, p.now.Location())
+		}
+		r.Due = &due
+	case p.clock != nil:
+		// a time alone is the next time it comes
+		today := dateOf(p.now)
+		due := today.at(p.clock, p.now.Location())
+		if due.Before(p.now) {
+			due = today.addDays(1).at(p.clock, p.now.Location())
+		}
+		r.Due = &due
+	}
+	return r
+}
+
+func parsePriority(word string) (int, bool) {
+	switch strings.ToLower(word[1:]) {
+	case "high", "h", "3", "!!":
+		return PriorityHigh, true
+	case "medium", "med", "m", "2", "!":
+		return PriorityMedium, true
+	case "low", "l", "1":
+		return PriorityLow, true
+	case "none", "0":
+		return PriorityNone, true
+	}
+	return 0, false
+}
+
+// split breaks text into words, keeping quoted lists like @"Home stuff"
+// together.
+func split(text string) []string {
+	var words []string
+	for len(text) > 0 {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if text == "" {
+			break
+		}
+
+		if strings.HasPrefix(text, `@"`) {
+			if end := strings.IndexByte(text[2:], '"'); end >= 0 {
+				words = append(words, text[:end+3])
+				text = text[end+3:]
+				continue
+			}
+		}
+
+		end := strings.IndexAny(text, " \t\r\n")
+		if end < 0 {
+			end = len(text)
+		}
+		words = append(words, text[:end])
+		text = text[end:]
+	}
+	return words
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func dateOf(t time.Time) civilDate {
+	year, month, day := t.Date()
+	return civilDate{year: year, month: month, day: day}
+}
+
+func (d civilDate) addDays(n int) civilDate {
+	return dateOf(time.Date(d.year, d.month, d.day+n, 0, 0, 0, 0, time.UTC))
+}
+
+func (d civilDate) weekday() time.Weekday {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC).Weekday()
+}
+
+// at is the start of the day, or the given time of it.
+func (d civilDate) at(clock *clockTime, loc *time.Location) time.Time {
+	var hour, minute int
+	if clock != nil {
+		hour, minute = clock.hour, clock.minute
+	}
+	return time.Date(d.year, d.month, d.day, hour, minute, 0, 0, loc)
+}
@@ -0,0 +1,273 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// now is Wednesday afternoon
+var now = time.Date(2023, 10, 18, 15, 0, 0, 0, time.UTC)
+
+func at(year int, month time.Month, day, hour, minute int) *time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	testTable := []struct {
+		name     string
+		input    string
+		expected Result
+	}{
+		{
+			name:  "Everything",
+			input: "Pay rent tomorrow 9am #finance !high @Household",
+			expected: Result{
+				Title:    "Pay rent",
+				List:     "Household",
+				Tags:     []string{"finance"},
+				Priority: PriorityHigh,
+				Due:      at(2023, 10, 19, 9, 0),
+				Parts: []Part{
+					{PartDue, "tomorrow"},
+					{PartDue, "9am"},
+					{PartTag, "#finance"},
+					{PartPriority, "!high"},
+					{PartList, "@Household"},
+				},
+			},
+		},
+		{
+			name:     "Title only",
+			input:    "  Call   mom ",
+			expected: Result{Title: "Call mom"},
+		},
+		{
+			name:  "Quoted list",
+			input: `@"Home stuff" Buy milk`,
+			expected: Result{
+				Title: "Buy milk",
+				List:  "Home stuff",
+				Parts: []Part{{PartList, `@"Home stuff"`}},
+			},
+		},
+		{
+			name:  "Second list stays in title",
+			input: "Email @Work @Home",
+			expected: Result{
+				Title: "Email @Home",
+				List:  "Work",
+				Parts: []Part{{PartList, "@Work"}},
+			},
+		},
+		{
+			name:  "Duplicate tags",
+			input: "Read #books #Books #fun",
+			expected: Result{
+				Title: "Read",
+				Tags:  []string{"books", "fun"},
+				Parts: []Part{{PartTag, "#books"}, {PartTag, "#Books"}, {PartTag, "#fun"}},
+			},
+		},
+		{
+			name:     "Lone markers",
+			input:    "Use @ and # and ! signs",
+			expected: Result{Title: "Use @ and # and ! signs"},
+		},
+		{
+			name:  "Misspelled priority stays in title",
+			input: "Fix bug !urgent",
+			expected: Result{
+				Title: "Fix bug !urgent",
+			},
+		},
+		{
+			name:  "Second date stays in title",
+			input: "Move meeting tomorrow to today",
+			expected: Result{
+				Title: "Move meeting to today",
+				Due:   at(2023, 10, 19, 0, 0),
+				Parts: []Part{{PartDue, "tomorrow"}},
+			},
+		},
+		{
+			name:  "Trailing punctuation",
+			input: "Call Bob tomorrow, then rest",
+			expected: Result{
+				Title: "Call Bob then rest",
+				Due:   at(2023, 10, 19, 0, 0),
+				Parts: []Part{{PartDue, "tomorrow,"}},
+			},
+		},
+		{
+			name:     "Numbers are not times",
+			input:    "Buy 2 apples in the shop",
+			expected: Result{Title: "Buy 2 apples in the shop"},
+		},
+		{
+			name:     "May is not always a month",
+			input:    "May the force be with you",
+			expected: Result{Title: "May the force be with you"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Parse(testCase.input, now))
+		})
+	}
+}
+
+func TestParse_Priority(t *testing.T) {
+	testTable := []struct {
+		input    string
+		expected int
+	}{
+		{"!high", PriorityHigh},
+		{"!H", PriorityHigh},
+		{"!3", PriorityHigh},
+		{"!!!", PriorityHigh},
+		{"!medium", PriorityMedium},
+		{"!med", PriorityMedium},
+		{"!2", PriorityMedium},
+		{"!!", PriorityMedium},
+		{"!low", PriorityLow},
+		{"!1", PriorityLow},
+		{"!none", PriorityNone},
+		{"!high !low", PriorityHigh},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.input, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Parse("Task "+testCase.input, now).Priority)
+		})
+	}
+}
+
+func TestParse_Due(t *testing.T) {
+	testTable := []struct {
+		input    string
+		expected *time.Time
+		parts    []string
+	}{
+		{"today", at(2023, 10, 18, 0, 0), []string{"today"}},
+		{"tonight", at(2023, 10, 18, 20, 0), []string{"tonight"}},
+		{"tonight 11pm", at(2023, 10, 18, 23, 0), []string{"tonight", "11pm"}},
+		{"Tomorrow", at(2023, 10, 19, 0, 0), []string{"Tomorrow"}},
+		{"tmrw", at(2023, 10, 19, 0, 0), []string{"tmrw"}},
+		{"friday", at(2023, 10, 20, 0, 0), []string{"friday"}},
+		{"wed", at(2023, 10, 18, 0, 0), []string{"wed"}},
+		{"tuesday", at(2023, 10, 24, 0, 0), []string{"tuesday"}},
+		{"this fri", at(2023, 10, 20, 0, 0), []string{"this fri"}},
+		{"on monday", at(2023, 10, 23, 0, 0), []string{"on monday"}},
+		{"next friday", at(2023, 10, 27, 0, 0), []string{"next friday"}},
+		{"next monday", at(2023, 10, 23, 0, 0), []string{"next monday"}},
+		{"next week", at(2023, 10, 23, 0, 0), []string{"next week"}},
+		{"next month", at(2023, 11, 1, 0, 0), []string{"next month"}},
+		{"next year", at(2024, 1, 1, 0, 0), []string{"next year"}},
+		{"in 3 days", at(2023, 10, 21, 0, 0), []string{"in 3 days"}},
+		{"in a day", at(2023, 10, 19, 0, 0), []string{"in a day"}},
+		{"in two weeks", at(2023, 11, 1, 0, 0), []string{"in two weeks"}},
+		{"in 1 month", at(2023, 11, 18, 0, 0), []string{"in 1 month"}},
+		{"in 2 years", at(2025, 10, 18, 0, 0), []string{"in 2 years"}},
+		{"in an hour", at(2023, 10, 18, 16, 0), []string{"in an hour"}},
+		{"in 30 mins", at(2023, 10, 18, 15, 30), []string{"in 30 mins"}},
+		{"in 2 hours 9am", at(2023, 10, 18, 17, 0), []string{"in 2 hours"}},
+		{"oct 25 3pm", at(2023, 10, 25, 15, 0), []string{"oct 25", "3pm"}},
+		{"October 25th", at(2023, 10, 25, 0, 0), []string{"October 25th"}},
+		{"2nd november", at(2023, 11, 2, 0, 0), []string{"2nd november"}},
+		{"apr 15", at(2024, 4, 15, 0, 0), []string{"apr 15"}},
+		{"jan 5 2025", at(2025, 1, 5, 0, 0), []string{"jan 5 2025"}},
+		{"feb 30", at(2024, 2, 29, 0, 0), []string{"feb 30"}},
+		{"on dec 24", at(2023, 12, 24, 0, 0), []string{"on dec 24"}},
+		{"2023-12-01", at(2023, 12, 1, 0, 0), []string{"2023-12-01"}},
+		{"9pm", at(2023, 10, 18, 21, 0), []string{"9pm"}},
+		{"9:30 pm", at(2023, 10, 18, 21, 30), []string{"9:30 pm"}},
+		{"21:00", at(2023, 10, 18, 21, 0), []string{"21:00"}},
+		{"at 17", at(2023, 10, 18, 17, 0), []string{"at 17"}},
+		{"at 5:15am", at(2023, 10, 19, 5, 15), []string{"at 5:15am"}},
+		{"9am", at(2023, 10, 19, 9, 0), []string{"9am"}},
+		{"at noon", at(2023, 10, 19, 12, 0), []string{"at noon"}},
+		{"midnight", at(2023, 10, 19, 0, 0), []string{"midnight"}},
+		{"12am friday", at(2023, 10, 20, 0, 0), []string{"12am", "friday"}},
+		{"12pm friday", at(2023, 10, 20, 12, 0), []string{"12pm", "friday"}},
+		{"13pm", nil, nil},
+		{"25:00", nil, nil},
+		{"at", nil, nil},
+		{"2023-02-30", nil, nil},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.input, func(t *testing.T) {
+			result := Parse(testCase.input, now)
+			assert.Equal(t, testCase.expected, result.Due)
+
+			var parts []string
+			for _, part := range result.Parts {
+				assert.Equal(t, PartDue, part.Kind)
+				parts = append(parts, part.Text)
+			}
+			assert.Equal(t, testCase.parts, parts)
+		})
+	}
+}
+
+func TestParse_Recurrence(t *testing.T) {
+	testTable := []struct {
+		input              string
+		expectedRecurrence string
+		expectedDue        *time.Time
+	}{
+		{"every monday", "every monday", at(2023, 10, 23, 0, 0)},
+		{"every wednesday", "every wednesday", at(2023, 10, 18, 0, 0)},
+		{"every wednesday 9am", "every wednesday", at(2023, 10, 25, 9, 0)},
+		{"every wednesday 6pm", "every wednesday", at(2023, 10, 18, 18, 0)},
+		{"every day", "every day", at(2023, 10, 18, 0, 0)},
+		{"daily 8am", "every day", at(2023, 10, 19, 8, 0)},
+		{"each week", "every week", at(2023, 10, 18, 0, 0)},
+		{"weekly 9am", "every week", at(2023, 10, 25, 9, 0)},
+		{"monthly 9am", "every month", at(2023, 11, 18, 9, 0)},
+		{"every 2 weeks", "every 2 weeks", at(2023, 10, 18, 0, 0)},
+		{"every 3 days 1pm", "every 3 days", at(2023, 10, 21, 13, 0)},
+		{"every weekday", "every weekday", at(2023, 10, 18, 0, 0)},
+		{"yearly", "every year", at(2023, 10, 18, 0, 0)},
+		{"every friday next week", "every friday", at(2023, 10, 20, 0, 0)},
+		{"next week every friday", "every friday", at(2023, 10, 23, 0, 0)},
+		{"every", "", nil},
+		{"every 1 days", "", nil},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.input, func(t *testing.T) {
+			result := Parse(testCase.input, now)
+			assert.Equal(t, testCase.expectedRecurrence, result.Recurrence)
+			assert.Equal(t, testCase.expectedDue, result.Due)
+		})
+	}
+}
+
+func TestParse_Weekend(t *testing.T) {
+	saturday := time.Date(2023, 10, 21, 10, 0, 0, 0, time.UTC)
+
+	result := Parse("Standup every weekday 9am", saturday)
+	assert.Equal(t, "every weekday", result.Recurrence)
+	assert.Equal(t, at(2023, 10, 23, 9, 0), result.Due)
+
+	result = Parse("Plan next saturday", saturday)
+	assert.Equal(t, at(2023, 10, 28, 0, 0), result.Due)
+
+	result = Parse("Rest sunday", saturday)
+	assert.Equal(t, at(2023, 10, 22, 0, 0), result.Due)
+}
+
+func TestParse_Location(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// late evening in UTC is already tomorrow in Moscow
+	local := time.Date(2023, 10, 18, 22, 30, 0, 0, time.UTC).In(moscow)
+
+	due := Parse("Call tomorrow 10am", local).Due
+	expected := time.Date(2023, 10, 20, 10, 0, 0, 0, moscow)
+	assert.Equal(t, &expected, due)
+}
@@ -0,0 +1,71 @@
+package todo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route groups limited separately.
+const (
+	RateLimitAuth = "auth"
+	RateLimitAPI  = "api"
+	RateLimitDAV  = "dav"
+)
+
+// RateLimit is a token bucket holding Requests tokens, refilled at Requests
+// per Period. The zero value doesn't limit.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseRateLimit reads limits like 10/1m or 100/h, an empty one or 0
+// doesn't limit.
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == "" || s == "0" {
+		return RateLimit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimit{}, errors.New("rate limit " + s + " isn't requests/period")
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return RateLimit{}, errors.New("rate limit " + s + " has invalid requests")
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, errors.New("rate limit " + s + " has invalid period")
+	}
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+func (l RateLimit) Unlimited() bool {
+	return l.Requests == 0
+}
+
+// Rate is the tokens added to the bucket every second.
+func (l RateLimit) Rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+func (l RateLimit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Period.String()
+}
+
+// RateLimitStatus is what's left of a bucket after taking a token from it.
+type RateLimitStatus struct {
+	Limit     RateLimit
+	Allowed   bool
+	Remaining int
+	// Reset is when the bucket is full again and RetryAfter when the next
+	// request is allowed.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// attachmentQuotaLock is the class of the advisory locks serializing the
+// uploads of a user, so that they can't together go over the quota.
+const attachmentQuotaLock = 41
+
+const attachmentColumns = "a.id, a.item_id, a.user_id, a.name, a.content_type, a.size, a.blob_key, a.created_at"
+
+type AttachmentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentPostgres(db *sqlx.DB) *AttachmentPostgres {
+	return &AttachmentPostgres{db: db}
+}
+
+// Create adds the attachment unless it would take the attachments of its
+// user over quota bytes.
+func (r *AttachmentPostgres) Create(ctx context.Context, attachment todo.Attachment, quota int64) (todo.Attachment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return attachment, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", attachmentQuotaLock, attachment.UserId); err != nil {
+		return attachment, err
+	}
+
+	used, err := attachmentUsage(ctx, tx, attachment.UserId)
+	if err != nil {
+		return attachment, err
+	}
+	if used+attachment.Size > quota {
+		return attachment, &todo.ErrQuotaExceeded{Quota: quota}
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (item_id, user_id, name, content_type, size, blob_key)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`, attachmentsTable)
+	row := tx.QueryRowContext(ctx, query, attachment.ItemId, attachment.UserId, attachment.Name,
+		attachment.ContentType, attachment.Size, attachment.Key)
+	if err := row.Scan(&attachment.Id, &attachment.CreatedAt); err != nil {
+		return attachment, err
+	}
+
+	return attachment, tx.Commit()
+}
+
+func (r *AttachmentPostgres) GetAll(ctx context.Context, itemId int) ([]todo.Attachment, error) {
+	attachments := make([]todo.Attachment, 0)
+	query := fmt.Sprintf("SELECT %s FROM %s a WHERE a.item_id=$1 ORDER BY a.id",
+		attachmentColumns, attachmentsTable)
+	err := r.db.SelectContext(ctx, &attachments, query, itemId)
+	return attachments, err
+}
+
+// GetById returns the attachment of the item if the user is a member of a
+// list the item is in.
+func (r *AttachmentPostgres) GetById(ctx context.Context, userId, itemId, attachmentId int) (todo.Attachment, error) {
+	var attachment todo.Attachment
+	query := fmt.Sprintf(`SELECT %s FROM %s a WHERE a.id=$1 AND a.item_id=$2 AND EXISTS
+		(SELECT 1 FROM %s li INNER JOIN %s ul ON ul.list_id=li.list_id
+			WHERE li.item_id=a.item_id AND ul.user_id=$3)`,
+		attachmentColumns, attachmentsTable, listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &attachment, query, attachmentId, itemId, userId)
+
+	if err == sql.ErrNoRows {
+		return attachment, &todo.ErrNoSuchAttachment{}
+	}
+
+	return attachment, err
+}
+
+// Delete removes the attachment, leaving its blob to be removed with the
+// other orphans.
+func (r *AttachmentPostgres) Delete(ctx context.Context, attachmentId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", attachmentsTable)
+	_, err := r.db.ExecContext(ctx, query, attachmentId)
+	return err
+}
+
+// Usage returns the bytes the attachments the user uploaded take.
+func (r *AttachmentPostgres) Usage(ctx context.Context, userId int) (int64, error) {
+	return attachmentUsage(ctx, r.db, userId)
+}
+
+func attachmentUsage(ctx context.Context, q querier, userId int) (int64, error) {
+	var used int64
+	query := fmt.Sprintf("SELECT COALESCE(SUM(size), 0) FROM %s WHERE user_id=$1", attachmentsTable)
+	err := q.GetContext(ctx, &used, query, userId)
+	return used, err
+}
+
+// GetOrphans returns the keys of up to limit blobs whose attachments were
+// deleted, oldest first.
+func (r *AttachmentPostgres) GetOrphans(ctx context.Context, limit int) ([]string, error) {
+	keys := make([]string, 0)
+	query := fmt.Sprintf("SELECT blob_key FROM %s ORDER BY id LIMIT $1", orphanedBlobsTable)
+	err := r.db.SelectContext(ctx, &keys, query, limit)
+	return keys, err
+}
+
+func (r *AttachmentPostgres) DeleteOrphans(ctx context.Context, keys []string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE blob_key = ANY($1)", orphanedBlobsTable)
+	_, err := r.db.ExecContext(ctx, query, pq.Array(keys))
+	return err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// backupColumns are the columns of the tables in a backup. Settings like
+// webhooks and feed tokens, and the CalDAV sync state are not backed up, but
+// item names and UIDs are, as clients know items by them.
+var backupColumns = map[string][]string{
+	usersTable:            {"id", "name", "username", "password_hash"},
+	todoListsTable:        {"id", "title", "description", "blocker_policy"},
+	usersListsTable:       {"id", "user_id", "list_id"},
+	listStatusesTable:     {"id", "list_id", "name", "position", "terminal", "transitions"},
+	todoItemsTable:        {"id", "title", "description", "done", "due", "priority", "tags", "status", "caldav_name", "caldav_uid"},
+	listsItemsTable:       {"id", "list_id", "item_id"},
+	itemDependenciesTable: {"id", "item_id", "blocker_id"},
+	commentsTable:         {"id", "item_id", "user_id", "body", "created_at", "updated_at"},
+	commentMentionsTable:  {"id", "comment_id", "user_id"},
+	attachmentsTable:      {"id", "item_id", "user_id", "name", "content_type", "size", "blob_key", "created_at"},
+	filtersTable:          {"id", "user_id", "name", "query", "created_at"},
+	twoFactorTable:        {"user_id", "secret", "enabled", "last_step"},
+	recoveryCodesTable:    {"id", "user_id", "code_hash", "used_at"},
+}
+
+// backupQueries select the rows of the backup tables. Links with a missing
+// side are left out, as they can't be restored.
+var backupQueries = map[string]string{
+	usersTable:            "SELECT id, name, username, password_hash FROM %s ORDER BY id",
+	todoListsTable:        "SELECT id, title, COALESCE(description, '') AS description, blocker_policy FROM %s ORDER BY id",
+	usersListsTable:       "SELECT id, user_id, list_id FROM %s WHERE user_id IS NOT NULL AND list_id IS NOT NULL ORDER BY id",
+	listStatusesTable:     "SELECT id, list_id, name, position, terminal, transitions FROM %s ORDER BY id",
+	todoItemsTable:        "SELECT id, title, COALESCE(description, '') AS description, done, due, priority, tags, status, caldav_name, caldav_uid FROM %s ORDER BY id",
+	listsItemsTable:       "SELECT id, list_id, item_id FROM %s WHERE list_id IS NOT NULL AND item_id IS NOT NULL ORDER BY id",
+	itemDependenciesTable: "SELECT id, item_id, blocker_id FROM %s ORDER BY id",
+	commentsTable:         "SELECT id, item_id, user_id, body, created_at, updated_at FROM %s ORDER BY id",
+	commentMentionsTable:  "SELECT id, comment_id, user_id FROM %s ORDER BY id",
+	attachmentsTable:      "SELECT id, item_id, user_id, name, content_type, size, blob_key, created_at FROM %s ORDER BY id",
+	filtersTable:          "SELECT id, user_id, name, query, created_at FROM %s ORDER BY id",
+	twoFactorTable:        "SELECT user_id, secret, enabled, last_step FROM %s ORDER BY user_id",
+	recoveryCodesTable:    "SELECT id, user_id, code_hash, used_at FROM %s ORDER BY id",
+}
+
+type BackupPostgres struct {
+	db *sqlx.DB
+}
+
+func NewBackupPostgres(db *sqlx.DB) *BackupPostgres {
+	return &BackupPostgres{db: db}
+}
+
+// Dump passes fn a function reading the tables from a single snapshot, so
+// that the backup is consistent while the app keeps running.
+func (r *BackupPostgres) Dump(ctx context.Context, fn func(dump func(table string, fn func(row any) error) error) error) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return fn(func(table string, fn func(row any) error) error {
+		query, ok := backupQueries[table]
+		if !ok {
+			return fmt.Errorf("unknown backup table %s", table)
+		}
+
+		rows, err := tx.QueryxContext(ctx, fmt.Sprintf(query, table))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			row := todo.NewBackupRow(table)
+			if err := rows.StructScan(row); err != nil {
+				return err
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// Restore passes fn a function inserting rows with their ids in a single
+// transaction, and moves the id sequences past the restored ids.
+func (r *BackupPostgres) Restore(ctx context.Context, fn func(insert func(table string, row any) error) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for table := range backupColumns {
+		var exists bool
+		query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", table)
+		if err := tx.GetContext(ctx, &exists, query); err != nil {
+			return err
+		}
+		if exists {
+			return &todo.ErrRestoreNotEmpty{}
+		}
+	}
+
+	err = fn(func(table string, row any) error {
+		columns, ok := backupColumns[table]
+		if !ok {
+			return fmt.Errorf("unknown backup table %s", table)
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (:%s)",
+			table, strings.Join(columns, ", "), strings.Join(columns, ", :"))
+		_, err := tx.NamedExecContext(ctx, query, row)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for table, columns := range backupColumns {
+		if columns[0] != "id" {
+			// keyed by the user, there's no sequence
+			continue
+		}
+		query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 0) + 1, false)
+			FROM %s`, table, table)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+const defaultBlobDir = "attachments"
+
+// BlobFS keeps blobs as files in a directory, named by their keys.
+type BlobFS struct {
+	dir string
+}
+
+func NewBlobFS(dir string) *BlobFS {
+	return &BlobFS{dir: dir}
+}
+
+// Put writes the blob to a temporary file first, so that a failed upload
+// never leaves a partial blob behind under the key.
+func (s *BlobFS) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, err := io.Copy(f, contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+
+	return size, os.Rename(f.Name(), filepath.Join(s.dir, filepath.Base(key)))
+}
+
+func (s *BlobFS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.Base(key)))
+	if os.IsNotExist(err) {
+		return nil, &todo.ErrNoSuchAttachment{}
+	}
+	return f, err
+}
+
+func (s *BlobFS) Delete(ctx context.Context, key string) error {
+	err := os.Remove(filepath.Join(s.dir, filepath.Base(key)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+// s3PartSize is the smallest part S3 accepts in multipart uploads but the
+// last. Uploads are sent a part at a time, so it's also all of an upload
+// that is held in memory.
+const s3PartSize = 5 << 20
+
+type S3Config struct {
+	// Endpoint is the base URL of the service, like
+	// https://s3.eu-central-1.amazonaws.com or http://minio:9000. Buckets
+	// are addressed by path, which S3-compatible services all support.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// BlobS3 keeps blobs as objects in an S3-compatible bucket. Requests are
+// signed with AWS Signature Version 4.
+type BlobS3 struct {
+	cfg      S3Config
+	client   *http.Client
+	partSize int
+	now      func() time.Time
+}
+
+func NewBlobS3(cfg S3Config) *BlobS3 {
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &BlobS3{cfg: cfg, client: &http.Client{}, partSize: s3PartSize, now: time.Now}
+}
+
+// Put uploads small blobs in one request and larger ones in parts, reading
+// no more than a part ahead.
+func (s *BlobS3) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	part := make([]byte, s.partSize)
+	n, err := io.ReadFull(r, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		_, err := s.do(ctx, http.MethodPut, key, nil, part[:n])
+		return int64(n), err
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	uploadId, err := s.createUpload(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := s.uploadParts(ctx, key, uploadId, part, r)
+	if err != nil {
+		query := url.Values{"uploadId": {uploadId}}
+		if _, abortErr := s.do(context.Background(), http.MethodDelete, key, query, nil); abortErr != nil {
+			return 0, fmt.Errorf("%w, aborting the upload: %s", err, abortErr.Error())
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadParts sends the full first part and the rest of r, then completes
+// the upload.
+func (s *BlobS3) uploadParts(ctx context.Context, key, uploadId string, part []byte, r io.Reader) (int64, error) {
+	var size int64
+	parts := make([]s3CompletedPart, 0)
+	n := len(part)
+
+	for n > 0 {
+		query := url.Values{
+			"partNumber": {fmt.Sprint(len(parts) + 1)},
+			"uploadId":   {uploadId},
+		}
+		resp, err := s.do(ctx, http.MethodPut, key, query, part[:n])
+		if err != nil {
+			return 0, err
+		}
+		parts = append(parts, s3CompletedPart{
+			PartNumber: len(parts) + 1,
+			ETag:       resp.Header.Get("ETag"),
+		})
+		size += int64(n)
+
+		n, err = io.ReadFull(r, part)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return 0, err
+	}
+
+	// completing can fail after a 200 response, with the error in the body
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadId}}, body)
+	if err != nil {
+		return 0, err
+	}
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(resp.Body, &result); err != nil {
+		return 0, err
+	}
+	if result.XMLName.Local == "Error" {
+		return 0, fmt.Errorf("s3: %s: %s", result.Code, result.Message)
+	}
+	return size, nil
+}
+
+func (s *BlobS3) createUpload(ctx context.Context, key string) (string, error) {
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		UploadId string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(resp.Body, &result); err != nil {
+		return "", err
+	}
+	if result.UploadId == "" {
+		return "", fmt.Errorf("s3: no upload id in response")
+	}
+	return result.UploadId, nil
+}
+
+func (s *BlobS3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, &todo.ErrNoSuchAttachment{}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *BlobS3) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	return err
+}
+
+type s3Response struct {
+	Header http.Header
+	Body   []byte
+}
+
+// do sends a request with a body small enough to be held in memory and
+// reads the whole response.
+func (s *BlobS3) do(ctx context.Context, method, key string, query url.Values, body []byte) (s3Response, error) {
+	req, err := s.newRequest(ctx, method, key, query, body)
+	if err != nil {
+		return s3Response{}, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return s3Response{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodDelete && query == nil {
+		// already gone
+		return s3Response{}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return s3Response{}, s3Error(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	return s3Response{Header: resp.Header, Body: data}, err
+}
+
+func (s *BlobS3) newRequest(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	u, err := url.Parse(s.cfg.Endpoint + "/" + s3Escape(s.cfg.Bucket, false) + "/" + s3Escape(key, false))
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = s3Query(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+	}
+	s.sign(req, body)
+	return req, nil
+}
+
+// sign adds the headers of Signature Version 4 to the request, signing the
+// host and all headers set so far.
+func (s *BlobS3) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3Query encodes the query sorted by key, as signatures expect it.
+func s3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape percent-encodes all but the unreserved characters of RFC 3986,
+// and slashes unless escapeSlash is set.
+func s3Escape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3Error(resp *http.Response) error {
+	var result struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &result) != nil || result.Code == "" {
+		return fmt.Errorf("s3: %s", resp.Status)
+	}
+	return fmt.Errorf("s3: %s: %s: %s", resp.Status, result.Code, result.Message)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
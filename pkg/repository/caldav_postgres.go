@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+type CalDAVPostgres struct {
+	db *sqlx.DB
+}
+
+func NewCalDAVPostgres(db *sqlx.DB) *CalDAVPostgres {
+	return &CalDAVPostgres{db: db}
+}
+
+const calendarObjectColumns = `ti.id, ti.title, ti.description, ti.done, ti.due, ti.priority, ti.tags, ti.status,
+	ti.caldav_name, COALESCE(ti.caldav_uid, '') AS caldav_uid, ti.sync_version, ti.modified_at`
+
+func (r *CalDAVPostgres) GetObjects(ctx context.Context, userId, listId int) ([]todo.CalendarObject, error) {
+	var objects []todo.CalendarObject
+	query := fmt.Sprintf(`SELECT %s FROM %s ti
+		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
+		WHERE li.list_id=$1 AND ul.user_id=$2 ORDER BY ti.id`,
+		calendarObjectColumns, todoItemsTable, listsItemsTable, usersListsTable)
+	err := r.db.SelectContext(ctx, &objects, query, listId, userId)
+
+	return objects, err
+}
+
+func (r *CalDAVPostgres) GetObject(ctx context.Context, userId, listId int, name string) (todo.CalendarObject, error) {
+	var object todo.CalendarObject
+	query := fmt.Sprintf(`SELECT %s FROM %s ti
+		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
+		WHERE li.list_id=$1 AND ul.user_id=$2 AND ti.caldav_name=$3
+		ORDER BY ti.id LIMIT 1`,
+		calendarObjectColumns, todoItemsTable, listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &object, query, listId, userId, name)
+
+	if err == sql.ErrNoRows {
+		return object, &todo.ErrNoSuchCalendarObject{}
+	}
+
+	return object, err
+}
+
+// GetChanges returns the objects changed after the given version and the names
+// of the ones removed from the list since then.
+func (r *CalDAVPostgres) GetChanges(ctx context.Context, userId, listId int, since int64) ([]todo.CalendarObject, []string, error) {
+	var objects []todo.CalendarObject
+	query := fmt.Sprintf(`SELECT %s FROM %s ti
+		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
+		WHERE li.list_id=$1 AND ul.user_id=$2 AND ti.sync_version>$3 ORDER BY ti.id`,
+		calendarObjectColumns, todoItemsTable, listsItemsTable, usersListsTable)
+	if err := r.db.SelectContext(ctx, &objects, query, listId, userId, since); err != nil {
+		return nil, nil, err
+	}
+
+	// a name that came back to the list is reported as changed, not deleted
+	var deleted []string
+	query = fmt.Sprintf(`SELECT DISTINCT t.name FROM %s t
+		INNER JOIN %s ul ON ul.list_id=t.list_id
+		WHERE t.list_id=$1 AND ul.user_id=$2 AND t.sync_version>$3
+		AND NOT EXISTS (SELECT 1 FROM %s ti INNER JOIN %s li ON li.item_id=ti.id
+			WHERE li.list_id=t.list_id AND ti.caldav_name=t.name)`,
+		caldavTombstonesTable, usersListsTable, todoItemsTable, listsItemsTable)
+	if err := r.db.SelectContext(ctx, &deleted, query, listId, userId, since); err != nil {
+		return nil, nil, err
+	}
+
+	return objects, deleted, nil
+}
+
+func (r *CalDAVPostgres) SyncToken(ctx context.Context, listId int) (int64, error) {
+	var token int64
+	query := fmt.Sprintf(`SELECT GREATEST(
+		(SELECT COALESCE(MAX(ti.sync_version), 0) FROM %s ti
+			INNER JOIN %s li ON li.item_id=ti.id WHERE li.list_id=$1),
+		(SELECT COALESCE(MAX(t.sync_version), 0) FROM %s t WHERE t.list_id=$1))`,
+		todoItemsTable, listsItemsTable, caldavTombstonesTable)
+	err := r.db.GetContext(ctx, &token, query, listId)
+
+	return token, err
+}
+
+func (r *CalDAVPostgres) SetObjectName(ctx context.Context, itemId int, name, uid string) error {
+	query := fmt.Sprintf("UPDATE %s SET caldav_name=$1, caldav_uid=NULLIF($2, '') WHERE id=$3",
+		todoItemsTable)
+	_, err := r.db.ExecContext(ctx, query, name, uid, itemId)
+	return err
+}
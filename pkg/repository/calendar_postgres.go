@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+type CalendarFeedPostgres struct {
+	db *sqlx.DB
+}
+
+func NewCalendarFeedPostgres(db *sqlx.DB) *CalendarFeedPostgres {
+	return &CalendarFeedPostgres{db: db}
+}
+
+func (r *CalendarFeedPostgres) GetToken(ctx context.Context, userId int) (string, error) {
+	var token string
+	query := fmt.Sprintf("SELECT token FROM %s WHERE user_id=$1", calendarFeedsTable)
+	err := r.db.GetContext(ctx, &token, query, userId)
+
+	if err == sql.ErrNoRows {
+		return "", &todo.ErrNoSuchFeed{}
+	}
+
+	return token, err
+}
+
+func (r *CalendarFeedPostgres) SetToken(ctx context.Context, userId int, token string) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, token) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token=EXCLUDED.token`, calendarFeedsTable)
+	_, err := r.db.ExecContext(ctx, query, userId, token)
+	return err
+}
+
+func (r *CalendarFeedPostgres) GetUserId(ctx context.Context, token string) (int, error) {
+	var userId int
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE token=$1", calendarFeedsTable)
+	err := r.db.GetContext(ctx, &userId, query, token)
+
+	if err == sql.ErrNoRows {
+		return 0, &todo.ErrNoSuchFeed{}
+	}
+
+	return userId, err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// commentColumns select a comment joined as c with its author joined as u.
+var commentColumns = fmt.Sprintf(`c.id, c.item_id, c.body, c.created_at, c.updated_at,
+	u.id AS "author.id", u.name AS "author.name", u.username AS "author.username",
+	COALESCE((SELECT jsonb_agg(mu.username ORDER BY mu.username) FROM %s m
+		INNER JOIN %s mu ON mu.id=m.user_id WHERE m.comment_id=c.id), '[]') AS mentions`,
+	commentMentionsTable, usersTable)
+
+type CommentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewCommentPostgres(db *sqlx.DB) *CommentPostgres {
+	return &CommentPostgres{db: db}
+}
+
+func (r *CommentPostgres) Create(ctx context.Context, userId, itemId int, body string, mentions []string) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (item_id, user_id, body) VALUES ($1, $2, $3) RETURNING id",
+		commentsTable)
+	if err := tx.QueryRowContext(ctx, query, itemId, userId, body).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := setMentions(ctx, tx, id, itemId, mentions); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// GetAll returns up to limit comments on the item with ids after the given
+// one, oldest first.
+func (r *CommentPostgres) GetAll(ctx context.Context, itemId, after, limit int) ([]todo.Comment, error) {
+	comments := make([]todo.Comment, 0)
+	query := fmt.Sprintf(`SELECT %s FROM %s c INNER JOIN %s u ON u.id=c.user_id
+		WHERE c.item_id=$1 AND c.id>$2 ORDER BY c.id LIMIT $3`,
+		commentColumns, commentsTable, usersTable)
+	err := r.db.SelectContext(ctx, &comments, query, itemId, after, limit)
+	return comments, err
+}
+
+// GetById returns the comment on the item if the user is a member of a list
+// the item is in.
+func (r *CommentPostgres) GetById(ctx context.Context, userId, itemId, commentId int) (todo.Comment, error) {
+	var comment todo.Comment
+	query := fmt.Sprintf(`SELECT %s FROM %s c INNER JOIN %s u ON u.id=c.user_id
+		WHERE c.id=$1 AND c.item_id=$2 AND EXISTS (SELECT 1 FROM %s li
+			INNER JOIN %s ul ON ul.list_id=li.list_id WHERE li.item_id=c.item_id AND ul.user_id=$3)`,
+		commentColumns, commentsTable, usersTable, listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &comment, query, commentId, itemId, userId)
+
+	if err == sql.ErrNoRows {
+		return comment, &todo.ErrNoSuchComment{}
+	}
+
+	return comment, err
+}
+
+func (r *CommentPostgres) Update(ctx context.Context, commentId int, body string, mentions []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var itemId int
+	query := fmt.Sprintf("UPDATE %s SET body=$1, updated_at=now() WHERE id=$2 RETURNING item_id",
+		commentsTable)
+	err = tx.QueryRowContext(ctx, query, body, commentId).Scan(&itemId)
+	if err == sql.ErrNoRows {
+		return &todo.ErrNoSuchComment{}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := setMentions(ctx, tx, commentId, itemId, mentions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *CommentPostgres) Delete(ctx context.Context, commentId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", commentsTable)
+	_, err := r.db.ExecContext(ctx, query, commentId)
+	return err
+}
+
+// IsListOwner reports whether the user owns a list the item is in. Lists
+// are owned by the user who created them, their first member.
+func (r *CommentPostgres) IsListOwner(ctx context.Context, userId, itemId int) (bool, error) {
+	var owner bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s li WHERE li.item_id=$1 AND
+		(SELECT ul.user_id FROM %s ul WHERE ul.list_id=li.list_id ORDER BY ul.id LIMIT 1)=$2)`,
+		listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &owner, query, itemId, userId)
+	return owner, err
+}
+
+// setMentions replaces the users the comment mentions. Only users that can
+// access the item are kept, others won't ever see the comment.
+func setMentions(ctx context.Context, tx *sqlx.Tx, commentId, itemId int, mentions []string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE comment_id=$1", commentMentionsTable)
+	if _, err := tx.ExecContext(ctx, query, commentId); err != nil {
+		return err
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	usernames := make([]string, len(mentions))
+	for i, username := range mentions {
+		usernames[i] = strings.ToLower(username)
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %s (comment_id, user_id)
+		SELECT DISTINCT $1::int, u.id FROM %s u
+		INNER JOIN %s ul ON ul.user_id=u.id INNER JOIN %s li ON li.list_id=ul.list_id
+		WHERE li.item_id=$2 AND lower(u.username) = ANY($3)`,
+		commentMentionsTable, usersTable, usersListsTable, listsItemsTable)
+	_, err := tx.ExecContext(ctx, query, commentId, itemId, pq.Array(usernames))
+	return err
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type DependencyPostgres struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+}
+
+func NewDependencyPostgres(db *sqlx.DB) *DependencyPostgres {
+	return &DependencyPostgres{db: db}
+}
+
+func (r *DependencyPostgres) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// GetBlockers returns the links of the items to their blockers, whichever
+// lists they are in, for walking the dependency graph.
+func (r *DependencyPostgres) GetBlockers(ctx context.Context, itemIds []int) ([]todo.ItemDependency, error) {
+	dependencies := make([]todo.ItemDependency, 0)
+	query := fmt.Sprintf(`SELECT id, item_id, blocker_id FROM %s
+		WHERE item_id = ANY($1) ORDER BY item_id, blocker_id`, itemDependenciesTable)
+	err := r.conn().SelectContext(ctx, &dependencies, query, pq.Array(itemIds))
+	return dependencies, err
+}
+
+// GetOpen returns the ids of the items that aren't done.
+func (r *DependencyPostgres) GetOpen(ctx context.Context, itemIds []int) ([]int, error) {
+	ids := make([]int, 0)
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id = ANY($1) AND NOT done ORDER BY id", todoItemsTable)
+	err := r.conn().SelectContext(ctx, &ids, query, pq.Array(itemIds))
+	return ids, err
+}
+
+// GetBlockerPolicy returns the blocker policy of the list the item is in.
+func (r *DependencyPostgres) GetBlockerPolicy(ctx context.Context, itemId int) (string, error) {
+	var policy string
+	query := fmt.Sprintf(`SELECT tl.blocker_policy FROM %s tl INNER JOIN %s li ON li.list_id=tl.id
+		WHERE li.item_id=$1`, todoListsTable, listsItemsTable)
+	err := r.conn().GetContext(ctx, &policy, query, itemId)
+
+	if err == sql.ErrNoRows {
+		return policy, &todo.ErrNoSuchItem{}
+	}
+
+	return policy, err
+}
+
+// GetDependencies returns the items blocking the item and the ones it
+// blocks, leaving out the ones the user can't access.
+func (r *DependencyPostgres) GetDependencies(ctx context.Context, userId, itemId int) (todo.ItemDependencies, error) {
+	dependencies := todo.ItemDependencies{
+		BlockedBy: make([]todo.TodoItem, 0),
+		Blocking:  make([]todo.TodoItem, 0),
+	}
+
+	query := `SELECT DISTINCT %s FROM %s ti
+		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
+		INNER JOIN %s dep ON dep.%s=ti.id
+		WHERE dep.%s=$1 AND ul.user_id=$2 ORDER BY ti.id`
+	blockedByQuery := fmt.Sprintf(query, itemColumns, todoItemsTable, listsItemsTable, usersListsTable,
+		itemDependenciesTable, "blocker_id", "item_id")
+	if err := r.conn().SelectContext(ctx, &dependencies.BlockedBy, blockedByQuery, itemId, userId); err != nil {
+		return dependencies, err
+	}
+
+	blockingQuery := fmt.Sprintf(query, itemColumns, todoItemsTable, listsItemsTable, usersListsTable,
+		itemDependenciesTable, "item_id", "blocker_id")
+	err := r.conn().SelectContext(ctx, &dependencies.Blocking, blockingQuery, itemId, userId)
+	return dependencies, err
+}
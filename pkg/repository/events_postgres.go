@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	eventsChannel      = "todo_events"
+	eventsPingInterval = 90 * time.Second
+)
+
+type eventEnvelope struct {
+	todo.Event
+	Recipients []int `json:"recipients"`
+}
+
+type EventsPostgres struct {
+	db  *sqlx.DB
+	dsn string
+}
+
+func NewEventsPostgres(db *sqlx.DB, cfg Config) *EventsPostgres {
+	return &EventsPostgres{db: db, dsn: cfg.dsn()}
+}
+
+func (r *EventsPostgres) Recipients(ctx context.Context, listId, itemId int) (int, []int, error) {
+	var rows []struct {
+		ListId int `db:"list_id"`
+		UserId int `db:"user_id"`
+	}
+
+	var err error
+	if itemId != 0 {
+		query := fmt.Sprintf(`SELECT li.list_id, ul.user_id FROM %s li
+			INNER JOIN %s ul ON ul.list_id=li.list_id WHERE li.item_id=$1`,
+			listsItemsTable, usersListsTable)
+		err = r.db.SelectContext(ctx, &rows, query, itemId)
+	} else {
+		query := fmt.Sprintf("SELECT ul.list_id, ul.user_id FROM %s ul WHERE ul.list_id=$1",
+			usersListsTable)
+		err = r.db.SelectContext(ctx, &rows, query, listId)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	userIds := make([]int, 0, len(rows))
+	for _, row := range rows {
+		listId = row.ListId
+		userIds = append(userIds, row.UserId)
+	}
+
+	return listId, userIds, nil
+}
+
+// Publish stores the event and notifies every replica of its id, which
+// then loads it, as events with many recipients don't fit in a
+// notification. Ids are taken under a lock held until commit, so
+// notifications arrive in id order and resuming from an id can't skip a
+// lower one arriving later.
+func (r *EventsPostgres) Publish(ctx context.Context, event todo.Event) (int64, error) {
+	payload, err := json.Marshal(eventEnvelope{Event: event, Recipients: event.Recipients})
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT pg_advisory_xact_lock(hashtext('%s'))", eventsTable)
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	query = fmt.Sprintf("INSERT INTO %s (payload) VALUES ($1) RETURNING id", eventsTable)
+	if err := tx.GetContext(ctx, &id, query, string(payload)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT pg_notify($1, $2)", eventsChannel,
+		strconv.FormatInt(id, 10)); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// DeleteExpired drops the events stored before before, which every replica
+// has long loaded.
+func (r *EventsPostgres) DeleteExpired(ctx context.Context, before time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < $1", eventsTable)
+	_, err := r.db.ExecContext(ctx, query, before)
+	return err
+}
+
+func (r *EventsPostgres) get(ctx context.Context, id int64) (todo.Event, error) {
+	var payload string
+	query := fmt.Sprintf("SELECT payload FROM %s WHERE id=$1", eventsTable)
+	if err := r.db.GetContext(ctx, &payload, query, id); err != nil {
+		return todo.Event{}, err
+	}
+
+	var envelope eventEnvelope
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil {
+		return todo.Event{}, err
+	}
+	envelope.Event.Id = id
+	envelope.Event.Recipients = envelope.Recipients
+	return envelope.Event, nil
+}
+
+// Listen delivers events published by every replica until ctx is done.
+func (r *EventsPostgres) Listen(ctx context.Context, handle func(todo.Event)) error {
+	logger := logging.FromContext(ctx)
+	listener := pq.NewListener(r.dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Event listener connection failed", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(eventsChannel); err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(eventsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			go listener.Ping()
+		case notification := <-listener.Notify:
+			// nil is sent after a reconnect, nothing to deliver
+			if notification == nil {
+				logger.Info("Event listener reconnected")
+				continue
+			}
+
+			id, err := strconv.ParseInt(notification.Extra, 10, 64)
+			if err != nil {
+				logger.Warn("Skipping malformed event", "error", err)
+				continue
+			}
+			event, err := r.get(ctx, id)
+			if err != nil {
+				logger.Error("Failed to load event", "id", id, "error", err)
+				continue
+			}
+			handle(event)
+		}
+	}
+}
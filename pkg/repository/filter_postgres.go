@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/filter"
+	"github.com/jmoiron/sqlx"
+)
+
+type FilterPostgres struct {
+	db *sqlx.DB
+}
+
+func NewFilterPostgres(db *sqlx.DB) *FilterPostgres {
+	return &FilterPostgres{db: db}
+}
+
+func (r *FilterPostgres) Create(ctx context.Context, userId int, f todo.Filter) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, name, query) VALUES ($1, $2, $3) RETURNING id",
+		filtersTable)
+	row := r.db.QueryRowContext(ctx, query, userId, f.Name, f.Query)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *FilterPostgres) GetAll(ctx context.Context, userId int) ([]todo.Filter, error) {
+	filters := make([]todo.Filter, 0)
+	query := fmt.Sprintf(`SELECT id, user_id, name, query, created_at FROM %s
+		WHERE user_id=$1 ORDER BY id`, filtersTable)
+	err := r.db.SelectContext(ctx, &filters, query, userId)
+	return filters, err
+}
+
+func (r *FilterPostgres) GetById(ctx context.Context, userId, filterId int) (todo.Filter, error) {
+	var f todo.Filter
+	query := fmt.Sprintf(`SELECT id, user_id, name, query, created_at FROM %s
+		WHERE user_id=$1 AND id=$2`, filtersTable)
+	err := r.db.GetContext(ctx, &f, query, userId, filterId)
+
+	if err == sql.ErrNoRows {
+		return f, &todo.ErrNoSuchFilter{}
+	}
+
+	return f, err
+}
+
+func (r *FilterPostgres) Update(ctx context.Context, userId, filterId int, input todo.UpdateFilterInput) error {
+	setValues := make([]string, 0)
+	args := make([]any, 0)
+	argId := 1
+
+	if input.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name=$%d", argId))
+		args = append(args, *input.Name)
+		argId++
+	}
+
+	if input.Query != nil {
+		setValues = append(setValues, fmt.Sprintf("query=$%d", argId))
+		args = append(args, *input.Query)
+		argId++
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE user_id=$%d AND id=$%d",
+		filtersTable, setQuery, argId, argId+1)
+	args = append(args, userId, filterId)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return &todo.ErrNoSuchFilter{}
+	}
+
+	return nil
+}
+
+func (r *FilterPostgres) Delete(ctx context.Context, userId, filterId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id=$1 AND id=$2", filtersTable)
+	_, err := r.db.ExecContext(ctx, query, userId, filterId)
+	return err
+}
+
+// filterCompiler turns filter expressions into conditions over the ti
+// (todo_items), li (lists_items) and tl (todo_lists) aliases. Values are
+// passed as parameters following the ones already in args.
+type filterCompiler struct {
+	now  time.Time
+	args []any
+}
+
+var filterOps = map[string]string{
+	filter.OpMatch: "=",
+	filter.OpEq:    "=",
+	filter.OpNe:    "<>",
+	filter.OpLt:    "<",
+	filter.OpLe:    "<=",
+	filter.OpGt:    ">",
+	filter.OpGe:    ">=",
+}
+
+func (c *filterCompiler) param(value any) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+func (c *filterCompiler) compile(expr filter.Expr) (string, error) {
+	switch expr := expr.(type) {
+	case filter.And:
+		return c.binary("AND", expr.Left, expr.Right)
+	case filter.Or:
+		return c.binary("OR", expr.Left, expr.Right)
+	case filter.Not:
+		inner, err := c.compile(expr.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + inner, nil
+	case filter.Condition:
+		return c.condition(expr)
+	}
+	return "", fmt.Errorf("unknown filter expression %T", expr)
+}
+
+func (c *filterCompiler) binary(op string, left, right filter.Expr) (string, error) {
+	l, err := c.compile(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := c.compile(right)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s %s %s)", l, op, r), nil
+}
+
+// condition never evaluates to NULL, so that negating it matches
+// everything it doesn't.
+func (c *filterCompiler) condition(cond filter.Condition) (string, error) {
+	op := filterOps[cond.Op]
+	negate := ""
+	if cond.Op == filter.OpNe {
+		negate = "NOT "
+	}
+
+	switch cond.Field {
+	case filter.FieldDone:
+		return fmt.Sprintf("(ti.done %s %s)", op, c.param(cond.Value)), nil
+
+	case filter.FieldPriority:
+		return fmt.Sprintf("(ti.priority %s %s)", op, c.param(cond.Value)), nil
+
+	case filter.FieldTag:
+		return fmt.Sprintf("%sEXISTS (SELECT 1 FROM jsonb_array_elements_text(ti.tags) tag WHERE lower(tag)=lower(%s))",
+			negate, c.param(cond.Value)), nil
+
+	case filter.FieldTitle, filter.FieldDescription:
+		column := "ti." + cond.Field
+		if cond.Field == filter.FieldDescription {
+			// descriptions are nullable, which would make description!="" never match
+			column = "COALESCE(ti.description, '')"
+		}
+		if cond.Op == filter.OpMatch {
+			return fmt.Sprintf("(%s ILIKE %s)", column, c.param("%"+escapeLike(cond.Value.(string))+"%")), nil
+		}
+		return fmt.Sprintf("(lower(%s) %s lower(%s))", column, op, c.param(cond.Value)), nil
+
+	case filter.FieldList:
+		if _, ok := cond.Value.(int); ok {
+			return fmt.Sprintf("(li.list_id %s %s)", op, c.param(cond.Value)), nil
+		}
+		return fmt.Sprintf("(lower(tl.title) %s lower(%s))", op, c.param(cond.Value)), nil
+
+	case filter.FieldDue:
+		return c.due(cond)
+	}
+	return "", fmt.Errorf("unknown filter field %s", cond.Field)
+}
+
+func (c *filterCompiler) due(cond filter.Condition) (string, error) {
+	switch value := cond.Value.(type) {
+	case nil:
+		if cond.Op == filter.OpNe {
+			return "(ti.due IS NOT NULL)", nil
+		}
+		return "(ti.due IS NULL)", nil
+
+	case filter.Offset:
+		at := c.now.Add(time.Duration(value))
+		return fmt.Sprintf("(ti.due IS NOT NULL AND ti.due %s %s)", filterOps[cond.Op], c.param(at)), nil
+
+	case filter.Days, filter.Date:
+		start, end := filter.Day(value, c.now)
+		switch cond.Op {
+		case filter.OpMatch, filter.OpEq:
+			return fmt.Sprintf("(ti.due IS NOT NULL AND ti.due >= %s AND ti.due < %s)",
+				c.param(start), c.param(end)), nil
+		case filter.OpNe:
+			return fmt.Sprintf("(ti.due IS NULL OR ti.due < %s OR ti.due >= %s)",
+				c.param(start), c.param(end)), nil
+		case filter.OpLt:
+			return fmt.Sprintf("(ti.due IS NOT NULL AND ti.due < %s)", c.param(start)), nil
+		case filter.OpLe:
+			return fmt.Sprintf("(ti.due IS NOT NULL AND ti.due < %s)", c.param(end)), nil
+		case filter.OpGt:
+			return fmt.Sprintf("(ti.due IS NOT NULL AND ti.due >= %s)", c.param(end)), nil
+		case filter.OpGe:
+			return fmt.Sprintf("(ti.due IS NOT NULL AND ti.due >= %s)", c.param(start)), nil
+		}
+	}
+	return "", fmt.Errorf("invalid due condition %s", cond)
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OrIX219/todo/pkg/filter"
+)
+
+func TestFilterCompiler(t *testing.T) {
+	now := time.Date(2023, time.October, 18, 15, 0, 0, 0, time.UTC)
+	today := time.Date(2023, time.October, 18, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	testTable := []struct {
+		name         string
+		query        string
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:  "Request example",
+			query: "done:false AND due<7d AND (tag:work OR priority>=high)",
+			expectedSQL: "(((ti.done = $2) AND (ti.due IS NOT NULL AND ti.due < $3)) AND " +
+				"(EXISTS (SELECT 1 FROM jsonb_array_elements_text(ti.tags) tag WHERE lower(tag)=lower($4)) OR " +
+				"(ti.priority >= $5)))",
+			expectedArgs: []any{1, false, now.AddDate(0, 0, 7), "work", filter.PriorityHigh},
+		},
+		{
+			name:         "NOT",
+			query:        "NOT tag:work",
+			expectedSQL:  "NOT EXISTS (SELECT 1 FROM jsonb_array_elements_text(ti.tags) tag WHERE lower(tag)=lower($2))",
+			expectedArgs: []any{1, "work"},
+		},
+		{
+			name:         "Without tag",
+			query:        "tag!=work",
+			expectedSQL:  "NOT EXISTS (SELECT 1 FROM jsonb_array_elements_text(ti.tags) tag WHERE lower(tag)=lower($2))",
+			expectedArgs: []any{1, "work"},
+		},
+		{
+			name:         "Title part",
+			query:        `title:"50%_off\\"`,
+			expectedSQL:  "(ti.title ILIKE $2)",
+			expectedArgs: []any{1, `%50\%\_off\\%`},
+		},
+		{
+			name:         "Description",
+			query:        `description!=""`,
+			expectedSQL:  "(lower(COALESCE(ti.description, '')) <> lower($2))",
+			expectedArgs: []any{1, ""},
+		},
+		{
+			name:         "Lists",
+			query:        "list:3 OR list=Inbox",
+			expectedSQL:  "((li.list_id = $2) OR (lower(tl.title) = lower($3)))",
+			expectedArgs: []any{1, 3, "Inbox"},
+		},
+		{
+			name:         "No due",
+			query:        "due:none OR due!=none",
+			expectedSQL:  "((ti.due IS NULL) OR (ti.due IS NOT NULL))",
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "Due today",
+			query:        "due:today",
+			expectedSQL:  "(ti.due IS NOT NULL AND ti.due >= $2 AND ti.due < $3)",
+			expectedArgs: []any{1, today, tomorrow},
+		},
+		{
+			name:         "Not due today",
+			query:        "due!=today",
+			expectedSQL:  "(ti.due IS NULL OR ti.due < $2 OR ti.due >= $3)",
+			expectedArgs: []any{1, today, tomorrow},
+		},
+		{
+			name:         "Overdue",
+			query:        "due<today",
+			expectedSQL:  "(ti.due IS NOT NULL AND ti.due < $2)",
+			expectedArgs: []any{1, today},
+		},
+		{
+			name:         "Until tomorrow",
+			query:        "due<=tomorrow",
+			expectedSQL:  "(ti.due IS NOT NULL AND ti.due < $2)",
+			expectedArgs: []any{1, tomorrow.AddDate(0, 0, 1)},
+		},
+		{
+			name:         "After a date",
+			query:        "due>2023-12-31",
+			expectedSQL:  "(ti.due IS NOT NULL AND ti.due >= $2)",
+			expectedArgs: []any{1, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:         "From a date",
+			query:        "due>=2023-12-31",
+			expectedSQL:  "(ti.due IS NOT NULL AND ti.due >= $2)",
+			expectedArgs: []any{1, time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:         "Past hours",
+			query:        "due>-12h",
+			expectedSQL:  "(ti.due IS NOT NULL AND ti.due > $2)",
+			expectedArgs: []any{1, now.Add(-12 * time.Hour)},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			expr, err := filter.Parse(testCase.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			compiler := &filterCompiler{now: now, args: []any{1}}
+			sql, err := compiler.compile(expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if sql != testCase.expectedSQL {
+				t.Errorf("got %s, want %s", sql, testCase.expectedSQL)
+			}
+			if !reflect.DeepEqual(compiler.args, testCase.expectedArgs) {
+				t.Errorf("got %v, want %v", compiler.args, testCase.expectedArgs)
+			}
+		})
+	}
+}
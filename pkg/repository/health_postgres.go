@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type HealthPostgres struct {
+	db *sqlx.DB
+}
+
+func NewHealthPostgres(db *sqlx.DB) *HealthPostgres {
+	return &HealthPostgres{db: db}
+}
+
+func (r *HealthPostgres) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the version of the newest migration applied and
+// not rolled back, the latest row of every version telling which it is.
+func (r *HealthPostgres) MigrationVersion(ctx context.Context) (int64, error) {
+	var version int64
+	query := fmt.Sprintf(`SELECT COALESCE(MAX(version_id), 0) FROM
+		(SELECT DISTINCT ON (version_id) version_id, is_applied FROM %s ORDER BY version_id, id DESC) v
+		WHERE is_applied`, migrationsTable)
+	err := r.db.GetContext(ctx, &version, query)
+	return version, err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+type IdempotencyMemory struct {
+	mu      sync.Mutex
+	lastId  int
+	records map[string]todo.IdempotencyRecord
+}
+
+func NewIdempotencyMemory() *IdempotencyMemory {
+	return &IdempotencyMemory{records: make(map[string]todo.IdempotencyRecord)}
+}
+
+func idempotencyMemoryKey(userId int, key string) string {
+	return fmt.Sprintf("%d:%s", userId, key)
+}
+
+func (r *IdempotencyMemory) Reserve(ctx context.Context, record todo.IdempotencyRecord, expiredBefore time.Time) (todo.IdempotencyRecord, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyMemoryKey(record.UserId, record.Key)
+	if existing, ok := r.records[k]; ok && !existing.CreatedAt.Before(expiredBefore) {
+		return existing, false, nil
+	}
+
+	r.lastId++
+	record.Id = r.lastId
+	record.StatusCode = 0
+	record.ContentType = ""
+	record.Response = nil
+	r.records[k] = record
+	return record, true, nil
+}
+
+func (r *IdempotencyMemory) Complete(ctx context.Context, record todo.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyMemoryKey(record.UserId, record.Key)
+	existing, ok := r.records[k]
+	if !ok {
+		return nil
+	}
+	existing.StatusCode = record.StatusCode
+	existing.ContentType = record.ContentType
+	existing.Response = append([]byte(nil), record.Response...)
+	r.records[k] = existing
+	return nil
+}
+
+func (r *IdempotencyMemory) Release(ctx context.Context, userId int, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := idempotencyMemoryKey(userId, key)
+	if existing, ok := r.records[k]; ok && !existing.Completed() {
+		delete(r.records, k)
+	}
+	return nil
+}
+
+func (r *IdempotencyMemory) DeleteExpired(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, record := range r.records {
+		if record.CreatedAt.Before(before) {
+			delete(r.records, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyPostgres struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyPostgres(db *sqlx.DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{db: db}
+}
+
+func (r *IdempotencyPostgres) Reserve(ctx context.Context, record todo.IdempotencyRecord, expiredBefore time.Time) (todo.IdempotencyRecord, bool, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %[1]s (user_id, key, fingerprint, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, key) DO UPDATE SET fingerprint=EXCLUDED.fingerprint, status_code=0,
+		content_type='', response=NULL, created_at=EXCLUDED.created_at
+		WHERE %[1]s.created_at < $5 RETURNING id`, idempotencyKeysTable)
+	err := r.db.QueryRowContext(ctx, query, record.UserId, record.Key, record.Fingerprint,
+		record.CreatedAt, expiredBefore).Scan(&id)
+	if err == nil {
+		record.Id = id
+		return record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return record, false, err
+	}
+
+	var existing todo.IdempotencyRecord
+	query = fmt.Sprintf(`SELECT id, user_id, key, fingerprint, status_code, content_type, response, created_at
+		FROM %s WHERE user_id=$1 AND key=$2`, idempotencyKeysTable)
+	err = r.db.GetContext(ctx, &existing, query, record.UserId, record.Key)
+	return existing, false, err
+}
+
+func (r *IdempotencyPostgres) Complete(ctx context.Context, record todo.IdempotencyRecord) error {
+	query := fmt.Sprintf(`UPDATE %s SET status_code=$1, content_type=$2, response=$3
+		WHERE user_id=$4 AND key=$5`, idempotencyKeysTable)
+	_, err := r.db.ExecContext(ctx, query, record.StatusCode, record.ContentType, record.Response,
+		record.UserId, record.Key)
+	return err
+}
+
+func (r *IdempotencyPostgres) Release(ctx context.Context, userId int, key string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id=$1 AND key=$2 AND status_code=0",
+		idempotencyKeysTable)
+	_, err := r.db.ExecContext(ctx, query, userId, key)
+	return err
+}
+
+func (r *IdempotencyPostgres) DeleteExpired(ctx context.Context, before time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < $1", idempotencyKeysTable)
+	_, err := r.db.ExecContext(ctx, query, before)
+	return err
+}
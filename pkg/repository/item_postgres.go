@@ -1,33 +1,55 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/filter"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// itemColumns select an item joined as ti with its blockers, as jsonb so
+// that items can be selected with DISTINCT.
+var itemColumns = fmt.Sprintf(`ti.id, ti.title, ti.description, ti.done, ti.due, ti.priority, ti.tags, ti.status,
+	COALESCE((SELECT jsonb_agg(d.blocker_id ORDER BY d.blocker_id) FROM %[1]s d WHERE d.item_id=ti.id), '[]')
+		AS blocked_by,
+	EXISTS (SELECT 1 FROM %[1]s d INNER JOIN %[2]s b ON b.id=d.blocker_id WHERE d.item_id=ti.id AND NOT b.done)
+		AS blocked`,
+	itemDependenciesTable, todoItemsTable)
+
 type TodoItemPostgres struct {
 	db *sqlx.DB
+	tx *sqlx.Tx
 }
 
 func NewTodoItemPostgres(db *sqlx.DB) *TodoItemPostgres {
 	return &TodoItemPostgres{db: db}
 }
 
-func (r *TodoItemPostgres) Create(listId int, item todo.TodoItem) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoItemPostgres) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *TodoItemPostgres) Create(ctx context.Context, listId int, item todo.TodoItem) (int, error) {
+	tx, err := beginScope(ctx, r.db, r.tx)
 	if err != nil {
 		return 0, err
 	}
 	defer tx.Rollback()
 
 	var itemId int
-	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id",
-		todoItemsTable)
-	row := tx.QueryRow(createItemQuery, item.Title, item.Description)
+	createItemQuery := fmt.Sprintf(`INSERT INTO %s (title, description, done, due, priority, tags, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, todoItemsTable)
+	row := tx.QueryRowContext(ctx, createItemQuery, item.Title, item.Description, item.Done, item.Due, item.Priority,
+		item.Tags, item.Status)
 	err = row.Scan(&itemId)
 	if err != nil {
 		return 0, err
@@ -35,21 +57,25 @@ func (r *TodoItemPostgres) Create(listId int, item todo.TodoItem) (int, error) {
 
 	createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)",
 		listsItemsTable)
-	_, err = tx.Exec(createListItemsQuery, listId, itemId)
+	_, err = tx.ExecContext(ctx, createListItemsQuery, listId, itemId)
 	if err != nil {
 		return 0, err
 	}
 
+	if err := setBlockers(ctx, tx.Tx, itemId, item.BlockedBy); err != nil {
+		return 0, err
+	}
+
 	return itemId, tx.Commit()
 }
 
-func (r *TodoItemPostgres) GetAll(userId, listId int) ([]todo.TodoItem, error) {
+func (r *TodoItemPostgres) GetAll(ctx context.Context, userId, listId int) ([]todo.TodoItem, error) {
 	var items []todo.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti
+	query := fmt.Sprintf(`SELECT %s FROM %s ti
 		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
 		WHERE li.list_id=$1 AND ul.user_id=$2`,
-		todoItemsTable, listsItemsTable, usersListsTable)
-	err := r.db.Select(&items, query, listId, userId)
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable)
+	err := r.conn().SelectContext(ctx, &items, query, listId, userId)
 
 	if err != nil {
 		switch err {
@@ -63,13 +89,44 @@ func (r *TodoItemPostgres) GetAll(userId, listId int) ([]todo.TodoItem, error) {
 	return items, nil
 }
 
-func (r *TodoItemPostgres) GetById(userId, itemId int) (todo.TodoItem, error) {
+func (r *TodoItemPostgres) GetAllByUser(ctx context.Context, userId int) ([]todo.TodoItem, error) {
+	var items []todo.TodoItem
+	query := fmt.Sprintf(`SELECT DISTINCT %s FROM %s ti
+		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
+		WHERE ul.user_id=$1 ORDER BY ti.id`,
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable)
+	err := r.conn().SelectContext(ctx, &items, query, userId)
+
+	return items, err
+}
+
+// GetAllByFilter returns the items of the user matching the expression, with
+// due dates relative to today resolved from now.
+func (r *TodoItemPostgres) GetAllByFilter(ctx context.Context, userId int, expr filter.Expr, now time.Time) ([]todo.TodoItem, error) {
+	compiler := &filterCompiler{now: now, args: []any{userId}}
+	where, err := compiler.compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]todo.TodoItem, 0)
+	query := fmt.Sprintf(`SELECT DISTINCT %s FROM %s ti
+		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
+		INNER JOIN %s tl ON tl.id=li.list_id
+		WHERE ul.user_id=$1 AND %s ORDER BY ti.id`,
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable, todoListsTable, where)
+	err = r.conn().SelectContext(ctx, &items, query, compiler.args...)
+
+	return items, err
+}
+
+func (r *TodoItemPostgres) GetById(ctx context.Context, userId, itemId int) (todo.TodoItem, error) {
 	var item todo.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti
+	query := fmt.Sprintf(`SELECT %s FROM %s ti
 		INNER JOIN %s li ON li.item_id=ti.id INNER JOIN %s ul ON ul.list_id=li.list_id
 		WHERE ti.id=$1 AND ul.user_id=$2`,
-		todoItemsTable, listsItemsTable, usersListsTable)
-	err := r.db.Get(&item, query, itemId, userId)
+		itemColumns, todoItemsTable, listsItemsTable, usersListsTable)
+	err := r.conn().GetContext(ctx, &item, query, itemId, userId)
 
 	if err == sql.ErrNoRows {
 		return item, &todo.ErrNoSuchItem{}
@@ -78,15 +135,47 @@ func (r *TodoItemPostgres) GetById(userId, itemId int) (todo.TodoItem, error) {
 	return item, err
 }
 
-func (r *TodoItemPostgres) Delete(userId, itemId int) error {
+func (r *TodoItemPostgres) Delete(ctx context.Context, userId, itemId int) error {
 	query := fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s ul WHERE
 		ti.id=li.item_id AND li.list_id=ul.list_id AND ul.user_id=$1 AND ti.id=$2`,
 		todoItemsTable, listsItemsTable, usersListsTable)
-	_, err := r.db.Exec(query, userId, itemId)
+	_, err := r.conn().ExecContext(ctx, query, userId, itemId)
 	return err
 }
 
-func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput) error {
+func (r *TodoItemPostgres) Update(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) error {
+	tx, err := beginScope(ctx, r.db, r.tx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateItem(ctx, tx.Tx, userId, itemId, input); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateItem updates the columns of the item and replaces its blockers when
+// they are given.
+func updateItem(ctx context.Context, tx *sqlx.Tx, userId, itemId int, input todo.UpdateItemInput) error {
+	if query, args := updateItemQuery(userId, itemId, input); query != "" {
+		if err := execAffectingItem(ctx, tx, query, args...); err != nil {
+			return err
+		}
+	} else if err := checkItemOwner(ctx, tx, userId, itemId); err != nil {
+		return err
+	}
+
+	if input.BlockedBy != nil {
+		return setBlockers(ctx, tx, itemId, *input.BlockedBy)
+	}
+	return nil
+}
+
+// updateItemQuery returns an empty query when no column changes.
+func updateItemQuery(userId, itemId int, input todo.UpdateItemInput) (string, []any) {
 	setValues := make([]string, 0)
 	args := make([]any, 0)
 	argId := 1
@@ -109,6 +198,35 @@ func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput
 		argId++
 	}
 
+	if input.Due != nil {
+		setValues = append(setValues, fmt.Sprintf("due=$%d", argId))
+		args = append(args, *input.Due)
+		argId++
+	} else if input.ClearDue {
+		setValues = append(setValues, "due=NULL")
+	}
+
+	if input.Priority != nil {
+		setValues = append(setValues, fmt.Sprintf("priority=$%d", argId))
+		args = append(args, *input.Priority)
+		argId++
+	}
+
+	if input.Tags != nil {
+		setValues = append(setValues, fmt.Sprintf("tags=$%d", argId))
+		args = append(args, *input.Tags)
+		argId++
+	}
+
+	if input.Status != nil {
+		setValues = append(setValues, fmt.Sprintf("status=$%d", argId))
+		args = append(args, *input.Status)
+		argId++
+	}
+
+	if len(setValues) == 0 {
+		return "", nil
+	}
 	setQuery := strings.Join(setValues, ", ")
 
 	query := fmt.Sprintf(`UPDATE %s ti SET %s FROM %s li, %s ul WHERE
@@ -116,11 +234,157 @@ func (r *TodoItemPostgres) Update(userId, itemId int, input todo.UpdateItemInput
 		todoItemsTable, setQuery, listsItemsTable, usersListsTable, argId, argId+1)
 	args = append(args, userId, itemId)
 
-	res, err := r.db.Exec(query, args...)
+	return query, args
+}
 
-	if rows, _ := res.RowsAffected(); rows == 0 {
+// Bulk executes all operations in a single transaction. In atomic mode the
+// first failure rolls everything back, otherwise every operation runs in its
+// own savepoint so that failures don't affect the others.
+func (r *TodoItemPostgres) Bulk(ctx context.Context, userId int, ops []todo.BulkItemOperation, atomic bool) ([]todo.BulkItemResult, bool, error) {
+	tx, err := beginScope(ctx, r.db, r.tx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	results := make([]todo.BulkItemResult, len(ops))
+	failed := false
+	for i, op := range ops {
+		results[i] = todo.BulkItemResult{Index: i, Op: op.Op}
+		if failed {
+			continue
+		}
+
+		if !atomic {
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_op"); err != nil {
+				return nil, false, err
+			}
+		}
+
+		id, err := r.bulkOp(ctx, tx.Tx, userId, op)
+		if err != nil {
+			results[i].Err = err
+			if atomic {
+				failed = true
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT bulk_op"); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+		results[i].Id = id
+
+		if !atomic {
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT bulk_op"); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	if failed {
+		return results, false, nil
+	}
+
+	return results, true, tx.Commit()
+}
+
+func (r *TodoItemPostgres) bulkOp(ctx context.Context, tx *sqlx.Tx, userId int, op todo.BulkItemOperation) (int, error) {
+	switch op.Op {
+	case todo.BulkOpCreate:
+		if err := checkListOwner(ctx, tx, userId, op.ListId); err != nil {
+			return 0, err
+		}
+
+		var itemId int
+		createItemQuery := fmt.Sprintf(`INSERT INTO %s (title, description, done, due, priority, tags, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`, todoItemsTable)
+		row := tx.QueryRowContext(ctx, createItemQuery, op.Item.Title, op.Item.Description, op.Item.Done, op.Item.Due,
+			op.Item.Priority, op.Item.Tags, op.Item.Status)
+		if err := row.Scan(&itemId); err != nil {
+			return 0, err
+		}
+
+		createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)",
+			listsItemsTable)
+		if _, err := tx.ExecContext(ctx, createListItemsQuery, op.ListId, itemId); err != nil {
+			return 0, err
+		}
+		return itemId, setBlockers(ctx, tx, itemId, op.Item.BlockedBy)
+
+	case todo.BulkOpUpdate:
+		return op.ItemId, updateItem(ctx, tx, userId, op.ItemId, *op.Update)
+
+	case todo.BulkOpDelete:
+		query := fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s ul WHERE
+			ti.id=li.item_id AND li.list_id=ul.list_id AND ul.user_id=$1 AND ti.id=$2`,
+			todoItemsTable, listsItemsTable, usersListsTable)
+		return op.ItemId, execAffectingItem(ctx, tx, query, userId, op.ItemId)
+
+	case todo.BulkOpMove:
+		if err := checkListOwner(ctx, tx, userId, op.ListId); err != nil {
+			return 0, err
+		}
+
+		query := fmt.Sprintf(`UPDATE %s li SET list_id=$1 FROM %s ul WHERE
+			li.list_id=ul.list_id AND ul.user_id=$2 AND li.item_id=$3`,
+			listsItemsTable, usersListsTable)
+		return op.ItemId, execAffectingItem(ctx, tx, query, op.ListId, userId, op.ItemId)
+	}
+
+	return 0, &todo.ErrInvalidBulkOperation{Reason: "unknown op"}
+}
+
+func checkListOwner(ctx context.Context, tx *sqlx.Tx, userId, listId int) error {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s ul WHERE ul.user_id=$1 AND ul.list_id=$2)",
+		usersListsTable)
+	if err := tx.GetContext(ctx, &exists, query, userId, listId); err != nil {
+		return err
+	}
+	if !exists {
+		return &todo.ErrNoSuchList{}
+	}
+	return nil
+}
+
+func checkItemOwner(ctx context.Context, tx *sqlx.Tx, userId, itemId int) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s li INNER JOIN %s ul ON ul.list_id=li.list_id
+		WHERE ul.user_id=$1 AND li.item_id=$2)`,
+		listsItemsTable, usersListsTable)
+	if err := tx.GetContext(ctx, &exists, query, userId, itemId); err != nil {
+		return err
+	}
+	if !exists {
 		return &todo.ErrNoSuchItem{}
 	}
+	return nil
+}
+
+// setBlockers replaces the blockers of the item.
+func setBlockers(ctx context.Context, tx *sqlx.Tx, itemId int, blockerIds todo.ItemIds) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE item_id=$1", itemDependenciesTable)
+	if _, err := tx.ExecContext(ctx, query, itemId); err != nil {
+		return err
+	}
+	if len(blockerIds) == 0 {
+		return nil
+	}
 
+	query = fmt.Sprintf("INSERT INTO %s (item_id, blocker_id) SELECT $1, unnest($2::int[])",
+		itemDependenciesTable)
+	_, err := tx.ExecContext(ctx, query, itemId, pq.Array([]int(blockerIds)))
 	return err
 }
+
+func execAffectingItem(ctx context.Context, tx *sqlx.Tx, query string, args ...any) error {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return &todo.ErrNoSuchItem{}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -11,52 +12,65 @@ import (
 
 type TodoListPostgres struct {
 	db *sqlx.DB
+	tx *sqlx.Tx
 }
 
 func NewTodoListPostgres(db *sqlx.DB) *TodoListPostgres {
 	return &TodoListPostgres{db: db}
 }
 
-func (r *TodoListPostgres) Create(userId int, list todo.TodoList) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoListPostgres) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *TodoListPostgres) Create(ctx context.Context, userId int, list todo.TodoList) (int, error) {
+	tx, err := beginScope(ctx, r.db, r.tx)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 	defer tx.Rollback()
 
+	policy := list.BlockerPolicy
+	if policy == "" {
+		policy = todo.BlockerPolicyReject
+	}
+
 	var id int
-	createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id",
-		todoListsTable)
-	row := tx.QueryRow(createListQuery, list.Title, list.Description)
+	createListQuery := fmt.Sprintf(`INSERT INTO %s (title, description, blocker_policy)
+		VALUES ($1, $2, $3) RETURNING id`, todoListsTable)
+	row := tx.QueryRowContext(ctx, createListQuery, list.Title, list.Description, policy)
 	if err := row.Scan(&id); err != nil {
 		return 0, err
 	}
 
 	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2)",
 		usersListsTable)
-	_, err = tx.Exec(createUsersListQuery, userId, id)
+	_, err = tx.ExecContext(ctx, createUsersListQuery, userId, id)
 	if err != nil {
-		return 0, nil
+		return 0, err
 	}
 
 	return id, tx.Commit()
 }
 
-func (r *TodoListPostgres) GetAll(userId int) ([]todo.TodoList, error) {
+func (r *TodoListPostgres) GetAll(ctx context.Context, userId int) ([]todo.TodoList, error) {
 	var lists []todo.TodoList
-	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id=$1",
+	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description, tl.blocker_policy FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id=$1",
 		todoListsTable, usersListsTable)
-	err := r.db.Select(&lists, query, userId)
+	err := r.conn().SelectContext(ctx, &lists, query, userId)
 
 	return lists, err
 }
 
-func (r *TodoListPostgres) GetById(userId, listId int) (todo.TodoList, error) {
+func (r *TodoListPostgres) GetById(ctx context.Context, userId, listId int) (todo.TodoList, error) {
 	var list todo.TodoList
-	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description FROM %s tl
+	query := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description, tl.blocker_policy FROM %s tl
 		INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id=$1 AND ul.list_id=$2`,
 		todoListsTable, usersListsTable)
-	err := r.db.Get(&list, query, userId, listId)
+	err := r.conn().GetContext(ctx, &list, query, userId, listId)
 
 	if err == sql.ErrNoRows {
 		return list, &todo.ErrNoSuchList{}
@@ -65,15 +79,15 @@ func (r *TodoListPostgres) GetById(userId, listId int) (todo.TodoList, error) {
 	return list, err
 }
 
-func (r *TodoListPostgres) Delete(userId, listId int) error {
+func (r *TodoListPostgres) Delete(ctx context.Context, userId, listId int) error {
 	query := fmt.Sprintf(`DELETE FROM %s tl USING %s ul WHERE
 		tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2`,
 		todoListsTable, usersListsTable)
-	_, err := r.db.Exec(query, userId, listId)
+	_, err := r.conn().ExecContext(ctx, query, userId, listId)
 	return err
 }
 
-func (r *TodoListPostgres) Update(userId, listId int, input todo.UpdateListInput) error {
+func (r *TodoListPostgres) Update(ctx context.Context, userId, listId int, input todo.UpdateListInput) error {
 	setValues := make([]string, 0)
 	args := make([]any, 0)
 	argId := 1
@@ -90,6 +104,12 @@ func (r *TodoListPostgres) Update(userId, listId int, input todo.UpdateListInput
 		argId++
 	}
 
+	if input.BlockerPolicy != nil {
+		setValues = append(setValues, fmt.Sprintf("blocker_policy=$%d", argId))
+		args = append(args, *input.BlockerPolicy)
+		argId++
+	}
+
 	setQuery := strings.Join(setValues, ", ")
 
 	query := fmt.Sprintf(`UPDATE %s tl SET %s FROM %s ul WHERE
@@ -97,7 +117,7 @@ func (r *TodoListPostgres) Update(userId, listId int, input todo.UpdateListInput
 		todoListsTable, setQuery, usersListsTable, argId, argId+1)
 	args = append(args, listId, userId)
 
-	res, err := r.db.Exec(query, args...)
+	res, err := r.conn().ExecContext(ctx, query, args...)
 
 	if rows, _ := res.RowsAffected(); rows == 0 {
 		return &todo.ErrNoSuchList{}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+type LoginPostgres struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+}
+
+func NewLoginPostgres(db *sqlx.DB) *LoginPostgres {
+	return &LoginPostgres{db: db}
+}
+
+func (r *LoginPostgres) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// Attempt runs fn with the repository bound to a transaction holding a lock
+// on the sign-ins of the username, so checking its lockout and counting a
+// failure can't interleave with another attempt. The transaction is
+// committed if fn succeeds and rolled back otherwise.
+func (r *LoginPostgres) Attempt(ctx context.Context, username string, fn func(logins Login) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))",
+		loginFailuresTable+":"+username); err != nil {
+		return err
+	}
+
+	if err := fn(&LoginPostgres{db: r.db, tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *LoginPostgres) GetFailures(ctx context.Context, username string) (todo.LoginFailures, error) {
+	var failures todo.LoginFailures
+	query := fmt.Sprintf("SELECT count, locked_until FROM %s WHERE username=$1", loginFailuresTable)
+	err := r.conn().GetContext(ctx, &failures, query, username)
+	if err == sql.ErrNoRows {
+		return todo.LoginFailures{}, nil
+	}
+	return failures, err
+}
+
+// AddFailure counts a failed sign-in and returns the failures so far,
+// starting over when the last one was before resetBefore.
+func (r *LoginPostgres) AddFailure(ctx context.Context, username string, at, resetBefore time.Time) (int, error) {
+	var count int
+	query := fmt.Sprintf(`INSERT INTO %[1]s AS f (username, count, failed_at, locked_until) VALUES ($1, 1, $2, $2)
+		ON CONFLICT (username) DO UPDATE
+		SET count=CASE WHEN f.failed_at < $3 THEN 1 ELSE f.count + 1 END, failed_at=$2
+		RETURNING count`, loginFailuresTable)
+	err := r.conn().QueryRowContext(ctx, query, username, at, resetBefore).Scan(&count)
+	return count, err
+}
+
+func (r *LoginPostgres) Lock(ctx context.Context, username string, until time.Time) error {
+	query := fmt.Sprintf("UPDATE %s SET locked_until=$1 WHERE username=$2", loginFailuresTable)
+	_, err := r.conn().ExecContext(ctx, query, until, username)
+	return err
+}
+
+func (r *LoginPostgres) ClearFailures(ctx context.Context, username string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE username=$1", loginFailuresTable)
+	_, err := r.conn().ExecContext(ctx, query, username)
+	return err
+}
+
+func (r *LoginPostgres) CreateSession(ctx context.Context, session todo.Session) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, ip, user_agent, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`, sessionsTable)
+	err := r.conn().QueryRowContext(ctx, query, session.UserId, session.IP, session.UserAgent,
+		session.CreatedAt, session.ExpiresAt).Scan(&id)
+	return id, err
+}
+
+// GetSessions returns the sessions of the user that haven't expired at now,
+// the latest first.
+func (r *LoginPostgres) GetSessions(ctx context.Context, userId int, now time.Time) ([]todo.Session, error) {
+	sessions := make([]todo.Session, 0)
+	query := fmt.Sprintf(`SELECT id, user_id, ip, user_agent, created_at, expires_at FROM %s
+		WHERE user_id=$1 AND expires_at > $2 ORDER BY created_at DESC, id DESC`, sessionsTable)
+	err := r.conn().SelectContext(ctx, &sessions, query, userId, now)
+	return sessions, err
+}
+
+// DeleteExpired drops the failures last counted before failedBefore that
+// no longer lock their username, and the sessions that expired before
+// expiredBefore.
+func (r *LoginPostgres) DeleteExpired(ctx context.Context, failedBefore, expiredBefore time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE failed_at < $1 AND locked_until < $2", loginFailuresTable)
+	if _, err := r.conn().ExecContext(ctx, query, failedBefore, expiredBefore); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE expires_at < $1", sessionsTable)
+	_, err := r.conn().ExecContext(ctx, query, expiredBefore)
+	return err
+}
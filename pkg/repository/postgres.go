@@ -1,10 +1,19 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
 const (
@@ -13,6 +22,28 @@ const (
 	usersListsTable = "users_lists"
 	todoItemsTable  = "todo_items"
 	listsItemsTable = "lists_items"
+
+	idempotencyKeysTable   = "idempotency_keys"
+	webhooksTable          = "webhooks"
+	webhookDeliveriesTable = "webhook_deliveries"
+	calendarFeedsTable     = "calendar_feeds"
+	caldavTombstonesTable  = "caldav_tombstones"
+	filtersTable           = "filters"
+	listStatusesTable      = "list_statuses"
+	itemDependenciesTable  = "item_dependencies"
+	commentsTable          = "comments"
+	commentMentionsTable   = "comment_mentions"
+	attachmentsTable       = "attachments"
+	orphanedBlobsTable     = "orphaned_blobs"
+	rateLimitsTable        = "rate_limits"
+	loginFailuresTable     = "login_failures"
+	sessionsTable          = "sessions"
+	twoFactorTable         = "two_factor"
+	recoveryCodesTable     = "recovery_codes"
+	eventsTable            = "events"
+
+	// migrationsTable is where goose keeps the migrations it applied
+	migrationsTable = "goose_db_version"
 )
 
 type Config struct {
@@ -22,13 +53,134 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// MaxOpenConns, MaxIdleConns and ConnMaxLifetime size the pool, zero
+	// keeps the database/sql defaults.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	// ConnectRetries is how many more times connecting is tried when the
+	// DB isn't up yet, waiting ConnectBackoff first and twice as long
+	// after every failure.
+	ConnectRetries int
+	ConnectBackoff time.Duration
+}
+
+func (cfg Config) dsn() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode)
 }
 
-func NewPostgres(cfg Config) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName, cfg.SSLMode))
+// NewPostgres connects to the DB with every statement traced in a span
+// named after its operation.
+func NewPostgres(ctx context.Context, cfg Config) (*sqlx.DB, error) {
+	sqlDB, err := otelsql.Open("postgres", cfg.dsn(),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanNameFormatter(sqlSpanName),
+		otelsql.WithAttributesGetter(sqlAttributes),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+		}))
 	if err != nil {
 		return nil, err
 	}
+
+	db := sqlx.NewDb(sqlDB, "postgres")
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	if err := connect(ctx, db.PingContext, cfg.ConnectRetries, cfg.ConnectBackoff); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return db, nil
 }
+
+// connect pings until the DB answers, backing off exponentially between
+// attempts, so the app doesn't die when it starts before the DB.
+func connect(ctx context.Context, ping func(context.Context) error, retries int, backoff time.Duration) error {
+	for attempt := 0; ; attempt++ {
+		err := ping(ctx)
+		if err == nil || attempt >= retries {
+			return err
+		}
+
+		logging.FromContext(ctx).Warn("DB isn't reachable, retrying",
+			"error", err, "attempt", attempt+1, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func sqlSpanName(ctx context.Context, method otelsql.Method, query string) string {
+	if operation := sqlOperation(query); operation != "" {
+		return "SQL " + operation
+	}
+	return string(method)
+}
+
+func sqlAttributes(ctx context.Context, method otelsql.Method, query string,
+	args []driver.NamedValue) []attribute.KeyValue {
+	if operation := sqlOperation(query); operation != "" {
+		return []attribute.KeyValue{semconv.DBOperation(operation)}
+	}
+	return nil
+}
+
+// sqlOperation returns the keyword a statement starts with, like SELECT.
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// querier is implemented by both *sqlx.DB and *sqlx.Tx.
+type querier interface {
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// scope is a transaction either owned by a repository method or joined from
+// an outer one, in which case committing is left to its owner.
+type scope struct {
+	*sqlx.Tx
+	owned bool
+}
+
+func beginScope(ctx context.Context, db *sqlx.DB, tx *sqlx.Tx) (scope, error) {
+	if tx != nil {
+		return scope{Tx: tx}, nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	return scope{Tx: tx, owned: true}, err
+}
+
+func (s scope) Commit() error {
+	if !s.owned {
+		return nil
+	}
+	return s.Tx.Commit()
+}
+
+func (s scope) Rollback() error {
+	if !s.owned {
+		return nil
+	}
+	return s.Tx.Rollback()
+}
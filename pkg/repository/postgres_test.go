@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestConnect(t *testing.T) {
+	errRefused := errors.New("connection refused")
+
+	testTable := []struct {
+		name             string
+		failures         int
+		retries          int
+		expectedErr      error
+		expectedAttempts int
+	}{
+		{
+			name:             "Up at once",
+			retries:          3,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "Up after retries",
+			failures:         2,
+			retries:          3,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Never up",
+			failures:         10,
+			retries:          3,
+			expectedErr:      errRefused,
+			expectedAttempts: 4,
+		},
+		{
+			name:             "No retries",
+			failures:         1,
+			expectedErr:      errRefused,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			attempts := 0
+			ping := func(ctx context.Context) error {
+				attempts++
+				if attempts <= testCase.failures {
+					return errRefused
+				}
+				return nil
+			}
+
+			err := connect(context.Background(), ping, testCase.retries, time.Millisecond)
+
+			assert.Equal(t, err, testCase.expectedErr)
+			assert.Equal(t, attempts, testCase.expectedAttempts)
+		})
+	}
+}
+
+func TestConnect_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := connect(ctx, func(ctx context.Context) error {
+		attempts++
+		return errors.New("connection refused")
+	}, 5, time.Hour)
+
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, attempts, 1)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// RateLimitMemory keeps buckets in the process, each replica limiting on
+// its own.
+type RateLimitMemory struct {
+	mu      sync.Mutex
+	buckets map[string]bucket
+}
+
+func NewRateLimitMemory() *RateLimitMemory {
+	return &RateLimitMemory{buckets: make(map[string]bucket)}
+}
+
+func (r *RateLimitMemory) Take(ctx context.Context, key string, limit todo.RateLimit, now time.Time) (float64, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := float64(limit.Requests)
+	if b, ok := r.buckets[key]; ok {
+		tokens = math.Min(tokens, b.tokens+now.Sub(b.updatedAt).Seconds()*limit.Rate())
+	}
+	if tokens < 1 {
+		return tokens, false, nil
+	}
+
+	r.buckets[key] = bucket{tokens: tokens - 1, updatedAt: now}
+	return tokens - 1, true, nil
+}
+
+func (r *RateLimitMemory) DeleteExpired(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, b := range r.buckets {
+		if b.updatedAt.Before(before) {
+			delete(r.buckets, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+// RateLimitPostgres keeps buckets in the DB, shared by every replica.
+type RateLimitPostgres struct {
+	db *sqlx.DB
+}
+
+func NewRateLimitPostgres(db *sqlx.DB) *RateLimitPostgres {
+	return &RateLimitPostgres{db: db}
+}
+
+// Take refills the bucket and takes a token in a single statement, so
+// concurrent requests of replicas can't both take the last one.
+func (r *RateLimitPostgres) Take(ctx context.Context, key string, limit todo.RateLimit, now time.Time) (float64, bool, error) {
+	var tokens float64
+	query := fmt.Sprintf(`INSERT INTO %[1]s AS b (key, tokens, updated_at) VALUES ($1, $2::float8 - 1, $3)
+		ON CONFLICT (key) DO UPDATE
+		SET tokens=LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM $3 - b.updated_at)::float8 * $4::float8) - 1,
+		updated_at=$3
+		WHERE LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM $3 - b.updated_at)::float8 * $4::float8) >= 1
+		RETURNING tokens`, rateLimitsTable)
+	err := r.db.QueryRowContext(ctx, query, key, limit.Requests, now, limit.Rate()).Scan(&tokens)
+	if err == nil {
+		return tokens, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+
+	var b struct {
+		Tokens    float64   `db:"tokens"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	query = fmt.Sprintf("SELECT tokens, updated_at FROM %s WHERE key=$1", rateLimitsTable)
+	if err := r.db.GetContext(ctx, &b, query, key); err != nil {
+		return 0, false, err
+	}
+	tokens = math.Min(float64(limit.Requests), b.Tokens+now.Sub(b.UpdatedAt).Seconds()*limit.Rate())
+	return tokens, false, nil
+}
+
+func (r *RateLimitPostgres) DeleteExpired(ctx context.Context, before time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE updated_at < $1", rateLimitsTable)
+	_, err := r.db.ExecContext(ctx, query, before)
+	return err
+}
@@ -1,41 +1,216 @@
 package repository
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/filter"
 	"github.com/jmoiron/sqlx"
 )
 
 type Authorization interface {
-	CreateUser(user todo.User) (int, error)
-	GetUser(username, password string) (todo.User, error)
+	CreateUser(ctx context.Context, user todo.User) (int, error)
+	GetUser(ctx context.Context, username, password string) (todo.User, error)
+}
+
+type Login interface {
+	Attempt(ctx context.Context, username string, fn func(logins Login) error) error
+	GetFailures(ctx context.Context, username string) (todo.LoginFailures, error)
+	AddFailure(ctx context.Context, username string, at, resetBefore time.Time) (int, error)
+	Lock(ctx context.Context, username string, until time.Time) error
+	ClearFailures(ctx context.Context, username string) error
+	CreateSession(ctx context.Context, session todo.Session) (int, error)
+	GetSessions(ctx context.Context, userId int, now time.Time) ([]todo.Session, error)
+	DeleteExpired(ctx context.Context, failedBefore, expiredBefore time.Time) error
+}
+
+type TwoFactor interface {
+	Get(ctx context.Context, userId int) (todo.TwoFactor, error)
+	SetPending(ctx context.Context, userId int, secret string) error
+	Enable(ctx context.Context, userId int, step int64, recoveryHashes []string) error
+	Disable(ctx context.Context, userId int) error
+	UseStep(ctx context.Context, userId int, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userId int, hash string) (bool, error)
 }
 
 type TodoList interface {
-	Create(userId int, list todo.TodoList) (int, error)
-	GetAll(userId int) ([]todo.TodoList, error)
-	GetById(userId, listId int) (todo.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input todo.UpdateListInput) error
+	Create(ctx context.Context, userId int, list todo.TodoList) (int, error)
+	GetAll(ctx context.Context, userId int) ([]todo.TodoList, error)
+	GetById(ctx context.Context, userId, listId int) (todo.TodoList, error)
+	Delete(ctx context.Context, userId, listId int) error
+	Update(ctx context.Context, userId, listId int, input todo.UpdateListInput) error
 }
 
 type TodoItem interface {
-	Create(listId int, item todo.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]todo.TodoItem, error)
-	GetById(userId, itemId int) (todo.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input todo.UpdateItemInput) error
+	Create(ctx context.Context, listId int, item todo.TodoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int) ([]todo.TodoItem, error)
+	GetAllByUser(ctx context.Context, userId int) ([]todo.TodoItem, error)
+	GetAllByFilter(ctx context.Context, userId int, expr filter.Expr, now time.Time) ([]todo.TodoItem, error)
+	GetById(ctx context.Context, userId, itemId int) (todo.TodoItem, error)
+	Delete(ctx context.Context, userId, itemId int) error
+	Update(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) error
+	Bulk(ctx context.Context, userId int, ops []todo.BulkItemOperation, atomic bool) ([]todo.BulkItemResult, bool, error)
+}
+
+type Idempotency interface {
+	Reserve(ctx context.Context, record todo.IdempotencyRecord, expiredBefore time.Time) (todo.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record todo.IdempotencyRecord) error
+	Release(ctx context.Context, userId int, key string) error
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+// RateLimit keeps token buckets. Take refills the bucket at the rate of the
+// limit and takes a token when there's a whole one, returning the tokens
+// left.
+type RateLimit interface {
+	Take(ctx context.Context, key string, limit todo.RateLimit, now time.Time) (float64, bool, error)
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type Events interface {
+	Recipients(ctx context.Context, listId, itemId int) (int, []int, error)
+	Publish(ctx context.Context, event todo.Event) (int64, error)
+	Listen(ctx context.Context, handle func(todo.Event)) error
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+type Webhook interface {
+	Create(ctx context.Context, userId int, webhook todo.Webhook) (int, error)
+	GetAll(ctx context.Context, userId int) ([]todo.Webhook, error)
+	GetById(ctx context.Context, userId, webhookId int) (todo.Webhook, error)
+	GetActive(ctx context.Context, userIds []int) ([]todo.Webhook, error)
+	Update(ctx context.Context, userId, webhookId int, input todo.UpdateWebhookInput) error
+	Delete(ctx context.Context, userId, webhookId int) error
+	RecordResult(ctx context.Context, webhookId int, success bool, disableAfter int) error
+	CreateDelivery(ctx context.Context, delivery todo.WebhookDelivery) (int, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]todo.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery todo.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, userId, webhookId, limit int) ([]todo.WebhookDelivery, error)
+}
+
+type CalendarFeed interface {
+	GetToken(ctx context.Context, userId int) (string, error)
+	SetToken(ctx context.Context, userId int, token string) error
+	GetUserId(ctx context.Context, token string) (int, error)
+}
+
+type CalDAV interface {
+	GetObjects(ctx context.Context, userId, listId int) ([]todo.CalendarObject, error)
+	GetObject(ctx context.Context, userId, listId int, name string) (todo.CalendarObject, error)
+	GetChanges(ctx context.Context, userId, listId int, since int64) ([]todo.CalendarObject, []string, error)
+	SyncToken(ctx context.Context, listId int) (int64, error)
+	SetObjectName(ctx context.Context, itemId int, name, uid string) error
+}
+
+type Transactor interface {
+	InTransaction(ctx context.Context, fn func(lists TodoList, items TodoItem) error) error
+	InDependencyTransaction(ctx context.Context, fn func(items TodoItem, dependencies Dependency) error) error
+}
+
+type Backup interface {
+	Dump(ctx context.Context, fn func(dump func(table string, fn func(row any) error) error) error) error
+	Restore(ctx context.Context, fn func(insert func(table string, row any) error) error) error
+}
+
+type Filter interface {
+	Create(ctx context.Context, userId int, filter todo.Filter) (int, error)
+	GetAll(ctx context.Context, userId int) ([]todo.Filter, error)
+	GetById(ctx context.Context, userId, filterId int) (todo.Filter, error)
+	Update(ctx context.Context, userId, filterId int, input todo.UpdateFilterInput) error
+	Delete(ctx context.Context, userId, filterId int) error
+}
+
+type Dependency interface {
+	GetBlockers(ctx context.Context, itemIds []int) ([]todo.ItemDependency, error)
+	GetOpen(ctx context.Context, itemIds []int) ([]int, error)
+	GetBlockerPolicy(ctx context.Context, itemId int) (string, error)
+	GetDependencies(ctx context.Context, userId, itemId int) (todo.ItemDependencies, error)
+}
+
+type Comment interface {
+	Create(ctx context.Context, userId, itemId int, body string, mentions []string) (int, error)
+	GetAll(ctx context.Context, itemId, after, limit int) ([]todo.Comment, error)
+	GetById(ctx context.Context, userId, itemId, commentId int) (todo.Comment, error)
+	Update(ctx context.Context, commentId int, body string, mentions []string) error
+	Delete(ctx context.Context, commentId int) error
+	IsListOwner(ctx context.Context, userId, itemId int) (bool, error)
+}
+
+type Attachment interface {
+	Create(ctx context.Context, attachment todo.Attachment, quota int64) (todo.Attachment, error)
+	GetAll(ctx context.Context, itemId int) ([]todo.Attachment, error)
+	GetById(ctx context.Context, userId, itemId, attachmentId int) (todo.Attachment, error)
+	Delete(ctx context.Context, attachmentId int) error
+	Usage(ctx context.Context, userId int) (int64, error)
+	GetOrphans(ctx context.Context, limit int) ([]string, error)
+	DeleteOrphans(ctx context.Context, keys []string) error
+}
+
+// BlobStore keeps the contents of attachments, addressed by keys. Put
+// stores nothing under the key if reading r fails.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Workflow interface {
+	GetStatuses(ctx context.Context, listId int) (todo.Workflow, error)
+	GetItemStatuses(ctx context.Context, itemId int) (todo.Workflow, error)
+	SetStatuses(ctx context.Context, listId int, statuses todo.Workflow) (todo.Workflow, error)
+}
+
+type Health interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, error)
 }
 
 type Repository struct {
 	Authorization
+	Login
+	TwoFactor
 	TodoList
 	TodoItem
+	Idempotency
+	RateLimit
+	Events
+	Webhook
+	CalendarFeed
+	CalDAV
+	Transactor
+	Backup
+	Filter
+	Workflow
+	Dependency
+	Comment
+	Attachment
+	BlobStore
+	Health
 }
 
-func NewRepository(db *sqlx.DB) *Repository {
+func NewRepository(db *sqlx.DB, cfg Config) *Repository {
 	return &Repository{
 		Authorization: NewAuthPostgres(db),
+		Login:         NewLoginPostgres(db),
+		TwoFactor:     NewTwoFactorPostgres(db),
 		TodoList:      NewTodoListPostgres(db),
 		TodoItem:      NewTodoItemPostgres(db),
+		Idempotency:   NewIdempotencyPostgres(db),
+		RateLimit:     NewRateLimitPostgres(db),
+		Events:        NewEventsPostgres(db, cfg),
+		Webhook:       NewWebhookPostgres(db),
+		CalendarFeed:  NewCalendarFeedPostgres(db),
+		CalDAV:        NewCalDAVPostgres(db),
+		Transactor:    NewTransactorPostgres(db),
+		Backup:        NewBackupPostgres(db),
+		Filter:        NewFilterPostgres(db),
+		Workflow:      NewWorkflowPostgres(db),
+		Dependency:    NewDependencyPostgres(db),
+		Comment:       NewCommentPostgres(db),
+		Attachment:    NewAttachmentPostgres(db),
+		BlobStore:     NewBlobFS(defaultBlobDir),
+		Health:        NewHealthPostgres(db),
 	}
 }
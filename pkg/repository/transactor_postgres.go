@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TransactorPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTransactorPostgres(db *sqlx.DB) *TransactorPostgres {
+	return &TransactorPostgres{db: db}
+}
+
+// InTransaction runs fn with repositories bound to a single transaction,
+// which is committed if fn succeeds and rolled back otherwise.
+func (r *TransactorPostgres) InTransaction(ctx context.Context, fn func(lists TodoList, items TodoItem) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	lists := &TodoListPostgres{db: r.db, tx: tx}
+	items := &TodoItemPostgres{db: r.db, tx: tx}
+	if err := fn(lists, items); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InDependencyTransaction is InTransaction holding a lock on changes to
+// the dependency graph until the transaction ends. The graph spans lists and
+// users, so checking it for cycles and changing it has to be serialized as a
+// whole.
+func (r *TransactorPostgres) InDependencyTransaction(ctx context.Context, fn func(items TodoItem, dependencies Dependency) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("SELECT pg_advisory_xact_lock(hashtext('%s'))", itemDependenciesTable)
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return err
+	}
+
+	items := &TodoItemPostgres{db: r.db, tx: tx}
+	dependencies := &DependencyPostgres{db: r.db, tx: tx}
+	if err := fn(items, dependencies); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
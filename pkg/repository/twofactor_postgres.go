@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+)
+
+type TwoFactorPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorPostgres(db *sqlx.DB) *TwoFactorPostgres {
+	return &TwoFactorPostgres{db: db}
+}
+
+// Get returns the TOTP state of the user, the zero one with the username
+// when there's none.
+func (r *TwoFactorPostgres) Get(ctx context.Context, userId int) (todo.TwoFactor, error) {
+	var twoFactor todo.TwoFactor
+	query := fmt.Sprintf(`SELECT u.id AS user_id, u.username, COALESCE(t.secret, '') AS secret,
+		COALESCE(t.enabled, false) AS enabled, COALESCE(t.last_step, 0) AS last_step
+		FROM %s u LEFT JOIN %s t ON t.user_id = u.id WHERE u.id=$1`, usersTable, twoFactorTable)
+	err := r.db.GetContext(ctx, &twoFactor, query, userId)
+	return twoFactor, err
+}
+
+// SetPending starts an enrollment over with a new secret, unless one is
+// already enabled.
+func (r *TwoFactorPostgres) SetPending(ctx context.Context, userId int, secret string) error {
+	query := fmt.Sprintf(`INSERT INTO %[1]s AS t (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret=EXCLUDED.secret, last_step=0
+		WHERE NOT t.enabled`, twoFactorTable)
+	_, err := r.db.ExecContext(ctx, query, userId, secret)
+	return err
+}
+
+// Enable turns on the pending enrollment, with the step of the code that
+// confirmed it and the hashes of new recovery codes replacing the old ones.
+func (r *TwoFactorPostgres) Enable(ctx context.Context, userId int, step int64, recoveryHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE %s SET enabled=true, last_step=$1 WHERE user_id=$2", twoFactorTable)
+	if _, err := tx.ExecContext(ctx, query, step, userId); err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE user_id=$1", recoveryCodesTable)
+	if _, err := tx.ExecContext(ctx, query, userId); err != nil {
+		return err
+	}
+	query = fmt.Sprintf("INSERT INTO %s (user_id, code_hash) VALUES ($1, $2)", recoveryCodesTable)
+	for _, hash := range recoveryHashes {
+		if _, err := tx.ExecContext(ctx, query, userId, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorPostgres) Disable(ctx context.Context, userId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id=$1", recoveryCodesTable)
+	if _, err := tx.ExecContext(ctx, query, userId); err != nil {
+		return err
+	}
+	query = fmt.Sprintf("DELETE FROM %s WHERE user_id=$1", twoFactorTable)
+	if _, err := tx.ExecContext(ctx, query, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseStep records the step of a code, false when it or a later one was
+// already used.
+func (r *TwoFactorPostgres) UseStep(ctx context.Context, userId int, step int64) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET last_step=$1 WHERE user_id=$2 AND last_step < $1", twoFactorTable)
+	result, err := r.db.ExecContext(ctx, query, step, userId)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
+
+// UseRecoveryCode spends the recovery code, false when there's no such
+// unused code.
+func (r *TwoFactorPostgres) UseRecoveryCode(ctx context.Context, userId int, hash string) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET used_at=now()
+		WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`, recoveryCodesTable)
+	result, err := r.db.ExecContext(ctx, query, userId, hash)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows == 1, err
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type webhookRow struct {
+	todo.Webhook
+	Events pq.StringArray `db:"events"`
+}
+
+func (r webhookRow) webhook() todo.Webhook {
+	webhook := r.Webhook
+	webhook.Events = r.Events
+	return webhook
+}
+
+type WebhookPostgres struct {
+	db *sqlx.DB
+}
+
+func NewWebhookPostgres(db *sqlx.DB) *WebhookPostgres {
+	return &WebhookPostgres{db: db}
+}
+
+func (r *WebhookPostgres) Create(ctx context.Context, userId int, webhook todo.Webhook) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, url, events, secret) VALUES ($1, $2, $3, $4)
+		RETURNING id`, webhooksTable)
+	row := r.db.QueryRowContext(ctx, query, userId, webhook.URL, pq.StringArray(webhook.Events), webhook.Secret)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *WebhookPostgres) GetAll(ctx context.Context, userId int) ([]todo.Webhook, error) {
+	var rows []webhookRow
+	query := fmt.Sprintf(`SELECT id, user_id, url, events, secret, active, failure_count, created_at
+		FROM %s WHERE user_id=$1 ORDER BY id`, webhooksTable)
+	if err := r.db.SelectContext(ctx, &rows, query, userId); err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]todo.Webhook, len(rows))
+	for i, row := range rows {
+		webhooks[i] = row.webhook()
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookPostgres) GetById(ctx context.Context, userId, webhookId int) (todo.Webhook, error) {
+	var row webhookRow
+	query := fmt.Sprintf(`SELECT id, user_id, url, events, secret, active, failure_count, created_at
+		FROM %s WHERE user_id=$1 AND id=$2`, webhooksTable)
+	err := r.db.GetContext(ctx, &row, query, userId, webhookId)
+
+	if err == sql.ErrNoRows {
+		return todo.Webhook{}, &todo.ErrNoSuchWebhook{}
+	}
+
+	return row.webhook(), err
+}
+
+func (r *WebhookPostgres) GetActive(ctx context.Context, userIds []int) ([]todo.Webhook, error) {
+	var rows []webhookRow
+	query := fmt.Sprintf(`SELECT id, user_id, url, events, secret, active, failure_count, created_at
+		FROM %s WHERE active AND user_id=ANY($1)`, webhooksTable)
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(userIds)); err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]todo.Webhook, len(rows))
+	for i, row := range rows {
+		webhooks[i] = row.webhook()
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookPostgres) Update(ctx context.Context, userId, webhookId int, input todo.UpdateWebhookInput) error {
+	setValues := make([]string, 0)
+	args := make([]any, 0)
+	argId := 1
+
+	if input.URL != nil {
+		setValues = append(setValues, fmt.Sprintf("url=$%d", argId))
+		args = append(args, *input.URL)
+		argId++
+	}
+
+	if input.Events != nil {
+		setValues = append(setValues, fmt.Sprintf("events=$%d", argId))
+		args = append(args, pq.StringArray(*input.Events))
+		argId++
+	}
+
+	if input.Active != nil {
+		// re-enabling gives the endpoint a fresh start
+		setValues = append(setValues, fmt.Sprintf("active=$%d, failure_count=0", argId))
+		args = append(args, *input.Active)
+		argId++
+	}
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE user_id=$%d AND id=$%d",
+		webhooksTable, setQuery, argId, argId+1)
+	args = append(args, userId, webhookId)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return &todo.ErrNoSuchWebhook{}
+	}
+
+	return nil
+}
+
+func (r *WebhookPostgres) Delete(ctx context.Context, userId, webhookId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id=$1 AND id=$2", webhooksTable)
+	_, err := r.db.ExecContext(ctx, query, userId, webhookId)
+	return err
+}
+
+// RecordResult tracks consecutive failures and disables the webhook once
+// disableAfter is reached.
+func (r *WebhookPostgres) RecordResult(ctx context.Context, webhookId int, success bool, disableAfter int) error {
+	query := fmt.Sprintf(`UPDATE %s SET
+		failure_count=CASE WHEN $2 THEN 0 ELSE failure_count+1 END,
+		active=CASE WHEN NOT $2 AND failure_count+1 >= $3 THEN false ELSE active END
+		WHERE id=$1`, webhooksTable)
+	_, err := r.db.ExecContext(ctx, query, webhookId, success, disableAfter)
+	return err
+}
+
+func (r *WebhookPostgres) CreateDelivery(ctx context.Context, delivery todo.WebhookDelivery) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (webhook_id, event_type, payload, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`, webhookDeliveriesTable)
+	row := r.db.QueryRowContext(ctx, query, delivery.WebhookId, delivery.EventType, delivery.Payload,
+		delivery.Status, delivery.NextAttemptAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// ClaimDeliveries leases due deliveries so that other replicas skip them
+// until the lease expires.
+func (r *WebhookPostgres) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]todo.WebhookDelivery, error) {
+	var deliveries []todo.WebhookDelivery
+	query := fmt.Sprintf(`WITH due AS (
+			SELECT wd.id FROM %[1]s wd INNER JOIN %[2]s w ON w.id=wd.webhook_id
+			WHERE wd.status=$1 AND wd.next_attempt_at <= now() AND w.active
+			ORDER BY wd.next_attempt_at LIMIT $2 FOR UPDATE OF wd SKIP LOCKED
+		)
+		UPDATE %[1]s wd SET next_attempt_at=now() + $3 * interval '1 second'
+		FROM due, %[2]s w WHERE wd.id=due.id AND w.id=wd.webhook_id
+		RETURNING wd.id, wd.webhook_id, wd.event_type, wd.payload, wd.status, wd.attempts,
+			wd.next_attempt_at, wd.response_status, wd.error, wd.created_at, wd.delivered_at,
+			w.url, w.secret`, webhookDeliveriesTable, webhooksTable)
+	err := r.db.SelectContext(ctx, &deliveries, query, todo.DeliveryPending, limit, lease.Seconds())
+	return deliveries, err
+}
+
+func (r *WebhookPostgres) UpdateDelivery(ctx context.Context, delivery todo.WebhookDelivery) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=$1, attempts=$2, next_attempt_at=$3,
+		response_status=$4, error=$5, delivered_at=$6 WHERE id=$7`, webhookDeliveriesTable)
+	_, err := r.db.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.ResponseStatus, delivery.Error, delivery.DeliveredAt, delivery.Id)
+	return err
+}
+
+func (r *WebhookPostgres) GetDeliveries(ctx context.Context, userId, webhookId, limit int) ([]todo.WebhookDelivery, error) {
+	deliveries := make([]todo.WebhookDelivery, 0)
+	query := fmt.Sprintf(`SELECT wd.id, wd.webhook_id, wd.event_type, wd.payload, wd.status,
+		wd.attempts, wd.next_attempt_at, wd.response_status, wd.error, wd.created_at, wd.delivered_at
+		FROM %s wd INNER JOIN %s w ON w.id=wd.webhook_id
+		WHERE w.user_id=$1 AND w.id=$2 ORDER BY wd.id DESC LIMIT $3`,
+		webhookDeliveriesTable, webhooksTable)
+	err := r.db.SelectContext(ctx, &deliveries, query, userId, webhookId, limit)
+	return deliveries, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type WorkflowPostgres struct {
+	db *sqlx.DB
+}
+
+func NewWorkflowPostgres(db *sqlx.DB) *WorkflowPostgres {
+	return &WorkflowPostgres{db: db}
+}
+
+func (r *WorkflowPostgres) GetStatuses(ctx context.Context, listId int) (todo.Workflow, error) {
+	statuses := make(todo.Workflow, 0)
+	query := fmt.Sprintf(`SELECT id, list_id, name, position, terminal, transitions FROM %s
+		WHERE list_id=$1 ORDER BY position`, listStatusesTable)
+	err := r.db.SelectContext(ctx, &statuses, query, listId)
+	return statuses, err
+}
+
+// GetItemStatuses returns the workflow of the list the item is in.
+func (r *WorkflowPostgres) GetItemStatuses(ctx context.Context, itemId int) (todo.Workflow, error) {
+	statuses := make(todo.Workflow, 0)
+	query := fmt.Sprintf(`SELECT ls.id, ls.list_id, ls.name, ls.position, ls.terminal, ls.transitions
+		FROM %s ls INNER JOIN %s li ON li.list_id=ls.list_id
+		WHERE li.item_id=$1 ORDER BY ls.position`, listStatusesTable, listsItemsTable)
+	err := r.db.SelectContext(ctx, &statuses, query, itemId)
+	return statuses, err
+}
+
+// SetStatuses replaces the workflow of the list. Items follow renamed
+// statuses, lose removed ones and are done when in the terminal status.
+func (r *WorkflowPostgres) SetStatuses(ctx context.Context, listId int, statuses todo.Workflow) (todo.Workflow, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var existing todo.Workflow
+	query := fmt.Sprintf("SELECT id, name FROM %s WHERE list_id=$1 FOR UPDATE", listStatusesTable)
+	if err := tx.SelectContext(ctx, &existing, query, listId); err != nil {
+		return nil, err
+	}
+
+	kept := make(map[int]string, len(statuses))
+	for _, status := range statuses {
+		if status.Id != 0 {
+			kept[status.Id] = status.Name
+		}
+	}
+
+	keptIds := make([]int, 0, len(kept))
+	oldNames := make([]string, 0, len(existing))
+	newNames := make([]string, 0, len(existing))
+	for _, status := range existing {
+		name, ok := kept[status.Id]
+		if ok {
+			keptIds = append(keptIds, status.Id)
+			delete(kept, status.Id)
+		}
+		if name != status.Name {
+			oldNames = append(oldNames, status.Name)
+			newNames = append(newNames, name)
+		}
+	}
+	for id := range kept {
+		return nil, &todo.ErrInvalidWorkflowInput{Reason: fmt.Sprintf("no status with id %d", id)}
+	}
+
+	// a single statement, so that swapped names don't collide
+	query = fmt.Sprintf(`UPDATE %s ti SET status=m.new FROM %s li,
+		(SELECT unnest($2::text[]) AS old, unnest($3::text[]) AS new) m
+		WHERE li.item_id=ti.id AND li.list_id=$1 AND ti.status=m.old`, todoItemsTable, listsItemsTable)
+	if _, err := tx.ExecContext(ctx, query, listId, pq.StringArray(oldNames), pq.StringArray(newNames)); err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE list_id=$1 AND NOT id=ANY($2)", listStatusesTable)
+	if _, err := tx.ExecContext(ctx, query, listId, pq.Array(keptIds)); err != nil {
+		return nil, err
+	}
+
+	for i := range statuses {
+		statuses[i].ListId = listId
+		statuses[i].Position = i
+		if statuses[i].Id != 0 {
+			query := fmt.Sprintf(`UPDATE %s SET name=$1, position=$2, terminal=$3, transitions=$4
+				WHERE id=$5`, listStatusesTable)
+			_, err = tx.ExecContext(ctx, query, statuses[i].Name, i, statuses[i].Terminal, statuses[i].Transitions,
+				statuses[i].Id)
+		} else {
+			query := fmt.Sprintf(`INSERT INTO %s (list_id, name, position, terminal, transitions)
+				VALUES ($1, $2, $3, $4, $5) RETURNING id`, listStatusesTable)
+			err = tx.QueryRowContext(ctx, query, listId, statuses[i].Name, i, statuses[i].Terminal,
+				statuses[i].Transitions).Scan(&statuses[i].Id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query = fmt.Sprintf(`UPDATE %s ti SET done=ls.terminal FROM %s li, %s ls
+		WHERE li.item_id=ti.id AND li.list_id=$1 AND ls.list_id=$1 AND ls.name=ti.status
+		AND ti.done<>ls.terminal`, todoItemsTable, listsItemsTable, listStatusesTable)
+	if _, err := tx.ExecContext(ctx, query, listId); err != nil {
+		return nil, err
+	}
+
+	return statuses, tx.Commit()
+}
@@ -2,26 +2,293 @@ package todo
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
+const unixAddrPrefix = "unix:"
+
+type ServerConfig struct {
+	// Addr is host:port to listen on, or unix:/path/to.sock for a unix
+	// socket.
+	Addr string
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// MaxBodyBytes caps every request body, zero leaves it to the routes.
+	MaxBodyBytes int64
+
+	// H2C serves HTTP/2 without TLS, for proxies that speak it. With TLS
+	// HTTP/2 is always on.
+	H2C bool
+
+	TLS TLSConfig
+}
+
+type TLSConfig struct {
+	// CertFile and KeyFile are reloaded on ReloadCertificates.
+	CertFile string
+	KeyFile  string
+
+	// AutocertDomains gets certificates from Let's Encrypt for the domains
+	// instead, kept in AutocertCacheDir.
+	AutocertDomains  []string
+	AutocertCacheDir string
+
+	// ClientCAFile turns on client certificate auth against the CAs in it,
+	// ClientAuth is either require or request, which lets clients without
+	// one through.
+	ClientCAFile string
+	ClientAuth   string
+
+	// RedirectAddr listens for plain HTTP and redirects it to HTTPS, also
+	// answering the ACME challenges of autocert.
+	RedirectAddr string
+}
+
+func (cfg TLSConfig) enabled() bool {
+	return cfg.CertFile != "" || len(cfg.AutocertDomains) > 0
+}
+
+func (cfg ServerConfig) withDefaults() ServerConfig {
+	if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = 10 * time.Second
+	}
+	if cfg.ReadHeaderTimeout == 0 {
+		cfg.ReadHeaderTimeout = cfg.ReadTimeout
+	}
+	if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = 60 * time.Second
+	}
+	if cfg.MaxHeaderBytes == 0 {
+		cfg.MaxHeaderBytes = 1 << 20
+	}
+	return cfg
+}
+
 type Server struct {
-	httpServer *http.Server
+	mu             sync.Mutex
+	httpServer     *http.Server
+	redirectServer *http.Server
+	certs          *certReloader
 }
 
-func (s *Server) Run(port string, handler http.Handler) error {
-	s.httpServer = &http.Server{
-		Addr:           ":" + port,
-		Handler:        handler,
-		MaxHeaderBytes: 1 << 20,
-		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+func (s *Server) Run(cfg ServerConfig, handler http.Handler) error {
+	cfg = cfg.withDefaults()
+
+	if cfg.MaxBodyBytes > 0 {
+		handler = http.MaxBytesHandler(handler, cfg.MaxBodyBytes)
+	}
+	if cfg.H2C && !cfg.TLS.enabled() {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+	}
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
 	}
 
-	return s.httpServer.ListenAndServe()
+	var challenges *autocert.Manager
+	if cfg.TLS.enabled() {
+		tlsConfig, manager, err := s.newTLSConfig(cfg.TLS)
+		if err != nil {
+			return err
+		}
+		httpServer.TLSConfig = tlsConfig
+		challenges = manager
+	}
+
+	listener, err := listen(cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	var redirectServer *http.Server
+	if cfg.TLS.enabled() && cfg.TLS.RedirectAddr != "" {
+		redirectServer = newRedirectServer(cfg, challenges)
+	}
+
+	s.mu.Lock()
+	s.httpServer = httpServer
+	s.redirectServer = redirectServer
+	s.mu.Unlock()
+
+	if redirectServer != nil {
+		// the app itself is still served, so a failing redirect only costs
+		// the plain HTTP clients
+		go func() {
+			err := redirectServer.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Redirect server failed", "addr", redirectServer.Addr, "error", err)
+			}
+		}()
+	}
+
+	if cfg.TLS.enabled() {
+		// the certificates come from the TLS config, which also gets h2
+		return httpServer.ServeTLS(listener, "", "")
+	}
+	return httpServer.Serve(listener)
 }
 
+// Shutdown waits for the requests in flight until ctx is done, then closes
+// the connections left.
 func (s *Server) Shutdown(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	s.mu.Lock()
+	httpServer, redirectServer := s.httpServer, s.redirectServer
+	s.mu.Unlock()
+
+	if redirectServer != nil {
+		redirectServer.Shutdown(ctx)
+	}
+	if httpServer == nil {
+		return nil
+	}
+	if err := httpServer.Shutdown(ctx); err != nil {
+		httpServer.Close()
+		return err
+	}
+	return nil
+}
+
+// ReloadCertificates reads the certificate and key files again, so renewed
+// certificates are served without a restart. Connections already made keep
+// the old one.
+func (s *Server) ReloadCertificates() error {
+	s.mu.Lock()
+	certs := s.certs
+	s.mu.Unlock()
+
+	if certs == nil {
+		return nil
+	}
+	return certs.reload()
+}
+
+func (s *Server) newTLSConfig(cfg TLSConfig) (*tls.Config, *autocert.Manager, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	var manager *autocert.Manager
+	if len(cfg.AutocertDomains) > 0 {
+		manager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+		}
+		if cfg.AutocertCacheDir != "" {
+			manager.Cache = autocert.DirCache(cfg.AutocertCacheDir)
+		}
+		tlsConfig.GetCertificate = manager.GetCertificate
+		tlsConfig.NextProtos = []string{"h2", "http/1.1", "acme-tls/1"}
+	} else {
+		certs := &certReloader{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+		if err := certs.reload(); err != nil {
+			return nil, nil, err
+		}
+		s.mu.Lock()
+		s.certs = certs
+		s.mu.Unlock()
+		tlsConfig.GetCertificate = certs.getCertificate
+	}
+
+	if cfg.ClientCAFile != "" {
+		pool, err := loadCertPool(cfg.ClientCAFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig.ClientCAs = pool
+
+		switch cfg.ClientAuth {
+		case "", "require":
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		case "request":
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, nil, errors.New("unknown client auth " + cfg.ClientAuth + ", expected require or request")
+		}
+	}
+
+	return tlsConfig, manager, nil
+}
+
+func newRedirectServer(cfg ServerConfig, challenges *autocert.Manager) *http.Server {
+	var handler http.Handler = httpsRedirect(cfg.Addr)
+	if challenges != nil {
+		handler = challenges.HTTPHandler(handler)
+	}
+
+	return &http.Server{
+		Addr:              cfg.TLS.RedirectAddr,
+		Handler:           handler,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// httpsRedirect sends clients to the same URL over HTTPS, on the port the
+// TLS listener has.
+func httpsRedirect(addr string) http.Handler {
+	_, port, _ := net.SplitHostPort(addr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
+
+// UnixSocket tells whether Addr is a unix socket.
+func (cfg ServerConfig) UnixSocket() bool {
+	return strings.HasPrefix(cfg.Addr, unixAddrPrefix)
+}
+
+func listen(addr string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, unixAddrPrefix); ok {
+		// a socket left by a previous run would make listening fail, but
+		// anything else at the path isn't ours to remove
+		info, err := os.Lstat(path)
+		switch {
+		case err == nil && info.Mode()&os.ModeSocket != 0:
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		case err == nil:
+			return nil, fmt.Errorf("%s exists and isn't a socket", path)
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", addr)
 }
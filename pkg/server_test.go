@@ -0,0 +1,189 @@
+package todo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestHttpsRedirect(t *testing.T) {
+	testTable := []struct {
+		name             string
+		addr             string
+		url              string
+		expectedLocation string
+	}{
+		{
+			name:             "Default port",
+			addr:             ":443",
+			url:              "http://todo.example:80/api/lists?page=2",
+			expectedLocation: "https://todo.example/api/lists?page=2",
+		},
+		{
+			name:             "Other port",
+			addr:             ":8443",
+			url:              "http://todo.example/healthz",
+			expectedLocation: "https://todo.example:8443/healthz",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			httpsRedirect(testCase.addr).ServeHTTP(w, httptest.NewRequest("GET", testCase.url, nil))
+
+			assert.Equal(t, w.Code, http.StatusPermanentRedirect)
+			assert.Equal(t, w.Header().Get("Location"), testCase.expectedLocation)
+		})
+	}
+}
+
+// writeCert writes a self-signed certificate for 127.0.0.1 with the serial
+// number, to tell certificates apart.
+func writeCert(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600)
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, 1)
+
+	addr := freeAddr(t)
+	server := new(Server)
+	go server.Run(ServerConfig{
+		Addr: addr,
+		TLS:  TLSConfig{CertFile: certFile, KeyFile: keyFile},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	defer server.Shutdown(context.Background())
+
+	get := func() *http.Response {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		}}
+		for i := 0; i < 50; i++ {
+			resp, err := client.Get("https://" + addr)
+			if err == nil {
+				resp.Body.Close()
+				return resp
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("Server didn't come up")
+		return nil
+	}
+
+	resp := get()
+	assert.Equal(t, resp.ProtoMajor, 2)
+	assert.Equal(t, resp.TLS.PeerCertificates[0].SerialNumber.Int64(), int64(1))
+
+	writeCert(t, certFile, keyFile, 2)
+	assert.Equal(t, server.ReloadCertificates(), nil)
+	assert.Equal(t, get().TLS.PeerCertificates[0].SerialNumber.Int64(), int64(2))
+
+	// a broken renewal keeps the current certificate
+	os.WriteFile(keyFile, []byte("broken"), 0o600)
+	assert.NotEqual(t, server.ReloadCertificates(), nil)
+	assert.Equal(t, get().TLS.PeerCertificates[0].SerialNumber.Int64(), int64(2))
+}
+
+func TestServer_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.sock")
+	// a socket left by a previous run is replaced
+	stale, err := net.Listen("unix", path)
+	assert.Equal(t, err, nil)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	server := new(Server)
+	go server.Run(ServerConfig{Addr: unixAddrPrefix + path, MaxBodyBytes: 4},
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := io.ReadAll(r.Body); err != nil {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+			}
+		}))
+	defer server.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		resp, err = client.Post("http://todo/", "text/plain", strings.NewReader("too long"))
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, err, nil)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusRequestEntityTooLarge)
+}
+
+func TestListen_NotSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.sock")
+	os.WriteFile(path, []byte("data"), 0o600)
+
+	_, err := listen(unixAddrPrefix + path)
+	assert.Equal(t, err.Error(), path+" exists and isn't a socket")
+
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, string(data), "data")
+}
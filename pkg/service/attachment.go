@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	// sniffLength is as much of a file as content type detection looks at
+	sniffLength = 512
+
+	orphanSweepInterval = time.Minute
+	orphanSweepBatch    = 100
+)
+
+type AttachmentService struct {
+	repo     repository.Attachment
+	blobs    repository.BlobStore
+	itemRepo repository.TodoItem
+	quota    int64
+}
+
+func NewAttachmentService(repo repository.Attachment, blobs repository.BlobStore,
+	itemRepo repository.TodoItem, quota int64) *AttachmentService {
+	return &AttachmentService{repo: repo, blobs: blobs, itemRepo: itemRepo, quota: quota}
+}
+
+// attachmentQuota returns the bytes a user's attachments may take, set by
+// ATTACHMENT_QUOTA.
+func attachmentQuota() int64 {
+	value := config.Get("ATTACHMENT_QUOTA", "")
+	if value == "" {
+		return todo.DefaultAttachmentQuota
+	}
+
+	quota, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || quota <= 0 {
+		slog.Warn("Invalid ATTACHMENT_QUOTA, using the default", "value", value,
+			"default", todo.DefaultAttachmentQuota)
+		return todo.DefaultAttachmentQuota
+	}
+	return quota
+}
+
+// Create streams the file from r into the blob store. Its content type is
+// detected from the data rather than trusted from the client, and reading
+// stops as soon as the file goes over what's left of the user's quota.
+func (s *AttachmentService) Create(ctx context.Context, userId, itemId int, name string, r io.Reader) (_ todo.Attachment, err error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.Create", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	name, err = todo.AttachmentName(name)
+	if err != nil {
+		return todo.Attachment{}, err
+	}
+	if _, err := s.itemRepo.GetById(ctx, userId, itemId); err != nil {
+		return todo.Attachment{}, err
+	}
+
+	used, err := s.repo.Usage(ctx, userId)
+	if err != nil {
+		return todo.Attachment{}, err
+	}
+	if used >= s.quota {
+		return todo.Attachment{}, &todo.ErrQuotaExceeded{Quota: s.quota}
+	}
+
+	br := bufio.NewReaderSize(r, sniffLength)
+	head, err := br.Peek(sniffLength)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return todo.Attachment{}, err
+	}
+
+	key, err := blobKey()
+	if err != nil {
+		return todo.Attachment{}, err
+	}
+
+	size, err := s.blobs.Put(ctx, key, &quotaReader{r: br, left: s.quota - used, quota: s.quota})
+	if err != nil {
+		return todo.Attachment{}, err
+	}
+
+	attachment, err := s.repo.Create(ctx, todo.Attachment{
+		ItemId:      itemId,
+		UserId:      userId,
+		Name:        name,
+		ContentType: http.DetectContentType(head),
+		Size:        size,
+		Key:         key,
+	}, s.quota)
+	if err != nil {
+		if err := s.blobs.Delete(context.Background(), key); err != nil {
+			logging.FromContext(ctx).Error("Failed to delete blob", "key", key, "error", err)
+		}
+		return todo.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) GetAll(ctx context.Context, userId, itemId int) (_ []todo.Attachment, err error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.GetAll", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.itemRepo.GetById(ctx, userId, itemId); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(ctx, itemId)
+}
+
+// Open returns the attachment with a reader of its contents, which the
+// caller has to close.
+func (s *AttachmentService) Open(ctx context.Context, userId, itemId, attachmentId int) (_ todo.Attachment, _ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.Open", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	attachment, err := s.repo.GetById(ctx, userId, itemId, attachmentId)
+	if err != nil {
+		return attachment, nil, err
+	}
+
+	rc, err := s.blobs.Get(ctx, attachment.Key)
+	return attachment, rc, err
+}
+
+func (s *AttachmentService) Delete(ctx context.Context, userId, itemId, attachmentId int) (err error) {
+	ctx, span := tracing.Start(ctx, "AttachmentService.Delete", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.repo.GetById(ctx, userId, itemId, attachmentId); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, attachmentId)
+}
+
+// Run removes the blobs of deleted attachments until the context is done.
+// Attachments go away with their items and lists without the service
+// knowing, so their blobs are collected afterwards instead.
+func (s *AttachmentService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(orphanSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.sweepOrphans(ctx); err != nil {
+				logging.FromContext(ctx).Error("Failed to sweep orphaned blobs", "error", err)
+			}
+		}
+	}
+}
+
+func (s *AttachmentService) sweepOrphans(ctx context.Context) error {
+	for {
+		keys, err := s.repo.GetOrphans(ctx, orphanSweepBatch)
+		if err != nil || len(keys) == 0 {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+		if err := s.repo.DeleteOrphans(ctx, keys); err != nil {
+			return err
+		}
+		if len(keys) < orphanSweepBatch {
+			return nil
+		}
+	}
+}
+
+func blobKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// quotaReader fails once more than left bytes are read.
+type quotaReader struct {
+	r     io.Reader
+	left  int64
+	quota int64
+}
+
+func (r *quotaReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.left+1 {
+		p = p[:r.left+1]
+	}
+	n, err := r.r.Read(p)
+	r.left -= int64(n)
+	if r.left < 0 {
+		return 0, &todo.ErrQuotaExceeded{Quota: r.quota}
+	}
+	return n, err
+}
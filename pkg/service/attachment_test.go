@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/go-playground/assert/v2"
+)
+
+type attachmentRepo struct {
+	attachments []todo.Attachment
+	orphans     []string
+}
+
+func (r *attachmentRepo) Create(ctx context.Context, attachment todo.Attachment, quota int64) (todo.Attachment, error) {
+	used, _ := r.Usage(ctx, attachment.UserId)
+	if used+attachment.Size > quota {
+		return attachment, &todo.ErrQuotaExceeded{Quota: quota}
+	}
+	attachment.Id = len(r.attachments) + 1
+	r.attachments = append(r.attachments, attachment)
+	return attachment, nil
+}
+
+func (r *attachmentRepo) GetAll(ctx context.Context, itemId int) ([]todo.Attachment, error) {
+	attachments := make([]todo.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.ItemId == itemId {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepo) GetById(ctx context.Context, userId, itemId, attachmentId int) (todo.Attachment, error) {
+	for _, attachment := range r.attachments {
+		if attachment.Id == attachmentId && attachment.ItemId == itemId {
+			return attachment, nil
+		}
+	}
+	return todo.Attachment{}, &todo.ErrNoSuchAttachment{}
+}
+
+func (r *attachmentRepo) Delete(ctx context.Context, attachmentId int) error {
+	for i, attachment := range r.attachments {
+		if attachment.Id == attachmentId {
+			r.orphans = append(r.orphans, attachment.Key)
+			r.attachments = append(r.attachments[:i], r.attachments[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func (r *attachmentRepo) Usage(ctx context.Context, userId int) (int64, error) {
+	var used int64
+	for _, attachment := range r.attachments {
+		if attachment.UserId == userId {
+			used += attachment.Size
+		}
+	}
+	return used, nil
+}
+
+func (r *attachmentRepo) GetOrphans(ctx context.Context, limit int) ([]string, error) {
+	if len(r.orphans) < limit {
+		limit = len(r.orphans)
+	}
+	return r.orphans[:limit], nil
+}
+
+func (r *attachmentRepo) DeleteOrphans(ctx context.Context, keys []string) error {
+	r.orphans = r.orphans[len(keys):]
+	return nil
+}
+
+func newAttachmentService(t *testing.T, quota int64) (*AttachmentService, *attachmentRepo, repository.BlobStore) {
+	repo := &attachmentRepo{}
+	blobs := repository.NewBlobFS(t.TempDir())
+	items := &workflowItemRepo{items: []todo.TodoItem{{Id: 1, Title: "Plan"}}}
+	return NewAttachmentService(repo, blobs, items, quota), repo, blobs
+}
+
+func TestAttachmentService_Create(t *testing.T) {
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, 600)...)
+
+	testTable := []struct {
+		name                string
+		fileName            string
+		itemId              int
+		data                []byte
+		expectedName        string
+		expectedContentType string
+		expectedError       error
+	}{
+		{
+			name:                "Text",
+			fileName:            "notes.txt",
+			itemId:              1,
+			data:                []byte("Buy milk"),
+			expectedName:        "notes.txt",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:                "Sniffed",
+			fileName:            `C:\Users\me\photo.txt`,
+			itemId:              1,
+			data:                png,
+			expectedName:        "photo.txt",
+			expectedContentType: "image/png",
+		},
+		{
+			name:                "Empty",
+			fileName:            "empty",
+			itemId:              1,
+			data:                []byte{},
+			expectedName:        "empty",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:          "No name",
+			fileName:      "",
+			itemId:        1,
+			data:          []byte("data"),
+			expectedError: &todo.ErrInvalidAttachment{Reason: "file name must not be empty"},
+		},
+		{
+			name:          "No such item",
+			fileName:      "notes.txt",
+			itemId:        2,
+			data:          []byte("data"),
+			expectedError: &todo.ErrNoSuchItem{},
+		},
+		{
+			name:          "Over quota",
+			fileName:      "big.bin",
+			itemId:        1,
+			data:          make([]byte, 1025),
+			expectedError: &todo.ErrQuotaExceeded{Quota: 1024},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, repo, blobs := newAttachmentService(t, 1024)
+
+			attachment, err := service.Create(context.Background(), 1, testCase.itemId,
+				testCase.fileName, bytes.NewReader(testCase.data))
+			assert.Equal(t, err, testCase.expectedError)
+			if testCase.expectedError != nil {
+				assert.Equal(t, len(repo.attachments), 0)
+				return
+			}
+
+			assert.Equal(t, attachment.Name, testCase.expectedName)
+			assert.Equal(t, attachment.ContentType, testCase.expectedContentType)
+			assert.Equal(t, attachment.Size, int64(len(testCase.data)))
+
+			rc, err := blobs.Get(context.Background(), attachment.Key)
+			assert.Equal(t, err, nil)
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			assert.Equal(t, data, testCase.data)
+		})
+	}
+}
+
+func TestAttachmentService_Quota(t *testing.T) {
+	service, repo, _ := newAttachmentService(t, 10)
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, 1, 1, "a.txt", strings.NewReader("123456"))
+	assert.Equal(t, err, nil)
+
+	// what's left of the quota, not all of it
+	_, err = service.Create(ctx, 1, 1, "b.txt", strings.NewReader("123456"))
+	assert.Equal(t, err, &todo.ErrQuotaExceeded{Quota: 10})
+
+	_, err = service.Create(ctx, 1, 1, "c.txt", strings.NewReader("1234"))
+	assert.Equal(t, err, nil)
+
+	_, err = service.Create(ctx, 1, 1, "d.txt", strings.NewReader(""))
+	assert.Equal(t, err, &todo.ErrQuotaExceeded{Quota: 10})
+
+	// other users have their own quota
+	_, err = service.Create(ctx, 2, 1, "e.txt", strings.NewReader("1234567890"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(repo.attachments), 3)
+}
+
+func TestAttachmentService_Delete(t *testing.T) {
+	service, repo, blobs := newAttachmentService(t, 1024)
+	ctx := context.Background()
+
+	attachment, err := service.Create(ctx, 1, 1, "a.txt", strings.NewReader("data"))
+	assert.Equal(t, err, nil)
+
+	assert.Equal(t, service.Delete(ctx, 1, 2, attachment.Id), &todo.ErrNoSuchAttachment{})
+	assert.Equal(t, service.Delete(ctx, 1, 1, attachment.Id), nil)
+	assert.Equal(t, repo.orphans, []string{attachment.Key})
+
+	// the blob stays until orphans are swept
+	_, _, err = service.Open(ctx, 1, 1, attachment.Id)
+	assert.Equal(t, err, &todo.ErrNoSuchAttachment{})
+	rc, err := blobs.Get(ctx, attachment.Key)
+	assert.Equal(t, err, nil)
+	rc.Close()
+
+	assert.Equal(t, service.sweepOrphans(ctx), nil)
+	assert.Equal(t, len(repo.orphans), 0)
+	_, err = blobs.Get(ctx, attachment.Key)
+	assert.Equal(t, err, &todo.ErrNoSuchAttachment{})
+}
@@ -1,57 +1,388 @@
 package service
 
 import (
+	"context"
 	"crypto/sha1"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/OrIX219/todo/pkg"
 	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/logging"
 	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
 	"github.com/dgrijalva/jwt-go"
 )
 
 const (
 	tokenTTL = 12 * time.Hour
+
+	// loginFailuresTTL is how long failed sign-ins are remembered after the
+	// last one.
+	loginFailuresTTL = 24 * time.Hour
+
+	maxUserAgentLength = 512
+
+	// maxUsernameLength is the longest username the users and logins
+	// tables hold, longer ones can't belong to an account.
+	maxUsernameLength = 255
+
+	// challengeTTL is how long the code of a two-factor sign-in may take.
+	challengeTTL     = 5 * time.Minute
+	twoFactorPurpose = "2fa"
 )
 
+// tokenClaims are the claims of both tokens and challenges, which have a
+// purpose and the username of the sign-in.
 type tokenClaims struct {
 	jwt.StandardClaims
-	UserId int `json:"user_id"`
+	UserId   int    `json:"user_id"`
+	Purpose  string `json:"purpose,omitempty"`
+	Username string `json:"username,omitempty"`
 }
 
 type AuthService struct {
-	repo repository.Authorization
+	repo      repository.Authorization
+	logins    repository.Login
+	twoFactor repository.TwoFactor
+	policy    todo.LockoutPolicy
 }
 
-func NewAuthService(repo repository.Authorization) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo repository.Authorization, logins repository.Login, twoFactor repository.TwoFactor,
+	policy todo.LockoutPolicy) *AuthService {
+	return &AuthService{repo: repo, logins: logins, twoFactor: twoFactor, policy: policy}
+}
+
+// lockoutPolicy returns after how many failed sign-ins usernames are locked
+// and for how long, set by LOGIN_MAX_FAILURES and LOGIN_LOCKOUT.
+func lockoutPolicy() todo.LockoutPolicy {
+	policy := todo.LockoutPolicy{MaxFailures: 10, Lockout: 15 * time.Minute}
+
+	if value := config.Get("LOGIN_MAX_FAILURES", ""); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			slog.Warn("Invalid LOGIN_MAX_FAILURES, using the default", "value", value,
+				"default", policy.MaxFailures)
+		} else {
+			policy.MaxFailures = n
+		}
+	}
+	if value := config.Get("LOGIN_LOCKOUT", ""); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			slog.Warn("Invalid LOGIN_LOCKOUT, using the default", "value", value,
+				"default", policy.Lockout.String())
+		} else {
+			policy.Lockout = d
+		}
+	}
+	return policy
 }
 
-func (s *AuthService) CreateUser(user todo.User) (int, error) {
+func (s *AuthService) CreateUser(ctx context.Context, user todo.User) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreateUser")
+	defer tracing.End(span, &err)
+
 	user.Password = generatePasswordHash(user.Password)
-	return s.repo.CreateUser(user)
+	return s.repo.CreateUser(ctx, user)
+}
+
+// Authenticate checks the credentials and returns the user's id. Failures
+// are counted per username, existing or not, and make the next attempts
+// wait, so passwords can't be guessed quickly nor usernames told apart.
+// Users with two-factor auth can't authenticate with a password alone.
+func (s *AuthService) Authenticate(ctx context.Context, username, password string) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authenticate")
+	defer tracing.End(span, &err)
+
+	userId, failures, err := s.checkPassword(ctx, username, password)
+	if err != nil {
+		return 0, err
+	}
+
+	twoFactor, err := s.twoFactor.Get(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+	if twoFactor.Enabled {
+		// the clients of basic auth have no way to answer a challenge
+		return 0, &todo.ErrTwoFactorRequired{}
+	}
+
+	return userId, clearFailures(ctx, s.logins, username, failures)
+}
+
+// GenerateToken signs the user in, recording where from in the login
+// history. Users with two-factor auth get a challenge to exchange with a
+// code at VerifyTwoFactor instead.
+func (s *AuthService) GenerateToken(ctx context.Context, username, password string, client todo.LoginClient) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GenerateToken")
+	defer tracing.End(span, &err)
+
+	userId, failures, err := s.checkPassword(ctx, username, password)
+	if err != nil {
+		return "", err
+	}
+
+	twoFactor, err := s.twoFactor.Get(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	if twoFactor.Enabled {
+		// failures are cleared only once the code is right too, so codes
+		// can't be guessed between correct passwords
+		now := time.Now()
+		challenge, err := signToken(&tokenClaims{
+			StandardClaims: jwt.StandardClaims{
+				ExpiresAt: now.Add(challengeTTL).Unix(),
+				IssuedAt:  now.Unix(),
+			},
+			UserId:   userId,
+			Purpose:  twoFactorPurpose,
+			Username: username,
+		})
+		if err != nil {
+			return "", err
+		}
+		return "", &todo.ErrTwoFactorRequired{Challenge: challenge}
+	}
+
+	if err := clearFailures(ctx, s.logins, username, failures); err != nil {
+		return "", err
+	}
+	return s.issueToken(ctx, userId, client)
+}
+
+// VerifyTwoFactor exchanges the challenge of a sign-in for a token with a
+// code from the user's app or a recovery code. Wrong codes count as failed
+// sign-ins of the username.
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, challenge, code string, client todo.LoginClient) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyTwoFactor")
+	defer tracing.End(span, &err)
+
+	claims, err := parseToken(challenge)
+	if err != nil || claims.Purpose != twoFactorPurpose {
+		return "", &todo.ErrInvalidChallenge{}
+	}
+	span.SetAttributes(tracing.UserId(claims.UserId))
+
+	err = s.attempt(ctx, claims.Username, func(logins repository.Login) error {
+		failures, err := checkLockout(ctx, logins, claims.Username)
+		if err != nil {
+			return err
+		}
+
+		twoFactor, err := s.twoFactor.Get(ctx, claims.UserId)
+		if err != nil {
+			return err
+		}
+		if !twoFactor.Enabled {
+			// turned off since the challenge was issued
+			return &todo.ErrInvalidChallenge{}
+		}
+
+		ok, err := verifyTwoFactorCode(ctx, s.twoFactor, twoFactor, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := s.addFailure(ctx, logins, claims.Username); err != nil {
+				return err
+			}
+			return &todo.ErrInvalidTwoFactorCode{}
+		}
+		return clearFailures(ctx, logins, claims.Username, failures)
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.issueToken(ctx, claims.UserId, client)
+}
+
+// Reauthenticate checks the password of a signed-in user, and a two-factor
+// code once two-factor auth is on, before changes to how they sign in. Wrong ones count as failed
+// sign-ins of the username, the way they do when signing in.
+func (s *AuthService) Reauthenticate(ctx context.Context, userId int, password, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Reauthenticate", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	twoFactor, err := s.twoFactor.Get(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	return s.attempt(ctx, twoFactor.Username, func(logins repository.Login) error {
+		failures, err := checkLockout(ctx, logins, twoFactor.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err := s.repo.GetUser(ctx, twoFactor.Username, generatePasswordHash(password))
+		if err != nil || user.Id != userId {
+			if err := s.addFailure(ctx, logins, twoFactor.Username); err != nil {
+				return err
+			}
+			return &todo.ErrInvalidCredentials{}
+		}
+
+		if twoFactor.Enabled {
+			ok, err := verifyTwoFactorCode(ctx, s.twoFactor, twoFactor, code)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if err := s.addFailure(ctx, logins, twoFactor.Username); err != nil {
+					return err
+				}
+				return &todo.ErrInvalidTwoFactorCode{}
+			}
+		}
+		return clearFailures(ctx, logins, twoFactor.Username, failures)
+	})
+}
+
+// checkPassword checks the credentials unless the username is locked, and
+// returns the failures before this attempt.
+func (s *AuthService) checkPassword(ctx context.Context, username, password string) (int, todo.LoginFailures, error) {
+	if utf8.RuneCountInString(username) > maxUsernameLength {
+		// nothing to lock, and the logins table couldn't count it anyway
+		return 0, todo.LoginFailures{}, &todo.ErrInvalidCredentials{}
+	}
+
+	var userId int
+	var failures todo.LoginFailures
+	err := s.attempt(ctx, username, func(logins repository.Login) error {
+		var err error
+		failures, err = checkLockout(ctx, logins, username)
+		if err != nil {
+			return err
+		}
+
+		user, err := s.repo.GetUser(ctx, username, generatePasswordHash(password))
+		if err != nil {
+			if err := s.addFailure(ctx, logins, username); err != nil {
+				return err
+			}
+			return &todo.ErrInvalidCredentials{}
+		}
+		userId = user.Id
+		return nil
+	})
+	return userId, failures, err
+}
+
+// attempt runs fn holding the lock on the sign-ins of the username, so
+// concurrent attempts can't all pass the lockout check before any of their
+// failures is counted. The error of fn is the outcome of the attempt, the
+// failure it counted is kept either way.
+func (s *AuthService) attempt(ctx context.Context, username string, fn func(logins repository.Login) error) error {
+	var outcome error
+	err := s.logins.Attempt(ctx, username, func(logins repository.Login) error {
+		outcome = fn(logins)
+		return nil
+	})
+	if outcome != nil {
+		return outcome
+	}
+	return err
+}
+
+func checkLockout(ctx context.Context, logins repository.Login, username string) (todo.LoginFailures, error) {
+	failures, err := logins.GetFailures(ctx, username)
+	if err != nil {
+		return failures, err
+	}
+	if now := time.Now(); now.Before(failures.LockedUntil) {
+		return failures, &todo.ErrAccountLocked{RetryAfter: failures.LockedUntil.Sub(now)}
+	}
+	return failures, nil
+}
+
+func (s *AuthService) addFailure(ctx context.Context, logins repository.Login, username string) error {
+	now := time.Now()
+	count, err := logins.AddFailure(ctx, username, now, now.Add(-loginFailuresTTL))
+	if err != nil {
+		return err
+	}
+	if delay := s.policy.Delay(count); delay > 0 {
+		if err := logins.Lock(ctx, username, now.Add(delay)); err != nil {
+			return err
+		}
+	}
+	if count == s.policy.MaxFailures {
+		logging.FromContext(ctx).Warn("Username locked after failed sign-ins",
+			"failures", count, "lockout", s.policy.Lockout)
+	}
+	return nil
+}
+
+func clearFailures(ctx context.Context, logins repository.Login, username string, failures todo.LoginFailures) error {
+	if failures.Count == 0 {
+		return nil
+	}
+	return logins.ClearFailures(ctx, username)
 }
 
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
-	user, err := s.repo.GetUser(username, generatePasswordHash(password))
+// DeleteExpired drops the failed sign-ins that are no longer remembered
+// and the sessions that expired.
+func (s *AuthService) DeleteExpired(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.DeleteExpired")
+	defer tracing.End(span, &err)
+
+	now := time.Now()
+	return s.logins.DeleteExpired(ctx, now.Add(-loginFailuresTTL), now)
+}
+
+// issueToken starts a session of the user and returns its token.
+func (s *AuthService) issueToken(ctx context.Context, userId int, client todo.LoginClient) (string, error) {
+	now := time.Now()
+	sessionId, err := s.logins.CreateSession(ctx, todo.Session{
+		UserId:    userId,
+		IP:        client.IP,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		CreatedAt: now,
+		ExpiresAt: now.Add(tokenTTL),
+	})
 	if err != nil {
-		return "", &todo.ErrInvalidCredentials{}
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
-			IssuedAt:  time.Now().Unix(),
+	return signToken(&tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        strconv.Itoa(sessionId),
+			ExpiresAt: now.Add(tokenTTL).Unix(),
+			IssuedAt:  now.Unix(),
 		},
-		user.Id,
+		UserId: userId,
 	})
+}
 
+func (s *AuthService) ParseToken(ctx context.Context, token string) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ParseToken")
+	defer tracing.End(span, &err)
+
+	claims, err := parseToken(token)
+	if err != nil {
+		return 0, err
+	}
+	if claims.Purpose != "" {
+		// challenges are signed with the same key but grant nothing
+		return 0, errors.New("Invalid token purpose")
+	}
+
+	return claims.UserId, nil
+}
+
+func signToken(claims *tokenClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(config.Config["AUTH_PRIVATE_KEY"]))
 }
 
-func (s *AuthService) ParseToken(token string) (int, error) {
+func parseToken(token string) (*tokenClaims, error) {
 	parsedToken, err := jwt.ParseWithClaims(token, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("Invalid signing method")
@@ -60,15 +391,22 @@ func (s *AuthService) ParseToken(token string) (int, error) {
 		return []byte(config.Config["AUTH_PRIVATE_KEY"]), nil
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	claims, ok := parsedToken.Claims.(*tokenClaims)
 	if !ok {
-		return 0, errors.New("Invalid token claims")
+		return nil, errors.New("Invalid token claims")
 	}
+	return claims, nil
+}
 
-	return claims.UserId, nil
+// GetSessions returns where the user is signed in, the latest first.
+func (s *AuthService) GetSessions(ctx context.Context, userId int) (_ []todo.Session, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetSessions", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.logins.GetSessions(ctx, userId, time.Now())
 }
 
 func generatePasswordHash(password string) string {
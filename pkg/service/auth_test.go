@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/go-playground/assert/v2"
+)
+
+type authRepo struct {
+	users map[string]todo.User
+}
+
+func (r *authRepo) CreateUser(ctx context.Context, user todo.User) (int, error) {
+	return 0, nil
+}
+
+func (r *authRepo) GetUser(ctx context.Context, username, password string) (todo.User, error) {
+	user, ok := r.users[username]
+	if !ok || user.Password != password {
+		return todo.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+type loginRepo struct {
+	failures map[string]todo.LoginFailures
+	sessions []todo.Session
+	attempts int
+}
+
+// Attempt rolls the failures back when fn fails, the way the transaction
+// would.
+func (r *loginRepo) Attempt(ctx context.Context, username string, fn func(logins repository.Login) error) error {
+	r.attempts++
+	saved := r.failures[username]
+	if err := fn(r); err != nil {
+		r.failures[username] = saved
+		return err
+	}
+	return nil
+}
+
+func (r *loginRepo) GetFailures(ctx context.Context, username string) (todo.LoginFailures, error) {
+	return r.failures[username], nil
+}
+
+func (r *loginRepo) AddFailure(ctx context.Context, username string, at, resetBefore time.Time) (int, error) {
+	failures := r.failures[username]
+	failures.Count++
+	r.failures[username] = failures
+	return failures.Count, nil
+}
+
+func (r *loginRepo) Lock(ctx context.Context, username string, until time.Time) error {
+	failures := r.failures[username]
+	failures.LockedUntil = until
+	r.failures[username] = failures
+	return nil
+}
+
+func (r *loginRepo) ClearFailures(ctx context.Context, username string) error {
+	delete(r.failures, username)
+	return nil
+}
+
+func (r *loginRepo) CreateSession(ctx context.Context, session todo.Session) (int, error) {
+	session.Id = len(r.sessions) + 1
+	r.sessions = append(r.sessions, session)
+	return session.Id, nil
+}
+
+func (r *loginRepo) GetSessions(ctx context.Context, userId int, now time.Time) ([]todo.Session, error) {
+	return r.sessions, nil
+}
+
+func (r *loginRepo) DeleteExpired(ctx context.Context, failedBefore, expiredBefore time.Time) error {
+	return nil
+}
+
+type twoFactorRepo struct {
+	states   map[int]todo.TwoFactor
+	recovery map[string]bool
+}
+
+func newTwoFactorRepo() *twoFactorRepo {
+	return &twoFactorRepo{
+		states:   map[int]todo.TwoFactor{1: {UserId: 1, Username: "alice"}},
+		recovery: make(map[string]bool),
+	}
+}
+
+func (r *twoFactorRepo) Get(ctx context.Context, userId int) (todo.TwoFactor, error) {
+	return r.states[userId], nil
+}
+
+func (r *twoFactorRepo) SetPending(ctx context.Context, userId int, secret string) error {
+	state := r.states[userId]
+	state.Secret = secret
+	state.LastStep = 0
+	r.states[userId] = state
+	return nil
+}
+
+func (r *twoFactorRepo) Enable(ctx context.Context, userId int, step int64, recoveryHashes []string) error {
+	state := r.states[userId]
+	state.Enabled = true
+	state.LastStep = step
+	r.states[userId] = state
+	r.recovery = make(map[string]bool)
+	for _, hash := range recoveryHashes {
+		r.recovery[hash] = true
+	}
+	return nil
+}
+
+func (r *twoFactorRepo) Disable(ctx context.Context, userId int) error {
+	r.states[userId] = todo.TwoFactor{UserId: userId, Username: r.states[userId].Username}
+	r.recovery = make(map[string]bool)
+	return nil
+}
+
+func (r *twoFactorRepo) UseStep(ctx context.Context, userId int, step int64) (bool, error) {
+	state := r.states[userId]
+	if step <= state.LastStep {
+		return false, nil
+	}
+	state.LastStep = step
+	r.states[userId] = state
+	return true, nil
+}
+
+func (r *twoFactorRepo) UseRecoveryCode(ctx context.Context, userId int, hash string) (bool, error) {
+	if !r.recovery[hash] {
+		return false, nil
+	}
+	delete(r.recovery, hash)
+	return true, nil
+}
+
+func newTestAuthService() (*AuthService, *loginRepo) {
+	s, logins, _ := newTestTwoFactorAuthService()
+	return s, logins
+}
+
+func newTestTwoFactorAuthService() (*AuthService, *loginRepo, *twoFactorRepo) {
+	logins := &loginRepo{failures: make(map[string]todo.LoginFailures)}
+	users := &authRepo{users: map[string]todo.User{
+		"alice": {Id: 1, Username: "alice", Password: generatePasswordHash("qwerty")},
+	}}
+	twoFactor := newTwoFactorRepo()
+	policy := todo.LockoutPolicy{MaxFailures: 5, Lockout: time.Minute}
+	return NewAuthService(users, logins, twoFactor, policy), logins, twoFactor
+}
+
+func TestAuthService_Authenticate(t *testing.T) {
+	s, logins := newTestAuthService()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := s.Authenticate(ctx, "alice", "wrong")
+		assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+	}
+	assert.Equal(t, logins.attempts, 3)
+	assert.Equal(t, logins.failures["alice"].Count, 3)
+
+	// past the free failures every attempt has to wait
+	_, err := s.Authenticate(ctx, "alice", "wrong")
+	assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+	_, err = s.Authenticate(ctx, "alice", "qwerty")
+	locked, ok := err.(*todo.ErrAccountLocked)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, locked.RetryAfter > 0 && locked.RetryAfter <= time.Second, true)
+
+	// once the wait is over the right password gets in and failures start over
+	logins.Lock(ctx, "alice", time.Now())
+	id, err := s.Authenticate(ctx, "alice", "qwerty")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, id, 1)
+	assert.Equal(t, logins.failures["alice"].Count, 0)
+}
+
+func TestAuthService_AuthenticateUnknownUser(t *testing.T) {
+	s, logins := newTestAuthService()
+	ctx := context.Background()
+
+	// usernames that don't exist fail and lock just like those that do
+	for i := 0; i < 5; i++ {
+		_, err := s.Authenticate(ctx, "mallory", "wrong")
+		assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+		_, err = s.Authenticate(ctx, "alice", "wrong")
+		assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+		logins.Lock(ctx, "mallory", time.Now())
+		logins.Lock(ctx, "alice", time.Now())
+	}
+	assert.Equal(t, logins.failures["mallory"].Count, logins.failures["alice"].Count)
+
+	logins.Lock(ctx, "mallory", time.Now().Add(time.Minute))
+	logins.Lock(ctx, "alice", time.Now().Add(time.Minute))
+	_, unknownErr := s.Authenticate(ctx, "mallory", "wrong")
+	_, knownErr := s.Authenticate(ctx, "alice", "wrong")
+	assert.Equal(t, unknownErr.Error(), knownErr.Error())
+}
+
+func TestAuthService_AuthenticateLongUsername(t *testing.T) {
+	s, logins := newTestAuthService()
+
+	username := strings.Repeat("a", maxUsernameLength+1)
+	_, err := s.Authenticate(context.Background(), username, "wrong")
+	assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+	assert.Equal(t, len(logins.failures), 0)
+}
+
+func TestAuthService_GenerateToken(t *testing.T) {
+	s, logins := newTestAuthService()
+	ctx := context.Background()
+
+	token, err := s.GenerateToken(ctx, "alice", "qwerty", todo.LoginClient{IP: "192.0.2.1", UserAgent: "todo-cli/1.0"})
+	assert.Equal(t, err, nil)
+
+	userId, err := s.ParseToken(ctx, token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, userId, 1)
+
+	sessions, _ := s.GetSessions(ctx, 1)
+	assert.Equal(t, len(sessions), 1)
+	assert.Equal(t, sessions[0].IP, "192.0.2.1")
+	assert.Equal(t, sessions[0].UserAgent, "todo-cli/1.0")
+	assert.Equal(t, sessions[0].ExpiresAt.Sub(sessions[0].CreatedAt), tokenTTL)
+
+	_, err = s.GenerateToken(ctx, "alice", "wrong", todo.LoginClient{IP: "192.0.2.1"})
+	assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+	assert.Equal(t, len(logins.sessions), 1)
+}
+
+func TestLockoutPolicy_Delay(t *testing.T) {
+	policy := todo.LockoutPolicy{MaxFailures: 10, Lockout: 15 * time.Minute}
+
+	testTable := []struct {
+		count         int
+		expectedDelay time.Duration
+	}{
+		{count: 1, expectedDelay: 0},
+		{count: 3, expectedDelay: 0},
+		{count: 4, expectedDelay: time.Second},
+		{count: 5, expectedDelay: 2 * time.Second},
+		{count: 9, expectedDelay: 32 * time.Second},
+		{count: 10, expectedDelay: 15 * time.Minute},
+		{count: 100, expectedDelay: 15 * time.Minute},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, policy.Delay(testCase.count), testCase.expectedDelay)
+	}
+}
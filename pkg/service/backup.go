@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/backup"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+type BackupService struct {
+	repo repository.Backup
+}
+
+func NewBackupService(repo repository.Backup) *BackupService {
+	return &BackupService{repo: repo}
+}
+
+func (s *BackupService) Dump(ctx context.Context, w io.Writer) (_ []todo.BackupTable, err error) {
+	ctx, span := tracing.Start(ctx, "BackupService.Dump")
+	defer tracing.End(span, &err)
+
+	bw, err := backup.NewWriter(w, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]todo.BackupTable, 0, len(todo.BackupTables))
+	err = s.repo.Dump(ctx, func(dump func(table string, fn func(row any) error) error) error {
+		for _, table := range todo.BackupTables {
+			rows, err := bw.WriteTable(table, func(write func(row any) error) error {
+				return dump(table, write)
+			})
+			if err != nil {
+				return err
+			}
+			tables = append(tables, todo.BackupTable{Name: table, Rows: rows})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tables, bw.Close()
+}
+
+// Restore fills an empty database from a backup. Nothing is restored unless
+// the whole backup is valid.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader) (_ []todo.BackupTable, err error) {
+	ctx, span := tracing.Start(ctx, "BackupService.Restore")
+	defer tracing.End(span, &err)
+
+	br, err := backup.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]todo.BackupTable, 0, len(todo.BackupTables))
+	err = s.repo.Restore(ctx, func(insert func(table string, row any) error) error {
+		for _, table := range backup.Tables(br.Version()) {
+			name, err := br.Next()
+			if err == io.EOF || err == nil && name != table {
+				return &todo.ErrInvalidBackup{Reason: "expected table " + table}
+			}
+			if err != nil {
+				return err
+			}
+
+			rows, err := br.Rows(func() any {
+				return todo.NewBackupRow(table)
+			}, func(row any) error {
+				return insert(table, row)
+			})
+			if err != nil {
+				return err
+			}
+			tables = append(tables, todo.BackupTable{Name: table, Rows: rows})
+		}
+
+		if name, err := br.Next(); err != io.EOF {
+			if err == nil {
+				err = &todo.ErrInvalidBackup{Reason: "unexpected table " + name}
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+// backupRepo keeps rows by table, restores are only kept if they succeed.
+type backupRepo struct {
+	tables map[string][]any
+}
+
+func (r *backupRepo) Dump(ctx context.Context, fn func(dump func(table string, fn func(row any) error) error) error) error {
+	return fn(func(table string, fn func(row any) error) error {
+		for _, row := range r.tables[table] {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *backupRepo) Restore(ctx context.Context, fn func(insert func(table string, row any) error) error) error {
+	if len(r.tables) > 0 {
+		return &todo.ErrRestoreNotEmpty{}
+	}
+
+	tables := make(map[string][]any)
+	err := fn(func(table string, row any) error {
+		tables[table] = append(tables[table], row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.tables = tables
+	return nil
+}
+
+func TestBackupService(t *testing.T) {
+	usedAt := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	source := &backupRepo{tables: map[string][]any{
+		todo.BackupUsers:      {&todo.BackupUser{Id: 2, Name: "Test", Username: "test", PasswordHash: "hash"}},
+		todo.BackupLists:      {&todo.TodoList{Id: 5, Title: "Home", BlockerPolicy: todo.BlockerPolicyWarn}},
+		todo.BackupUsersLists: {&todo.UsersList{Id: 1, UserId: 2, ListId: 5}},
+		todo.BackupListStatuses: {
+			&todo.ListStatus{Id: 1, ListId: 5, Name: "Doing", Position: 0},
+			&todo.ListStatus{Id: 2, ListId: 5, Name: "Done", Position: 1, Terminal: true},
+		},
+		todo.BackupItems: {
+			&todo.BackupItem{TodoItem: todo.TodoItem{Id: 9, Title: "Buy milk", Done: true, Status: "Done"}},
+			&todo.BackupItem{TodoItem: todo.TodoItem{Id: 10, Title: "Bake", Status: "Doing"}},
+		},
+		todo.BackupListsItems: {
+			&todo.ListsItem{Id: 4, ListId: 5, ItemId: 9},
+			&todo.ListsItem{Id: 5, ListId: 5, ItemId: 10},
+		},
+		todo.BackupDependencies: {&todo.ItemDependency{Id: 1, ItemId: 10, BlockerId: 9}},
+		todo.BackupComments: {&todo.BackupComment{
+			Id: 3, ItemId: 10, UserId: 2, Body: "Ask @test", CreatedAt: time.Date(2023, 12, 4, 10, 0, 0, 0, time.UTC),
+		}},
+		todo.BackupMentions: {&todo.CommentMention{Id: 1, CommentId: 3, UserId: 2}},
+		todo.BackupAttachments: {&todo.BackupAttachment{
+			Id: 1, ItemId: 10, UserId: 2, Name: "recipe.pdf", ContentType: "application/pdf", Size: 1024,
+			Key: "0123456789abcdef0123456789abcdef", CreatedAt: time.Date(2023, 12, 11, 10, 0, 0, 0, time.UTC),
+		}},
+		todo.BackupFilters: {&todo.BackupFilter{
+			Id: 1, UserId: 2, Name: "Urgent", Query: "priority:high", CreatedAt: time.Date(2023, 11, 13, 10, 0, 0, 0, time.UTC),
+		}},
+		todo.BackupTwoFactors: {&todo.BackupTwoFactor{UserId: 2, Secret: "JBSWY3DPEHPK3PXP", Enabled: true, LastStep: 56789}},
+		todo.BackupRecoveryCodes: {
+			&todo.BackupRecoveryCode{Id: 1, UserId: 2, CodeHash: "hash1"},
+			&todo.BackupRecoveryCode{Id: 2, UserId: 2, CodeHash: "hash2", UsedAt: &usedAt},
+		},
+	}}
+
+	var buf bytes.Buffer
+	tables, err := NewBackupService(source).Dump(context.Background(), &buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []todo.BackupTable{
+		{Name: todo.BackupUsers, Rows: 1},
+		{Name: todo.BackupLists, Rows: 1},
+		{Name: todo.BackupUsersLists, Rows: 1},
+		{Name: todo.BackupListStatuses, Rows: 2},
+		{Name: todo.BackupItems, Rows: 2},
+		{Name: todo.BackupListsItems, Rows: 2},
+		{Name: todo.BackupDependencies, Rows: 1},
+		{Name: todo.BackupComments, Rows: 1},
+		{Name: todo.BackupMentions, Rows: 1},
+		{Name: todo.BackupAttachments, Rows: 1},
+		{Name: todo.BackupFilters, Rows: 1},
+		{Name: todo.BackupTwoFactors, Rows: 1},
+		{Name: todo.BackupRecoveryCodes, Rows: 2},
+	}, tables)
+	data := buf.Bytes()
+
+	target := &backupRepo{}
+	restored, err := NewBackupService(target).Restore(context.Background(), bytes.NewReader(data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, tables, restored)
+	assert.Equal(t, source.tables, target.tables)
+
+	_, err = NewBackupService(target).Restore(context.Background(), bytes.NewReader(data))
+	assert.Equal(t, &todo.ErrRestoreNotEmpty{}, err)
+
+	empty := &backupRepo{}
+	_, err = NewBackupService(empty).Restore(context.Background(), bytes.NewReader(data[:len(data)/2]))
+	_, ok := err.(*todo.ErrInvalidBackup)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, 0, len(empty.tables))
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"unicode/utf8"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/ical"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+// maxTextLength is the size of the title and description columns.
+const maxTextLength = 255
+
+type CalDAVService struct {
+	repo     repository.CalDAV
+	listRepo repository.TodoList
+	items    TodoItem
+}
+
+func NewCalDAVService(repo repository.CalDAV, listRepo repository.TodoList, items TodoItem) *CalDAVService {
+	return &CalDAVService{repo: repo, listRepo: listRepo, items: items}
+}
+
+func (s *CalDAVService) Calendars(ctx context.Context, userId int) (_ []todo.CalendarCollection, err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Calendars", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	lists, err := s.listRepo.GetAll(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := make([]todo.CalendarCollection, len(lists))
+	for i, list := range lists {
+		token, err := s.repo.SyncToken(ctx, list.Id)
+		if err != nil {
+			return nil, err
+		}
+		calendars[i] = todo.CalendarCollection{List: list, SyncToken: token}
+	}
+
+	return calendars, nil
+}
+
+func (s *CalDAVService) Calendar(ctx context.Context, userId, listId int) (_ todo.CalendarCollection, err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Calendar", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	list, err := s.listRepo.GetById(ctx, userId, listId)
+	if err != nil {
+		return todo.CalendarCollection{}, err
+	}
+
+	token, err := s.repo.SyncToken(ctx, listId)
+	if err != nil {
+		return todo.CalendarCollection{}, err
+	}
+
+	return todo.CalendarCollection{List: list, SyncToken: token}, nil
+}
+
+func (s *CalDAVService) Objects(ctx context.Context, userId, listId int) (_ []todo.CalendarObject, err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Objects", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.listRepo.GetById(ctx, userId, listId); err != nil {
+		return nil, err
+	}
+
+	objects, err := s.repo.GetObjects(ctx, userId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, renderObjects(objects)
+}
+
+func (s *CalDAVService) Object(ctx context.Context, userId, listId int, name string) (_ todo.CalendarObject, err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Object", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	object, err := s.repo.GetObject(ctx, userId, listId, name)
+	if err != nil {
+		return object, err
+	}
+
+	return object, renderObject(&object)
+}
+
+// Changes returns the objects changed and the names of the ones deleted after
+// the given sync token, along with the current token.
+func (s *CalDAVService) Changes(ctx context.Context, userId, listId int, since int64) (_ []todo.CalendarObject, _ []string, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.Changes", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	calendar, err := s.Calendar(ctx, userId, listId)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if since < 0 || since > calendar.SyncToken {
+		return nil, nil, 0, &todo.ErrInvalidSyncToken{}
+	}
+
+	objects, deleted, err := s.repo.GetChanges(ctx, userId, listId, since)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return objects, deleted, calendar.SyncToken, renderObjects(objects)
+}
+
+// PutObject creates or replaces the item stored under the name. ifMatch and
+// ifNoneMatch are the values of the conditional request headers.
+func (s *CalDAVService) PutObject(ctx context.Context, userId, listId int, name string, data io.Reader,
+	ifMatch, ifNoneMatch string) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.PutObject", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.listRepo.GetById(ctx, userId, listId); err != nil {
+		return false, err
+	}
+
+	existing, err := s.repo.GetObject(ctx, userId, listId, name)
+	exists := err == nil
+	if _, ok := err.(*todo.ErrNoSuchCalendarObject); err != nil && !ok {
+		return false, err
+	}
+
+	if ifNoneMatch == "*" && exists {
+		return false, &todo.ErrPreconditionFailed{}
+	}
+	if ifMatch != "" && (!exists || (ifMatch != "*" && ifMatch != existing.ETag())) {
+		return false, &todo.ErrPreconditionFailed{}
+	}
+
+	calendar, err := ical.Decode(data)
+	if err != nil {
+		return false, &todo.ErrInvalidCalendarData{Reason: err.Error()}
+	}
+	if len(calendar.Todos) == 0 {
+		return false, &todo.ErrUnsupportedCalendarComponent{}
+	}
+
+	// recurrence overrides share the UID of the master task, which is the
+	// only one kept
+	task := calendar.Todos[0]
+	item := newTodoItem(task)
+
+	if !exists {
+		itemId, err := s.items.Create(ctx, userId, listId, item)
+		if err != nil {
+			return false, err
+		}
+		return true, s.repo.SetObjectName(ctx, itemId, name, task.UID)
+	}
+
+	input := todo.UpdateItemInput{
+		Title:       &item.Title,
+		Description: &item.Description,
+		Done:        &item.Done,
+		Due:         item.Due,
+		Priority:    &item.Priority,
+		ClearDue:    item.Due == nil,
+	}
+	if _, err := s.items.Update(ctx, userId, existing.Id, input); err != nil {
+		return false, err
+	}
+
+	if task.UID != existing.UID && task.UID != ItemUID(existing.Id) {
+		return false, s.repo.SetObjectName(ctx, existing.Id, name, task.UID)
+	}
+	return false, nil
+}
+
+func (s *CalDAVService) DeleteObject(ctx context.Context, userId, listId int, name, ifMatch string) (err error) {
+	ctx, span := tracing.Start(ctx, "CalDAVService.DeleteObject", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	object, err := s.repo.GetObject(ctx, userId, listId, name)
+	if err != nil {
+		return err
+	}
+
+	if ifMatch != "" && ifMatch != "*" && ifMatch != object.ETag() {
+		return &todo.ErrPreconditionFailed{}
+	}
+
+	return s.items.Delete(ctx, userId, object.Id)
+}
+
+func renderObjects(objects []todo.CalendarObject) error {
+	for i := range objects {
+		if err := renderObject(&objects[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderObject(object *todo.CalendarObject) error {
+	task := newCalendarTodo(object.TodoItem, object.Modified)
+	if object.UID != "" {
+		task.UID = object.UID
+	}
+	task.LastModified = &object.Modified
+	object.UID = task.UID
+
+	var buf bytes.Buffer
+	calendar := ical.Calendar{ProdId: calendarProdId, Todos: []ical.Todo{task}}
+	if err := calendar.Encode(&buf); err != nil {
+		return err
+	}
+
+	object.Data = buf.String()
+	return nil
+}
+
+func newTodoItem(task ical.Todo) todo.TodoItem {
+	return todo.TodoItem{
+		Title:       truncate(task.Summary, maxTextLength),
+		Description: truncate(task.Description, maxTextLength),
+		Done:        task.Completed,
+		Due:         task.Due,
+		Priority:    itemPriority(task.Priority),
+	}
+}
+
+// itemPriority is the inverse of icalPriority.
+func itemPriority(priority int) int {
+	switch {
+	case priority >= 1 && priority <= 4:
+		return todo.PriorityHigh
+	case priority == 5:
+		return todo.PriorityMedium
+	case priority >= 6 && priority <= 9:
+		return todo.PriorityLow
+	default:
+		return todo.PriorityNone
+	}
+}
+
+// truncate cuts s to at most n characters.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+type caldavRepo struct {
+	objects map[string]todo.CalendarObject
+	names   map[int]string
+	token   int64
+}
+
+func (r *caldavRepo) GetObjects(ctx context.Context, userId, listId int) ([]todo.CalendarObject, error) {
+	var objects []todo.CalendarObject
+	for _, object := range r.objects {
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (r *caldavRepo) GetObject(ctx context.Context, userId, listId int, name string) (todo.CalendarObject, error) {
+	object, ok := r.objects[name]
+	if !ok {
+		return object, &todo.ErrNoSuchCalendarObject{}
+	}
+	return object, nil
+}
+
+func (r *caldavRepo) GetChanges(ctx context.Context, userId, listId int, since int64) ([]todo.CalendarObject, []string, error) {
+	return nil, nil, nil
+}
+
+func (r *caldavRepo) SyncToken(ctx context.Context, listId int) (int64, error) { return r.token, nil }
+
+func (r *caldavRepo) SetObjectName(ctx context.Context, itemId int, name, uid string) error {
+	r.names[itemId] = name + " " + uid
+	return nil
+}
+
+const caldavTask = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//client//EN\r\n" +
+	"BEGIN:VTODO\r\nUID:abc@client\r\nSUMMARY:Buy milk\r\nPRIORITY:2\r\n" +
+	"STATUS:COMPLETED\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+
+func TestCalDAVService_PutObject(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoItem)
+
+	due := time.Date(2023, 10, 2, 9, 0, 0, 0, time.UTC)
+	existing := todo.CalendarObject{
+		TodoItem: todo.TodoItem{Id: 5, Title: "Milk", Due: &due},
+		Name:     "abc.ics",
+		Version:  7,
+	}
+	title, description, done, priority := "Buy milk", "", true, todo.PriorityHigh
+
+	testTable := []struct {
+		name            string
+		inputName       string
+		inputData       string
+		ifMatch         string
+		ifNoneMatch     string
+		mockBehavior    mockBehavior
+		expectedCreated bool
+		expectedError   error
+		expectedNames   map[int]string
+	}{
+		{
+			name:      "Create",
+			inputName: "new.ics",
+			inputData: caldavTask,
+			mockBehavior: func(s *mock_service.MockTodoItem) {
+				s.EXPECT().Create(gomock.Any(), 1, 1, todo.TodoItem{
+					Title:    "Buy milk",
+					Done:     true,
+					Priority: todo.PriorityHigh,
+				}).Return(6, nil)
+			},
+			expectedCreated: true,
+			expectedNames:   map[int]string{6: "new.ics abc@client"},
+		},
+		{
+			name:      "Update",
+			inputName: "abc.ics",
+			inputData: caldavTask,
+			ifMatch:   `"7"`,
+			mockBehavior: func(s *mock_service.MockTodoItem) {
+				s.EXPECT().Update(gomock.Any(), 1, 5, todo.UpdateItemInput{
+					Title:       &title,
+					Description: &description,
+					Done:        &done,
+					Priority:    &priority,
+					ClearDue:    true,
+				}).Return(nil, nil)
+			},
+			expectedNames: map[int]string{5: "abc.ics abc@client"},
+		},
+		{
+			name:          "Exists",
+			inputName:     "abc.ics",
+			inputData:     caldavTask,
+			ifNoneMatch:   "*",
+			mockBehavior:  func(s *mock_service.MockTodoItem) {},
+			expectedError: &todo.ErrPreconditionFailed{},
+		},
+		{
+			name:          "Changed",
+			inputName:     "abc.ics",
+			inputData:     caldavTask,
+			ifMatch:       `"6"`,
+			mockBehavior:  func(s *mock_service.MockTodoItem) {},
+			expectedError: &todo.ErrPreconditionFailed{},
+		},
+		{
+			name:      "Event",
+			inputName: "event.ics",
+			inputData: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:event\r\nEND:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+			mockBehavior:  func(s *mock_service.MockTodoItem) {},
+			expectedError: &todo.ErrUnsupportedCalendarComponent{},
+		},
+		{
+			name:          "Invalid data",
+			inputName:     "new.ics",
+			inputData:     "not a calendar",
+			mockBehavior:  func(s *mock_service.MockTodoItem) {},
+			expectedError: &todo.ErrInvalidCalendarData{Reason: "ical: line 1: malformed content line"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			items := mock_service.NewMockTodoItem(c)
+			testCase.mockBehavior(items)
+
+			repo := &caldavRepo{
+				objects: map[string]todo.CalendarObject{existing.Name: existing},
+				names:   make(map[int]string),
+			}
+			lists := &collabListRepo{members: map[int][]int{1: {1}}}
+			s := NewCalDAVService(repo, lists, items)
+
+			created, err := s.PutObject(context.Background(), 1, 1, testCase.inputName, strings.NewReader(testCase.inputData),
+				testCase.ifMatch, testCase.ifNoneMatch)
+
+			assert.Equal(t, testCase.expectedError, err)
+			assert.Equal(t, testCase.expectedCreated, created)
+			if testCase.expectedNames != nil {
+				assert.Equal(t, testCase.expectedNames, repo.names)
+			}
+		})
+	}
+}
+
+func TestCalDAVService_Changes(t *testing.T) {
+	repo := &caldavRepo{token: 10}
+	lists := &collabListRepo{members: map[int][]int{1: {1}}}
+	s := NewCalDAVService(repo, lists, nil)
+
+	_, _, token, err := s.Changes(context.Background(), 1, 1, 10)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(10), token)
+
+	_, _, _, err = s.Changes(context.Background(), 1, 1, 11)
+	assert.Equal(t, &todo.ErrInvalidSyncToken{}, err)
+
+	_, _, _, err = s.Changes(context.Background(), 2, 1, 0)
+	assert.Equal(t, &todo.ErrNoSuchList{}, err)
+}
+
+func TestCalDAVService_Object(t *testing.T) {
+	modified := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	repo := &caldavRepo{objects: map[string]todo.CalendarObject{
+		"item-3.ics": {
+			TodoItem: todo.TodoItem{Id: 3, Title: "Pay rent", Priority: todo.PriorityLow},
+			Name:     "item-3.ics",
+			Modified: modified,
+		},
+	}}
+	s := NewCalDAVService(repo, &collabListRepo{}, nil)
+
+	object, err := s.Object(context.Background(), 1, 1, "item-3.ics")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ItemUID(3), object.UID)
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//OrIX219//todo//EN\r\n"+
+		"CALSCALE:GREGORIAN\r\nBEGIN:VTODO\r\nUID:"+ItemUID(3)+"\r\n"+
+		"DTSTAMP:20231001T120000Z\r\nLAST-MODIFIED:20231001T120000Z\r\nSUMMARY:Pay rent\r\n"+
+		"STATUS:NEEDS-ACTION\r\nPRIORITY:9\r\nEND:VTODO\r\nEND:VCALENDAR\r\n", object.Data)
+}
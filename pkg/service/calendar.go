@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/ical"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	calendarProdId   = "-//OrIX219//todo//EN"
+	feedTokenLength  = 32
+	feedCalendarName = "Todo"
+)
+
+type CalendarService struct {
+	repo     repository.CalendarFeed
+	itemRepo repository.TodoItem
+	listRepo repository.TodoList
+}
+
+func NewCalendarService(repo repository.CalendarFeed, itemRepo repository.TodoItem,
+	listRepo repository.TodoList) *CalendarService {
+	return &CalendarService{repo: repo, itemRepo: itemRepo, listRepo: listRepo}
+}
+
+func (s *CalendarService) ListCalendar(ctx context.Context, userId, listId int) (_ ical.Calendar, err error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.ListCalendar", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	list, err := s.listRepo.GetById(ctx, userId, listId)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	items, err := s.itemRepo.GetAll(ctx, userId, listId)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	return newCalendar(list.Title, items), nil
+}
+
+func (s *CalendarService) FeedCalendar(ctx context.Context, token string) (_ ical.Calendar, err error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.FeedCalendar")
+	defer tracing.End(span, &err)
+
+	userId, err := s.repo.GetUserId(ctx, token)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	items, err := s.itemRepo.GetAllByUser(ctx, userId)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+
+	return newCalendar(feedCalendarName, items), nil
+}
+
+// FeedToken returns the secret of the user's feed url, creating it on first
+// use.
+func (s *CalendarService) FeedToken(ctx context.Context, userId int) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.FeedToken", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	token, err := s.repo.GetToken(ctx, userId)
+	if _, ok := err.(*todo.ErrNoSuchFeed); ok {
+		return s.RotateFeedToken(ctx, userId)
+	}
+	return token, err
+}
+
+func (s *CalendarService) RotateFeedToken(ctx context.Context, userId int) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "CalendarService.RotateFeedToken", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	secret := make([]byte, feedTokenLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(secret)
+	return token, s.repo.SetToken(ctx, userId, token)
+}
+
+func newCalendar(name string, items []todo.TodoItem) ical.Calendar {
+	now := time.Now()
+	todos := make([]ical.Todo, len(items))
+	for i, item := range items {
+		todos[i] = newCalendarTodo(item, now)
+	}
+
+	return ical.Calendar{
+		ProdId: calendarProdId,
+		Name:   name,
+		Todos:  todos,
+	}
+}
+
+func newCalendarTodo(item todo.TodoItem, stamp time.Time) ical.Todo {
+	return ical.Todo{
+		UID:         ItemUID(item.Id),
+		Summary:     item.Title,
+		Description: item.Description,
+		Completed:   item.Done,
+		Due:         item.Due,
+		Priority:    icalPriority(item.Priority),
+		Stamp:       stamp,
+	}
+}
+
+// ItemUID is the stable iCalendar UID of an item.
+func ItemUID(itemId int) string {
+	return fmt.Sprintf("item-%d@%s", itemId, config.Get("CALENDAR_UID_DOMAIN", "todo-app"))
+}
+
+// icalPriority maps item priorities onto the RFC 5545 scale, where 1 is the
+// highest, 9 the lowest and 0 is undefined.
+func icalPriority(priority int) int {
+	switch priority {
+	case todo.PriorityHigh:
+		return 1
+	case todo.PriorityMedium:
+		return 5
+	case todo.PriorityLow:
+		return 9
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	collabClientBuffer = 32
+	// collabMaxEditing is how many items a client may mark as being edited
+	// at once, so joining a room fits in the buffer of a client.
+	collabMaxEditing = 8
+)
+
+// CollabService is a hub of per list rooms. Clients are plain channels so the
+// hub knows nothing about the transport. Rooms are per replica: changes come
+// from every replica through the events, but presence and editing only cover
+// the clients connected here.
+type CollabService struct {
+	listRepo repository.TodoList
+
+	mu     sync.Mutex
+	rooms  map[int]map[*todo.CollabClient]map[int]struct{}
+	closed bool
+}
+
+func NewCollabService(listRepo repository.TodoList) *CollabService {
+	return &CollabService{
+		listRepo: listRepo,
+		rooms:    make(map[int]map[*todo.CollabClient]map[int]struct{}),
+	}
+}
+
+func (s *CollabService) Join(ctx context.Context, userId, listId int) (_ *todo.CollabClient, err error) {
+	ctx, span := tracing.Start(ctx, "CollabService.Join", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.listRepo.GetById(ctx, userId, listId); err != nil {
+		return nil, err
+	}
+
+	client := &todo.CollabClient{
+		ListId: listId,
+		UserId: userId,
+		Send:   make(chan todo.CollabMessage, collabClientBuffer),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		client.GoingAway = true
+		close(client.Send)
+		return client, nil
+	}
+
+	room, ok := s.rooms[listId]
+	if !ok {
+		room = make(map[*todo.CollabClient]map[int]struct{})
+		s.rooms[listId] = room
+	}
+	room[client] = make(map[int]struct{})
+
+	s.broadcastPresence(listId)
+	for other, editing := range room {
+		for itemId := range editing {
+			if !s.send(client, editingMessage(other, itemId, true)) {
+				// dropped, its channel is closed now
+				return client, nil
+			}
+		}
+	}
+
+	return client, nil
+}
+
+func (s *CollabService) Leave(client *todo.CollabClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(client)
+}
+
+// Shutdown disconnects every client and those joining later, as the
+// server doesn't wait for upgraded connections.
+func (s *CollabService) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for listId, room := range s.rooms {
+		for client := range room {
+			client.GoingAway = true
+			close(client.Send)
+		}
+		delete(s.rooms, listId)
+	}
+}
+
+func (s *CollabService) SetEditing(client *todo.CollabClient, itemId int, editing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, ok := s.rooms[client.ListId][client]
+	if !ok {
+		return
+	}
+	if _, ok := items[itemId]; ok == editing {
+		return
+	}
+	if editing && len(items) >= collabMaxEditing {
+		return
+	}
+
+	if editing {
+		items[itemId] = struct{}{}
+	} else {
+		delete(items, itemId)
+	}
+	s.broadcast(client.ListId, editingMessage(client, itemId, editing), client)
+}
+
+// Presence returns the users connected to the list on this replica.
+func (s *CollabService) Presence(listId int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.presence(listId)
+}
+
+// HandleEvent forwards list and item changes to everyone in the list's room.
+func (s *CollabService) HandleEvent(event todo.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := todo.CollabMessage{
+		Type:   todo.CollabEvent,
+		ListId: event.ListId,
+		UserId: event.ActorId,
+		ItemId: event.ItemId,
+		Event:  &event,
+	}
+	for client := range s.rooms[event.ListId] {
+		if event.IsRecipient(client.UserId) {
+			s.send(client, message)
+		}
+	}
+}
+
+func (s *CollabService) remove(client *todo.CollabClient) {
+	room := s.rooms[client.ListId]
+	editing, ok := room[client]
+	if !ok {
+		return
+	}
+
+	delete(room, client)
+	close(client.Send)
+	if len(room) == 0 {
+		delete(s.rooms, client.ListId)
+		return
+	}
+
+	for itemId := range editing {
+		s.broadcast(client.ListId, editingMessage(client, itemId, false), nil)
+	}
+	s.broadcastPresence(client.ListId)
+}
+
+// send drops clients that don't keep up instead of blocking the whole room,
+// the transport notices the closed channel and disconnects them. It tells
+// whether the client is still there, nothing may be sent to it otherwise.
+func (s *CollabService) send(client *todo.CollabClient, message todo.CollabMessage) bool {
+	if _, ok := s.rooms[client.ListId][client]; !ok {
+		return false
+	}
+
+	select {
+	case client.Send <- message:
+		return true
+	default:
+		s.remove(client)
+		return false
+	}
+}
+
+func (s *CollabService) broadcast(listId int, message todo.CollabMessage, except *todo.CollabClient) {
+	for client := range s.rooms[listId] {
+		if client != except {
+			s.send(client, message)
+		}
+	}
+}
+
+func (s *CollabService) broadcastPresence(listId int) {
+	s.broadcast(listId, todo.CollabMessage{
+		Type:   todo.CollabPresence,
+		ListId: listId,
+		Users:  s.presence(listId),
+	}, nil)
+}
+
+func (s *CollabService) presence(listId int) []int {
+	seen := make(map[int]struct{})
+	users := make([]int, 0)
+	for client := range s.rooms[listId] {
+		if _, ok := seen[client.UserId]; !ok {
+			seen[client.UserId] = struct{}{}
+			users = append(users, client.UserId)
+		}
+	}
+	sort.Ints(users)
+	return users
+}
+
+func editingMessage(client *todo.CollabClient, itemId int, editing bool) todo.CollabMessage {
+	return todo.CollabMessage{
+		Type:    todo.CollabEditing,
+		ListId:  client.ListId,
+		UserId:  client.UserId,
+		ItemId:  itemId,
+		Editing: &editing,
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	_ "github.com/OrIX219/todo/testing"
+	"github.com/go-playground/assert/v2"
+)
+
+type collabListRepo struct {
+	members map[int][]int
+}
+
+func (r *collabListRepo) Create(ctx context.Context, userId int, list todo.TodoList) (int, error) {
+	return 0, nil
+}
+func (r *collabListRepo) GetAll(ctx context.Context, userId int) ([]todo.TodoList, error) {
+	return nil, nil
+}
+func (r *collabListRepo) Delete(ctx context.Context, userId, listId int) error { return nil }
+func (r *collabListRepo) Update(ctx context.Context, userId, listId int, input todo.UpdateListInput) error {
+	return nil
+}
+
+func (r *collabListRepo) GetById(ctx context.Context, userId, listId int) (todo.TodoList, error) {
+	for _, id := range r.members[listId] {
+		if id == userId {
+			return todo.TodoList{Id: listId}, nil
+		}
+	}
+	return todo.TodoList{}, &todo.ErrNoSuchList{}
+}
+
+func receive(t *testing.T, client *todo.CollabClient) todo.CollabMessage {
+	t.Helper()
+	select {
+	case message := <-client.Send:
+		return message
+	default:
+		t.Fatal("No message received")
+		return todo.CollabMessage{}
+	}
+}
+
+func TestCollabService(t *testing.T) {
+	hub := NewCollabService(&collabListRepo{members: map[int][]int{1: {1, 2}}})
+
+	_, err := hub.Join(context.Background(), 3, 1)
+	assert.Equal(t, &todo.ErrNoSuchList{}, err)
+
+	first, err := hub.Join(context.Background(), 1, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []int{1}, receive(t, first).Users)
+
+	hub.SetEditing(first, 5, true)
+
+	second, err := hub.Join(context.Background(), 2, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []int{1, 2}, receive(t, first).Users)
+	assert.Equal(t, []int{1, 2}, receive(t, second).Users)
+
+	// late joiners learn who is editing what
+	editing := receive(t, second)
+	assert.Equal(t, todo.CollabEditing, editing.Type)
+	assert.Equal(t, 1, editing.UserId)
+	assert.Equal(t, 5, editing.ItemId)
+
+	hub.HandleEvent(todo.Event{Id: 1, Type: todo.EventItemUpdated, ListId: 1, ItemId: 5,
+		ActorId: 2, Recipients: []int{1, 2}})
+	assert.Equal(t, todo.CollabEvent, receive(t, first).Type)
+	assert.Equal(t, todo.CollabEvent, receive(t, second).Type)
+
+	hub.Leave(first)
+	_, ok := <-first.Send
+	assert.Equal(t, false, ok)
+
+	stopped := receive(t, second)
+	assert.Equal(t, todo.CollabEditing, stopped.Type)
+	assert.Equal(t, false, *stopped.Editing)
+	assert.Equal(t, []int{2}, receive(t, second).Users)
+	assert.Equal(t, []int{2}, hub.Presence(1))
+}
+
+func TestCollabService_slowClient(t *testing.T) {
+	hub := NewCollabService(&collabListRepo{members: map[int][]int{1: {1}}})
+
+	client, err := hub.Join(context.Background(), 1, 1)
+	assert.Equal(t, nil, err)
+
+	for i := 0; i <= collabClientBuffer; i++ {
+		hub.HandleEvent(todo.Event{Id: int64(i), ListId: 1, Recipients: []int{1}})
+	}
+
+	for range client.Send {
+	}
+	assert.Equal(t, []int{}, hub.Presence(1))
+}
+
+func TestCollabService_joinBusyRoom(t *testing.T) {
+	members := make([]int, 0, 6)
+	for userId := 1; userId <= 6; userId++ {
+		members = append(members, userId)
+	}
+	hub := NewCollabService(&collabListRepo{members: map[int][]int{1: members}})
+
+	for userId := 1; userId <= 5; userId++ {
+		client, err := hub.Join(context.Background(), userId, 1)
+		assert.Equal(t, nil, err)
+		for itemId := 1; itemId <= collabClientBuffer; itemId++ {
+			hub.SetEditing(client, userId*100+itemId, true)
+		}
+	}
+
+	// more hints than the buffer holds drop the late joiner instead of
+	// sending on its closed channel
+	late, err := hub.Join(context.Background(), 6, 1)
+	assert.Equal(t, nil, err)
+	received := 0
+	for range late.Send {
+		received++
+	}
+	assert.Equal(t, collabClientBuffer, received)
+}
+
+func TestCollabService_editingLimit(t *testing.T) {
+	hub := NewCollabService(&collabListRepo{members: map[int][]int{1: {1, 2}}})
+
+	first, _ := hub.Join(context.Background(), 1, 1)
+	for itemId := 1; itemId <= collabMaxEditing+1; itemId++ {
+		hub.SetEditing(first, itemId, true)
+	}
+
+	second, _ := hub.Join(context.Background(), 2, 1)
+	receive(t, second)
+	for i := 0; i < collabMaxEditing; i++ {
+		assert.Equal(t, todo.CollabEditing, receive(t, second).Type)
+	}
+	assert.Equal(t, 0, len(second.Send))
+}
+
+func TestCollabService_Shutdown(t *testing.T) {
+	hub := NewCollabService(&collabListRepo{members: map[int][]int{1: {1}}})
+
+	client, err := hub.Join(context.Background(), 1, 1)
+	assert.Equal(t, nil, err)
+	receive(t, client)
+
+	hub.Shutdown()
+	_, ok := <-client.Send
+	assert.Equal(t, false, ok)
+	assert.Equal(t, true, client.GoingAway)
+	assert.Equal(t, []int{}, hub.Presence(1))
+
+	// clients joining while the server shuts down are sent away at once
+	late, err := hub.Join(context.Background(), 1, 1)
+	assert.Equal(t, nil, err)
+	_, ok = <-late.Send
+	assert.Equal(t, false, ok)
+	assert.Equal(t, true, late.GoingAway)
+	hub.Leave(late)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+type CommentService struct {
+	repo     repository.Comment
+	itemRepo repository.TodoItem
+}
+
+func NewCommentService(repo repository.Comment, itemRepo repository.TodoItem) *CommentService {
+	return &CommentService{repo: repo, itemRepo: itemRepo}
+}
+
+func (s *CommentService) Create(ctx context.Context, userId, itemId int, input todo.CommentInput) (_ todo.Comment, err error) {
+	ctx, span := tracing.Start(ctx, "CommentService.Create", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return todo.Comment{}, err
+	}
+	if _, err := s.itemRepo.GetById(ctx, userId, itemId); err != nil {
+		return todo.Comment{}, err
+	}
+
+	id, err := s.repo.Create(ctx, userId, itemId, input.Body, todo.ParseMentions(input.Body))
+	if err != nil {
+		return todo.Comment{}, err
+	}
+
+	return s.repo.GetById(ctx, userId, itemId, id)
+}
+
+// GetAll returns a page of the comments on the item, limit defaulting to
+// todo.DefaultCommentsLimit.
+func (s *CommentService) GetAll(ctx context.Context, userId, itemId, after, limit int) (_ todo.CommentsPage, err error) {
+	ctx, span := tracing.Start(ctx, "CommentService.GetAll", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	if limit == 0 {
+		limit = todo.DefaultCommentsLimit
+	}
+	if limit < 0 || limit > todo.MaxCommentsLimit || after < 0 {
+		return todo.CommentsPage{}, &todo.ErrInvalidCommentInput{Reason: "invalid page"}
+	}
+	if _, err := s.itemRepo.GetById(ctx, userId, itemId); err != nil {
+		return todo.CommentsPage{}, err
+	}
+
+	// one more tells whether there is a next page
+	comments, err := s.repo.GetAll(ctx, itemId, after, limit+1)
+	if err != nil {
+		return todo.CommentsPage{}, err
+	}
+
+	page := todo.CommentsPage{Data: comments}
+	if len(comments) > limit {
+		page.Data = comments[:limit]
+		page.Next = comments[limit-1].Id
+	}
+	return page, nil
+}
+
+// Update edits the comment, which only its author can do.
+func (s *CommentService) Update(ctx context.Context, userId, itemId, commentId int, input todo.CommentInput) (_ todo.Comment, err error) {
+	ctx, span := tracing.Start(ctx, "CommentService.Update", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return todo.Comment{}, err
+	}
+
+	comment, err := s.repo.GetById(ctx, userId, itemId, commentId)
+	if err != nil {
+		return todo.Comment{}, err
+	}
+	if comment.Author.Id != userId {
+		return todo.Comment{}, &todo.ErrCommentForbidden{Reason: "Only the author can edit the comment"}
+	}
+
+	if err := s.repo.Update(ctx, commentId, input.Body, todo.ParseMentions(input.Body)); err != nil {
+		return todo.Comment{}, err
+	}
+
+	return s.repo.GetById(ctx, userId, itemId, commentId)
+}
+
+// Delete removes the comment if the user wrote it or owns a list the item
+// is in.
+func (s *CommentService) Delete(ctx context.Context, userId, itemId, commentId int) (err error) {
+	ctx, span := tracing.Start(ctx, "CommentService.Delete", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	comment, err := s.repo.GetById(ctx, userId, itemId, commentId)
+	if err != nil {
+		return err
+	}
+
+	if comment.Author.Id != userId {
+		owner, err := s.repo.IsListOwner(ctx, userId, itemId)
+		if err != nil {
+			return err
+		}
+		if !owner {
+			return &todo.ErrCommentForbidden{
+				Reason: "Only the author or the owner of the list can delete the comment",
+			}
+		}
+	}
+
+	return s.repo.Delete(ctx, commentId)
+}
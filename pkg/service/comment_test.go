@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+type commentRepo struct {
+	comments []todo.Comment
+	owners   map[int]bool
+	deleted  []int
+}
+
+func (r *commentRepo) Create(ctx context.Context, userId, itemId int, body string, mentions []string) (int, error) {
+	id := len(r.comments) + 1
+	r.comments = append(r.comments, todo.Comment{
+		Id:       id,
+		ItemId:   itemId,
+		Author:   todo.CommentAuthor{Id: userId},
+		Body:     body,
+		Mentions: mentions,
+	})
+	return id, nil
+}
+
+func (r *commentRepo) GetAll(ctx context.Context, itemId, after, limit int) ([]todo.Comment, error) {
+	comments := make([]todo.Comment, 0)
+	for _, comment := range r.comments {
+		if comment.ItemId == itemId && comment.Id > after && len(comments) < limit {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+func (r *commentRepo) GetById(ctx context.Context, userId, itemId, commentId int) (todo.Comment, error) {
+	for _, comment := range r.comments {
+		if comment.Id == commentId && comment.ItemId == itemId {
+			return comment, nil
+		}
+	}
+	return todo.Comment{}, &todo.ErrNoSuchComment{}
+}
+
+func (r *commentRepo) Update(ctx context.Context, commentId int, body string, mentions []string) error {
+	r.comments[commentId-1].Body = body
+	r.comments[commentId-1].Mentions = mentions
+	return nil
+}
+
+func (r *commentRepo) Delete(ctx context.Context, commentId int) error {
+	r.deleted = append(r.deleted, commentId)
+	return nil
+}
+
+func (r *commentRepo) IsListOwner(ctx context.Context, userId, itemId int) (bool, error) {
+	return r.owners[userId], nil
+}
+
+func newCommentService() (*CommentService, *commentRepo) {
+	repo := &commentRepo{owners: map[int]bool{1: true}}
+	items := &workflowItemRepo{items: []todo.TodoItem{{Id: 1, Title: "Plan"}, {Id: 2, Title: "Ship"}}}
+	return NewCommentService(repo, items), repo
+}
+
+func TestCommentService_Create(t *testing.T) {
+	testTable := []struct {
+		name             string
+		body             string
+		itemId           int
+		expectedMentions todo.Usernames
+		expectedError    error
+	}{
+		{
+			name:   "No mentions",
+			body:   "Looks **good**",
+			itemId: 1,
+		},
+		{
+			name:             "Mentions",
+			body:             "@alice can you check this with @bob.smith? cc @Alice",
+			itemId:           1,
+			expectedMentions: todo.Usernames{"alice", "bob.smith"},
+		},
+		{
+			name:             "Punctuation",
+			body:             "Thanks, @carol.\n(@dave) and @erin-",
+			itemId:           1,
+			expectedMentions: todo.Usernames{"carol", "dave", "erin"},
+		},
+		{
+			name:             "Not mentions",
+			body:             "Mail me at me@example.com, run `git log @{u}` or\n```\n@decorator\n```\n@@twice",
+			itemId:           1,
+			expectedMentions: todo.Usernames{},
+		},
+		{
+			name:          "Empty",
+			body:          " \n",
+			itemId:        1,
+			expectedError: &todo.ErrInvalidCommentInput{Reason: "body must not be empty"},
+		},
+		{
+			name:          "No such item",
+			body:          "Hello",
+			itemId:        3,
+			expectedError: &todo.ErrNoSuchItem{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, _ := newCommentService()
+
+			comment, err := service.Create(context.Background(), 2, testCase.itemId, todo.CommentInput{Body: testCase.body})
+			assert.Equal(t, err, testCase.expectedError)
+			if testCase.expectedError == nil {
+				assert.Equal(t, comment.Body, testCase.body)
+				assert.Equal(t, comment.Author.Id, 2)
+				if testCase.expectedMentions == nil {
+					testCase.expectedMentions = todo.Usernames{}
+				}
+				assert.Equal(t, comment.Mentions, testCase.expectedMentions)
+			}
+		})
+	}
+}
+
+func TestCommentService_GetAll(t *testing.T) {
+	service, repo := newCommentService()
+	for i := 0; i < 5; i++ {
+		repo.Create(context.Background(), 1, 1+i%2, "Comment", nil)
+	}
+
+	ids := func(page todo.CommentsPage) []int {
+		ids := make([]int, len(page.Data))
+		for i, comment := range page.Data {
+			ids[i] = comment.Id
+		}
+		return ids
+	}
+
+	page, err := service.GetAll(context.Background(), 1, 1, 0, 2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ids(page), []int{1, 3})
+	assert.Equal(t, page.Next, 3)
+
+	page, err = service.GetAll(context.Background(), 1, 1, page.Next, 2)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ids(page), []int{5})
+	assert.Equal(t, page.Next, 0)
+
+	page, err = service.GetAll(context.Background(), 1, 2, 0, 0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ids(page), []int{2, 4})
+
+	_, err = service.GetAll(context.Background(), 1, 1, 0, todo.MaxCommentsLimit+1)
+	assert.Equal(t, err, &todo.ErrInvalidCommentInput{Reason: "invalid page"})
+
+	_, err = service.GetAll(context.Background(), 1, 3, 0, 0)
+	assert.Equal(t, err, &todo.ErrNoSuchItem{})
+}
+
+func TestCommentService_Update(t *testing.T) {
+	service, repo := newCommentService()
+	repo.Create(context.Background(), 2, 1, "Frist", nil)
+
+	comment, err := service.Update(context.Background(), 2, 1, 1, todo.CommentInput{Body: "First, @alice"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, comment.Body, "First, @alice")
+	assert.Equal(t, comment.Mentions, todo.Usernames{"alice"})
+
+	_, err = service.Update(context.Background(), 1, 1, 1, todo.CommentInput{Body: "Second"})
+	assert.Equal(t, err, &todo.ErrCommentForbidden{Reason: "Only the author can edit the comment"})
+
+	_, err = service.Update(context.Background(), 2, 2, 1, todo.CommentInput{Body: "Second"})
+	assert.Equal(t, err, &todo.ErrNoSuchComment{})
+}
+
+func TestCommentService_Delete(t *testing.T) {
+	service, repo := newCommentService()
+	repo.Create(context.Background(), 2, 1, "Mine", nil)
+	repo.Create(context.Background(), 3, 1, "Theirs", nil)
+
+	// authors and the owner of the list can delete
+	assert.Equal(t, service.Delete(context.Background(), 2, 1, 1), nil)
+	assert.Equal(t, service.Delete(context.Background(), 1, 1, 2), nil)
+	assert.Equal(t, repo.deleted, []int{1, 2})
+
+	err := service.Delete(context.Background(), 2, 1, 2)
+	assert.Equal(t, err, &todo.ErrCommentForbidden{
+		Reason: "Only the author or the owner of the list can delete the comment",
+	})
+}
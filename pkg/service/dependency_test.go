@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+// dependencyRepo takes the blockers and done from the items.
+type dependencyRepo struct {
+	items  []todo.TodoItem
+	policy string
+}
+
+func (r *dependencyRepo) GetBlockers(ctx context.Context, itemIds []int) ([]todo.ItemDependency, error) {
+	dependencies := make([]todo.ItemDependency, 0)
+	for _, id := range itemIds {
+		for _, item := range r.items {
+			if item.Id != id {
+				continue
+			}
+			for _, blocker := range item.BlockedBy {
+				dependencies = append(dependencies, todo.ItemDependency{ItemId: id, BlockerId: blocker})
+			}
+		}
+	}
+	return dependencies, nil
+}
+
+func (r *dependencyRepo) GetOpen(ctx context.Context, itemIds []int) ([]int, error) {
+	open := make([]int, 0)
+	for _, id := range itemIds {
+		for _, item := range r.items {
+			if item.Id == id && !item.Done {
+				open = append(open, id)
+			}
+		}
+	}
+	return open, nil
+}
+
+func (r *dependencyRepo) GetBlockerPolicy(ctx context.Context, itemId int) (string, error) {
+	return r.policy, nil
+}
+
+func (r *dependencyRepo) GetDependencies(ctx context.Context, userId, itemId int) (todo.ItemDependencies, error) {
+	return todo.ItemDependencies{}, nil
+}
+
+// dependencyTransactor runs transactions on the repositories as they are,
+// counting the ones holding the dependency lock.
+type dependencyTransactor struct {
+	items        repository.TodoItem
+	dependencies repository.Dependency
+	locked       int
+}
+
+func (t *dependencyTransactor) InTransaction(ctx context.Context, fn func(lists repository.TodoList, items repository.TodoItem) error) error {
+	return fn(nil, t.items)
+}
+
+func (t *dependencyTransactor) InDependencyTransaction(ctx context.Context, fn func(items repository.TodoItem, dependencies repository.Dependency) error) error {
+	t.locked++
+	return fn(t.items, t.dependencies)
+}
+
+// Deploy waits for Review, which waits for Write.
+var dependentItems = []todo.TodoItem{
+	{Id: 1, Title: "Deploy", BlockedBy: todo.ItemIds{2}, Blocked: true},
+	{Id: 2, Title: "Review", BlockedBy: todo.ItemIds{3}, Blocked: true},
+	{Id: 3, Title: "Write"},
+	{Id: 4, Title: "Announce", BlockedBy: todo.ItemIds{3}, Blocked: true, Done: true},
+	{Id: 5, Title: "Docs"},
+	{Id: 6, Title: "Plan", Done: true},
+}
+
+func newDependencyItemService(t *testing.T, policy string) (*TodoItemService, *workflowItemRepo) {
+	c := gomock.NewController(t)
+	events := mock_service.NewMockEvents(c)
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	items := &workflowItemRepo{items: dependentItems}
+	lists := &transferRepo{lists: []todo.TodoList{{Id: 1, Title: "Release", BlockerPolicy: policy}}}
+	dependencies := &dependencyRepo{items: dependentItems, policy: policy}
+	transactor := &dependencyTransactor{items: items, dependencies: dependencies}
+	return NewTodoItemService(items, lists, &workflowRepo{}, dependencies, transactor, events), items
+}
+
+func TestTodoItemService_Update_Dependencies(t *testing.T) {
+	blockers := func(ids ...int) *todo.ItemIds {
+		blockedBy := todo.ItemIds(ids)
+		return &blockedBy
+	}
+	done := true
+
+	testTable := []struct {
+		name             string
+		policy           string
+		itemId           int
+		input            todo.UpdateItemInput
+		expectedWarnings []todo.Warning
+		expectedError    error
+	}{
+		{
+			name:   "New blocker",
+			itemId: 3,
+			input:  todo.UpdateItemInput{BlockedBy: blockers(5)},
+		},
+		{
+			name:   "No blockers",
+			itemId: 1,
+			input:  todo.UpdateItemInput{BlockedBy: blockers()},
+		},
+		{
+			name:          "Cycle",
+			itemId:        3,
+			input:         todo.UpdateItemInput{BlockedBy: blockers(5, 1)},
+			expectedError: &todo.ErrDependencyCycle{Cycle: []int{3, 1, 2, 3}},
+		},
+		{
+			name:          "Short cycle",
+			itemId:        2,
+			input:         todo.UpdateItemInput{BlockedBy: blockers(1)},
+			expectedError: &todo.ErrDependencyCycle{Cycle: []int{2, 1, 2}},
+		},
+		{
+			name:          "Itself",
+			itemId:        3,
+			input:         todo.UpdateItemInput{BlockedBy: blockers(3)},
+			expectedError: &todo.ErrInvalidDependency{Reason: "an item can't block itself"},
+		},
+		{
+			name:          "No such blocker",
+			itemId:        3,
+			input:         todo.UpdateItemInput{BlockedBy: blockers(9)},
+			expectedError: &todo.ErrInvalidDependency{Reason: "no item 9"},
+		},
+		{
+			name:          "Duplicate blocker",
+			itemId:        3,
+			input:         todo.UpdateItemInput{BlockedBy: blockers(5, 5)},
+			expectedError: &todo.ErrInvalidDependency{Reason: "duplicate blocker 5"},
+		},
+		{
+			name:          "Done while blocked",
+			itemId:        1,
+			input:         todo.UpdateItemInput{Done: &done},
+			expectedError: &todo.ErrItemBlocked{Blockers: []int{2}},
+		},
+		{
+			name:   "Done while blocked in a warning list",
+			policy: todo.BlockerPolicyWarn,
+			itemId: 1,
+			input:  todo.UpdateItemInput{Done: &done},
+			expectedWarnings: []todo.Warning{{
+				Code:    "blocked",
+				Message: "Item is blocked by open items 2",
+			}},
+		},
+		{
+			name:          "Done with new open blockers",
+			itemId:        3,
+			input:         todo.UpdateItemInput{Done: &done, BlockedBy: blockers(5, 6)},
+			expectedError: &todo.ErrItemBlocked{Blockers: []int{5}},
+		},
+		{
+			name:   "Done without blockers",
+			itemId: 1,
+			input:  todo.UpdateItemInput{Done: &done, BlockedBy: blockers(6)},
+		},
+		{
+			name:   "Already done",
+			itemId: 4,
+			input:  todo.UpdateItemInput{Done: &done},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, items := newDependencyItemService(t, testCase.policy)
+
+			warnings, err := service.Update(context.Background(), 1, testCase.itemId, testCase.input)
+			assert.Equal(t, err, testCase.expectedError)
+			assert.Equal(t, warnings, testCase.expectedWarnings)
+			// checked and written holding the lock
+			assert.Equal(t, service.transactor.(*dependencyTransactor).locked, 1)
+			if testCase.expectedError == nil {
+				assert.Equal(t, items.updates, []todo.UpdateItemInput{testCase.input})
+			} else {
+				assert.Equal(t, len(items.updates), 0)
+			}
+		})
+	}
+}
+
+func TestTodoItemService_Update_HiddenCycle(t *testing.T) {
+	service, _ := newDependencyItemService(t, "")
+	// Write waits for an item of someone else's list, which waits for Deploy
+	hidden := append([]todo.TodoItem{}, dependentItems...)
+	hidden[2].BlockedBy = todo.ItemIds{8}
+	hidden = append(hidden, todo.TodoItem{Id: 8, Title: "Hidden", BlockedBy: todo.ItemIds{1}})
+	service.transactor.(*dependencyTransactor).dependencies = &dependencyRepo{items: hidden}
+
+	_, err := service.Update(context.Background(), 1, 2, todo.UpdateItemInput{BlockedBy: &todo.ItemIds{3}})
+	assert.Equal(t, err, &todo.ErrDependencyCycle{Cycle: []int{2, 3, 1, 2}})
+}
+
+func TestTodoItemService_Update_NoLock(t *testing.T) {
+	service, _ := newDependencyItemService(t, "")
+
+	title := "Deploy to production"
+	_, err := service.Update(context.Background(), 1, 1, todo.UpdateItemInput{Title: &title})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, service.transactor.(*dependencyTransactor).locked, 0)
+}
+
+func TestTodoItemService_Create_Dependencies(t *testing.T) {
+	service, items := newDependencyItemService(t, "")
+
+	_, err := service.Create(context.Background(), 1, 1, todo.TodoItem{Title: "Ship", BlockedBy: todo.ItemIds{1, 6}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, items.created[0].BlockedBy, todo.ItemIds{1, 6})
+
+	_, err = service.Create(context.Background(), 1, 1, todo.TodoItem{Title: "Ship", Done: true, BlockedBy: todo.ItemIds{1, 6}})
+	assert.Equal(t, err, &todo.ErrItemBlocked{Blockers: []int{1}})
+
+	_, err = service.Create(context.Background(), 1, 1, todo.TodoItem{Title: "Ship", BlockedBy: todo.ItemIds{9}})
+	assert.Equal(t, err, &todo.ErrInvalidDependency{Reason: "no item 9"})
+}
+
+func TestTodoItemService_Bulk_Dependencies(t *testing.T) {
+	service, items := newDependencyItemService(t, todo.BlockerPolicyWarn)
+	items.TodoItem = bulkItemRepo{}
+
+	done := true
+	output, err := service.Bulk(context.Background(), 1, todo.BulkItemsInput{Operations: []todo.BulkItemOperation{
+		{Op: todo.BulkOpUpdate, ItemId: 5, Update: &todo.UpdateItemInput{BlockedBy: &todo.ItemIds{3}}},
+		{Op: todo.BulkOpUpdate, ItemId: 3, Update: &todo.UpdateItemInput{BlockedBy: &todo.ItemIds{5}}},
+		{Op: todo.BulkOpUpdate, ItemId: 2, Update: &todo.UpdateItemInput{Done: &done}},
+	}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, output.Results[0].Status, todo.BulkStatusOk)
+	assert.Equal(t, output.Results[1].Code, "dependency_cycle")
+	assert.Equal(t, output.Results[1].Message, "Dependencies can't form a cycle: 3 blocked by 5 blocked by 3")
+	assert.Equal(t, output.Results[2].Status, todo.BulkStatusOk)
+	assert.Equal(t, output.Results[2].Warnings, []todo.Warning{{
+		Code:    "blocked",
+		Message: "Item is blocked by open items 3",
+	}})
+}
+
+func TestTodoItemService_GetAll_Blocked(t *testing.T) {
+	service, _ := newDependencyItemService(t, "")
+
+	titles := func(blocked *bool) []string {
+		items, err := service.GetAll(context.Background(), 1, 1, blocked)
+		assert.Equal(t, err, nil)
+		titles := make([]string, len(items))
+		for i, item := range items {
+			titles[i] = item.Title
+		}
+		return titles
+	}
+
+	blocked, unblocked := true, false
+	assert.Equal(t, len(titles(nil)), len(dependentItems))
+	assert.Equal(t, titles(&blocked), []string{"Deploy", "Review", "Announce"})
+	assert.Equal(t, titles(&unblocked), []string{"Write", "Docs", "Plan"})
+}
+
+func TestTodoItemService_Update_Completed(t *testing.T) {
+	c := gomock.NewController(t)
+	events := mock_service.NewMockEvents(c)
+	completed := make(map[int]bool)
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, event todo.Event) error {
+		completed[event.ItemId] = event.Completed
+		return nil
+	}).AnyTimes()
+
+	service, _ := newDependencyItemService(t, "")
+	service.events = events
+
+	done := true
+	for _, itemId := range []int{5, 6} {
+		_, err := service.Update(context.Background(), 1, itemId, todo.UpdateItemInput{Done: &done})
+		assert.Equal(t, err, nil)
+	}
+
+	// Plan was done already
+	assert.Equal(t, completed, map[int]bool{5: true, 6: false})
+}
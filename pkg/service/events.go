@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/metrics"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	eventsReplayBufferSize = 1000
+	eventsSubscriberBuffer = 64
+	// eventsTTL is how long published events are stored for the replicas
+	// to load them.
+	eventsTTL = time.Hour
+)
+
+// EventService fans out events received from the repository to local
+// subscribers and keeps a bounded buffer of recent events for resuming.
+type EventService struct {
+	repo repository.Events
+
+	mu     sync.Mutex
+	buffer []todo.Event
+	// firstId is the first event this replica saw, those before it may
+	// have been missed.
+	firstId     int64
+	evictedId   int64
+	subscribers map[*todo.EventSubscription]struct{}
+	listeners   []func(todo.Event)
+	publishers  []func(context.Context, todo.Event)
+}
+
+func NewEventService(repo repository.Events) *EventService {
+	return &EventService{
+		repo:        repo,
+		buffer:      make([]todo.Event, 0, eventsReplayBufferSize),
+		subscribers: make(map[*todo.EventSubscription]struct{}),
+	}
+}
+
+// Prepare resolves the list and the users an event should be delivered to.
+// It has to be called before deletions, while memberships still exist.
+func (s *EventService) Prepare(ctx context.Context, event todo.Event) (_ todo.Event, err error) {
+	ctx, span := tracing.Start(ctx, "EventService.Prepare")
+	defer tracing.End(span, &err)
+
+	listId, recipients, err := s.repo.Recipients(ctx, event.ListId, event.ItemId)
+	if err != nil {
+		return event, err
+	}
+	event.ListId = listId
+	event.Recipients = recipients
+	return event, nil
+}
+
+func (s *EventService) Publish(ctx context.Context, event todo.Event) (err error) {
+	ctx, span := tracing.Start(ctx, "EventService.Publish")
+	defer tracing.End(span, &err)
+
+	if event.Recipients == nil {
+		var err error
+		event, err = s.Prepare(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+	if len(event.Recipients) == 0 {
+		return nil
+	}
+
+	id, err := s.repo.Publish(ctx, event)
+	if err != nil {
+		return err
+	}
+	event.Id = id
+
+	s.mu.Lock()
+	publishers := s.publishers
+	s.mu.Unlock()
+
+	for _, fn := range publishers {
+		fn(ctx, event)
+	}
+	return nil
+}
+
+// DeleteExpired drops the stored events every replica has loaded.
+func (s *EventService) DeleteExpired(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "EventService.DeleteExpired")
+	defer tracing.End(span, &err)
+
+	return s.repo.DeleteExpired(ctx, time.Now().Add(-eventsTTL))
+}
+
+// Subscribe registers a subscriber and returns the buffered events newer than
+// lastEventId. ok is false when some of those events were already evicted or
+// came before this replica started listening.
+func (s *EventService) Subscribe(userId int, lastEventId int64) (*todo.EventSubscription, []todo.Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &todo.EventSubscription{
+		UserId: userId,
+		Events: make(chan todo.Event, eventsSubscriberBuffer),
+	}
+	s.subscribers[sub] = struct{}{}
+
+	if lastEventId == 0 {
+		return sub, nil, true
+	}
+
+	backlog := make([]todo.Event, 0)
+	for _, event := range s.buffer {
+		if event.Id > lastEventId && event.IsRecipient(userId) {
+			backlog = append(backlog, event)
+		}
+	}
+
+	ok := s.firstId != 0 && lastEventId >= s.firstId && lastEventId >= s.evictedId
+	return sub, backlog, ok
+}
+
+func (s *EventService) Unsubscribe(sub *todo.EventSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.Events)
+	}
+}
+
+// Run dispatches events until ctx is done, then closes every subscription so
+// that streaming handlers return.
+func (s *EventService) Run(ctx context.Context) error {
+	err := s.repo.Listen(ctx, s.dispatch)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.Events)
+	}
+
+	return err
+}
+
+// OnEvent registers fn to be called with every dispatched event.
+func (s *EventService) OnEvent(fn func(todo.Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, fn)
+}
+
+// OnPublish registers fn to be called with every event published by this
+// replica, unlike OnEvent it sees each event exactly once cluster-wide. fn
+// runs with the context of the publishing request.
+func (s *EventService) OnPublish(fn func(context.Context, todo.Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.publishers = append(s.publishers, fn)
+}
+
+func (s *EventService) dispatch(event todo.Event) {
+	s.mu.Lock()
+
+	if s.firstId == 0 {
+		s.firstId = event.Id
+	}
+	if len(s.buffer) == eventsReplayBufferSize {
+		s.evictedId = s.buffer[0].Id
+		s.buffer = append(s.buffer[:0], s.buffer[1:]...)
+	}
+	s.buffer = append(s.buffer, event)
+
+	for sub := range s.subscribers {
+		if !event.IsRecipient(sub.UserId) {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			// slow consumer, it will resume from the replay buffer
+			delete(s.subscribers, sub)
+			close(sub.Events)
+		}
+	}
+
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+func newEvent(eventType string, userId, listId, itemId int, data any) todo.Event {
+	event := todo.Event{
+		Type:    eventType,
+		ListId:  listId,
+		ItemId:  itemId,
+		ActorId: userId,
+	}
+	if data != nil {
+		event.Data, _ = json.Marshal(data)
+	}
+	return event
+}
+
+func publishEvent(ctx context.Context, events Events, event todo.Event) {
+	if err := events.Publish(ctx, event); err != nil {
+		logging.FromContext(ctx).Error("Failed to publish event", "type", event.Type, "error", err)
+	}
+}
+
+// countEvent keeps the business metrics. They're counted from the events
+// published by this replica, so every way of changing items is covered and
+// each change is counted once across replicas.
+func countEvent(ctx context.Context, event todo.Event) {
+	switch event.Type {
+	case todo.EventListCreated:
+		metrics.ListsCreated.Inc()
+	case todo.EventItemCreated:
+		metrics.ItemsCreated.Inc()
+	case todo.EventItemUpdated:
+		if event.Completed {
+			metrics.ItemsCompleted.Inc()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestEventService_Subscribe(t *testing.T) {
+	s := NewEventService(nil)
+
+	// nothing seen yet, so nothing is known to be complete
+	_, _, ok := s.Subscribe(1, 5)
+	assert.Equal(t, ok, false)
+
+	for id := int64(10); id < 10+eventsReplayBufferSize+2; id++ {
+		s.dispatch(todo.Event{Id: id, Recipients: []int{1}})
+	}
+
+	testTable := []struct {
+		name            string
+		lastEventId     int64
+		expectedBacklog int
+		expectedOk      bool
+	}{
+		{
+			name:        "New stream",
+			lastEventId: 0,
+			expectedOk:  true,
+		},
+		{
+			name:            "Before the replica started",
+			lastEventId:     5,
+			expectedBacklog: eventsReplayBufferSize,
+		},
+		{
+			name:            "Evicted",
+			lastEventId:     10,
+			expectedBacklog: eventsReplayBufferSize,
+		},
+		{
+			name:            "Buffered",
+			lastEventId:     11,
+			expectedBacklog: eventsReplayBufferSize,
+			expectedOk:      true,
+		},
+		{
+			name:            "Up to date",
+			lastEventId:     10 + eventsReplayBufferSize + 1,
+			expectedBacklog: 0,
+			expectedOk:      true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			sub, backlog, ok := s.Subscribe(1, testCase.lastEventId)
+			defer s.Unsubscribe(sub)
+
+			assert.Equal(t, len(backlog), testCase.expectedBacklog)
+			assert.Equal(t, ok, testCase.expectedOk)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/filter"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+type FilterService struct {
+	repo     repository.Filter
+	itemRepo repository.TodoItem
+	now      func() time.Time
+}
+
+func NewFilterService(repo repository.Filter, itemRepo repository.TodoItem) *FilterService {
+	return &FilterService{repo: repo, itemRepo: itemRepo, now: time.Now}
+}
+
+func (s *FilterService) Create(ctx context.Context, userId int, input todo.FilterInput) (_ todo.Filter, err error) {
+	ctx, span := tracing.Start(ctx, "FilterService.Create", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return todo.Filter{}, err
+	}
+	if _, err := parseFilter(input.Query); err != nil {
+		return todo.Filter{}, err
+	}
+
+	f := todo.Filter{
+		UserId: userId,
+		Name:   input.Name,
+		Query:  input.Query,
+	}
+	id, err := s.repo.Create(ctx, userId, f)
+	if err != nil {
+		return todo.Filter{}, err
+	}
+
+	return s.repo.GetById(ctx, userId, id)
+}
+
+func (s *FilterService) GetAll(ctx context.Context, userId int) (_ []todo.Filter, err error) {
+	ctx, span := tracing.Start(ctx, "FilterService.GetAll", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetAll(ctx, userId)
+}
+
+func (s *FilterService) GetById(ctx context.Context, userId, filterId int) (_ todo.Filter, err error) {
+	ctx, span := tracing.Start(ctx, "FilterService.GetById", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetById(ctx, userId, filterId)
+}
+
+func (s *FilterService) Update(ctx context.Context, userId, filterId int, input todo.UpdateFilterInput) (err error) {
+	ctx, span := tracing.Start(ctx, "FilterService.Update", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	if input.Query != nil {
+		if _, err := parseFilter(*input.Query); err != nil {
+			return err
+		}
+	}
+	return s.repo.Update(ctx, userId, filterId, input)
+}
+
+func (s *FilterService) Delete(ctx context.Context, userId, filterId int) (err error) {
+	ctx, span := tracing.Start(ctx, "FilterService.Delete", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.repo.Delete(ctx, userId, filterId)
+}
+
+// GetItems returns the items of the user currently matching the filter. Days
+// like today are UTC days.
+func (s *FilterService) GetItems(ctx context.Context, userId, filterId int) (_ []todo.TodoItem, err error) {
+	ctx, span := tracing.Start(ctx, "FilterService.GetItems", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	f, err := s.repo.GetById(ctx, userId, filterId)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := parseFilter(f.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemRepo.GetAllByFilter(ctx, userId, expr, s.now().UTC())
+}
+
+func parseFilter(query string) (filter.Expr, error) {
+	expr, err := filter.Parse(query)
+	if syntaxErr, ok := err.(*filter.SyntaxError); ok {
+		return nil, &todo.ErrInvalidFilterInput{
+			Reason: fmt.Sprintf("query at column %d: %s", syntaxErr.Column, syntaxErr.Msg),
+		}
+	}
+	return expr, err
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/filter"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/go-playground/assert/v2"
+)
+
+type filterRepo struct {
+	filters []todo.Filter
+	updated bool
+}
+
+func (r *filterRepo) Create(ctx context.Context, userId int, f todo.Filter) (int, error) {
+	f.Id = len(r.filters) + 1
+	r.filters = append(r.filters, f)
+	return f.Id, nil
+}
+
+func (r *filterRepo) GetAll(ctx context.Context, userId int) ([]todo.Filter, error) {
+	return r.filters, nil
+}
+func (r *filterRepo) Delete(ctx context.Context, userId, filterId int) error { return nil }
+func (r *filterRepo) Update(ctx context.Context, userId, filterId int, input todo.UpdateFilterInput) error {
+	r.updated = true
+	return nil
+}
+
+func (r *filterRepo) GetById(ctx context.Context, userId, filterId int) (todo.Filter, error) {
+	if filterId < 1 || filterId > len(r.filters) || r.filters[filterId-1].UserId != userId {
+		return todo.Filter{}, &todo.ErrNoSuchFilter{}
+	}
+	return r.filters[filterId-1], nil
+}
+
+// filterItemRepo records the expression items were asked for.
+type filterItemRepo struct {
+	repository.TodoItem
+	expr filter.Expr
+	now  time.Time
+}
+
+func (r *filterItemRepo) GetAllByFilter(ctx context.Context, userId int, expr filter.Expr, now time.Time) ([]todo.TodoItem, error) {
+	r.expr, r.now = expr, now
+	return []todo.TodoItem{{Id: 1, Title: "Report"}}, nil
+}
+
+func TestFilterService_Create(t *testing.T) {
+	repo := &filterRepo{}
+	service := NewFilterService(repo, &filterItemRepo{})
+
+	f, err := service.Create(context.Background(), 1, todo.FilterInput{Name: "Work", Query: "done:false AND tag:work"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, f, todo.Filter{Id: 1, UserId: 1, Name: "Work", Query: "done:false AND tag:work"})
+
+	_, err = service.Create(context.Background(), 1, todo.FilterInput{Name: "Work", Query: "done:false AND prio>=high"})
+	assert.Equal(t, err, &todo.ErrInvalidFilterInput{
+		Reason: `query at column 16: unknown field "prio", did you mean "priority"?`,
+	})
+
+	_, err = service.Create(context.Background(), 1, todo.FilterInput{Name: " ", Query: "done:false"})
+	assert.Equal(t, err, &todo.ErrInvalidFilterInput{Reason: "name must not be empty"})
+	assert.Equal(t, len(repo.filters), 1)
+}
+
+func TestFilterService_Update(t *testing.T) {
+	repo := &filterRepo{}
+	service := NewFilterService(repo, &filterItemRepo{})
+
+	query := "tag:work OR"
+	err := service.Update(context.Background(), 1, 1, todo.UpdateFilterInput{Query: &query})
+	assert.Equal(t, err, &todo.ErrInvalidFilterInput{
+		Reason: `query at column 12: expected a condition after "OR", got end of filter`,
+	})
+	assert.Equal(t, repo.updated, false)
+
+	query = "tag:work OR tag:home"
+	err = service.Update(context.Background(), 1, 1, todo.UpdateFilterInput{Query: &query})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, repo.updated, true)
+}
+
+func TestFilterService_GetItems(t *testing.T) {
+	repo := &filterRepo{filters: []todo.Filter{
+		{Id: 1, UserId: 1, Name: "Soon", Query: "due<=tomorrow"},
+	}}
+	items := &filterItemRepo{}
+	service := NewFilterService(repo, items)
+	location := time.FixedZone("UTC+3", 3*60*60)
+	service.now = func() time.Time {
+		return time.Date(2023, time.October, 19, 1, 0, 0, 0, location)
+	}
+
+	got, err := service.GetItems(context.Background(), 1, 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, got, []todo.TodoItem{{Id: 1, Title: "Report"}})
+	assert.Equal(t, items.expr, filter.Condition{Field: filter.FieldDue, Op: filter.OpLe, Value: filter.Days(1)})
+	assert.Equal(t, items.now, time.Date(2023, time.October, 18, 22, 0, 0, 0, time.UTC))
+
+	_, err = service.GetItems(context.Background(), 2, 1)
+	assert.Equal(t, err, &todo.ErrNoSuchFilter{})
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+// readinessTimeout bounds the checks of a readiness probe, so a stuck DB
+// fails it instead of hanging it.
+const readinessTimeout = 2 * time.Second
+
+type HealthService struct {
+	repo          repository.Health
+	migrationsDir string
+	draining      atomic.Bool
+}
+
+func NewHealthService(repo repository.Health, migrationsDir string) *HealthService {
+	return &HealthService{repo: repo, migrationsDir: migrationsDir}
+}
+
+// migrationsDir returns where the migrations goose applies are, set by
+// MIGRATIONS_DIR.
+func migrationsDir() string {
+	return config.Get("MIGRATIONS_DIR", "migrations")
+}
+
+// Ready tells whether the app can serve requests: it isn't shutting down,
+// the DB answers and every migration it ships with was applied.
+func (s *HealthService) Ready(ctx context.Context) (err error) {
+	if s.draining.Load() {
+		return &todo.ErrNotReady{Reason: "shutting down"}
+	}
+
+	ctx, span := tracing.Start(ctx, "HealthService.Ready")
+	defer tracing.End(span, &err)
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := s.repo.Ping(ctx); err != nil {
+		logging.FromContext(ctx).Warn("DB ping failed", "error", err)
+		return &todo.ErrNotReady{Reason: "database unavailable"}
+	}
+
+	expected, err := latestMigration(s.migrationsDir)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to read migrations", "error", err)
+		return &todo.ErrNotReady{Reason: "migrations unavailable"}
+	}
+	applied, err := s.repo.MigrationVersion(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to get migration version", "error", err)
+		return &todo.ErrNotReady{Reason: "database unavailable"}
+	}
+	if applied < expected {
+		return &todo.ErrNotReady{Reason: fmt.Sprintf("database at migration %d, expected %d", applied, expected)}
+	}
+	return nil
+}
+
+// Drain fails readiness from now on, so load balancers stop sending
+// requests before the server shuts down.
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *HealthService) Version() todo.BuildInfo {
+	info := todo.BuildInfo{Version: "unknown"}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.GoVersion = build.GoVersion
+	if build.Main.Version != "" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.Time = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// latestMigration returns the version of the newest migration in dir, the
+// number goose takes from the start of its file name.
+func latestMigration(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
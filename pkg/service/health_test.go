@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+type healthRepo struct {
+	pingErr    error
+	version    int64
+	versionErr error
+}
+
+func (r *healthRepo) Ping(ctx context.Context) error {
+	return r.pingErr
+}
+
+func (r *healthRepo) MigrationVersion(ctx context.Context) (int64, error) {
+	return r.version, r.versionErr
+}
+
+func migrationsFixture(t *testing.T, names ...string) string {
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	dir := migrationsFixture(t, "20230101000000_init.sql", "20231204000000_comments.sql", "README.md")
+
+	testTable := []struct {
+		name           string
+		repo           *healthRepo
+		migrationsDir  string
+		drain          bool
+		expectedReason string
+	}{
+		{
+			name: "OK",
+			repo: &healthRepo{version: 20231204000000},
+		},
+		{
+			name:           "Shutting down",
+			repo:           &healthRepo{version: 20231204000000},
+			drain:          true,
+			expectedReason: "shutting down",
+		},
+		{
+			name:           "DB unavailable",
+			repo:           &healthRepo{pingErr: errors.New("connection refused")},
+			expectedReason: "database unavailable",
+		},
+		{
+			name:           "Pending migrations",
+			repo:           &healthRepo{version: 20230101000000},
+			expectedReason: "database at migration 20230101000000, expected 20231204000000",
+		},
+		{
+			name:           "No version",
+			repo:           &healthRepo{versionErr: errors.New("relation goose_db_version does not exist")},
+			expectedReason: "database unavailable",
+		},
+		{
+			name:           "No migrations",
+			repo:           &healthRepo{version: 20231204000000},
+			migrationsDir:  filepath.Join(dir, "missing"),
+			expectedReason: "migrations unavailable",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			migrationsDir := dir
+			if testCase.migrationsDir != "" {
+				migrationsDir = testCase.migrationsDir
+			}
+			s := NewHealthService(testCase.repo, migrationsDir)
+			if testCase.drain {
+				s.Drain()
+			}
+
+			err := s.Ready(context.Background())
+			if testCase.expectedReason == "" {
+				assert.Equal(t, err, nil)
+				return
+			}
+			notReady, ok := err.(*todo.ErrNotReady)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, notReady.Reason, testCase.expectedReason)
+		})
+	}
+}
+
+func TestLatestMigration(t *testing.T) {
+	dir := migrationsFixture(t, "00002_lists.sql", "00010_items.sql", "notes.sql")
+
+	version, err := latestMigration(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, version, int64(10))
+}
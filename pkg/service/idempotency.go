@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+type IdempotencyService struct {
+	repo repository.Idempotency
+}
+
+func NewIdempotencyService(repo repository.Idempotency) *IdempotencyService {
+	return &IdempotencyService{repo: repo}
+}
+
+// Begin reserves the key for a new request. If the key was already used
+// within the TTL, the stored record is returned instead and ok is false.
+func (s *IdempotencyService) Begin(ctx context.Context, userId int, key, fingerprint string) (_ todo.IdempotencyRecord, _ bool, err error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Begin", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	now := time.Now()
+	record, reserved, err := s.repo.Reserve(ctx, todo.IdempotencyRecord{
+		UserId:      userId,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+	}, now.Add(-idempotencyKeyTTL))
+	if err != nil || reserved {
+		return record, reserved, err
+	}
+
+	if record.Fingerprint != fingerprint {
+		return record, false, &todo.ErrIdempotencyKeyReused{}
+	}
+	if !record.Completed() {
+		return record, false, &todo.ErrIdempotencyRequestInProgress{}
+	}
+
+	return record, false, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, record todo.IdempotencyRecord) (err error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Complete")
+	defer tracing.End(span, &err)
+
+	return s.repo.Complete(ctx, record)
+}
+
+func (s *IdempotencyService) Abort(ctx context.Context, userId int, key string) (err error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.Abort", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.repo.Release(ctx, userId, key)
+}
+
+func (s *IdempotencyService) DeleteExpired(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "IdempotencyService.DeleteExpired")
+	defer tracing.End(span, &err)
+
+	return s.repo.DeleteExpired(ctx, time.Now().Add(-idempotencyKeyTTL))
+}
@@ -5,9 +5,12 @@
 package mock_service
 
 import (
+	context "context"
+	io "io"
 	reflect "reflect"
 
 	pkg "github.com/OrIX219/todo/pkg"
+	ical "github.com/OrIX219/todo/pkg/ical"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,49 +37,189 @@ func (m *MockAuthorization) EXPECT() *MockAuthorizationMockRecorder {
 	return m.recorder
 }
 
+// Authenticate mocks base method.
+func (m *MockAuthorization) Authenticate(ctx context.Context, username, password string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, username, password)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAuthorizationMockRecorder) Authenticate(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthorization)(nil).Authenticate), ctx, username, password)
+}
+
 // CreateUser mocks base method.
-func (m *MockAuthorization) CreateUser(user pkg.User) (int, error) {
+func (m *MockAuthorization) CreateUser(ctx context.Context, user pkg.User) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockAuthorizationMockRecorder) CreateUser(user interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthorization)(nil).CreateUser), ctx, user)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockAuthorization) DeleteExpired(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockAuthorizationMockRecorder) DeleteExpired(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthorization)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockAuthorization)(nil).DeleteExpired), ctx)
 }
 
 // GenerateToken mocks base method.
-func (m *MockAuthorization) GenerateToken(username, password string) (string, error) {
+func (m *MockAuthorization) GenerateToken(ctx context.Context, username, password string, client pkg.LoginClient) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", username, password)
+	ret := m.ctrl.Call(m, "GenerateToken", ctx, username, password, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockAuthorizationMockRecorder) GenerateToken(username, password interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) GenerateToken(ctx, username, password, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), ctx, username, password, client)
+}
+
+// GetSessions mocks base method.
+func (m *MockAuthorization) GetSessions(ctx context.Context, userId int) ([]pkg.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userId)
+	ret0, _ := ret[0].([]pkg.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockAuthorizationMockRecorder) GetSessions(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockAuthorization)(nil).GenerateToken), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockAuthorization)(nil).GetSessions), ctx, userId)
 }
 
 // ParseToken mocks base method.
-func (m *MockAuthorization) ParseToken(token string) (int, error) {
+func (m *MockAuthorization) ParseToken(ctx context.Context, token string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ParseToken", token)
+	ret := m.ctrl.Call(m, "ParseToken", ctx, token)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ParseToken indicates an expected call of ParseToken.
-func (mr *MockAuthorizationMockRecorder) ParseToken(token interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) ParseToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), ctx, token)
+}
+
+// Reauthenticate mocks base method.
+func (m *MockAuthorization) Reauthenticate(ctx context.Context, userId int, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reauthenticate", ctx, userId, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reauthenticate indicates an expected call of Reauthenticate.
+func (mr *MockAuthorizationMockRecorder) Reauthenticate(ctx, userId, password, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reauthenticate", reflect.TypeOf((*MockAuthorization)(nil).Reauthenticate), ctx, userId, password, code)
+}
+
+// VerifyTwoFactor mocks base method.
+func (m *MockAuthorization) VerifyTwoFactor(ctx context.Context, challenge, code string, client pkg.LoginClient) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTwoFactor", ctx, challenge, code, client)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTwoFactor indicates an expected call of VerifyTwoFactor.
+func (mr *MockAuthorizationMockRecorder) VerifyTwoFactor(ctx, challenge, code, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTwoFactor", reflect.TypeOf((*MockAuthorization)(nil).VerifyTwoFactor), ctx, challenge, code, client)
+}
+
+// MockTwoFactor is a mock of TwoFactor interface.
+type MockTwoFactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorMockRecorder
+}
+
+// MockTwoFactorMockRecorder is the mock recorder for MockTwoFactor.
+type MockTwoFactorMockRecorder struct {
+	mock *MockTwoFactor
+}
+
+// NewMockTwoFactor creates a new mock instance.
+func NewMockTwoFactor(ctrl *gomock.Controller) *MockTwoFactor {
+	mock := &MockTwoFactor{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactor) EXPECT() *MockTwoFactorMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockTwoFactor) Confirm(ctx context.Context, userId int, password, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, userId, password, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockTwoFactorMockRecorder) Confirm(ctx, userId, password, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockTwoFactor)(nil).Confirm), ctx, userId, password, code)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactor) Disable(ctx context.Context, userId int, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userId, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorMockRecorder) Disable(ctx, userId, password, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactor)(nil).Disable), ctx, userId, password, code)
+}
+
+// Enroll mocks base method.
+func (m *MockTwoFactor) Enroll(ctx context.Context, userId int, password string) (pkg.TwoFactorEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, userId, password)
+	ret0, _ := ret[0].(pkg.TwoFactorEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockTwoFactorMockRecorder) Enroll(ctx, userId, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseToken", reflect.TypeOf((*MockAuthorization)(nil).ParseToken), token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockTwoFactor)(nil).Enroll), ctx, userId, password)
 }
 
 // MockTodoList is a mock of TodoList interface.
@@ -103,76 +246,76 @@ func (m *MockTodoList) EXPECT() *MockTodoListMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockTodoList) Create(userId int, list pkg.TodoList) (int, error) {
+func (m *MockTodoList) Create(ctx context.Context, userId int, list pkg.TodoList) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", userId, list)
+	ret := m.ctrl.Call(m, "Create", ctx, userId, list)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTodoListMockRecorder) Create(userId, list interface{}) *gomock.Call {
+func (mr *MockTodoListMockRecorder) Create(ctx, userId, list interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodoList)(nil).Create), userId, list)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodoList)(nil).Create), ctx, userId, list)
 }
 
 // Delete mocks base method.
-func (m *MockTodoList) Delete(userId, listId int) error {
+func (m *MockTodoList) Delete(ctx context.Context, userId, listId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", userId, listId)
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, listId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTodoListMockRecorder) Delete(userId, listId interface{}) *gomock.Call {
+func (mr *MockTodoListMockRecorder) Delete(ctx, userId, listId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoList)(nil).Delete), userId, listId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoList)(nil).Delete), ctx, userId, listId)
 }
 
 // GetAll mocks base method.
-func (m *MockTodoList) GetAll(userId int) ([]pkg.TodoList, error) {
+func (m *MockTodoList) GetAll(ctx context.Context, userId int) ([]pkg.TodoList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", userId)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
 	ret0, _ := ret[0].([]pkg.TodoList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockTodoListMockRecorder) GetAll(userId interface{}) *gomock.Call {
+func (mr *MockTodoListMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTodoList)(nil).GetAll), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTodoList)(nil).GetAll), ctx, userId)
 }
 
 // GetById mocks base method.
-func (m *MockTodoList) GetById(userId, listId int) (pkg.TodoList, error) {
+func (m *MockTodoList) GetById(ctx context.Context, userId, listId int) (pkg.TodoList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", userId, listId)
+	ret := m.ctrl.Call(m, "GetById", ctx, userId, listId)
 	ret0, _ := ret[0].(pkg.TodoList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockTodoListMockRecorder) GetById(userId, listId interface{}) *gomock.Call {
+func (mr *MockTodoListMockRecorder) GetById(ctx, userId, listId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTodoList)(nil).GetById), userId, listId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTodoList)(nil).GetById), ctx, userId, listId)
 }
 
 // Update mocks base method.
-func (m *MockTodoList) Update(userId, listId int, input pkg.UpdateListInput) error {
+func (m *MockTodoList) Update(ctx context.Context, userId, listId int, input pkg.UpdateListInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", userId, listId, input)
+	ret := m.ctrl.Call(m, "Update", ctx, userId, listId, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockTodoListMockRecorder) Update(userId, listId, input interface{}) *gomock.Call {
+func (mr *MockTodoListMockRecorder) Update(ctx, userId, listId, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoList)(nil).Update), userId, listId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoList)(nil).Update), ctx, userId, listId, input)
 }
 
 // MockTodoItem is a mock of TodoItem interface.
@@ -198,75 +341,1351 @@ func (m *MockTodoItem) EXPECT() *MockTodoItemMockRecorder {
 	return m.recorder
 }
 
+// Bulk mocks base method.
+func (m *MockTodoItem) Bulk(ctx context.Context, userId int, input pkg.BulkItemsInput) (pkg.BulkItemsOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Bulk", ctx, userId, input)
+	ret0, _ := ret[0].(pkg.BulkItemsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Bulk indicates an expected call of Bulk.
+func (mr *MockTodoItemMockRecorder) Bulk(ctx, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bulk", reflect.TypeOf((*MockTodoItem)(nil).Bulk), ctx, userId, input)
+}
+
 // Create mocks base method.
-func (m *MockTodoItem) Create(userId, listId int, item pkg.TodoItem) (int, error) {
+func (m *MockTodoItem) Create(ctx context.Context, userId, listId int, item pkg.TodoItem) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", userId, listId, item)
+	ret := m.ctrl.Call(m, "Create", ctx, userId, listId, item)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockTodoItemMockRecorder) Create(userId, listId, item interface{}) *gomock.Call {
+func (mr *MockTodoItemMockRecorder) Create(ctx, userId, listId, item interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodoItem)(nil).Create), userId, listId, item)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodoItem)(nil).Create), ctx, userId, listId, item)
 }
 
 // Delete mocks base method.
-func (m *MockTodoItem) Delete(userId, itemId int) error {
+func (m *MockTodoItem) Delete(ctx context.Context, userId, itemId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", userId, itemId)
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, itemId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockTodoItemMockRecorder) Delete(userId, itemId interface{}) *gomock.Call {
+func (mr *MockTodoItemMockRecorder) Delete(ctx, userId, itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoItem)(nil).Delete), ctx, userId, itemId)
+}
+
+// Dependencies mocks base method.
+func (m *MockTodoItem) Dependencies(ctx context.Context, userId, itemId int) (pkg.ItemDependencies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dependencies", ctx, userId, itemId)
+	ret0, _ := ret[0].(pkg.ItemDependencies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dependencies indicates an expected call of Dependencies.
+func (mr *MockTodoItemMockRecorder) Dependencies(ctx, userId, itemId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoItem)(nil).Delete), userId, itemId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dependencies", reflect.TypeOf((*MockTodoItem)(nil).Dependencies), ctx, userId, itemId)
 }
 
 // GetAll mocks base method.
-func (m *MockTodoItem) GetAll(userId, listId int) ([]pkg.TodoItem, error) {
+func (m *MockTodoItem) GetAll(ctx context.Context, userId, listId int, blocked *bool) ([]pkg.TodoItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", userId, listId)
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, listId, blocked)
 	ret0, _ := ret[0].([]pkg.TodoItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockTodoItemMockRecorder) GetAll(userId, listId interface{}) *gomock.Call {
+func (mr *MockTodoItemMockRecorder) GetAll(ctx, userId, listId, blocked interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTodoItem)(nil).GetAll), userId, listId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockTodoItem)(nil).GetAll), ctx, userId, listId, blocked)
 }
 
 // GetById mocks base method.
-func (m *MockTodoItem) GetById(userId, itemId int) (pkg.TodoItem, error) {
+func (m *MockTodoItem) GetById(ctx context.Context, userId, itemId int) (pkg.TodoItem, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", userId, itemId)
+	ret := m.ctrl.Call(m, "GetById", ctx, userId, itemId)
 	ret0, _ := ret[0].(pkg.TodoItem)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockTodoItemMockRecorder) GetById(userId, itemId interface{}) *gomock.Call {
+func (mr *MockTodoItemMockRecorder) GetById(ctx, userId, itemId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTodoItem)(nil).GetById), userId, itemId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockTodoItem)(nil).GetById), ctx, userId, itemId)
 }
 
 // Update mocks base method.
-func (m *MockTodoItem) Update(userId, itemId int, input pkg.UpdateItemInput) error {
+func (m *MockTodoItem) Update(ctx context.Context, userId, itemId int, input pkg.UpdateItemInput) ([]pkg.Warning, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, userId, itemId, input)
+	ret0, _ := ret[0].([]pkg.Warning)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTodoItemMockRecorder) Update(ctx, userId, itemId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoItem)(nil).Update), ctx, userId, itemId, input)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// Abort mocks base method.
+func (m *MockIdempotency) Abort(ctx context.Context, userId int, key string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", userId, itemId, input)
+	ret := m.ctrl.Call(m, "Abort", ctx, userId, key)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Update indicates an expected call of Update.
-func (mr *MockTodoItemMockRecorder) Update(userId, itemId, input interface{}) *gomock.Call {
+// Abort indicates an expected call of Abort.
+func (mr *MockIdempotencyMockRecorder) Abort(ctx, userId, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abort", reflect.TypeOf((*MockIdempotency)(nil).Abort), ctx, userId, key)
+}
+
+// Begin mocks base method.
+func (m *MockIdempotency) Begin(ctx context.Context, userId int, key, fingerprint string) (pkg.IdempotencyRecord, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx, userId, key, fingerprint)
+	ret0, _ := ret[0].(pkg.IdempotencyRecord)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyMockRecorder) Begin(ctx, userId, key, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotency)(nil).Begin), ctx, userId, key, fingerprint)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotency) Complete(ctx context.Context, record pkg.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyMockRecorder) Complete(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotency)(nil).Complete), ctx, record)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockIdempotency) DeleteExpired(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockIdempotencyMockRecorder) DeleteExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockIdempotency)(nil).DeleteExpired), ctx)
+}
+
+// MockRateLimit is a mock of RateLimit interface.
+type MockRateLimit struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitMockRecorder
+}
+
+// MockRateLimitMockRecorder is the mock recorder for MockRateLimit.
+type MockRateLimitMockRecorder struct {
+	mock *MockRateLimit
+}
+
+// NewMockRateLimit creates a new mock instance.
+func NewMockRateLimit(ctrl *gomock.Controller) *MockRateLimit {
+	mock := &MockRateLimit{ctrl: ctrl}
+	mock.recorder = &MockRateLimitMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimit) EXPECT() *MockRateLimitMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimit) Allow(ctx context.Context, group, key string) (pkg.RateLimitStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, group, key)
+	ret0, _ := ret[0].(pkg.RateLimitStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimitMockRecorder) Allow(ctx, group, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimit)(nil).Allow), ctx, group, key)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockRateLimit) DeleteExpired(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockRateLimitMockRecorder) DeleteExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRateLimit)(nil).DeleteExpired), ctx)
+}
+
+// MockEvents is a mock of Events interface.
+type MockEvents struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsMockRecorder
+}
+
+// MockEventsMockRecorder is the mock recorder for MockEvents.
+type MockEventsMockRecorder struct {
+	mock *MockEvents
+}
+
+// NewMockEvents creates a new mock instance.
+func NewMockEvents(ctrl *gomock.Controller) *MockEvents {
+	mock := &MockEvents{ctrl: ctrl}
+	mock.recorder = &MockEventsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEvents) EXPECT() *MockEventsMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockEvents) DeleteExpired(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockEventsMockRecorder) DeleteExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockEvents)(nil).DeleteExpired), ctx)
+}
+
+// Prepare mocks base method.
+func (m *MockEvents) Prepare(ctx context.Context, event pkg.Event) (pkg.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prepare", ctx, event)
+	ret0, _ := ret[0].(pkg.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prepare indicates an expected call of Prepare.
+func (mr *MockEventsMockRecorder) Prepare(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepare", reflect.TypeOf((*MockEvents)(nil).Prepare), ctx, event)
+}
+
+// Publish mocks base method.
+func (m *MockEvents) Publish(ctx context.Context, event pkg.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventsMockRecorder) Publish(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEvents)(nil).Publish), ctx, event)
+}
+
+// Run mocks base method.
+func (m *MockEvents) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockEventsMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockEvents)(nil).Run), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockEvents) Subscribe(userId int, lastEventId int64) (*pkg.EventSubscription, []pkg.Event, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userId, lastEventId)
+	ret0, _ := ret[0].(*pkg.EventSubscription)
+	ret1, _ := ret[1].([]pkg.Event)
+	ret2, _ := ret[2].(bool)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventsMockRecorder) Subscribe(userId, lastEventId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEvents)(nil).Subscribe), userId, lastEventId)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockEvents) Unsubscribe(sub *pkg.EventSubscription) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Unsubscribe", sub)
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockEventsMockRecorder) Unsubscribe(sub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockEvents)(nil).Unsubscribe), sub)
+}
+
+// MockCollab is a mock of Collab interface.
+type MockCollab struct {
+	ctrl     *gomock.Controller
+	recorder *MockCollabMockRecorder
+}
+
+// MockCollabMockRecorder is the mock recorder for MockCollab.
+type MockCollabMockRecorder struct {
+	mock *MockCollab
+}
+
+// NewMockCollab creates a new mock instance.
+func NewMockCollab(ctrl *gomock.Controller) *MockCollab {
+	mock := &MockCollab{ctrl: ctrl}
+	mock.recorder = &MockCollabMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCollab) EXPECT() *MockCollabMockRecorder {
+	return m.recorder
+}
+
+// Join mocks base method.
+func (m *MockCollab) Join(ctx context.Context, userId, listId int) (*pkg.CollabClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Join", ctx, userId, listId)
+	ret0, _ := ret[0].(*pkg.CollabClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Join indicates an expected call of Join.
+func (mr *MockCollabMockRecorder) Join(ctx, userId, listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Join", reflect.TypeOf((*MockCollab)(nil).Join), ctx, userId, listId)
+}
+
+// Leave mocks base method.
+func (m *MockCollab) Leave(client *pkg.CollabClient) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Leave", client)
+}
+
+// Leave indicates an expected call of Leave.
+func (mr *MockCollabMockRecorder) Leave(client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Leave", reflect.TypeOf((*MockCollab)(nil).Leave), client)
+}
+
+// Presence mocks base method.
+func (m *MockCollab) Presence(listId int) []int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Presence", listId)
+	ret0, _ := ret[0].([]int)
+	return ret0
+}
+
+// Presence indicates an expected call of Presence.
+func (mr *MockCollabMockRecorder) Presence(listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Presence", reflect.TypeOf((*MockCollab)(nil).Presence), listId)
+}
+
+// SetEditing mocks base method.
+func (m *MockCollab) SetEditing(client *pkg.CollabClient, itemId int, editing bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEditing", client, itemId, editing)
+}
+
+// SetEditing indicates an expected call of SetEditing.
+func (mr *MockCollabMockRecorder) SetEditing(client, itemId, editing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEditing", reflect.TypeOf((*MockCollab)(nil).SetEditing), client, itemId, editing)
+}
+
+// Shutdown mocks base method.
+func (m *MockCollab) Shutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Shutdown")
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockCollabMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockCollab)(nil).Shutdown))
+}
+
+// MockWebhook is a mock of Webhook interface.
+type MockWebhook struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookMockRecorder
+}
+
+// MockWebhookMockRecorder is the mock recorder for MockWebhook.
+type MockWebhookMockRecorder struct {
+	mock *MockWebhook
+}
+
+// NewMockWebhook creates a new mock instance.
+func NewMockWebhook(ctrl *gomock.Controller) *MockWebhook {
+	mock := &MockWebhook{ctrl: ctrl}
+	mock.recorder = &MockWebhookMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhook) EXPECT() *MockWebhookMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhook) Create(ctx context.Context, userId int, input pkg.WebhookInput) (pkg.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, input)
+	ret0, _ := ret[0].(pkg.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhookMockRecorder) Create(ctx, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhook)(nil).Create), ctx, userId, input)
+}
+
+// Delete mocks base method.
+func (m *MockWebhook) Delete(ctx context.Context, userId, webhookId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, webhookId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhookMockRecorder) Delete(ctx, userId, webhookId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhook)(nil).Delete), ctx, userId, webhookId)
+}
+
+// GetAll mocks base method.
+func (m *MockWebhook) GetAll(ctx context.Context, userId int) ([]pkg.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]pkg.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockWebhookMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockWebhook)(nil).GetAll), ctx, userId)
+}
+
+// GetById mocks base method.
+func (m *MockWebhook) GetById(ctx context.Context, userId, webhookId int) (pkg.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, userId, webhookId)
+	ret0, _ := ret[0].(pkg.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockWebhookMockRecorder) GetById(ctx, userId, webhookId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockWebhook)(nil).GetById), ctx, userId, webhookId)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhook) GetDeliveries(ctx context.Context, userId, webhookId int) ([]pkg.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, userId, webhookId)
+	ret0, _ := ret[0].([]pkg.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookMockRecorder) GetDeliveries(ctx, userId, webhookId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhook)(nil).GetDeliveries), ctx, userId, webhookId)
+}
+
+// Run mocks base method.
+func (m *MockWebhook) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockWebhookMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockWebhook)(nil).Run), ctx)
+}
+
+// SendTest mocks base method.
+func (m *MockWebhook) SendTest(ctx context.Context, userId, webhookId int) (pkg.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTest", ctx, userId, webhookId)
+	ret0, _ := ret[0].(pkg.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendTest indicates an expected call of SendTest.
+func (mr *MockWebhookMockRecorder) SendTest(ctx, userId, webhookId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTest", reflect.TypeOf((*MockWebhook)(nil).SendTest), ctx, userId, webhookId)
+}
+
+// Update mocks base method.
+func (m *MockWebhook) Update(ctx context.Context, userId, webhookId int, input pkg.UpdateWebhookInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, userId, webhookId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockWebhookMockRecorder) Update(ctx, userId, webhookId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWebhook)(nil).Update), ctx, userId, webhookId, input)
+}
+
+// MockCalendar is a mock of Calendar interface.
+type MockCalendar struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalendarMockRecorder
+}
+
+// MockCalendarMockRecorder is the mock recorder for MockCalendar.
+type MockCalendarMockRecorder struct {
+	mock *MockCalendar
+}
+
+// NewMockCalendar creates a new mock instance.
+func NewMockCalendar(ctrl *gomock.Controller) *MockCalendar {
+	mock := &MockCalendar{ctrl: ctrl}
+	mock.recorder = &MockCalendarMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCalendar) EXPECT() *MockCalendarMockRecorder {
+	return m.recorder
+}
+
+// FeedCalendar mocks base method.
+func (m *MockCalendar) FeedCalendar(ctx context.Context, token string) (ical.Calendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FeedCalendar", ctx, token)
+	ret0, _ := ret[0].(ical.Calendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FeedCalendar indicates an expected call of FeedCalendar.
+func (mr *MockCalendarMockRecorder) FeedCalendar(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedCalendar", reflect.TypeOf((*MockCalendar)(nil).FeedCalendar), ctx, token)
+}
+
+// FeedToken mocks base method.
+func (m *MockCalendar) FeedToken(ctx context.Context, userId int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FeedToken", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FeedToken indicates an expected call of FeedToken.
+func (mr *MockCalendarMockRecorder) FeedToken(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeedToken", reflect.TypeOf((*MockCalendar)(nil).FeedToken), ctx, userId)
+}
+
+// ListCalendar mocks base method.
+func (m *MockCalendar) ListCalendar(ctx context.Context, userId, listId int) (ical.Calendar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCalendar", ctx, userId, listId)
+	ret0, _ := ret[0].(ical.Calendar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCalendar indicates an expected call of ListCalendar.
+func (mr *MockCalendarMockRecorder) ListCalendar(ctx, userId, listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCalendar", reflect.TypeOf((*MockCalendar)(nil).ListCalendar), ctx, userId, listId)
+}
+
+// RotateFeedToken mocks base method.
+func (m *MockCalendar) RotateFeedToken(ctx context.Context, userId int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateFeedToken", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateFeedToken indicates an expected call of RotateFeedToken.
+func (mr *MockCalendarMockRecorder) RotateFeedToken(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateFeedToken", reflect.TypeOf((*MockCalendar)(nil).RotateFeedToken), ctx, userId)
+}
+
+// MockCalDAV is a mock of CalDAV interface.
+type MockCalDAV struct {
+	ctrl     *gomock.Controller
+	recorder *MockCalDAVMockRecorder
+}
+
+// MockCalDAVMockRecorder is the mock recorder for MockCalDAV.
+type MockCalDAVMockRecorder struct {
+	mock *MockCalDAV
+}
+
+// NewMockCalDAV creates a new mock instance.
+func NewMockCalDAV(ctrl *gomock.Controller) *MockCalDAV {
+	mock := &MockCalDAV{ctrl: ctrl}
+	mock.recorder = &MockCalDAVMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCalDAV) EXPECT() *MockCalDAVMockRecorder {
+	return m.recorder
+}
+
+// Calendar mocks base method.
+func (m *MockCalDAV) Calendar(ctx context.Context, userId, listId int) (pkg.CalendarCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Calendar", ctx, userId, listId)
+	ret0, _ := ret[0].(pkg.CalendarCollection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Calendar indicates an expected call of Calendar.
+func (mr *MockCalDAVMockRecorder) Calendar(ctx, userId, listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Calendar", reflect.TypeOf((*MockCalDAV)(nil).Calendar), ctx, userId, listId)
+}
+
+// Calendars mocks base method.
+func (m *MockCalDAV) Calendars(ctx context.Context, userId int) ([]pkg.CalendarCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Calendars", ctx, userId)
+	ret0, _ := ret[0].([]pkg.CalendarCollection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Calendars indicates an expected call of Calendars.
+func (mr *MockCalDAVMockRecorder) Calendars(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Calendars", reflect.TypeOf((*MockCalDAV)(nil).Calendars), ctx, userId)
+}
+
+// Changes mocks base method.
+func (m *MockCalDAV) Changes(ctx context.Context, userId, listId int, since int64) ([]pkg.CalendarObject, []string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changes", ctx, userId, listId, since)
+	ret0, _ := ret[0].([]pkg.CalendarObject)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(int64)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// Changes indicates an expected call of Changes.
+func (mr *MockCalDAVMockRecorder) Changes(ctx, userId, listId, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockCalDAV)(nil).Changes), ctx, userId, listId, since)
+}
+
+// DeleteObject mocks base method.
+func (m *MockCalDAV) DeleteObject(ctx context.Context, userId, listId int, name, ifMatch string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObject", ctx, userId, listId, name, ifMatch)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteObject indicates an expected call of DeleteObject.
+func (mr *MockCalDAVMockRecorder) DeleteObject(ctx, userId, listId, name, ifMatch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObject", reflect.TypeOf((*MockCalDAV)(nil).DeleteObject), ctx, userId, listId, name, ifMatch)
+}
+
+// Object mocks base method.
+func (m *MockCalDAV) Object(ctx context.Context, userId, listId int, name string) (pkg.CalendarObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Object", ctx, userId, listId, name)
+	ret0, _ := ret[0].(pkg.CalendarObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Object indicates an expected call of Object.
+func (mr *MockCalDAVMockRecorder) Object(ctx, userId, listId, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Object", reflect.TypeOf((*MockCalDAV)(nil).Object), ctx, userId, listId, name)
+}
+
+// Objects mocks base method.
+func (m *MockCalDAV) Objects(ctx context.Context, userId, listId int) ([]pkg.CalendarObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Objects", ctx, userId, listId)
+	ret0, _ := ret[0].([]pkg.CalendarObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Objects indicates an expected call of Objects.
+func (mr *MockCalDAVMockRecorder) Objects(ctx, userId, listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Objects", reflect.TypeOf((*MockCalDAV)(nil).Objects), ctx, userId, listId)
+}
+
+// PutObject mocks base method.
+func (m *MockCalDAV) PutObject(ctx context.Context, userId, listId int, name string, data io.Reader, ifMatch, ifNoneMatch string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutObject", ctx, userId, listId, name, data, ifMatch, ifNoneMatch)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutObject indicates an expected call of PutObject.
+func (mr *MockCalDAVMockRecorder) PutObject(ctx, userId, listId, name, data, ifMatch, ifNoneMatch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockCalDAV)(nil).PutObject), ctx, userId, listId, name, data, ifMatch, ifNoneMatch)
+}
+
+// MockTransfer is a mock of Transfer interface.
+type MockTransfer struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransferMockRecorder
+}
+
+// MockTransferMockRecorder is the mock recorder for MockTransfer.
+type MockTransferMockRecorder struct {
+	mock *MockTransfer
+}
+
+// NewMockTransfer creates a new mock instance.
+func NewMockTransfer(ctrl *gomock.Controller) *MockTransfer {
+	mock := &MockTransfer{ctrl: ctrl}
+	mock.recorder = &MockTransferMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransfer) EXPECT() *MockTransferMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockTransfer) Export(ctx context.Context, userId int, format string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, userId, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockTransferMockRecorder) Export(ctx, userId, format, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockTransfer)(nil).Export), ctx, userId, format, w)
+}
+
+// Import mocks base method.
+func (m *MockTransfer) Import(ctx context.Context, userId int, r io.Reader, options pkg.ImportOptions) (pkg.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, userId, r, options)
+	ret0, _ := ret[0].(pkg.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockTransferMockRecorder) Import(ctx, userId, r, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockTransfer)(nil).Import), ctx, userId, r, options)
+}
+
+// MockBackup is a mock of Backup interface.
+type MockBackup struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackupMockRecorder
+}
+
+// MockBackupMockRecorder is the mock recorder for MockBackup.
+type MockBackupMockRecorder struct {
+	mock *MockBackup
+}
+
+// NewMockBackup creates a new mock instance.
+func NewMockBackup(ctrl *gomock.Controller) *MockBackup {
+	mock := &MockBackup{ctrl: ctrl}
+	mock.recorder = &MockBackupMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackup) EXPECT() *MockBackupMockRecorder {
+	return m.recorder
+}
+
+// Dump mocks base method.
+func (m *MockBackup) Dump(ctx context.Context, w io.Writer) ([]pkg.BackupTable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dump", ctx, w)
+	ret0, _ := ret[0].([]pkg.BackupTable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dump indicates an expected call of Dump.
+func (mr *MockBackupMockRecorder) Dump(ctx, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dump", reflect.TypeOf((*MockBackup)(nil).Dump), ctx, w)
+}
+
+// Restore mocks base method.
+func (m *MockBackup) Restore(ctx context.Context, r io.Reader) ([]pkg.BackupTable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, r)
+	ret0, _ := ret[0].([]pkg.BackupTable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockBackupMockRecorder) Restore(ctx, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockBackup)(nil).Restore), ctx, r)
+}
+
+// MockQuickAdd is a mock of QuickAdd interface.
+type MockQuickAdd struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuickAddMockRecorder
+}
+
+// MockQuickAddMockRecorder is the mock recorder for MockQuickAdd.
+type MockQuickAddMockRecorder struct {
+	mock *MockQuickAdd
+}
+
+// NewMockQuickAdd creates a new mock instance.
+func NewMockQuickAdd(ctrl *gomock.Controller) *MockQuickAdd {
+	mock := &MockQuickAdd{ctrl: ctrl}
+	mock.recorder = &MockQuickAddMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuickAdd) EXPECT() *MockQuickAddMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockQuickAdd) Add(ctx context.Context, userId int, input pkg.QuickAddInput, dryRun bool) (pkg.QuickAddOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, userId, input, dryRun)
+	ret0, _ := ret[0].(pkg.QuickAddOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockQuickAddMockRecorder) Add(ctx, userId, input, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockQuickAdd)(nil).Add), ctx, userId, input, dryRun)
+}
+
+// MockFilter is a mock of Filter interface.
+type MockFilter struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilterMockRecorder
+}
+
+// MockFilterMockRecorder is the mock recorder for MockFilter.
+type MockFilterMockRecorder struct {
+	mock *MockFilter
+}
+
+// NewMockFilter creates a new mock instance.
+func NewMockFilter(ctrl *gomock.Controller) *MockFilter {
+	mock := &MockFilter{ctrl: ctrl}
+	mock.recorder = &MockFilterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFilter) EXPECT() *MockFilterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFilter) Create(ctx context.Context, userId int, input pkg.FilterInput) (pkg.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, input)
+	ret0, _ := ret[0].(pkg.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFilterMockRecorder) Create(ctx, userId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFilter)(nil).Create), ctx, userId, input)
+}
+
+// Delete mocks base method.
+func (m *MockFilter) Delete(ctx context.Context, userId, filterId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, filterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFilterMockRecorder) Delete(ctx, userId, filterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFilter)(nil).Delete), ctx, userId, filterId)
+}
+
+// GetAll mocks base method.
+func (m *MockFilter) GetAll(ctx context.Context, userId int) ([]pkg.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId)
+	ret0, _ := ret[0].([]pkg.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockFilterMockRecorder) GetAll(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockFilter)(nil).GetAll), ctx, userId)
+}
+
+// GetById mocks base method.
+func (m *MockFilter) GetById(ctx context.Context, userId, filterId int) (pkg.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, userId, filterId)
+	ret0, _ := ret[0].(pkg.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockFilterMockRecorder) GetById(ctx, userId, filterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockFilter)(nil).GetById), ctx, userId, filterId)
+}
+
+// GetItems mocks base method.
+func (m *MockFilter) GetItems(ctx context.Context, userId, filterId int) ([]pkg.TodoItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItems", ctx, userId, filterId)
+	ret0, _ := ret[0].([]pkg.TodoItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItems indicates an expected call of GetItems.
+func (mr *MockFilterMockRecorder) GetItems(ctx, userId, filterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItems", reflect.TypeOf((*MockFilter)(nil).GetItems), ctx, userId, filterId)
+}
+
+// Update mocks base method.
+func (m *MockFilter) Update(ctx context.Context, userId, filterId int, input pkg.UpdateFilterInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, userId, filterId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockFilterMockRecorder) Update(ctx, userId, filterId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFilter)(nil).Update), ctx, userId, filterId, input)
+}
+
+// MockComment is a mock of Comment interface.
+type MockComment struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentMockRecorder
+}
+
+// MockCommentMockRecorder is the mock recorder for MockComment.
+type MockCommentMockRecorder struct {
+	mock *MockComment
+}
+
+// NewMockComment creates a new mock instance.
+func NewMockComment(ctrl *gomock.Controller) *MockComment {
+	mock := &MockComment{ctrl: ctrl}
+	mock.recorder = &MockCommentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockComment) EXPECT() *MockCommentMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockComment) Create(ctx context.Context, userId, itemId int, input pkg.CommentInput) (pkg.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, itemId, input)
+	ret0, _ := ret[0].(pkg.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommentMockRecorder) Create(ctx, userId, itemId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockComment)(nil).Create), ctx, userId, itemId, input)
+}
+
+// Delete mocks base method.
+func (m *MockComment) Delete(ctx context.Context, userId, itemId, commentId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, itemId, commentId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentMockRecorder) Delete(ctx, userId, itemId, commentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockComment)(nil).Delete), ctx, userId, itemId, commentId)
+}
+
+// GetAll mocks base method.
+func (m *MockComment) GetAll(ctx context.Context, userId, itemId, after, limit int) (pkg.CommentsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, itemId, after, limit)
+	ret0, _ := ret[0].(pkg.CommentsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockCommentMockRecorder) GetAll(ctx, userId, itemId, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockComment)(nil).GetAll), ctx, userId, itemId, after, limit)
+}
+
+// Update mocks base method.
+func (m *MockComment) Update(ctx context.Context, userId, itemId, commentId int, input pkg.CommentInput) (pkg.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, userId, itemId, commentId, input)
+	ret0, _ := ret[0].(pkg.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCommentMockRecorder) Update(ctx, userId, itemId, commentId, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockComment)(nil).Update), ctx, userId, itemId, commentId, input)
+}
+
+// MockAttachment is a mock of Attachment interface.
+type MockAttachment struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentMockRecorder
+}
+
+// MockAttachmentMockRecorder is the mock recorder for MockAttachment.
+type MockAttachmentMockRecorder struct {
+	mock *MockAttachment
+}
+
+// NewMockAttachment creates a new mock instance.
+func NewMockAttachment(ctrl *gomock.Controller) *MockAttachment {
+	mock := &MockAttachment{ctrl: ctrl}
+	mock.recorder = &MockAttachmentMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachment) EXPECT() *MockAttachmentMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAttachment) Create(ctx context.Context, userId, itemId int, name string, r io.Reader) (pkg.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, itemId, name, r)
+	ret0, _ := ret[0].(pkg.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAttachmentMockRecorder) Create(ctx, userId, itemId, name, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAttachment)(nil).Create), ctx, userId, itemId, name, r)
+}
+
+// Delete mocks base method.
+func (m *MockAttachment) Delete(ctx context.Context, userId, itemId, attachmentId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, itemId, attachmentId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAttachmentMockRecorder) Delete(ctx, userId, itemId, attachmentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAttachment)(nil).Delete), ctx, userId, itemId, attachmentId)
+}
+
+// GetAll mocks base method.
+func (m *MockAttachment) GetAll(ctx context.Context, userId, itemId int) ([]pkg.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, userId, itemId)
+	ret0, _ := ret[0].([]pkg.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockAttachmentMockRecorder) GetAll(ctx, userId, itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockAttachment)(nil).GetAll), ctx, userId, itemId)
+}
+
+// Open mocks base method.
+func (m *MockAttachment) Open(ctx context.Context, userId, itemId, attachmentId int) (pkg.Attachment, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, userId, itemId, attachmentId)
+	ret0, _ := ret[0].(pkg.Attachment)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockAttachmentMockRecorder) Open(ctx, userId, itemId, attachmentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockAttachment)(nil).Open), ctx, userId, itemId, attachmentId)
+}
+
+// Run mocks base method.
+func (m *MockAttachment) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockAttachmentMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockAttachment)(nil).Run), ctx)
+}
+
+// MockHealth is a mock of Health interface.
+type MockHealth struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthMockRecorder
+}
+
+// MockHealthMockRecorder is the mock recorder for MockHealth.
+type MockHealthMockRecorder struct {
+	mock *MockHealth
+}
+
+// NewMockHealth creates a new mock instance.
+func NewMockHealth(ctrl *gomock.Controller) *MockHealth {
+	mock := &MockHealth{ctrl: ctrl}
+	mock.recorder = &MockHealthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealth) EXPECT() *MockHealthMockRecorder {
+	return m.recorder
+}
+
+// Drain mocks base method.
+func (m *MockHealth) Drain() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Drain")
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockHealthMockRecorder) Drain() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockHealth)(nil).Drain))
+}
+
+// Ready mocks base method.
+func (m *MockHealth) Ready(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockHealthMockRecorder) Ready(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockHealth)(nil).Ready), ctx)
+}
+
+// Version mocks base method.
+func (m *MockHealth) Version() pkg.BuildInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(pkg.BuildInfo)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockHealthMockRecorder) Version() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockHealth)(nil).Version))
+}
+
+// MockWorkflow is a mock of Workflow interface.
+type MockWorkflow struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkflowMockRecorder
+}
+
+// MockWorkflowMockRecorder is the mock recorder for MockWorkflow.
+type MockWorkflowMockRecorder struct {
+	mock *MockWorkflow
+}
+
+// NewMockWorkflow creates a new mock instance.
+func NewMockWorkflow(ctrl *gomock.Controller) *MockWorkflow {
+	mock := &MockWorkflow{ctrl: ctrl}
+	mock.recorder = &MockWorkflowMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkflow) EXPECT() *MockWorkflowMockRecorder {
+	return m.recorder
+}
+
+// Board mocks base method.
+func (m *MockWorkflow) Board(ctx context.Context, userId, listId int) (pkg.Board, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Board", ctx, userId, listId)
+	ret0, _ := ret[0].(pkg.Board)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Board indicates an expected call of Board.
+func (mr *MockWorkflowMockRecorder) Board(ctx, userId, listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Board", reflect.TypeOf((*MockWorkflow)(nil).Board), ctx, userId, listId)
+}
+
+// GetStatuses mocks base method.
+func (m *MockWorkflow) GetStatuses(ctx context.Context, userId, listId int) (pkg.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatuses", ctx, userId, listId)
+	ret0, _ := ret[0].(pkg.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatuses indicates an expected call of GetStatuses.
+func (mr *MockWorkflowMockRecorder) GetStatuses(ctx, userId, listId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatuses", reflect.TypeOf((*MockWorkflow)(nil).GetStatuses), ctx, userId, listId)
+}
+
+// SetStatuses mocks base method.
+func (m *MockWorkflow) SetStatuses(ctx context.Context, userId, listId int, input pkg.WorkflowInput) (pkg.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatuses", ctx, userId, listId, input)
+	ret0, _ := ret[0].(pkg.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatuses indicates an expected call of SetStatuses.
+func (mr *MockWorkflowMockRecorder) SetStatuses(ctx, userId, listId, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoItem)(nil).Update), userId, itemId, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatuses", reflect.TypeOf((*MockWorkflow)(nil).SetStatuses), ctx, userId, listId, input)
 }
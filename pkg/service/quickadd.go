@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/quickadd"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+type QuickAddService struct {
+	listRepo repository.TodoList
+	items    TodoItem
+	now      func() time.Time
+}
+
+func NewQuickAddService(listRepo repository.TodoList, items TodoItem) *QuickAddService {
+	return &QuickAddService{listRepo: listRepo, items: items, now: time.Now}
+}
+
+// Add creates the item described by the text in the list it names, or only
+// interprets the text on a dry run. Recurring phrases are only reported on a
+// dry run.
+func (s *QuickAddService) Add(ctx context.Context, userId int, input todo.QuickAddInput, dryRun bool) (_ todo.QuickAddOutput, err error) {
+	ctx, span := tracing.Start(ctx, "QuickAddService.Add", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	location := time.UTC
+	if input.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(input.Timezone); err != nil {
+			return todo.QuickAddOutput{}, &todo.ErrInvalidQuickAdd{Reason: "unknown timezone " + input.Timezone}
+		}
+	}
+
+	result := quickadd.Parse(input.Text, s.now().In(location))
+	item := todo.TodoItem{
+		Title:    result.Title,
+		Due:      result.Due,
+		Priority: result.Priority,
+		Tags:     todo.Tags(result.Tags),
+	}
+
+	switch {
+	case item.Title == "":
+		return todo.QuickAddOutput{}, &todo.ErrInvalidQuickAdd{Reason: "no title"}
+	case utf8.RuneCountInString(item.Title) > maxTextLength:
+		return todo.QuickAddOutput{}, &todo.ErrInvalidQuickAdd{Reason: "title is too long"}
+	}
+	if err := item.Tags.Validate(); err != nil {
+		return todo.QuickAddOutput{}, &todo.ErrInvalidQuickAdd{Reason: err.Error()}
+	}
+
+	list, err := s.findList(ctx, userId, result.List, input.ListId)
+	if err != nil {
+		return todo.QuickAddOutput{}, err
+	}
+
+	output := todo.QuickAddOutput{
+		DryRun:     dryRun,
+		ListId:     list.Id,
+		List:       list.Title,
+		Item:       item,
+		Recurrence: result.Recurrence,
+		Parts:      make([]todo.QuickAddPart, 0, len(result.Parts)),
+	}
+	for _, part := range result.Parts {
+		output.Parts = append(output.Parts, todo.QuickAddPart{Kind: part.Kind, Text: part.Text})
+	}
+	if dryRun {
+		return output, nil
+	}
+	if output.Recurrence != "" {
+		// items can't repeat yet, so creating only the first occurrence
+		// would silently drop part of what was asked for
+		return todo.QuickAddOutput{}, &todo.ErrInvalidQuickAdd{Reason: "recurring items aren't supported"}
+	}
+
+	output.Id, err = s.items.Create(ctx, userId, list.Id, item)
+	if err != nil {
+		return todo.QuickAddOutput{}, err
+	}
+	output.Item.Id = output.Id
+	return output, nil
+}
+
+// findList looks up a list by its title, preferring an exact match over one
+// ignoring case. Without a title the given list is used.
+func (s *QuickAddService) findList(ctx context.Context, userId int, title string, listId int) (todo.TodoList, error) {
+	if title == "" {
+		if listId == 0 {
+			return todo.TodoList{}, &todo.ErrInvalidQuickAdd{Reason: "no list given"}
+		}
+		return s.listRepo.GetById(ctx, userId, listId)
+	}
+
+	lists, err := s.listRepo.GetAll(ctx, userId)
+	if err != nil {
+		return todo.TodoList{}, err
+	}
+
+	var found *todo.TodoList
+	for i, list := range lists {
+		if list.Title == title {
+			return list, nil
+		}
+		if found == nil && strings.EqualFold(list.Title, title) {
+			found = &lists[i]
+		}
+	}
+	if found == nil {
+		return todo.TodoList{}, &todo.ErrNoSuchList{}
+	}
+	return *found, nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+)
+
+// quickAddItems records the items created through the item service.
+type quickAddItems struct {
+	TodoItem
+	created map[int][]todo.TodoItem
+}
+
+func (s *quickAddItems) Create(ctx context.Context, userId, listId int, item todo.TodoItem) (int, error) {
+	s.created[listId] = append(s.created[listId], item)
+	return 7, nil
+}
+
+func newQuickAddService() (*QuickAddService, *quickAddItems) {
+	repo := &transferRepo{lists: []todo.TodoList{
+		{Id: 1, Title: "Inbox"},
+		{Id: 2, Title: "household"},
+		{Id: 3, Title: "Household"},
+	}}
+	items := &quickAddItems{created: map[int][]todo.TodoItem{}}
+	service := NewQuickAddService(repo, items)
+	service.now = func() time.Time {
+		return time.Date(2023, time.October, 18, 15, 0, 0, 0, time.UTC)
+	}
+	return service, items
+}
+
+func TestQuickAddService_Add(t *testing.T) {
+	service, items := newQuickAddService()
+
+	output, err := service.Add(context.Background(), 1, todo.QuickAddInput{
+		Text: "Pay rent tomorrow 9am #finance !high @household",
+	}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	due := time.Date(2023, time.October, 19, 9, 0, 0, 0, time.UTC)
+	item := todo.TodoItem{
+		Title:    "Pay rent",
+		Due:      &due,
+		Priority: todo.PriorityHigh,
+		Tags:     todo.Tags{"finance"},
+	}
+	if !reflect.DeepEqual(items.created[2], []todo.TodoItem{item}) {
+		t.Errorf("created %+v, want %+v", items.created, item)
+	}
+	if output.Id != 7 || output.Item.Id != 7 || output.ListId != 2 || output.List != "household" {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if len(output.Parts) != 5 {
+		t.Errorf("parts %+v, want 5", output.Parts)
+	}
+}
+
+func TestQuickAddService_Add_List(t *testing.T) {
+	testTable := []struct {
+		name       string
+		input      todo.QuickAddInput
+		expectedId int
+	}{
+		{
+			name:       "Exact title",
+			input:      todo.QuickAddInput{Text: "Pay rent @Household"},
+			expectedId: 3,
+		},
+		{
+			name:       "Any case",
+			input:      todo.QuickAddInput{Text: "Pay rent @INBOX"},
+			expectedId: 1,
+		},
+		{
+			name:       "Title over list id",
+			input:      todo.QuickAddInput{Text: "Pay rent @Inbox", ListId: 3},
+			expectedId: 1,
+		},
+		{
+			name:       "List id",
+			input:      todo.QuickAddInput{Text: "Pay rent", ListId: 2},
+			expectedId: 2,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, _ := newQuickAddService()
+			output, err := service.Add(context.Background(), 1, testCase.input, true)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if output.ListId != testCase.expectedId {
+				t.Errorf("list %d, want %d", output.ListId, testCase.expectedId)
+			}
+		})
+	}
+}
+
+func TestQuickAddService_Add_DryRun(t *testing.T) {
+	service, items := newQuickAddService()
+
+	output, err := service.Add(context.Background(), 1, todo.QuickAddInput{
+		Text:     "Call mom tonight every sunday @Inbox",
+		Timezone: "Europe/Berlin",
+	}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(items.created) != 0 {
+		t.Errorf("dry run created %+v", items.created)
+	}
+
+	location, _ := time.LoadLocation("Europe/Berlin")
+	due := time.Date(2023, time.October, 18, 20, 0, 0, 0, location)
+	if !output.DryRun || output.Id != 0 || output.Item.Due == nil || !output.Item.Due.Equal(due) {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if output.Recurrence == "" {
+		t.Errorf("recurrence not reported")
+	}
+}
+
+func TestQuickAddService_Add_Error(t *testing.T) {
+	testTable := []struct {
+		name          string
+		input         todo.QuickAddInput
+		expectedError error
+	}{
+		{
+			name:          "No title",
+			input:         todo.QuickAddInput{Text: "#finance !high @Inbox"},
+			expectedError: &todo.ErrInvalidQuickAdd{Reason: "no title"},
+		},
+		{
+			name:          "No list",
+			input:         todo.QuickAddInput{Text: "Pay rent"},
+			expectedError: &todo.ErrInvalidQuickAdd{Reason: "no list given"},
+		},
+		{
+			name:          "Unknown list",
+			input:         todo.QuickAddInput{Text: "Pay rent @Garage"},
+			expectedError: &todo.ErrNoSuchList{},
+		},
+		{
+			name:          "Unknown timezone",
+			input:         todo.QuickAddInput{Text: "Pay rent @Inbox", Timezone: "Mars/Olympus"},
+			expectedError: &todo.ErrInvalidQuickAdd{Reason: "unknown timezone Mars/Olympus"},
+		},
+		{
+			name:          "Recurring",
+			input:         todo.QuickAddInput{Text: "Water plants every monday @Inbox"},
+			expectedError: &todo.ErrInvalidQuickAdd{Reason: "recurring items aren't supported"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, items := newQuickAddService()
+			_, err := service.Add(context.Background(), 1, testCase.input, false)
+			if !reflect.DeepEqual(err, testCase.expectedError) {
+				t.Errorf("error %v, want %v", err, testCase.expectedError)
+			}
+			if len(items.created) != 0 {
+				t.Errorf("created %+v", items.created)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+// defaultRateLimits are the limits of the route groups, sign-ins being
+// limited hard against password guessing. CalDAV clients send their
+// password with every request of a sync, so they get more room.
+var defaultRateLimits = map[string]todo.RateLimit{
+	todo.RateLimitAuth: {Requests: 10, Period: time.Minute},
+	todo.RateLimitAPI:  {Requests: 600, Period: time.Minute},
+	todo.RateLimitDAV:  {Requests: 120, Period: time.Minute},
+}
+
+type RateLimitService struct {
+	repo   repository.RateLimit
+	limits map[string]todo.RateLimit
+}
+
+func NewRateLimitService(repo repository.RateLimit, limits map[string]todo.RateLimit) *RateLimitService {
+	return &RateLimitService{repo: repo, limits: limits}
+}
+
+// rateLimits returns the limits of the route groups, each set by
+// RATE_LIMIT_<GROUP>.
+func rateLimits() map[string]todo.RateLimit {
+	limits := make(map[string]todo.RateLimit, len(defaultRateLimits))
+	for group, fallback := range defaultRateLimits {
+		key := "RATE_LIMIT_" + strings.ToUpper(group)
+		value := config.Get(key, "")
+		if value == "" {
+			limits[group] = fallback
+			continue
+		}
+
+		limit, err := todo.ParseRateLimit(value)
+		if err != nil {
+			slog.Warn("Invalid "+key+", using the default", "value", value, "default", fallback.String())
+			limit = fallback
+		}
+		limits[group] = limit
+	}
+	return limits
+}
+
+// Allow takes a token from the bucket of the key in the group, a client
+// being limited separately in every group.
+func (s *RateLimitService) Allow(ctx context.Context, group, key string) (_ todo.RateLimitStatus, err error) {
+	limit := s.limits[group]
+	if limit.Unlimited() {
+		return todo.RateLimitStatus{Limit: limit, Allowed: true}, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "RateLimitService.Allow")
+	defer tracing.End(span, &err)
+
+	tokens, allowed, err := s.repo.Take(ctx, group+":"+key, limit, time.Now())
+	if err != nil {
+		return todo.RateLimitStatus{}, err
+	}
+
+	status := todo.RateLimitStatus{
+		Limit:     limit,
+		Allowed:   allowed,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsDuration((float64(limit.Requests) - tokens) / limit.Rate()),
+	}
+	if !allowed {
+		status.RetryAfter = secondsDuration((1 - tokens) / limit.Rate())
+	}
+	return status, nil
+}
+
+// DeleteExpired drops the buckets that have been full for a while, which
+// are the same as no bucket.
+func (s *RateLimitService) DeleteExpired(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "RateLimitService.DeleteExpired")
+	defer tracing.End(span, &err)
+
+	var longest time.Duration
+	for _, limit := range s.limits {
+		longest = max(longest, limit.Period)
+	}
+	return s.repo.DeleteExpired(ctx, time.Now().Add(-longest))
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRateLimitService_Allow(t *testing.T) {
+	s := NewRateLimitService(repository.NewRateLimitMemory(), map[string]todo.RateLimit{
+		todo.RateLimitAuth: {Requests: 2, Period: time.Hour},
+	})
+	ctx := context.Background()
+
+	first, err := s.Allow(ctx, todo.RateLimitAuth, "ip:192.0.2.1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, first.Allowed, true)
+	assert.Equal(t, first.Remaining, 1)
+
+	second, _ := s.Allow(ctx, todo.RateLimitAuth, "ip:192.0.2.1")
+	assert.Equal(t, second.Allowed, true)
+	assert.Equal(t, second.Remaining, 0)
+
+	third, _ := s.Allow(ctx, todo.RateLimitAuth, "ip:192.0.2.1")
+	assert.Equal(t, third.Allowed, false)
+	// a token comes back every half an hour
+	assert.Equal(t, third.RetryAfter > 29*time.Minute && third.RetryAfter <= 30*time.Minute, true)
+	assert.Equal(t, third.Reset > 59*time.Minute && third.Reset <= time.Hour, true)
+
+	// other clients and groups have buckets of their own
+	other, _ := s.Allow(ctx, todo.RateLimitAuth, "ip:192.0.2.2")
+	assert.Equal(t, other.Allowed, true)
+	unlimited, _ := s.Allow(ctx, todo.RateLimitAPI, "ip:192.0.2.1")
+	assert.Equal(t, unlimited.Allowed, true)
+}
+
+func TestRateLimitMemory_Refill(t *testing.T) {
+	repo := repository.NewRateLimitMemory()
+	limit := todo.RateLimit{Requests: 2, Period: time.Minute}
+	now := time.Date(2023, 12, 18, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	repo.Take(ctx, "auth:ip", limit, now)
+	repo.Take(ctx, "auth:ip", limit, now)
+	_, allowed, _ := repo.Take(ctx, "auth:ip", limit, now.Add(10*time.Second))
+	assert.Equal(t, allowed, false)
+
+	tokens, allowed, _ := repo.Take(ctx, "auth:ip", limit, now.Add(30*time.Second))
+	assert.Equal(t, allowed, true)
+	assert.Equal(t, tokens, 0.0)
+
+	// buckets never hold more than the limit
+	tokens, _, _ = repo.Take(ctx, "auth:ip", limit, now.Add(time.Hour))
+	assert.Equal(t, tokens, 1.0)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	testTable := []struct {
+		input         string
+		expectedLimit todo.RateLimit
+		expectedErr   bool
+	}{
+		{input: "10/1m", expectedLimit: todo.RateLimit{Requests: 10, Period: time.Minute}},
+		{input: "100/h", expectedLimit: todo.RateLimit{Requests: 100, Period: time.Hour}},
+		{input: "0"},
+		{input: ""},
+		{input: "10", expectedErr: true},
+		{input: "ten/1m", expectedErr: true},
+		{input: "10/0s", expectedErr: true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.input, func(t *testing.T) {
+			limit, err := todo.ParseRateLimit(testCase.input)
+			assert.Equal(t, err != nil, testCase.expectedErr)
+			assert.Equal(t, limit, testCase.expectedLimit)
+		})
+	}
+}
@@ -1,44 +1,211 @@
 package service
 
 import (
+	"context"
+	"io"
+
 	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/ical"
 	"github.com/OrIX219/todo/pkg/repository"
 )
 
 //go:generate mockgen -source=service.go -destination=mock/mock.go
 
 type Authorization interface {
-	CreateUser(user todo.User) (int, error)
-	GenerateToken(username, password string) (string, error)
-	ParseToken(token string) (int, error)
+	CreateUser(ctx context.Context, user todo.User) (int, error)
+	Authenticate(ctx context.Context, username, password string) (int, error)
+	GenerateToken(ctx context.Context, username, password string, client todo.LoginClient) (string, error)
+	ParseToken(ctx context.Context, token string) (int, error)
+	GetSessions(ctx context.Context, userId int) ([]todo.Session, error)
+	VerifyTwoFactor(ctx context.Context, challenge, code string, client todo.LoginClient) (string, error)
+	Reauthenticate(ctx context.Context, userId int, password, code string) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type TwoFactor interface {
+	Enroll(ctx context.Context, userId int, password string) (todo.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userId int, password, code string) ([]string, error)
+	Disable(ctx context.Context, userId int, password, code string) error
 }
 
 type TodoList interface {
-	Create(userId int, list todo.TodoList) (int, error)
-	GetAll(userId int) ([]todo.TodoList, error)
-	GetById(userId, listId int) (todo.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input todo.UpdateListInput) error
+	Create(ctx context.Context, userId int, list todo.TodoList) (int, error)
+	GetAll(ctx context.Context, userId int) ([]todo.TodoList, error)
+	GetById(ctx context.Context, userId, listId int) (todo.TodoList, error)
+	Delete(ctx context.Context, userId, listId int) error
+	Update(ctx context.Context, userId, listId int, input todo.UpdateListInput) error
 }
 
 type TodoItem interface {
-	Create(userId, listId int, item todo.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]todo.TodoItem, error)
-	GetById(userId, itemId int) (todo.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input todo.UpdateItemInput) error
+	Create(ctx context.Context, userId, listId int, item todo.TodoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int, blocked *bool) ([]todo.TodoItem, error)
+	GetById(ctx context.Context, userId, itemId int) (todo.TodoItem, error)
+	Delete(ctx context.Context, userId, itemId int) error
+	Update(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) ([]todo.Warning, error)
+	Bulk(ctx context.Context, userId int, input todo.BulkItemsInput) (todo.BulkItemsOutput, error)
+	Dependencies(ctx context.Context, userId, itemId int) (todo.ItemDependencies, error)
+}
+
+type Idempotency interface {
+	Begin(ctx context.Context, userId int, key, fingerprint string) (todo.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record todo.IdempotencyRecord) error
+	Abort(ctx context.Context, userId int, key string) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type RateLimit interface {
+	Allow(ctx context.Context, group, key string) (todo.RateLimitStatus, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type Events interface {
+	Prepare(ctx context.Context, event todo.Event) (todo.Event, error)
+	Publish(ctx context.Context, event todo.Event) error
+	Subscribe(userId int, lastEventId int64) (*todo.EventSubscription, []todo.Event, bool)
+	Unsubscribe(sub *todo.EventSubscription)
+	Run(ctx context.Context) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type Collab interface {
+	Join(ctx context.Context, userId, listId int) (*todo.CollabClient, error)
+	Leave(client *todo.CollabClient)
+	SetEditing(client *todo.CollabClient, itemId int, editing bool)
+	Presence(listId int) []int
+	Shutdown()
+}
+
+type Webhook interface {
+	Create(ctx context.Context, userId int, input todo.WebhookInput) (todo.Webhook, error)
+	GetAll(ctx context.Context, userId int) ([]todo.Webhook, error)
+	GetById(ctx context.Context, userId, webhookId int) (todo.Webhook, error)
+	Update(ctx context.Context, userId, webhookId int, input todo.UpdateWebhookInput) error
+	Delete(ctx context.Context, userId, webhookId int) error
+	GetDeliveries(ctx context.Context, userId, webhookId int) ([]todo.WebhookDelivery, error)
+	SendTest(ctx context.Context, userId, webhookId int) (todo.WebhookDelivery, error)
+	Run(ctx context.Context) error
+}
+
+type Calendar interface {
+	ListCalendar(ctx context.Context, userId, listId int) (ical.Calendar, error)
+	FeedCalendar(ctx context.Context, token string) (ical.Calendar, error)
+	FeedToken(ctx context.Context, userId int) (string, error)
+	RotateFeedToken(ctx context.Context, userId int) (string, error)
+}
+
+type CalDAV interface {
+	Calendars(ctx context.Context, userId int) ([]todo.CalendarCollection, error)
+	Calendar(ctx context.Context, userId, listId int) (todo.CalendarCollection, error)
+	Objects(ctx context.Context, userId, listId int) ([]todo.CalendarObject, error)
+	Object(ctx context.Context, userId, listId int, name string) (todo.CalendarObject, error)
+	Changes(ctx context.Context, userId, listId int, since int64) ([]todo.CalendarObject, []string, int64, error)
+	PutObject(ctx context.Context, userId, listId int, name string, data io.Reader, ifMatch, ifNoneMatch string) (bool, error)
+	DeleteObject(ctx context.Context, userId, listId int, name, ifMatch string) error
+}
+
+type Transfer interface {
+	Export(ctx context.Context, userId int, format string, w io.Writer) error
+	Import(ctx context.Context, userId int, r io.Reader, options todo.ImportOptions) (todo.ImportReport, error)
+}
+
+type Backup interface {
+	Dump(ctx context.Context, w io.Writer) ([]todo.BackupTable, error)
+	Restore(ctx context.Context, r io.Reader) ([]todo.BackupTable, error)
+}
+
+type QuickAdd interface {
+	Add(ctx context.Context, userId int, input todo.QuickAddInput, dryRun bool) (todo.QuickAddOutput, error)
+}
+
+type Filter interface {
+	Create(ctx context.Context, userId int, input todo.FilterInput) (todo.Filter, error)
+	GetAll(ctx context.Context, userId int) ([]todo.Filter, error)
+	GetById(ctx context.Context, userId, filterId int) (todo.Filter, error)
+	Update(ctx context.Context, userId, filterId int, input todo.UpdateFilterInput) error
+	Delete(ctx context.Context, userId, filterId int) error
+	GetItems(ctx context.Context, userId, filterId int) ([]todo.TodoItem, error)
+}
+
+type Comment interface {
+	Create(ctx context.Context, userId, itemId int, input todo.CommentInput) (todo.Comment, error)
+	GetAll(ctx context.Context, userId, itemId, after, limit int) (todo.CommentsPage, error)
+	Update(ctx context.Context, userId, itemId, commentId int, input todo.CommentInput) (todo.Comment, error)
+	Delete(ctx context.Context, userId, itemId, commentId int) error
+}
+
+type Attachment interface {
+	Create(ctx context.Context, userId, itemId int, name string, r io.Reader) (todo.Attachment, error)
+	GetAll(ctx context.Context, userId, itemId int) ([]todo.Attachment, error)
+	Open(ctx context.Context, userId, itemId, attachmentId int) (todo.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, userId, itemId, attachmentId int) error
+	Run(ctx context.Context) error
+}
+
+type Health interface {
+	Ready(ctx context.Context) error
+	Drain()
+	Version() todo.BuildInfo
+}
+
+type Workflow interface {
+	GetStatuses(ctx context.Context, userId, listId int) (todo.Workflow, error)
+	SetStatuses(ctx context.Context, userId, listId int, input todo.WorkflowInput) (todo.Workflow, error)
+	Board(ctx context.Context, userId, listId int) (todo.Board, error)
 }
 
 type Service struct {
 	Authorization
+	TwoFactor
 	TodoList
 	TodoItem
+	Idempotency
+	RateLimit
+	Events
+	Collab
+	Webhook
+	Calendar
+	CalDAV
+	Transfer
+	Backup
+	QuickAdd
+	Filter
+	Workflow
+	Comment
+	Attachment
+	Health
 }
 
 func NewService(repos *repository.Repository) *Service {
+	events := NewEventService(repos.Events)
+	collab := NewCollabService(repos.TodoList)
+	events.OnEvent(collab.HandleEvent)
+	webhook := NewWebhookService(repos.Webhook)
+	events.OnPublish(webhook.HandleEvent)
+	events.OnPublish(countEvent)
+	todoItem := NewTodoItemService(repos.TodoItem, repos.TodoList, repos.Workflow, repos.Dependency,
+		repos.Transactor, events)
+
+	auth := NewAuthService(repos.Authorization, repos.Login, repos.TwoFactor, lockoutPolicy())
+
 	return &Service{
-		Authorization: NewAuthService(repos.Authorization),
-		TodoList:      NewTodoListService(repos.TodoList),
-		TodoItem:      NewTodoItemService(repos.TodoItem, repos.TodoList),
+		Authorization: auth,
+		TwoFactor:     NewTwoFactorService(repos.TwoFactor, auth),
+		TodoList:      NewTodoListService(repos.TodoList, events),
+		TodoItem:      todoItem,
+		Idempotency:   NewIdempotencyService(repos.Idempotency),
+		RateLimit:     NewRateLimitService(repos.RateLimit, rateLimits()),
+		Events:        events,
+		Collab:        collab,
+		Webhook:       webhook,
+		Calendar:      NewCalendarService(repos.CalendarFeed, repos.TodoItem, repos.TodoList),
+		CalDAV:        NewCalDAVService(repos.CalDAV, repos.TodoList, todoItem),
+		Transfer:      NewTransferService(repos.TodoList, repos.TodoItem, repos.Transactor),
+		Backup:        NewBackupService(repos.Backup),
+		QuickAdd:      NewQuickAddService(repos.TodoList, todoItem),
+		Filter:        NewFilterService(repos.Filter, repos.TodoItem),
+		Workflow:      NewWorkflowService(repos.Workflow, repos.TodoList, repos.TodoItem, events),
+		Comment:       NewCommentService(repos.Comment, repos.TodoItem),
+		Attachment:    NewAttachmentService(repos.Attachment, repos.BlobStore, repos.TodoItem, attachmentQuota()),
+		Health:        NewHealthService(repos.Health, migrationsDir()),
 	}
 }
@@ -1,40 +1,654 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/logging"
 	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
 )
 
+// errRollback rolls back a transaction without failing.
+var errRollback = errors.New("rollback")
+
 type TodoItemService struct {
-	repo     repository.TodoItem
-	listRepo repository.TodoList
+	repo           repository.TodoItem
+	listRepo       repository.TodoList
+	workflowRepo   repository.Workflow
+	dependencyRepo repository.Dependency
+	transactor     repository.Transactor
+	events         Events
 }
 
-func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList) *TodoItemService {
-	return &TodoItemService{repo: repo, listRepo: listRepo}
+func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList, workflowRepo repository.Workflow,
+	dependencyRepo repository.Dependency, transactor repository.Transactor, events Events) *TodoItemService {
+	return &TodoItemService{repo: repo, listRepo: listRepo, workflowRepo: workflowRepo,
+		dependencyRepo: dependencyRepo, transactor: transactor, events: events}
 }
 
-func (s *TodoItemService) Create(userId, listId int, item todo.TodoItem) (int, error) {
-	_, err := s.listRepo.GetById(userId, listId)
+// Create doesn't warn about done items with open blockers, it only rejects
+// them when the list does.
+func (s *TodoItemService) Create(ctx context.Context, userId, listId int, item todo.TodoItem) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Create", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	list, err := s.listRepo.GetById(ctx, userId, listId)
 	if err != nil {
 		return 0, err
 	}
 
-	return s.repo.Create(listId, item)
+	item, err = s.initialStatus(ctx, listId, item)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	lock := item.Done && len(item.BlockedBy) > 0
+	err = s.guardDependencies(ctx, lock, func(s *TodoItemService) error {
+		if _, err := s.checkNewBlockers(ctx, userId, list, item); err != nil {
+			return err
+		}
+		id, err = s.repo.Create(ctx, listId, item)
+		return err
+	})
+	if err != nil {
+		return id, err
+	}
+
+	item.Id = id
+	publishEvent(ctx, s.events, newEvent(todo.EventItemCreated, userId, listId, id, item))
+	return id, nil
+}
+
+// GetAll returns the items of the list, only the blocked or the unblocked
+// ones when blocked is set.
+func (s *TodoItemService) GetAll(ctx context.Context, userId, listId int, blocked *bool) (_ []todo.TodoItem, err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.GetAll", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	items, err := s.repo.GetAll(ctx, userId, listId)
+	if err != nil || blocked == nil {
+		return items, err
+	}
+
+	filtered := make([]todo.TodoItem, 0, len(items))
+	for _, item := range items {
+		if item.Blocked == *blocked {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+func (s *TodoItemService) GetById(ctx context.Context, userId, itemId int) (_ todo.TodoItem, err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.GetById", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetById(ctx, userId, itemId)
+}
+
+func (s *TodoItemService) Delete(ctx context.Context, userId, itemId int) (err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Delete", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	event, err := s.events.Prepare(ctx, newEvent(todo.EventItemDeleted, userId, 0, itemId, nil))
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, userId, itemId); err != nil {
+		return err
+	}
+
+	if event.IsRecipient(userId) {
+		publishEvent(ctx, s.events, event)
+	}
+	return nil
+}
+
+// Update returns warnings when the list lets a blocked item be done.
+func (s *TodoItemService) Update(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) (_ []todo.Warning, err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Update", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	input, err = s.moveItem(ctx, userId, itemId, input)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []todo.Warning
+	var completed bool
+	err = s.guardDependencies(ctx, changesDependencies(input), func(s *TodoItemService) error {
+		var err error
+		warnings, err = s.checkBlockers(ctx, userId, itemId, input, nil)
+		if err != nil {
+			return err
+		}
+		if completed, err = s.completes(ctx, userId, itemId, input); err != nil {
+			return err
+		}
+		return s.repo.Update(ctx, userId, itemId, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	event := newEvent(todo.EventItemUpdated, userId, 0, itemId, input)
+	event.Completed = completed
+	publishEvent(ctx, s.events, event)
+	return warnings, nil
+}
+
+// completes tells whether the update marks an open item done. Clients like
+// CalDAV send done with every update, which doesn't complete anything.
+func (s *TodoItemService) completes(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) (bool, error) {
+	if input.Done == nil || !*input.Done {
+		return false, nil
+	}
+
+	item, err := s.repo.GetById(ctx, userId, itemId)
+	if err != nil {
+		return false, err
+	}
+	return !item.Done, nil
+}
+
+func (s *TodoItemService) Dependencies(ctx context.Context, userId, itemId int) (_ todo.ItemDependencies, err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Dependencies", tracing.UserId(userId), tracing.ItemId(itemId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.repo.GetById(ctx, userId, itemId); err != nil {
+		return todo.ItemDependencies{}, err
+	}
+	return s.dependencyRepo.GetDependencies(ctx, userId, itemId)
+}
+
+func (s *TodoItemService) Bulk(ctx context.Context, userId int, input todo.BulkItemsInput) (_ todo.BulkItemsOutput, err error) {
+	ctx, span := tracing.Start(ctx, "TodoItemService.Bulk", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	lock := false
+	for _, op := range input.Operations {
+		lock = lock || bulkChangesDependencies(op)
+	}
+
+	var output todo.BulkItemsOutput
+	var publish func()
+	err = s.guardDependencies(ctx, lock, func(s *TodoItemService) error {
+		var err error
+		output, publish, err = s.bulk(ctx, userId, input)
+		if err == nil && !output.Applied {
+			// the repository leaves a joined transaction to its owner
+			return errRollback
+		}
+		return err
+	})
+	if err != nil && err != errRollback {
+		return todo.BulkItemsOutput{}, err
+	}
+
+	// only once committed
+	if publish != nil {
+		publish()
+	}
+	return output, nil
+}
+
+// bulk applies the operations, returning a function publishing their
+// events.
+func (s *TodoItemService) bulk(ctx context.Context, userId int, input todo.BulkItemsInput) (todo.BulkItemsOutput, func(), error) {
+	results := make([]todo.BulkItemResult, len(input.Operations))
+	ops := make([]todo.BulkItemOperation, 0, len(input.Operations))
+	indexes := make([]int, 0, len(input.Operations))
+	warnings := make([][]todo.Warning, len(input.Operations))
+	// blockers set by earlier operations, which cycles have to take into
+	// account
+	pending := make(map[int]todo.ItemIds)
+	// items completed by earlier operations, and the operations completing
+	// them
+	completing := make(map[int]bool)
+	completed := make([]bool, len(input.Operations))
+	invalid := false
+	for i, op := range input.Operations {
+		results[i] = todo.BulkItemResult{Index: i, Op: op.Op}
+		if err := op.Validate(); err != nil {
+			results[i].Err = err
+			invalid = true
+			continue
+		}
+		op, err := s.bulkStatus(ctx, userId, op)
+		if err != nil {
+			results[i].Err = err
+			invalid = true
+			continue
+		}
+		warnings[i], err = s.bulkBlockers(ctx, userId, op, pending)
+		if err != nil {
+			results[i].Err = err
+			invalid = true
+			continue
+		}
+		if op.Op == todo.BulkOpUpdate && !completing[op.ItemId] {
+			if completing[op.ItemId], err = s.completes(ctx, userId, op.ItemId, *op.Update); err != nil {
+				results[i].Err = err
+				invalid = true
+				continue
+			}
+			completed[len(ops)] = completing[op.ItemId]
+		}
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+
+	applied := false
+	var publish func()
+	if len(ops) > 0 && !(input.Atomic && invalid) {
+		// deleted and moved items lose their memberships, resolve them first
+		var err error
+		events := make([]todo.Event, len(ops))
+		for j, op := range ops {
+			if op.Op != todo.BulkOpDelete && op.Op != todo.BulkOpMove {
+				continue
+			}
+			eventType := todo.EventItemDeleted
+			if op.Op == todo.BulkOpMove {
+				eventType = todo.EventItemMoved
+			}
+			events[j], err = s.events.Prepare(ctx, newEvent(eventType, userId, 0, op.ItemId, op))
+			if err != nil {
+				return todo.BulkItemsOutput{}, nil, err
+			}
+		}
+
+		opResults, committed, err := s.repo.Bulk(ctx, userId, ops, input.Atomic)
+		if err != nil {
+			return todo.BulkItemsOutput{}, nil, err
+		}
+		for j, res := range opResults {
+			res.Index = indexes[j]
+			if res.Err == nil {
+				res.Warnings = warnings[indexes[j]]
+			}
+			results[indexes[j]] = res
+		}
+		applied = committed
+
+		if applied {
+			publish = func() {
+				s.publishBulkEvents(ctx, userId, ops, opResults, events, completed)
+			}
+		}
+	}
+
+	for i := range results {
+		fillBulkStatus(&results[i], applied)
+	}
+
+	return todo.BulkItemsOutput{Applied: applied, Results: results}, publish, nil
+}
+
+// guardDependencies runs fn with the service bound to a transaction holding
+// the dependency lock when lock is set, so that no other change can get
+// between checking the blockers and writing them.
+func (s *TodoItemService) guardDependencies(ctx context.Context, lock bool, fn func(s *TodoItemService) error) error {
+	if !lock {
+		return fn(s)
+	}
+
+	return s.transactor.InDependencyTransaction(ctx, func(items repository.TodoItem, dependencies repository.Dependency) error {
+		tx := *s
+		tx.repo = items
+		tx.dependencyRepo = dependencies
+		return fn(&tx)
+	})
 }
 
-func (s *TodoItemService) GetAll(userId, listId int) ([]todo.TodoItem, error) {
-	return s.repo.GetAll(userId, listId)
+// changesDependencies tells whether the update sets blockers or completes
+// the item, the changes checked against the dependency graph.
+func changesDependencies(input todo.UpdateItemInput) bool {
+	return input.BlockedBy != nil || (input.Done != nil && *input.Done)
 }
 
-func (s *TodoItemService) GetById(userId, itemId int) (todo.TodoItem, error) {
-	return s.repo.GetById(userId, itemId)
+func bulkChangesDependencies(op todo.BulkItemOperation) bool {
+	switch op.Op {
+	case todo.BulkOpCreate:
+		return op.Item != nil && op.Item.Done && len(op.Item.BlockedBy) > 0
+	case todo.BulkOpUpdate:
+		return op.Update != nil && changesDependencies(*op.Update)
+	}
+	return false
+}
+
+// initialStatus puts new items of lists with a workflow in the status they
+// name, or the one matching done.
+func (s *TodoItemService) initialStatus(ctx context.Context, listId int, item todo.TodoItem) (todo.TodoItem, error) {
+	workflow, err := s.workflowRepo.GetStatuses(ctx, listId)
+	if err != nil {
+		return item, err
+	}
+	if len(workflow) == 0 {
+		if item.Status != "" {
+			return item, &todo.ErrNoSuchStatus{Name: item.Status}
+		}
+		return item, nil
+	}
+
+	status := workflow.Current(todo.TodoItem{Done: item.Done})
+	if item.Status != "" {
+		var ok bool
+		if status, ok = workflow.Find(item.Status); !ok {
+			return item, &todo.ErrNoSuchStatus{Name: item.Status}
+		}
+	}
+	item.Status = status.Name
+	item.Done = status.Terminal
+	return item, nil
+}
+
+// moveItem validates the transition when the status or done changes, and
+// sets both so that they agree.
+func (s *TodoItemService) moveItem(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) (todo.UpdateItemInput, error) {
+	if input.Status == nil && input.Done == nil {
+		return input, nil
+	}
+
+	item, err := s.repo.GetById(ctx, userId, itemId)
+	if err != nil {
+		return input, err
+	}
+	workflow, err := s.workflowRepo.GetItemStatuses(ctx, itemId)
+	if err != nil {
+		return input, err
+	}
+	if len(workflow) == 0 {
+		if input.Status != nil {
+			return input, &todo.ErrNoSuchStatus{Name: *input.Status}
+		}
+		return input, nil
+	}
+
+	name := ""
+	if input.Status != nil {
+		name = *input.Status
+	}
+	status, err := workflow.Move(item, name, input.Done)
+	if err != nil {
+		return input, err
+	}
+	input.Status = &status.Name
+	input.Done = &status.Terminal
+	return input, nil
 }
 
-func (s *TodoItemService) Delete(userId, itemId int) error {
-	return s.repo.Delete(userId, itemId)
+func (s *TodoItemService) bulkStatus(ctx context.Context, userId int, op todo.BulkItemOperation) (todo.BulkItemOperation, error) {
+	switch op.Op {
+	case todo.BulkOpCreate:
+		if _, err := s.listRepo.GetById(ctx, userId, op.ListId); err != nil {
+			return op, err
+		}
+		item, err := s.initialStatus(ctx, op.ListId, *op.Item)
+		op.Item = &item
+		return op, err
+	case todo.BulkOpUpdate:
+		update, err := s.moveItem(ctx, userId, op.ItemId, *op.Update)
+		op.Update = &update
+		return op, err
+	}
+	return op, nil
 }
 
-func (s *TodoItemService) Update(userId, itemId int, input todo.UpdateItemInput) error {
-	return s.repo.Update(userId, itemId, input)
+func (s *TodoItemService) bulkBlockers(ctx context.Context, userId int, op todo.BulkItemOperation,
+	pending map[int]todo.ItemIds) ([]todo.Warning, error) {
+	switch op.Op {
+	case todo.BulkOpCreate:
+		list, err := s.listRepo.GetById(ctx, userId, op.ListId)
+		if err != nil {
+			return nil, err
+		}
+		return s.checkNewBlockers(ctx, userId, list, *op.Item)
+	case todo.BulkOpUpdate:
+		warnings, err := s.checkBlockers(ctx, userId, op.ItemId, *op.Update, pending)
+		if err == nil && op.Update.BlockedBy != nil {
+			pending[op.ItemId] = *op.Update.BlockedBy
+		}
+		return warnings, err
+	}
+	return nil, nil
+}
+
+// checkNewBlockers checks the blockers of an item about to be created in
+// the list. New items can't be part of a cycle yet.
+func (s *TodoItemService) checkNewBlockers(ctx context.Context, userId int, list todo.TodoList, item todo.TodoItem) ([]todo.Warning, error) {
+	if err := s.checkBlockerAccess(ctx, userId, 0, item.BlockedBy); err != nil {
+		return nil, err
+	}
+	if !item.Done || len(item.BlockedBy) == 0 {
+		return nil, nil
+	}
+	return s.checkOpenBlockers(ctx, list.BlockerPolicy, item.BlockedBy)
+}
+
+// checkBlockers validates new blockers of the item and whether it can be
+// done with the blockers it ends up with.
+func (s *TodoItemService) checkBlockers(ctx context.Context, userId, itemId int, input todo.UpdateItemInput,
+	pending map[int]todo.ItemIds) ([]todo.Warning, error) {
+	if input.BlockedBy != nil {
+		if err := s.checkBlockerAccess(ctx, userId, itemId, *input.BlockedBy); err != nil {
+			return nil, err
+		}
+		if err := s.checkCycle(ctx, userId, itemId, *input.BlockedBy, pending); err != nil {
+			return nil, err
+		}
+	}
+	if input.Done == nil || !*input.Done {
+		return nil, nil
+	}
+
+	// clients like CalDAV send done with every update, only completing an
+	// item is checked
+	item, err := s.repo.GetById(ctx, userId, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if item.Done {
+		return nil, nil
+	}
+	blockers := item.BlockedBy
+	if input.BlockedBy != nil {
+		blockers = *input.BlockedBy
+	}
+	if len(blockers) == 0 {
+		return nil, nil
+	}
+
+	policy, err := s.dependencyRepo.GetBlockerPolicy(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkOpenBlockers(ctx, policy, blockers)
+}
+
+// checkOpenBlockers rejects completing an item with open blockers, or warns
+// about it when the policy says so.
+func (s *TodoItemService) checkOpenBlockers(ctx context.Context, policy string, blockers todo.ItemIds) ([]todo.Warning, error) {
+	open, err := s.dependencyRepo.GetOpen(ctx, blockers)
+	if err != nil || len(open) == 0 {
+		return nil, err
+	}
+
+	blocked := &todo.ErrItemBlocked{Blockers: open}
+	if policy != todo.BlockerPolicyWarn {
+		return nil, blocked
+	}
+	return []todo.Warning{{Code: "blocked", Message: blocked.Error()}}, nil
+}
+
+// checkBlockerAccess makes sure the user can access the blockers, which may
+// be in any of their lists.
+func (s *TodoItemService) checkBlockerAccess(ctx context.Context, userId, itemId int, blockers todo.ItemIds) error {
+	if err := blockers.Validate(); err != nil {
+		return &todo.ErrInvalidDependency{Reason: err.Error()}
+	}
+	for _, blocker := range blockers {
+		if blocker == itemId {
+			return &todo.ErrInvalidDependency{Reason: "an item can't block itself"}
+		}
+		if _, err := s.repo.GetById(ctx, userId, blocker); err != nil {
+			if _, ok := err.(*todo.ErrNoSuchItem); ok {
+				return &todo.ErrInvalidDependency{Reason: fmt.Sprintf("no item %d", blocker)}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCycle walks the blockers of the blockers breadth first and fails
+// when it gets back to the item. Pending blockers replace the stored ones
+// of their items.
+func (s *TodoItemService) checkCycle(ctx context.Context, userId, itemId int, blockers todo.ItemIds, pending map[int]todo.ItemIds) error {
+	// next leads from every item reached back towards the item
+	next := make(map[int]int)
+	frontier := make([]int, 0, len(blockers))
+	for _, blocker := range blockers {
+		next[blocker] = itemId
+		frontier = append(frontier, blocker)
+	}
+
+	for len(frontier) > 0 {
+		edges := make([]todo.ItemDependency, 0)
+		stored := make([]int, 0, len(frontier))
+		for _, id := range frontier {
+			if ids, ok := pending[id]; ok {
+				for _, blocker := range ids {
+					edges = append(edges, todo.ItemDependency{ItemId: id, BlockerId: blocker})
+				}
+			} else {
+				stored = append(stored, id)
+			}
+		}
+		if len(stored) > 0 {
+			dependencies, err := s.dependencyRepo.GetBlockers(ctx, stored)
+			if err != nil {
+				return err
+			}
+			edges = append(edges, dependencies...)
+		}
+
+		frontier = frontier[:0]
+		for _, edge := range edges {
+			if edge.BlockerId == itemId {
+				path := make([]int, 0)
+				for id := edge.ItemId; id != itemId; id = next[id] {
+					path = append(path, id)
+				}
+				cycle := []int{itemId}
+				for i := len(path) - 1; i >= 0; i-- {
+					cycle = append(cycle, path[i])
+				}
+				cycle, err := s.visibleItems(ctx, userId, append(cycle, itemId))
+				if err != nil {
+					return err
+				}
+				return &todo.ErrDependencyCycle{Cycle: cycle}
+			}
+			if _, seen := next[edge.BlockerId]; !seen {
+				next[edge.BlockerId] = edge.ItemId
+				frontier = append(frontier, edge.BlockerId)
+			}
+		}
+	}
+	return nil
+}
+
+// visibleItems leaves out the items the user can't access, the graph goes
+// through other users' lists.
+func (s *TodoItemService) visibleItems(ctx context.Context, userId int, ids []int) ([]int, error) {
+	visible := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, err := s.repo.GetById(ctx, userId, id); err != nil {
+			if _, ok := err.(*todo.ErrNoSuchItem); ok {
+				continue
+			}
+			return nil, err
+		}
+		visible = append(visible, id)
+	}
+	return visible, nil
+}
+
+func (s *TodoItemService) publishBulkEvents(ctx context.Context, userId int, ops []todo.BulkItemOperation,
+	results []todo.BulkItemResult, prepared []todo.Event, completed []bool) {
+	for j, op := range ops {
+		if results[j].Err != nil {
+			continue
+		}
+
+		switch op.Op {
+		case todo.BulkOpCreate:
+			item := *op.Item
+			item.Id = results[j].Id
+			publishEvent(ctx, s.events, newEvent(todo.EventItemCreated, userId, op.ListId, item.Id, item))
+		case todo.BulkOpUpdate:
+			event := newEvent(todo.EventItemUpdated, userId, 0, op.ItemId, op.Update)
+			event.Completed = completed[j]
+			publishEvent(ctx, s.events, event)
+		case todo.BulkOpDelete:
+			publishEvent(ctx, s.events, prepared[j])
+		case todo.BulkOpMove:
+			// members of both the source and the target list have to know
+			event := prepared[j]
+			target, err := s.events.Prepare(ctx, newEvent(todo.EventItemMoved, userId, op.ListId, 0, nil))
+			if err != nil {
+				logging.FromContext(ctx).Error("Failed to prepare event", "error", err)
+				continue
+			}
+			for _, id := range target.Recipients {
+				if !event.IsRecipient(id) {
+					event.Recipients = append(event.Recipients, id)
+				}
+			}
+			event.ListId = op.ListId
+			publishEvent(ctx, s.events, event)
+		}
+	}
+}
+
+func fillBulkStatus(res *todo.BulkItemResult, applied bool) {
+	if res.Err == nil {
+		if applied {
+			res.Status = todo.BulkStatusOk
+		} else {
+			res.Status = todo.BulkStatusRolledBack
+			res.Id = 0
+		}
+		return
+	}
+
+	res.Status = todo.BulkStatusError
+	res.Message = res.Err.Error()
+	switch res.Err.(type) {
+	case *todo.ErrNoSuchList:
+		res.Code = "no_such_list"
+	case *todo.ErrNoSuchItem:
+		res.Code = "no_such_item"
+	case *todo.ErrInvalidUpdateItemInput:
+		res.Code = "invalid_update_input"
+	case *todo.ErrInvalidBulkOperation:
+		res.Code = "invalid_operation"
+	case *todo.ErrNoSuchStatus:
+		res.Code = "no_such_status"
+	case *todo.ErrInvalidTransition:
+		res.Code = "invalid_transition"
+	case *todo.ErrInvalidDependency:
+		res.Code = "invalid_dependency"
+	case *todo.ErrDependencyCycle:
+		res.Code = "dependency_cycle"
+	case *todo.ErrItemBlocked:
+		res.Code = "blocked"
+	default:
+		res.Code = "internal_error"
+		res.Message = "Internal error"
+	}
 }
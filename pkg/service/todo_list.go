@@ -1,37 +1,80 @@
 package service
 
 import (
+	"context"
+
 	"github.com/OrIX219/todo/pkg"
 	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
 )
 
 type TodoListService struct {
-	repo repository.TodoList
+	repo   repository.TodoList
+	events Events
 }
 
-func NewTodoListService(repo repository.TodoList) *TodoListService {
-	return &TodoListService{repo: repo}
+func NewTodoListService(repo repository.TodoList, events Events) *TodoListService {
+	return &TodoListService{repo: repo, events: events}
 }
 
-func (s *TodoListService) Create(userId int, list todo.TodoList) (int, error) {
-	return s.repo.Create(userId, list)
+func (s *TodoListService) Create(ctx context.Context, userId int, list todo.TodoList) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.Create", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	id, err := s.repo.Create(ctx, userId, list)
+	if err != nil {
+		return id, err
+	}
+
+	list.Id = id
+	publishEvent(ctx, s.events, newEvent(todo.EventListCreated, userId, id, 0, list))
+	return id, nil
 }
 
-func (s *TodoListService) GetAll(userId int) ([]todo.TodoList, error) {
-	return s.repo.GetAll(userId)
+func (s *TodoListService) GetAll(ctx context.Context, userId int) (_ []todo.TodoList, err error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.GetAll", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetAll(ctx, userId)
 }
 
-func (s *TodoListService) GetById(userId, listId int) (todo.TodoList, error) {
-	return s.repo.GetById(userId, listId)
+func (s *TodoListService) GetById(ctx context.Context, userId, listId int) (_ todo.TodoList, err error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.GetById", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	return s.repo.GetById(ctx, userId, listId)
 }
 
-func (s *TodoListService) Delete(userId, listId int) error {
-	return s.repo.Delete(userId, listId)
+func (s *TodoListService) Delete(ctx context.Context, userId, listId int) (err error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.Delete", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	event, err := s.events.Prepare(ctx, newEvent(todo.EventListDeleted, userId, listId, 0, nil))
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, userId, listId); err != nil {
+		return err
+	}
+
+	if event.IsRecipient(userId) {
+		publishEvent(ctx, s.events, event)
+	}
+	return nil
 }
 
-func (s *TodoListService) Update(userId, listId int, input todo.UpdateListInput) error {
+func (s *TodoListService) Update(ctx context.Context, userId, listId int, input todo.UpdateListInput) (err error) {
+	ctx, span := tracing.Start(ctx, "TodoListService.Update", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
 	if err := input.Validate(); err != nil {
 		return err
 	}
-	return s.repo.Update(userId, listId, input)
+	if err := s.repo.Update(ctx, userId, listId, input); err != nil {
+		return err
+	}
+
+	publishEvent(ctx, s.events, newEvent(todo.EventListUpdated, userId, listId, 0, input))
+	return nil
 }
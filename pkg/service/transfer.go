@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+	"github.com/OrIX219/todo/pkg/transfer"
+)
+
+type TransferService struct {
+	listRepo   repository.TodoList
+	itemRepo   repository.TodoItem
+	transactor repository.Transactor
+}
+
+func NewTransferService(listRepo repository.TodoList, itemRepo repository.TodoItem,
+	transactor repository.Transactor) *TransferService {
+	return &TransferService{listRepo: listRepo, itemRepo: itemRepo, transactor: transactor}
+}
+
+// Export writes all lists of the user to w list by list.
+func (s *TransferService) Export(ctx context.Context, userId int, format string, w io.Writer) (err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.Export", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	enc, err := transfer.NewEncoder(w, format)
+	if err != nil {
+		return err
+	}
+
+	lists, err := s.listRepo.GetAll(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, list := range lists {
+		items, err := s.itemRepo.GetAll(ctx, userId, list.Id)
+		if err != nil {
+			return err
+		}
+		if err := enc.WriteList(todo.ExportedList{TodoList: list, Items: items}); err != nil {
+			return err
+		}
+	}
+
+	return enc.Close()
+}
+
+// importPlan is what an import does with one list.
+type importPlan struct {
+	report todo.ImportListReport
+	list   todo.TodoList
+	items  []todo.TodoItem
+}
+
+// Import creates the lists and items read from r in a single transaction, or
+// only reports what would be created on a dry run. Export files of other
+// tools are mapped first, and the report tells how.
+func (s *TransferService) Import(ctx context.Context, userId int, r io.Reader, options todo.ImportOptions) (_ todo.ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "TransferService.Import", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	if options.OnDuplicate == "" {
+		options.OnDuplicate = todo.OnDuplicateCreate
+	}
+	switch options.OnDuplicate {
+	case todo.OnDuplicateCreate, todo.OnDuplicateMerge, todo.OnDuplicateSkip:
+	default:
+		return todo.ImportReport{}, &todo.ErrInvalidImport{Reason: "unknown on_duplicate " + options.OnDuplicate}
+	}
+
+	var lists []todo.ExportedList
+	var mapping []todo.ImportMapping
+	if options.Source != "" {
+		lists, mapping, err = transfer.DecodeSource(r, options.Source, options.FileName)
+	} else {
+		lists, err = transfer.Decode(r, options.Format)
+	}
+	if err != nil {
+		return todo.ImportReport{}, err
+	}
+	if err := validateImport(lists); err != nil {
+		return todo.ImportReport{}, err
+	}
+
+	plans, err := s.planImport(ctx, userId, lists, options.OnDuplicate)
+	if err != nil {
+		return todo.ImportReport{}, err
+	}
+
+	if !options.DryRun {
+		err = s.transactor.InTransaction(ctx, func(lists repository.TodoList, items repository.TodoItem) error {
+			for i := range plans {
+				if err := applyImport(ctx, userId, &plans[i], lists, items); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return todo.ImportReport{}, err
+		}
+	}
+
+	report := todo.ImportReport{
+		Source:  options.Source,
+		Mapping: mapping,
+		DryRun:  options.DryRun,
+		Lists:   make([]todo.ImportListReport, 0, len(plans)),
+	}
+	for _, plan := range plans {
+		switch plan.report.Action {
+		case todo.ImportActionCreate:
+			report.CreatedLists++
+		case todo.ImportActionSkip:
+			report.SkippedLists++
+		}
+		report.CreatedItems += plan.report.Items
+		report.SkippedItems += plan.report.SkippedItems
+		report.Lists = append(report.Lists, plan.report)
+	}
+	return report, nil
+}
+
+// planImport decides what to do with every list. Unless duplicates are
+// created anyway, lists with the same title are merged within the import as
+// well, and merged items are skipped if the list already has their titles.
+func (s *TransferService) planImport(ctx context.Context, userId int, lists []todo.ExportedList, onDuplicate string) ([]importPlan, error) {
+	existing := make(map[string]int)
+	if onDuplicate != todo.OnDuplicateCreate {
+		userLists, err := s.listRepo.GetAll(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		for _, list := range userLists {
+			if _, ok := existing[list.Title]; !ok {
+				existing[list.Title] = list.Id
+			}
+		}
+	}
+
+	var plans []importPlan
+	planned := make(map[string]int)
+	titles := make(map[int]map[string]bool)
+	for _, list := range lists {
+		i, ok := planned[list.Title]
+		if !ok || onDuplicate == todo.OnDuplicateCreate {
+			plan := importPlan{
+				report: todo.ImportListReport{Title: list.Title, Action: todo.ImportActionCreate},
+				list:   todo.TodoList{Title: list.Title, Description: list.Description},
+			}
+			if id, ok := existing[list.Title]; ok {
+				plan.report.Id = id
+				plan.report.Action = onDuplicate
+			}
+
+			i = len(plans)
+			planned[list.Title] = i
+			plans = append(plans, plan)
+			titles[i] = make(map[string]bool)
+
+			if plan.report.Action == todo.ImportActionMerge {
+				items, err := s.itemRepo.GetAll(ctx, userId, plan.report.Id)
+				if err != nil {
+					return nil, err
+				}
+				for _, item := range items {
+					titles[i][item.Title] = true
+				}
+			}
+		}
+
+		plan := &plans[i]
+		for _, item := range list.Items {
+			dedupe := onDuplicate != todo.OnDuplicateCreate
+			if plan.report.Action == todo.ImportActionSkip || dedupe && titles[i][item.Title] {
+				plan.report.SkippedItems++
+				continue
+			}
+
+			titles[i][item.Title] = true
+			// blockers are ids of the exporting database
+			item.Id = 0
+			item.BlockedBy = nil
+			plan.items = append(plan.items, item)
+			plan.report.Items++
+		}
+	}
+
+	return plans, nil
+}
+
+func applyImport(ctx context.Context, userId int, plan *importPlan, lists repository.TodoList, items repository.TodoItem) error {
+	switch plan.report.Action {
+	case todo.ImportActionSkip:
+		return nil
+	case todo.ImportActionCreate:
+		id, err := lists.Create(ctx, userId, plan.list)
+		if err != nil {
+			return err
+		}
+		plan.report.Id = id
+	}
+
+	for _, item := range plan.items {
+		if _, err := items.Create(ctx, plan.report.Id, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateImport(lists []todo.ExportedList) error {
+	for i, list := range lists {
+		if err := validateImportText(list.Title, list.Description); err != nil {
+			return &todo.ErrInvalidImport{Reason: fmt.Sprintf("list %d: %s", i+1, err)}
+		}
+		if !todo.ValidBlockerPolicy(list.BlockerPolicy) {
+			return &todo.ErrInvalidImport{
+				Reason: fmt.Sprintf("list %d: unknown blocker policy %q", i+1, list.BlockerPolicy),
+			}
+		}
+
+		for j, item := range list.Items {
+			err := validateImportText(item.Title, item.Description)
+			if err == nil && (item.Priority < todo.PriorityNone || item.Priority > todo.PriorityHigh) {
+				err = fmt.Errorf("invalid priority %d", item.Priority)
+			}
+			if err == nil {
+				err = item.Tags.Validate()
+			}
+			if err != nil {
+				return &todo.ErrInvalidImport{Reason: fmt.Sprintf("list %d, item %d: %s", i+1, j+1, err)}
+			}
+		}
+	}
+	return nil
+}
+
+func validateImportText(title, description string) error {
+	switch {
+	case title == "":
+		return fmt.Errorf("title is required")
+	case utf8.RuneCountInString(title) > maxTextLength:
+		return fmt.Errorf("title is longer than %d characters", maxTextLength)
+	case utf8.RuneCountInString(description) > maxTextLength:
+		return fmt.Errorf("description is longer than %d characters", maxTextLength)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/go-playground/assert/v2"
+)
+
+type transferRepo struct {
+	lists []todo.TodoList
+	items map[int][]todo.TodoItem
+	err   error
+}
+
+func (r *transferRepo) Create(ctx context.Context, userId int, list todo.TodoList) (int, error) {
+	list.Id = len(r.lists) + 1
+	r.lists = append(r.lists, list)
+	return list.Id, nil
+}
+
+func (r *transferRepo) GetAll(ctx context.Context, userId int) ([]todo.TodoList, error) {
+	return r.lists, nil
+}
+func (r *transferRepo) Delete(ctx context.Context, userId, listId int) error { return nil }
+func (r *transferRepo) Update(ctx context.Context, userId, listId int, input todo.UpdateListInput) error {
+	return nil
+}
+
+func (r *transferRepo) GetById(ctx context.Context, userId, listId int) (todo.TodoList, error) {
+	return r.lists[listId-1], nil
+}
+
+// transferItemRepo shares the lists of transferRepo, so items can be created
+// in new lists.
+type transferItemRepo struct {
+	repository.TodoItem
+	repo *transferRepo
+}
+
+func (r transferItemRepo) Create(ctx context.Context, listId int, item todo.TodoItem) (int, error) {
+	if r.repo.err != nil {
+		return 0, r.repo.err
+	}
+	r.repo.items[listId] = append(r.repo.items[listId], item)
+	return len(r.repo.items[listId]), nil
+}
+
+func (r transferItemRepo) GetAll(ctx context.Context, userId, listId int) ([]todo.TodoItem, error) {
+	return r.repo.items[listId], nil
+}
+
+type transferTransactor struct {
+	repo *transferRepo
+}
+
+func (t transferTransactor) InTransaction(ctx context.Context, fn func(lists repository.TodoList, items repository.TodoItem) error) error {
+	return fn(t.repo, transferItemRepo{repo: t.repo})
+}
+
+func (t transferTransactor) InDependencyTransaction(ctx context.Context, fn func(items repository.TodoItem, dependencies repository.Dependency) error) error {
+	return fn(transferItemRepo{repo: t.repo}, &dependencyRepo{})
+}
+
+func newTransferService() (*TransferService, *transferRepo) {
+	repo := &transferRepo{
+		lists: []todo.TodoList{{Id: 1, Title: "Home", Description: "Chores"}},
+		items: map[int][]todo.TodoItem{1: {{Id: 1, Title: "Buy milk", Done: true}}},
+	}
+	return NewTransferService(repo, transferItemRepo{repo: repo}, transferTransactor{repo: repo}), repo
+}
+
+func TestTransferService_Export(t *testing.T) {
+	s, _ := newTransferService()
+
+	var buf bytes.Buffer
+	assert.Equal(t, nil, s.Export(context.Background(), 1, todo.FormatMarkdown, &buf))
+	assert.Equal(t, "# Home\n\nChores\n\n- [x] Buy milk\n", buf.String())
+
+	err := s.Export(context.Background(), 1, "xml", &buf)
+	assert.Equal(t, &todo.ErrUnsupportedFormat{Format: "xml"}, err)
+}
+
+func TestTransferService_Import(t *testing.T) {
+	const input = "# Home\n- [ ] Buy milk\n- [ ] Pay rent\n# Work\n- [ ] Report\n# Home\n- [ ] Pay rent\n"
+
+	testTable := []struct {
+		name           string
+		input          string
+		options        todo.ImportOptions
+		expectedReport todo.ImportReport
+		expectedError  error
+		expectedLists  int
+		expectedItems  map[int]int
+	}{
+		{
+			name:    "Create",
+			input:   input,
+			options: todo.ImportOptions{Format: todo.FormatMarkdown},
+			expectedReport: todo.ImportReport{
+				CreatedLists: 3,
+				CreatedItems: 4,
+				Lists: []todo.ImportListReport{
+					{Title: "Home", Id: 2, Action: todo.ImportActionCreate, Items: 2},
+					{Title: "Work", Id: 3, Action: todo.ImportActionCreate, Items: 1},
+					{Title: "Home", Id: 4, Action: todo.ImportActionCreate, Items: 1},
+				},
+			},
+			expectedLists: 4,
+			expectedItems: map[int]int{1: 1, 2: 2, 3: 1, 4: 1},
+		},
+		{
+			name:    "Merge",
+			input:   input,
+			options: todo.ImportOptions{Format: todo.FormatMarkdown, OnDuplicate: todo.OnDuplicateMerge},
+			expectedReport: todo.ImportReport{
+				CreatedLists: 1,
+				CreatedItems: 2,
+				SkippedItems: 2,
+				Lists: []todo.ImportListReport{
+					{Title: "Home", Id: 1, Action: todo.ImportActionMerge, Items: 1, SkippedItems: 2},
+					{Title: "Work", Id: 2, Action: todo.ImportActionCreate, Items: 1},
+				},
+			},
+			expectedLists: 2,
+			expectedItems: map[int]int{1: 2, 2: 1},
+		},
+		{
+			name:    "Skip dry run",
+			input:   input,
+			options: todo.ImportOptions{Format: todo.FormatMarkdown, OnDuplicate: todo.OnDuplicateSkip, DryRun: true},
+			expectedReport: todo.ImportReport{
+				DryRun:       true,
+				CreatedLists: 1,
+				CreatedItems: 1,
+				SkippedLists: 1,
+				SkippedItems: 3,
+				Lists: []todo.ImportListReport{
+					{Title: "Home", Id: 1, Action: todo.ImportActionSkip, SkippedItems: 3},
+					{Title: "Work", Action: todo.ImportActionCreate, Items: 1},
+				},
+			},
+			expectedLists: 1,
+			expectedItems: map[int]int{1: 1},
+		},
+		{
+			name:    "Source",
+			input:   `{"name":"Home","lists":[{"id":"l1","name":"To do"}],"cards":[{"idList":"l1","name":"Pay rent"}]}`,
+			options: todo.ImportOptions{Source: todo.SourceTrello, OnDuplicate: todo.OnDuplicateMerge},
+			expectedReport: todo.ImportReport{
+				Source: todo.SourceTrello,
+				Mapping: []todo.ImportMapping{
+					{From: "board", To: todo.MappingList, Count: 1},
+					{From: "list", To: todo.MappingDesc, Count: 1},
+					{From: "card", To: todo.MappingItem, Count: 1},
+				},
+				CreatedItems: 1,
+				Lists: []todo.ImportListReport{
+					{Title: "Home", Id: 1, Action: todo.ImportActionMerge, Items: 1},
+				},
+			},
+			expectedLists: 1,
+			expectedItems: map[int]int{1: 2},
+		},
+		{
+			name:          "Invalid priority",
+			input:         `[{"title":"Home","items":[{"title":"A","priority":7}]}]`,
+			options:       todo.ImportOptions{Format: todo.FormatJSON},
+			expectedError: &todo.ErrInvalidImport{Reason: "list 1, item 1: invalid priority 7"},
+			expectedLists: 1,
+			expectedItems: map[int]int{1: 1},
+		},
+		{
+			name:          "Missing title",
+			input:         `[{"description":"x"}]`,
+			options:       todo.ImportOptions{Format: todo.FormatJSON},
+			expectedError: &todo.ErrInvalidImport{Reason: "list 1: title is required"},
+			expectedLists: 1,
+			expectedItems: map[int]int{1: 1},
+		},
+		{
+			name:          "Unknown on_duplicate",
+			input:         input,
+			options:       todo.ImportOptions{Format: todo.FormatMarkdown, OnDuplicate: "replace"},
+			expectedError: &todo.ErrInvalidImport{Reason: "unknown on_duplicate replace"},
+			expectedLists: 1,
+			expectedItems: map[int]int{1: 1},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, repo := newTransferService()
+
+			report, err := s.Import(context.Background(), 1, strings.NewReader(testCase.input), testCase.options)
+			assert.Equal(t, testCase.expectedError, err)
+			if testCase.expectedError == nil {
+				assert.Equal(t, testCase.expectedReport, report)
+			}
+
+			assert.Equal(t, testCase.expectedLists, len(repo.lists))
+			items := make(map[int]int)
+			for listId, listItems := range repo.items {
+				items[listId] = len(listItems)
+			}
+			assert.Equal(t, testCase.expectedItems, items)
+		})
+	}
+}
+
+func TestTransferService_Import_Error(t *testing.T) {
+	s, repo := newTransferService()
+	repo.err = errors.New("db is down")
+
+	_, err := s.Import(context.Background(), 1, strings.NewReader("- [ ] Task\n"), todo.ImportOptions{Format: todo.FormatMarkdown})
+	assert.Equal(t, repo.err, err)
+}
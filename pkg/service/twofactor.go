@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/totp"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeSize is the random bytes of a code, 16 base32 characters
+	// shown in two halves.
+	recoveryCodeSize = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorService struct {
+	repo repository.TwoFactor
+	auth Authorization
+}
+
+func NewTwoFactorService(repo repository.TwoFactor, auth Authorization) *TwoFactorService {
+	return &TwoFactorService{repo: repo, auth: auth}
+}
+
+// Enroll starts enrolling the user with a new secret, to be confirmed with a
+// code from the app it was added to. It takes the password, so a stolen
+// token alone can't hand out the secret.
+func (s *TwoFactorService) Enroll(ctx context.Context, userId int, password string) (_ todo.TwoFactorEnrollment, err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Enroll", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	twoFactor, err := s.repo.Get(ctx, userId)
+	if err != nil {
+		return todo.TwoFactorEnrollment{}, err
+	}
+	if twoFactor.Enabled {
+		return todo.TwoFactorEnrollment{}, &todo.ErrTwoFactorEnabled{}
+	}
+	if err := s.auth.Reauthenticate(ctx, userId, password, ""); err != nil {
+		return todo.TwoFactorEnrollment{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return todo.TwoFactorEnrollment{}, err
+	}
+	if err := s.repo.SetPending(ctx, userId, secret); err != nil {
+		return todo.TwoFactorEnrollment{}, err
+	}
+
+	issuer := config.Get("TOTP_ISSUER", "todo-app")
+	return todo.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(issuer, twoFactor.Username, secret),
+	}, nil
+}
+
+// Confirm turns two-factor auth on once the code shows the app has the
+// secret, returning recovery codes for when the app is lost. They're only
+// ever shown here, only their hashes are kept. It takes the password too,
+// like Enroll.
+func (s *TwoFactorService) Confirm(ctx context.Context, userId int, password, code string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Confirm", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	twoFactor, err := s.repo.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, &todo.ErrTwoFactorEnabled{}
+	}
+	if twoFactor.Secret == "" {
+		return nil, &todo.ErrTwoFactorNotEnrolled{}
+	}
+	if err := s.auth.Reauthenticate(ctx, userId, password, ""); err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, &todo.ErrInvalidTwoFactorCode{}
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = code[:8] + "-" + code[8:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.repo.Enable(ctx, userId, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor auth off, which takes the password and a code
+// like signing in, so a stolen token alone can't.
+func (s *TwoFactorService) Disable(ctx context.Context, userId int, password, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Disable", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	twoFactor, err := s.repo.Get(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !twoFactor.Enabled {
+		return &todo.ErrTwoFactorNotEnrolled{}
+	}
+
+	if err := s.auth.Reauthenticate(ctx, userId, password, code); err != nil {
+		return err
+	}
+	return s.repo.Disable(ctx, userId)
+}
+
+// verifyTwoFactorCode accepts a code from the app or a recovery code, each
+// only once.
+func verifyTwoFactorCode(ctx context.Context, repo repository.TwoFactor, twoFactor todo.TwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(twoFactor.Secret, code, time.Now()); ok {
+		return repo.UseStep(ctx, twoFactor.UserId, step)
+	}
+	if len(code) == totp.Digits {
+		return false, nil
+	}
+	return repo.UseRecoveryCode(ctx, twoFactor.UserId, hashRecoveryCode(code))
+}
+
+// hashRecoveryCode hashes the code the way it's typed in, dashes and case
+// aside. The codes are random enough that a plain hash can't be reversed.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/totp"
+	"github.com/go-playground/assert/v2"
+)
+
+func currentCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	auth, logins, repo := newTestTwoFactorAuthService()
+	s := NewTwoFactorService(repo, auth)
+	ctx := context.Background()
+
+	// a token alone can't get the secret
+	_, err := s.Enroll(ctx, 1, "wrong")
+	assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+	assert.Equal(t, logins.failures["alice"].Count, 1)
+	assert.Equal(t, repo.states[1].Secret, "")
+
+	enrollment, err := s.Enroll(ctx, 1, "qwerty")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, repo.states[1].Secret, enrollment.Secret)
+	assert.Equal(t, enrollment.URI, totp.URI("todo-app", "alice", enrollment.Secret))
+
+	_, err = s.Confirm(ctx, 1, "qwerty", "000000")
+	assert.Equal(t, err, &todo.ErrInvalidTwoFactorCode{})
+	assert.Equal(t, repo.states[1].Enabled, false)
+
+	code := currentCode(t, enrollment.Secret, 0)
+	_, err = s.Confirm(ctx, 1, "wrong", code)
+	assert.Equal(t, err, &todo.ErrInvalidCredentials{})
+	assert.Equal(t, repo.states[1].Enabled, false)
+
+	codes, err := s.Confirm(ctx, 1, "qwerty", code)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(codes), recoveryCodeCount)
+	assert.Equal(t, len(codes[0]), 17)
+	assert.Equal(t, repo.states[1].Enabled, true)
+	// only hashes of the recovery codes are kept
+	assert.Equal(t, repo.recovery[codes[0]], false)
+	assert.Equal(t, repo.recovery[hashRecoveryCode(codes[0])], true)
+
+	_, err = s.Enroll(ctx, 1, "qwerty")
+	assert.Equal(t, err, &todo.ErrTwoFactorEnabled{})
+}
+
+func TestTwoFactorService_Confirm_NotEnrolled(t *testing.T) {
+	s := NewTwoFactorService(newTwoFactorRepo(), nil)
+
+	_, err := s.Confirm(context.Background(), 1, "qwerty", "123456")
+	assert.Equal(t, err, &todo.ErrTwoFactorNotEnrolled{})
+}
+
+func TestAuthService_TwoFactorSignIn(t *testing.T) {
+	s, logins, repo := newTestTwoFactorAuthService()
+	twoFactor := NewTwoFactorService(repo, s)
+	ctx := context.Background()
+	client := todo.LoginClient{IP: "192.0.2.1"}
+
+	enrollment, _ := twoFactor.Enroll(ctx, 1, "qwerty")
+	codes, _ := twoFactor.Confirm(ctx, 1, "qwerty", currentCode(t, enrollment.Secret, -1))
+
+	_, err := s.GenerateToken(ctx, "alice", "qwerty", client)
+	required, ok := err.(*todo.ErrTwoFactorRequired)
+	assert.Equal(t, ok, true)
+	assert.NotEqual(t, required.Challenge, "")
+	assert.Equal(t, len(logins.sessions), 0)
+
+	// the challenge isn't a token
+	_, err = s.ParseToken(ctx, required.Challenge)
+	assert.NotEqual(t, err, nil)
+
+	_, err = s.VerifyTwoFactor(ctx, required.Challenge, "000000", client)
+	assert.Equal(t, err, &todo.ErrInvalidTwoFactorCode{})
+	assert.Equal(t, logins.failures["alice"].Count, 1)
+
+	code := currentCode(t, enrollment.Secret, 0)
+	token, err := s.VerifyTwoFactor(ctx, required.Challenge, code, client)
+	assert.Equal(t, err, nil)
+	userId, err := s.ParseToken(ctx, token)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, userId, 1)
+	assert.Equal(t, len(logins.sessions), 1)
+	assert.Equal(t, logins.failures["alice"].Count, 0)
+
+	// codes are single use
+	_, err = s.VerifyTwoFactor(ctx, required.Challenge, code, client)
+	assert.Equal(t, err, &todo.ErrInvalidTwoFactorCode{})
+
+	// recovery codes work once, typed however
+	_, err = s.VerifyTwoFactor(ctx, required.Challenge, " "+codes[1]+" ", client)
+	assert.Equal(t, err, nil)
+	_, err = s.VerifyTwoFactor(ctx, required.Challenge, codes[1], client)
+	assert.Equal(t, err, &todo.ErrInvalidTwoFactorCode{})
+
+	_, err = s.VerifyTwoFactor(ctx, token, code, client)
+	assert.Equal(t, err, &todo.ErrInvalidChallenge{})
+
+	// basic auth can't answer a challenge
+	_, err = s.Authenticate(ctx, "alice", "qwerty")
+	assert.Equal(t, err, &todo.ErrTwoFactorRequired{})
+
+	assert.Equal(t, twoFactor.Disable(ctx, 1, "qwerty", codes[2]), nil)
+	_, err = s.Authenticate(ctx, "alice", "qwerty")
+	assert.Equal(t, err, nil)
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	auth, logins, repo := newTestTwoFactorAuthService()
+	s := NewTwoFactorService(repo, auth)
+	ctx := context.Background()
+
+	assert.Equal(t, s.Disable(ctx, 1, "qwerty", "123456"), &todo.ErrTwoFactorNotEnrolled{})
+
+	enrollment, _ := s.Enroll(ctx, 1, "qwerty")
+	s.Confirm(ctx, 1, "qwerty", currentCode(t, enrollment.Secret, -1))
+	code := currentCode(t, enrollment.Secret, 0)
+
+	// a token alone isn't enough, and wrong guesses count as failed sign-ins
+	assert.Equal(t, s.Disable(ctx, 1, "wrong", code), &todo.ErrInvalidCredentials{})
+	assert.Equal(t, logins.failures["alice"].Count, 1)
+	assert.Equal(t, s.Disable(ctx, 1, "qwerty", "000000"), &todo.ErrInvalidTwoFactorCode{})
+	assert.Equal(t, logins.failures["alice"].Count, 2)
+	assert.Equal(t, repo.states[1].Enabled, true)
+
+	logins.Lock(ctx, "alice", time.Now().Add(time.Minute))
+	_, locked := s.Disable(ctx, 1, "qwerty", code).(*todo.ErrAccountLocked)
+	assert.Equal(t, locked, true)
+
+	logins.Lock(ctx, "alice", time.Now())
+	assert.Equal(t, s.Disable(ctx, 1, "qwerty", code), nil)
+	assert.Equal(t, repo.states[1].Enabled, false)
+	assert.Equal(t, logins.failures["alice"].Count, 0)
+}
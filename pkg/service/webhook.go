@@ -0,0 +1,416 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/config"
+	"github.com/OrIX219/todo/pkg/logging"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+const (
+	WebhookSignatureHeader = "X-Todo-Signature"
+	WebhookEventHeader     = "X-Todo-Event"
+	WebhookDeliveryHeader  = "X-Todo-Delivery"
+
+	webhookTimeout       = 10 * time.Second
+	webhookPollInterval  = 5 * time.Second
+	webhookClaimBatch    = 20
+	webhookMaxAttempts   = 8
+	webhookBaseBackoff   = 30 * time.Second
+	webhookMaxBackoff    = time.Hour
+	webhookDisableAfter  = 20
+	webhookMaxDeliveries = 100
+
+	// webhookClaimLease covers a whole batch sent one after another, so a
+	// slow endpoint can't let another worker claim the same deliveries.
+	webhookClaimLease = webhookClaimBatch*webhookTimeout + time.Minute
+
+	// webhookResponseDrainMax is read from responses so connections can be
+	// reused, the rest is dropped.
+	webhookResponseDrainMax = 4096
+)
+
+type webhookPayload struct {
+	Type      string     `json:"type"`
+	CreatedAt time.Time  `json:"created_at"`
+	Event     todo.Event `json:"event"`
+}
+
+type WebhookService struct {
+	repo   repository.Webhook
+	client *http.Client
+}
+
+func NewWebhookService(repo repository.Webhook) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		client: newWebhookClient(webhookAllowPrivate()),
+	}
+}
+
+// webhookAllowPrivate lets webhooks reach internal addresses when
+// WEBHOOK_ALLOW_PRIVATE is set, for installs where every user is trusted.
+func webhookAllowPrivate() bool {
+	value := config.Get("WEBHOOK_ALLOW_PRIVATE", "")
+	if value == "" {
+		return false
+	}
+
+	allow, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Invalid WEBHOOK_ALLOW_PRIVATE, using the default", "value", value, "default", false)
+		return false
+	}
+	return allow
+}
+
+// newWebhookClient checks the address of every connection it makes, after
+// DNS resolved it and for redirects too, so a webhook can't be pointed at
+// the internal network, not even by changing its DNS records later.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("Address %s is not allowed for webhooks", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			// a proxy would make the connection, not us
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   webhookTimeout,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// nonPublicPrefixes are the ranges IsGlobalUnicast lets through that still
+// don't lead to the internet.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *WebhookService) Create(ctx context.Context, userId int, input todo.WebhookInput) (_ todo.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Create", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return todo.Webhook{}, err
+	}
+
+	webhook := todo.Webhook{
+		UserId: userId,
+		URL:    input.URL,
+		Events: input.Events,
+		Secret: input.Secret,
+		Active: true,
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return todo.Webhook{}, err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	id, err := s.repo.Create(ctx, userId, webhook)
+	if err != nil {
+		return todo.Webhook{}, err
+	}
+	webhook.Id = id
+
+	// the secret is only ever shown once
+	return webhook, nil
+}
+
+func (s *WebhookService) GetAll(ctx context.Context, userId int) (_ []todo.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetAll", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	webhooks, err := s.repo.GetAll(ctx, userId)
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, err
+}
+
+func (s *WebhookService) GetById(ctx context.Context, userId, webhookId int) (_ todo.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetById", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	webhook, err := s.repo.GetById(ctx, userId, webhookId)
+	webhook.Secret = ""
+	return webhook, err
+}
+
+func (s *WebhookService) Update(ctx context.Context, userId, webhookId int, input todo.UpdateWebhookInput) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Update", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, userId, webhookId, input)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userId, webhookId int) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Delete", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	return s.repo.Delete(ctx, userId, webhookId)
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, userId, webhookId int) (_ []todo.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.GetDeliveries", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.repo.GetById(ctx, userId, webhookId); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDeliveries(ctx, userId, webhookId, webhookMaxDeliveries)
+}
+
+// SendTest delivers a ping event right away and returns the logged result.
+// Test deliveries don't count towards disabling the webhook.
+func (s *WebhookService) SendTest(ctx context.Context, userId, webhookId int) (_ todo.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.SendTest", tracing.UserId(userId))
+	defer tracing.End(span, &err)
+
+	webhook, err := s.repo.GetById(ctx, userId, webhookId)
+	if err != nil {
+		return todo.WebhookDelivery{}, err
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		Type:      todo.WebhookEventPing,
+		CreatedAt: time.Now().UTC(),
+		Event:     todo.Event{Type: todo.WebhookEventPing, ActorId: userId},
+	})
+	if err != nil {
+		return todo.WebhookDelivery{}, err
+	}
+
+	delivery := todo.WebhookDelivery{
+		WebhookId:     webhook.Id,
+		EventType:     todo.WebhookEventPing,
+		Payload:       string(payload),
+		Status:        todo.DeliveryPending,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+		URL:           webhook.URL,
+		Secret:        webhook.Secret,
+	}
+	delivery.Id, err = s.repo.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return todo.WebhookDelivery{}, err
+	}
+
+	s.attempt(&delivery)
+	if delivery.Status == todo.DeliveryPending {
+		// no retries for test events
+		delivery.Status = todo.DeliveryFailed
+	}
+
+	return delivery, s.repo.UpdateDelivery(ctx, delivery)
+}
+
+// HandleEvent queues a delivery for every active webhook of the event's
+// recipients that subscribed to its type.
+func (s *WebhookService) HandleEvent(ctx context.Context, event todo.Event) {
+	ctx, span := tracing.Start(ctx, "WebhookService.HandleEvent")
+	defer span.End()
+
+	webhooks, err := s.repo.GetActive(ctx, event.Recipients)
+	if err != nil {
+		tracing.Fail(span, err)
+		logging.FromContext(ctx).Error("Failed to queue webhook delivery", "error", err)
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(webhookPayload{
+				Type:      event.Type,
+				CreatedAt: time.Now().UTC(),
+				Event:     event,
+			})
+			if err != nil {
+				tracing.Fail(span, err)
+				logging.FromContext(ctx).Error("Failed to queue webhook delivery", "error", err)
+				return
+			}
+		}
+
+		_, err := s.repo.CreateDelivery(ctx, todo.WebhookDelivery{
+			WebhookId:     webhook.Id,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        todo.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+		if err != nil {
+			tracing.Fail(span, err)
+			logging.FromContext(ctx).Error("Failed to queue webhook delivery", "error", err)
+		}
+	}
+}
+
+// Run delivers queued events until ctx is done.
+func (s *WebhookService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.deliverDue(ctx); err != nil {
+				logging.FromContext(ctx).Error("Failed to deliver webhooks", "error", err)
+			}
+		}
+	}
+}
+
+func (s *WebhookService) deliverDue(ctx context.Context) error {
+	deliveries, err := s.repo.ClaimDeliveries(ctx, webhookClaimBatch, webhookClaimLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		s.attempt(delivery)
+
+		if err := s.repo.UpdateDelivery(ctx, *delivery); err != nil {
+			return err
+		}
+		err := s.repo.RecordResult(ctx, delivery.WebhookId,
+			delivery.Status == todo.DeliverySucceeded, webhookDisableAfter)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt sends the delivery once and schedules the next attempt on failure.
+func (s *WebhookService) attempt(delivery *todo.WebhookDelivery) {
+	delivery.Attempts++
+
+	status, err := s.send(*delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		now := time.Now()
+		delivery.Status = todo.DeliverySucceeded
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = todo.DeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+}
+
+func (s *WebhookService) send(delivery todo.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL,
+		bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(delivery.Id))
+	req.Header.Set(WebhookSignatureHeader,
+		SignWebhookPayload(delivery.Secret, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// the body isn't kept, it could be anything the receiver answers with
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseDrainMax))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("Unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the signature header value receivers should
+// compare against: "sha256=" followed by the hex HMAC of the raw body.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+type webhookRepo struct {
+	webhook    todo.Webhook
+	deliveries []todo.WebhookDelivery
+	results    []bool
+}
+
+func (r *webhookRepo) Create(ctx context.Context, userId int, webhook todo.Webhook) (int, error) {
+	return 1, nil
+}
+func (r *webhookRepo) GetAll(ctx context.Context, userId int) ([]todo.Webhook, error) {
+	return nil, nil
+}
+func (r *webhookRepo) Delete(ctx context.Context, userId, webhookId int) error { return nil }
+func (r *webhookRepo) Update(ctx context.Context, userId, webhookId int, input todo.UpdateWebhookInput) error {
+	return nil
+}
+func (r *webhookRepo) GetDeliveries(ctx context.Context, userId, webhookId, limit int) ([]todo.WebhookDelivery, error) {
+	return r.deliveries, nil
+}
+
+func (r *webhookRepo) GetById(ctx context.Context, userId, webhookId int) (todo.Webhook, error) {
+	if r.webhook.Id != webhookId || r.webhook.UserId != userId {
+		return todo.Webhook{}, &todo.ErrNoSuchWebhook{}
+	}
+	return r.webhook, nil
+}
+
+func (r *webhookRepo) GetActive(ctx context.Context, userIds []int) ([]todo.Webhook, error) {
+	return []todo.Webhook{r.webhook}, nil
+}
+
+func (r *webhookRepo) RecordResult(ctx context.Context, webhookId int, success bool, disableAfter int) error {
+	r.results = append(r.results, success)
+	return nil
+}
+
+func (r *webhookRepo) CreateDelivery(ctx context.Context, delivery todo.WebhookDelivery) (int, error) {
+	delivery.Id = len(r.deliveries) + 1
+	r.deliveries = append(r.deliveries, delivery)
+	return delivery.Id, nil
+}
+
+func (r *webhookRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]todo.WebhookDelivery, error) {
+	claimed := make([]todo.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == todo.DeliveryPending {
+			delivery.URL = r.webhook.URL
+			delivery.Secret = r.webhook.Secret
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *webhookRepo) UpdateDelivery(ctx context.Context, delivery todo.WebhookDelivery) error {
+	r.deliveries[delivery.Id-1] = delivery
+	return nil
+}
+
+func TestWebhookService_SendTest(t *testing.T) {
+	var signature, body string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := &webhookRepo{webhook: todo.Webhook{Id: 1, UserId: 1, URL: receiver.URL,
+		Secret: "secret", Events: []string{"*"}, Active: true}}
+	s := NewWebhookService(repo)
+	s.client = newWebhookClient(true)
+
+	delivery, err := s.SendTest(context.Background(), 1, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, todo.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Equal(t, SignWebhookPayload("secret", []byte(body)), signature)
+	assert.Equal(t, 0, len(repo.results))
+
+	_, err = s.SendTest(context.Background(), 2, 1)
+	assert.Equal(t, &todo.ErrNoSuchWebhook{}, err)
+}
+
+func TestWebhookService_retries(t *testing.T) {
+	status := http.StatusInternalServerError
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	repo := &webhookRepo{webhook: todo.Webhook{Id: 1, UserId: 1, URL: receiver.URL,
+		Secret: "secret", Events: []string{"item.*"}, Active: true}}
+	s := NewWebhookService(repo)
+	s.client = newWebhookClient(true)
+
+	s.HandleEvent(context.Background(), todo.Event{Id: 1, Type: todo.EventListCreated, Recipients: []int{1}})
+	assert.Equal(t, 0, len(repo.deliveries))
+
+	s.HandleEvent(context.Background(), todo.Event{Id: 2, Type: todo.EventItemCreated, Recipients: []int{1}})
+	assert.Equal(t, 1, len(repo.deliveries))
+
+	assert.Equal(t, nil, s.deliverDue(context.Background()))
+	delivery := repo.deliveries[0]
+	assert.Equal(t, todo.DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Equal(t, true, delivery.NextAttemptAt.After(time.Now()))
+
+	status = http.StatusOK
+	assert.Equal(t, nil, s.deliverDue(context.Background()))
+	assert.Equal(t, todo.DeliverySucceeded, repo.deliveries[0].Status)
+	assert.Equal(t, []bool{false, true}, repo.results)
+}
+
+func TestWebhookService_privateAddress(t *testing.T) {
+	var called bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	repo := &webhookRepo{webhook: todo.Webhook{Id: 1, UserId: 1, URL: receiver.URL,
+		Secret: "secret", Events: []string{"*"}, Active: true}}
+	s := NewWebhookService(repo)
+	s.client = newWebhookClient(false)
+
+	delivery, err := s.SendTest(context.Background(), 1, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, todo.DeliveryFailed, delivery.Status)
+	assert.Equal(t, true, strings.Contains(delivery.Error, "Address 127.0.0.1 is not allowed for webhooks"))
+	assert.Equal(t, false, called)
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	testTable := []struct {
+		addr     string
+		expected bool
+	}{
+		{addr: "93.184.216.34", expected: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{addr: "127.0.0.1", expected: false},
+		{addr: "::1", expected: false},
+		{addr: "10.1.2.3", expected: false},
+		{addr: "172.16.0.1", expected: false},
+		{addr: "192.168.1.1", expected: false},
+		{addr: "169.254.169.254", expected: false},
+		{addr: "100.64.0.1", expected: false},
+		{addr: "0.0.0.0", expected: false},
+		{addr: "fd00::1", expected: false},
+		{addr: "fe80::1", expected: false},
+		{addr: "::ffff:10.0.0.1", expected: false},
+		{addr: "64:ff9b::a00:1", expected: false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.addr, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, isPublicAddr(netip.MustParseAddr(testCase.addr)))
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	testTable := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+
+	for _, testCase := range testTable {
+		assert.Equal(t, testCase.expected, webhookBackoff(testCase.attempts))
+	}
+}
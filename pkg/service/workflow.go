@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	"github.com/OrIX219/todo/pkg/tracing"
+)
+
+type WorkflowService struct {
+	repo     repository.Workflow
+	listRepo repository.TodoList
+	itemRepo repository.TodoItem
+	events   Events
+}
+
+func NewWorkflowService(repo repository.Workflow, listRepo repository.TodoList,
+	itemRepo repository.TodoItem, events Events) *WorkflowService {
+	return &WorkflowService{repo: repo, listRepo: listRepo, itemRepo: itemRepo, events: events}
+}
+
+func (s *WorkflowService) GetStatuses(ctx context.Context, userId, listId int) (_ todo.Workflow, err error) {
+	ctx, span := tracing.Start(ctx, "WorkflowService.GetStatuses", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	if _, err := s.listRepo.GetById(ctx, userId, listId); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStatuses(ctx, listId)
+}
+
+// SetStatuses replaces the workflow of the list, see todo.WorkflowInput.
+func (s *WorkflowService) SetStatuses(ctx context.Context, userId, listId int, input todo.WorkflowInput) (_ todo.Workflow, err error) {
+	ctx, span := tracing.Start(ctx, "WorkflowService.SetStatuses", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.listRepo.GetById(ctx, userId, listId); err != nil {
+		return nil, err
+	}
+
+	workflow := make(todo.Workflow, len(input.Statuses))
+	for i, status := range input.Statuses {
+		workflow[i] = todo.ListStatus{Id: status.Id, Name: status.Name, Terminal: status.Terminal}
+	}
+	// transitions use the names of the statuses, whatever their case
+	for i, status := range input.Statuses {
+		for _, transition := range status.Transitions {
+			target, _ := workflow.Find(transition)
+			workflow[i].Transitions = append(workflow[i].Transitions, target.Name)
+		}
+	}
+
+	workflow, err = s.repo.SetStatuses(ctx, listId, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	publishEvent(ctx, s.events, newEvent(todo.EventListUpdated, userId, listId, 0, input))
+	return workflow, nil
+}
+
+// Board groups the items of the list by status. Lists without a workflow
+// get columns for undone and done items.
+func (s *WorkflowService) Board(ctx context.Context, userId, listId int) (_ todo.Board, err error) {
+	ctx, span := tracing.Start(ctx, "WorkflowService.Board", tracing.UserId(userId), tracing.ListId(listId))
+	defer tracing.End(span, &err)
+
+	workflow, err := s.GetStatuses(ctx, userId, listId)
+	if err != nil {
+		return todo.Board{}, err
+	}
+	if len(workflow) == 0 {
+		workflow = todo.DefaultWorkflow
+	}
+
+	items, err := s.itemRepo.GetAll(ctx, userId, listId)
+	if err != nil {
+		return todo.Board{}, err
+	}
+
+	board := todo.Board{ListId: listId, Columns: make([]todo.BoardColumn, len(workflow))}
+	columns := make(map[string]int, len(workflow))
+	for i, status := range workflow {
+		board.Columns[i] = todo.BoardColumn{Status: status, Items: make([]todo.TodoItem, 0)}
+		columns[status.Name] = i
+	}
+	for _, item := range items {
+		status := workflow.Current(item)
+		item.Status = status.Name
+		column := &board.Columns[columns[status.Name]]
+		column.Items = append(column.Items, item)
+	}
+	return board, nil
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	todo "github.com/OrIX219/todo/pkg"
+	"github.com/OrIX219/todo/pkg/repository"
+	mock_service "github.com/OrIX219/todo/pkg/service/mock"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+)
+
+type workflowRepo struct {
+	statuses todo.Workflow
+}
+
+func (r *workflowRepo) GetStatuses(ctx context.Context, listId int) (todo.Workflow, error) {
+	return r.statuses, nil
+}
+func (r *workflowRepo) GetItemStatuses(ctx context.Context, itemId int) (todo.Workflow, error) {
+	return r.statuses, nil
+}
+
+func (r *workflowRepo) SetStatuses(ctx context.Context, listId int, statuses todo.Workflow) (todo.Workflow, error) {
+	for i := range statuses {
+		statuses[i].ListId = listId
+		statuses[i].Position = i
+		if statuses[i].Id == 0 {
+			statuses[i].Id = 10 + i
+		}
+	}
+	r.statuses = statuses
+	return statuses, nil
+}
+
+type workflowItemRepo struct {
+	repository.TodoItem
+	items   []todo.TodoItem
+	created []todo.TodoItem
+	updates []todo.UpdateItemInput
+}
+
+func (r *workflowItemRepo) Create(ctx context.Context, listId int, item todo.TodoItem) (int, error) {
+	r.created = append(r.created, item)
+	return len(r.created), nil
+}
+
+func (r *workflowItemRepo) GetAll(ctx context.Context, userId, listId int) ([]todo.TodoItem, error) {
+	return r.items, nil
+}
+
+func (r *workflowItemRepo) GetById(ctx context.Context, userId, itemId int) (todo.TodoItem, error) {
+	for _, item := range r.items {
+		if item.Id == itemId {
+			return item, nil
+		}
+	}
+	return todo.TodoItem{}, &todo.ErrNoSuchItem{}
+}
+
+func (r *workflowItemRepo) Update(ctx context.Context, userId, itemId int, input todo.UpdateItemInput) error {
+	r.updates = append(r.updates, input)
+	return nil
+}
+
+// kanban moves items forward one column at a time, or back from review.
+var kanban = todo.Workflow{
+	{Id: 1, Name: "Backlog", Transitions: todo.StatusNames{"In Progress"}},
+	{Id: 2, Name: "In Progress", Transitions: todo.StatusNames{"Review", "Backlog"}},
+	{Id: 3, Name: "Review", Transitions: todo.StatusNames{"Done", "In Progress"}},
+	{Id: 4, Name: "Done", Terminal: true},
+}
+
+var kanbanItems = []todo.TodoItem{
+	{Id: 1, Title: "Spec", Status: "Backlog"},
+	{Id: 2, Title: "Build", Status: "In Progress"},
+	{Id: 3, Title: "Test", Status: "Review"},
+	{Id: 4, Title: "Ship", Status: "Done", Done: true},
+	{Id: 5, Title: "Old"},
+	{Id: 6, Title: "Older", Done: true},
+	{Id: 7, Title: "Moved", Status: "Doing"},
+}
+
+func newWorkflowItemService(t *testing.T, workflow todo.Workflow) (*TodoItemService, *workflowItemRepo) {
+	c := gomock.NewController(t)
+	events := mock_service.NewMockEvents(c)
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	items := &workflowItemRepo{items: kanbanItems}
+	lists := &transferRepo{lists: []todo.TodoList{{Id: 1, Title: "Team"}}}
+	dependencies := &dependencyRepo{}
+	transactor := &dependencyTransactor{items: items, dependencies: dependencies}
+	return NewTodoItemService(items, lists, &workflowRepo{statuses: workflow}, dependencies, transactor, events), items
+}
+
+func TestTodoItemService_Update_Workflow(t *testing.T) {
+	status := func(name string) *string { return &name }
+	done := func(done bool) *bool { return &done }
+	title := "Renamed"
+
+	testTable := []struct {
+		name          string
+		itemId        int
+		input         todo.UpdateItemInput
+		expected      todo.UpdateItemInput
+		expectedError error
+	}{
+		{
+			name:     "Next status",
+			itemId:   1,
+			input:    todo.UpdateItemInput{Status: status("in progress")},
+			expected: todo.UpdateItemInput{Status: status("In Progress"), Done: done(false)},
+		},
+		{
+			name:     "Back",
+			itemId:   3,
+			input:    todo.UpdateItemInput{Status: status("In Progress"), Title: &title},
+			expected: todo.UpdateItemInput{Status: status("In Progress"), Done: done(false), Title: &title},
+		},
+		{
+			name:          "Skipping statuses",
+			itemId:        1,
+			input:         todo.UpdateItemInput{Status: status("Done")},
+			expectedError: &todo.ErrInvalidTransition{From: "Backlog", To: "Done"},
+		},
+		{
+			name:     "Done from review",
+			itemId:   3,
+			input:    todo.UpdateItemInput{Done: done(true)},
+			expected: todo.UpdateItemInput{Status: status("Done"), Done: done(true)},
+		},
+		{
+			name:          "Done from backlog",
+			itemId:        1,
+			input:         todo.UpdateItemInput{Done: done(true)},
+			expectedError: &todo.ErrInvalidTransition{From: "Backlog", To: "Done"},
+		},
+		{
+			name:     "Undone",
+			itemId:   4,
+			input:    todo.UpdateItemInput{Done: done(false)},
+			expected: todo.UpdateItemInput{Status: status("Backlog"), Done: done(false)},
+		},
+		{
+			name:     "Still undone",
+			itemId:   2,
+			input:    todo.UpdateItemInput{Done: done(false)},
+			expected: todo.UpdateItemInput{Status: status("In Progress"), Done: done(false)},
+		},
+		{
+			name:     "Done before the workflow",
+			itemId:   6,
+			input:    todo.UpdateItemInput{Status: status("Review")},
+			expected: todo.UpdateItemInput{Status: status("Review"), Done: done(false)},
+		},
+		{
+			name:          "Undone before the workflow",
+			itemId:        5,
+			input:         todo.UpdateItemInput{Status: status("Review")},
+			expectedError: &todo.ErrInvalidTransition{From: "Backlog", To: "Review"},
+		},
+		{
+			name:     "Status of another list",
+			itemId:   7,
+			input:    todo.UpdateItemInput{Status: status("In Progress")},
+			expected: todo.UpdateItemInput{Status: status("In Progress"), Done: done(false)},
+		},
+		{
+			name:          "Unknown status",
+			itemId:        1,
+			input:         todo.UpdateItemInput{Status: status("Blocked")},
+			expectedError: &todo.ErrNoSuchStatus{Name: "Blocked"},
+		},
+		{
+			name:          "Status and done disagree",
+			itemId:        3,
+			input:         todo.UpdateItemInput{Status: status("Done"), Done: done(false)},
+			expectedError: &todo.ErrInvalidUpdateItemInput{},
+		},
+		{
+			name:     "Other fields",
+			itemId:   1,
+			input:    todo.UpdateItemInput{Title: &title},
+			expected: todo.UpdateItemInput{Title: &title},
+		},
+		{
+			name:          "No such item",
+			itemId:        9,
+			input:         todo.UpdateItemInput{Done: done(true)},
+			expectedError: &todo.ErrNoSuchItem{},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, items := newWorkflowItemService(t, kanban)
+
+			_, err := service.Update(context.Background(), 1, testCase.itemId, testCase.input)
+			assert.Equal(t, err, testCase.expectedError)
+			if testCase.expectedError == nil {
+				assert.Equal(t, items.updates, []todo.UpdateItemInput{testCase.expected})
+			} else {
+				assert.Equal(t, len(items.updates), 0)
+			}
+		})
+	}
+}
+
+func TestTodoItemService_Update_NoWorkflow(t *testing.T) {
+	service, items := newWorkflowItemService(t, todo.Workflow{})
+
+	done, status := true, "Review"
+	_, err := service.Update(context.Background(), 1, 1, todo.UpdateItemInput{Done: &done})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, items.updates, []todo.UpdateItemInput{{Done: &done}})
+
+	_, err = service.Update(context.Background(), 1, 1, todo.UpdateItemInput{Status: &status})
+	assert.Equal(t, err, &todo.ErrNoSuchStatus{Name: "Review"})
+}
+
+func TestTodoItemService_Create_Workflow(t *testing.T) {
+	testTable := []struct {
+		name          string
+		item          todo.TodoItem
+		expected      todo.TodoItem
+		expectedError error
+	}{
+		{
+			name:     "Initial",
+			item:     todo.TodoItem{Title: "Plan"},
+			expected: todo.TodoItem{Title: "Plan", Status: "Backlog"},
+		},
+		{
+			name:     "Done",
+			item:     todo.TodoItem{Title: "Plan", Done: true},
+			expected: todo.TodoItem{Title: "Plan", Status: "Done", Done: true},
+		},
+		{
+			name:     "Status",
+			item:     todo.TodoItem{Title: "Plan", Status: "review", Done: true},
+			expected: todo.TodoItem{Title: "Plan", Status: "Review"},
+		},
+		{
+			name:          "Unknown status",
+			item:          todo.TodoItem{Title: "Plan", Status: "Blocked"},
+			expectedError: &todo.ErrNoSuchStatus{Name: "Blocked"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			service, items := newWorkflowItemService(t, kanban)
+
+			_, err := service.Create(context.Background(), 1, 1, testCase.item)
+			assert.Equal(t, err, testCase.expectedError)
+			if testCase.expectedError == nil {
+				assert.Equal(t, items.created, []todo.TodoItem{testCase.expected})
+			}
+		})
+	}
+}
+
+func TestTodoItemService_Bulk_Workflow(t *testing.T) {
+	service, items := newWorkflowItemService(t, kanban)
+	items.TodoItem = bulkItemRepo{}
+
+	status := "Done"
+	output, err := service.Bulk(context.Background(), 1, todo.BulkItemsInput{Operations: []todo.BulkItemOperation{
+		{Op: todo.BulkOpCreate, ListId: 1, Item: &todo.TodoItem{Title: "Plan"}},
+		{Op: todo.BulkOpUpdate, ItemId: 1, Update: &todo.UpdateItemInput{Status: &status}},
+	}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, output.Results[0].Status, todo.BulkStatusOk)
+	assert.Equal(t, output.Results[1].Code, "invalid_transition")
+	assert.Equal(t, output.Results[1].Message, `Items can't move from "Backlog" to "Done"`)
+}
+
+// bulkItemRepo applies all operations.
+type bulkItemRepo struct {
+	repository.TodoItem
+}
+
+func (bulkItemRepo) Bulk(ctx context.Context, userId int, ops []todo.BulkItemOperation, atomic bool) ([]todo.BulkItemResult, bool, error) {
+	results := make([]todo.BulkItemResult, len(ops))
+	for i, op := range ops {
+		results[i] = todo.BulkItemResult{Index: i, Op: op.Op, Id: i + 1}
+	}
+	return results, true, nil
+}
+
+func TestWorkflowService_SetStatuses(t *testing.T) {
+	c := gomock.NewController(t)
+	events := mock_service.NewMockEvents(c)
+	events.EXPECT().Publish(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	repo := &workflowRepo{}
+	lists := &transferRepo{lists: []todo.TodoList{{Id: 1, Title: "Team"}}}
+	service := NewWorkflowService(repo, lists, &workflowItemRepo{}, events)
+
+	statuses, err := service.SetStatuses(context.Background(), 1, 1, todo.WorkflowInput{Statuses: []todo.StatusInput{
+		{Id: 1, Name: "Todo", Transitions: []string{"DONE"}},
+		{Name: "Done", Terminal: true},
+	}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, statuses, todo.Workflow{
+		{Id: 1, ListId: 1, Name: "Todo", Position: 0, Transitions: todo.StatusNames{"Done"}},
+		{Id: 11, ListId: 1, Name: "Done", Position: 1, Terminal: true},
+	})
+
+	testTable := []struct {
+		name          string
+		statuses      []todo.StatusInput
+		expectedError error
+	}{
+		{
+			name:          "No terminal status",
+			statuses:      []todo.StatusInput{{Name: "Todo"}, {Name: "Doing"}},
+			expectedError: &todo.ErrInvalidWorkflowInput{Reason: "exactly one status must be terminal"},
+		},
+		{
+			name:          "Two terminal statuses",
+			statuses:      []todo.StatusInput{{Name: "Todo"}, {Name: "Done", Terminal: true}, {Name: "Won't do", Terminal: true}},
+			expectedError: &todo.ErrInvalidWorkflowInput{Reason: "exactly one status must be terminal"},
+		},
+		{
+			name:          "Only terminal status",
+			statuses:      []todo.StatusInput{{Name: "Done", Terminal: true}},
+			expectedError: &todo.ErrInvalidWorkflowInput{Reason: "a status besides the terminal one is needed"},
+		},
+		{
+			name:          "Duplicate name",
+			statuses:      []todo.StatusInput{{Name: "Todo"}, {Name: "todo", Terminal: true}},
+			expectedError: &todo.ErrInvalidWorkflowInput{Reason: `duplicate status "todo"`},
+		},
+		{
+			name:          "Unknown transition",
+			statuses:      []todo.StatusInput{{Name: "Todo", Transitions: []string{"Doing"}}, {Name: "Done", Terminal: true}},
+			expectedError: &todo.ErrInvalidWorkflowInput{Reason: `status "Todo" moves to unknown status "Doing"`},
+		},
+		{
+			name:          "Empty name",
+			statuses:      []todo.StatusInput{{Name: " "}, {Name: "Done", Terminal: true}},
+			expectedError: &todo.ErrInvalidWorkflowInput{Reason: `invalid status name " "`},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := service.SetStatuses(context.Background(), 1, 1, todo.WorkflowInput{Statuses: testCase.statuses})
+			assert.Equal(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestWorkflowService_Board(t *testing.T) {
+	lists := &transferRepo{lists: []todo.TodoList{{Id: 1, Title: "Team"}}}
+	items := &workflowItemRepo{items: kanbanItems}
+
+	board, err := NewWorkflowService(&workflowRepo{statuses: kanban}, lists, items, nil).Board(context.Background(), 1, 1)
+	assert.Equal(t, err, nil)
+	titles := make(map[string][]string)
+	for _, column := range board.Columns {
+		for _, item := range column.Items {
+			assert.Equal(t, item.Status, column.Status.Name)
+			titles[column.Status.Name] = append(titles[column.Status.Name], item.Title)
+		}
+	}
+	assert.Equal(t, len(board.Columns), 4)
+	assert.Equal(t, titles, map[string][]string{
+		"Backlog":     {"Spec", "Old", "Moved"},
+		"In Progress": {"Build"},
+		"Review":      {"Test"},
+		"Done":        {"Ship", "Older"},
+	})
+
+	board, err = NewWorkflowService(&workflowRepo{}, lists, items, nil).Board(context.Background(), 1, 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, board.Columns[0].Status.Name, "Open")
+	assert.Equal(t, len(board.Columns[0].Items), 5)
+	assert.Equal(t, len(board.Columns[1].Items), 2)
+}
@@ -1,9 +1,18 @@
 package todo
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type TodoList struct {
 	Id          int    `json:"id" db:"id"`
 	Title       string `json:"title" db:"title" binding:"required"`
 	Description string `json:"description" db:"description"`
+	// BlockerPolicy is BlockerPolicyReject or BlockerPolicyWarn.
+	BlockerPolicy string `json:"blocker_policy,omitempty" db:"blocker_policy" binding:"omitempty,oneof=reject warn"`
 }
 
 type ErrNoSuchList struct{}
@@ -13,18 +22,86 @@ func (e *ErrNoSuchList) Error() string {
 }
 
 type UsersList struct {
-	Id     int
-	UserId int
-	ListId int
+	Id     int `json:"id" db:"id"`
+	UserId int `json:"user_id" db:"user_id"`
+	ListId int `json:"list_id" db:"list_id"`
 }
 
 type TodoItem struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
-	Done        bool   `json:"done" db:"done"`
+	Id          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title" binding:"required"`
+	Description string     `json:"description" db:"description"`
+	Done        bool       `json:"done" db:"done"`
+	Due         *time.Time `json:"due,omitempty" db:"due"`
+	Priority    int        `json:"priority,omitempty" db:"priority" binding:"min=0,max=3"`
+	Tags        Tags       `json:"tags,omitempty" db:"tags" binding:"max=20,dive,min=1,max=64"`
+	// Status is the name of the workflow status of the item, see Workflow.
+	Status string `json:"status,omitempty" db:"status" binding:"max=64"`
+	// BlockedBy are the ids of the items that have to be done first, from
+	// any list of the user. Blocked tells whether any of them is open.
+	BlockedBy ItemIds `json:"blocked_by,omitempty" db:"blocked_by" binding:"max=50,dive,min=1"`
+	Blocked   bool    `json:"blocked,omitempty" db:"blocked"`
+}
+
+// Tags are stored as a JSON array.
+type Tags []string
+
+const (
+	maxTags      = 20
+	maxTagLength = 64
+)
+
+func (t Tags) Validate() error {
+	if len(t) > maxTags {
+		return fmt.Errorf("more than %d tags", maxTags)
+	}
+	for _, tag := range t {
+		if tag == "" || len([]rune(tag)) > maxTagLength {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	return nil
+}
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]string(t))
 }
 
+func (t *Tags) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return t.unmarshal(src)
+	case string:
+		return t.unmarshal([]byte(src))
+	}
+	return fmt.Errorf("cannot scan %T into tags", src)
+}
+
+func (t *Tags) unmarshal(data []byte) error {
+	var tags []string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	*t = tags
+	return nil
+}
+
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
 type ErrNoSuchItem struct{}
 
 func (e *ErrNoSuchItem) Error() string {
@@ -32,14 +109,15 @@ func (e *ErrNoSuchItem) Error() string {
 }
 
 type ListsItem struct {
-	Id     int
-	ListId int
-	ItemId int
+	Id     int `json:"id" db:"id"`
+	ListId int `json:"list_id" db:"list_id"`
+	ItemId int `json:"item_id" db:"item_id"`
 }
 
 type UpdateListInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
+	Title         *string `json:"title"`
+	Description   *string `json:"description"`
+	BlockerPolicy *string `json:"blocker_policy"`
 }
 
 type ErrInvalidUpdateListInput struct{}
@@ -49,16 +127,27 @@ func (e *ErrInvalidUpdateListInput) Error() string {
 }
 
 func (i UpdateListInput) Validate() error {
-	if i.Title == nil && i.Description == nil {
+	if i.Title == nil && i.Description == nil && i.BlockerPolicy == nil {
+		return &ErrInvalidUpdateListInput{}
+	}
+	if i.BlockerPolicy != nil && (*i.BlockerPolicy == "" || !ValidBlockerPolicy(*i.BlockerPolicy)) {
 		return &ErrInvalidUpdateListInput{}
 	}
 	return nil
 }
 
 type UpdateItemInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Done        *bool   `json:"done"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Done        *bool      `json:"done"`
+	Due         *time.Time `json:"due"`
+	Priority    *int       `json:"priority"`
+	Tags        *Tags      `json:"tags"`
+	Status      *string    `json:"status"`
+	BlockedBy   *ItemIds   `json:"blocked_by"`
+	// ClearDue removes the due date. It is only set internally, e.g. when a
+	// CalDAV client drops DUE from a task.
+	ClearDue bool `json:"-"`
 }
 
 type ErrInvalidUpdateItemInput struct{}
@@ -68,8 +157,112 @@ func (e *ErrInvalidUpdateItemInput) Error() string {
 }
 
 func (i UpdateItemInput) Validate() error {
-	if i.Title == nil && i.Description == nil && i.Done == nil {
+	if i.Title == nil && i.Description == nil && i.Done == nil &&
+		i.Due == nil && i.Priority == nil && i.Tags == nil && i.Status == nil && i.BlockedBy == nil && !i.ClearDue {
+		return &ErrInvalidUpdateItemInput{}
+	}
+	if i.Due != nil && i.ClearDue {
 		return &ErrInvalidUpdateItemInput{}
 	}
+	if i.Priority != nil && (*i.Priority < PriorityNone || *i.Priority > PriorityHigh) {
+		return &ErrInvalidUpdateItemInput{}
+	}
+	if i.Tags != nil && i.Tags.Validate() != nil {
+		return &ErrInvalidUpdateItemInput{}
+	}
+	if i.Status != nil && (*i.Status == "" || len([]rune(*i.Status)) > maxStatusNameLength) {
+		return &ErrInvalidUpdateItemInput{}
+	}
+	if i.BlockedBy != nil && i.BlockedBy.Validate() != nil {
+		return &ErrInvalidUpdateItemInput{}
+	}
+	return nil
+}
+
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpDelete = "delete"
+	BulkOpMove   = "move"
+)
+
+type BulkItemOperation struct {
+	Op     string           `json:"op" binding:"required"`
+	ItemId int              `json:"item_id"`
+	ListId int              `json:"list_id"`
+	Item   *TodoItem        `json:"item"`
+	Update *UpdateItemInput `json:"update"`
+}
+
+type BulkItemsInput struct {
+	Atomic     bool                `json:"atomic"`
+	Operations []BulkItemOperation `json:"operations" binding:"required,min=1,max=100"`
+}
+
+const (
+	BulkStatusOk         = "ok"
+	BulkStatusError      = "error"
+	BulkStatusRolledBack = "rolled_back"
+)
+
+type BulkItemResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	Id      int    `json:"id,omitempty"`
+	Status  string `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Warnings are set for operations that went through anyway.
+	Warnings []Warning `json:"warnings,omitempty"`
+	Err      error     `json:"-"`
+}
+
+type ErrInvalidBulkOperation struct {
+	Reason string
+}
+
+func (e *ErrInvalidBulkOperation) Error() string {
+	return "Invalid bulk operation: " + e.Reason
+}
+
+func (op BulkItemOperation) Validate() error {
+	switch op.Op {
+	case BulkOpCreate:
+		if op.ListId <= 0 {
+			return &ErrInvalidBulkOperation{"list_id is required"}
+		}
+		if op.Item == nil || op.Item.Title == "" {
+			return &ErrInvalidBulkOperation{"item with title is required"}
+		}
+		if op.Item.Priority < PriorityNone || op.Item.Priority > PriorityHigh {
+			return &ErrInvalidBulkOperation{"priority is out of range"}
+		}
+		if err := op.Item.BlockedBy.Validate(); err != nil {
+			return &ErrInvalidBulkOperation{err.Error()}
+		}
+	case BulkOpUpdate:
+		if op.ItemId <= 0 {
+			return &ErrInvalidBulkOperation{"item_id is required"}
+		}
+		if op.Update == nil {
+			return &ErrInvalidUpdateItemInput{}
+		}
+		return op.Update.Validate()
+	case BulkOpDelete:
+		if op.ItemId <= 0 {
+			return &ErrInvalidBulkOperation{"item_id is required"}
+		}
+	case BulkOpMove:
+		if op.ItemId <= 0 || op.ListId <= 0 {
+			return &ErrInvalidBulkOperation{"item_id and list_id are required"}
+		}
+	default:
+		return &ErrInvalidBulkOperation{"unknown op"}
+	}
 	return nil
 }
+
+type BulkItemsOutput struct {
+	Applied bool             `json:"applied"`
+	Results []BulkItemResult `json:"results"`
+}
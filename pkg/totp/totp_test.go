@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// the last six digits of the eight digit codes in RFC 6238
+	testTable := []struct {
+		unix         int64
+		expectedCode string
+	}{
+		{unix: 59, expectedCode: "287082"},
+		{unix: 1111111109, expectedCode: "081804"},
+		{unix: 1111111111, expectedCode: "050471"},
+		{unix: 1234567890, expectedCode: "005924"},
+		{unix: 2000000000, expectedCode: "279037"},
+		{unix: 20000000000, expectedCode: "353130"},
+	}
+
+	for _, testCase := range testTable {
+		code, err := Code(rfcSecret, Step(time.Unix(testCase.unix, 0)))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, code, testCase.expectedCode)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := Validate(rfcSecret, "050471", now)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, step, Step(now))
+
+	// the code of the previous step is still accepted
+	step, ok = Validate(rfcSecret, "050471", now.Add(Period))
+	assert.Equal(t, ok, true)
+	assert.Equal(t, step, Step(now))
+
+	_, ok = Validate(rfcSecret, "050471", now.Add(3*Period))
+	assert.Equal(t, ok, false)
+	_, ok = Validate(rfcSecret, "123456", now)
+	assert.Equal(t, ok, false)
+	_, ok = Validate(rfcSecret, "5047", now)
+	assert.Equal(t, ok, false)
+}
+
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(secret), 32)
+
+	u, err := url.Parse(URI("todo-app", "alice", secret))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, u.Scheme, "otpauth")
+	assert.Equal(t, u.Host, "totp")
+	assert.Equal(t, u.Path, "/todo-app:alice")
+	assert.Equal(t, u.Query().Get("secret"), secret)
+	assert.Equal(t, u.Query().Get("issuer"), "todo-app")
+}
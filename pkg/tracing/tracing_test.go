@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestSetup(t *testing.T) {
+	testTable := []struct {
+		name          string
+		cfg           Config
+		expectedError string
+	}{
+		{
+			name: "Disabled",
+			cfg:  Config{Exporter: "none"},
+		},
+		{
+			name: "Stdout",
+			cfg:  Config{Exporter: "stdout", SampleRatio: 0.5},
+		},
+		{
+			name:          "Unknown exporter",
+			cfg:           Config{Exporter: "zipkin"},
+			expectedError: "unknown trace exporter zipkin, expected none, stdout or otlp",
+		},
+		{
+			name:          "Invalid ratio",
+			cfg:           Config{Exporter: "stdout", SampleRatio: 2},
+			expectedError: "trace sample ratio 2 isn't between 0 and 1",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), testCase.cfg)
+			if testCase.expectedError != "" {
+				assert.Equal(t, err.Error(), testCase.expectedError)
+				return
+			}
+			assert.Equal(t, err, nil)
+			assert.Equal(t, shutdown(context.Background()), nil)
+		})
+	}
+}
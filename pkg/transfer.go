@@ -0,0 +1,106 @@
+package todo
+
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Sources are the tools whose export files can be imported.
+const (
+	SourceTodoist       = "todoist"
+	SourceMicrosoftToDo = "microsoft-todo"
+	SourceTrello        = "trello"
+)
+
+// ExportedList is a list with its items, the unit of export and import.
+type ExportedList struct {
+	TodoList
+	Items []TodoItem `json:"items"`
+}
+
+const (
+	// OnDuplicateCreate imports lists as new even if their titles are taken
+	OnDuplicateCreate = "create"
+	// OnDuplicateMerge adds items to the list with the same title, skipping
+	// the ones with titles already there
+	OnDuplicateMerge = "merge"
+	// OnDuplicateSkip ignores lists whose titles are taken
+	OnDuplicateSkip = "skip"
+)
+
+type ImportOptions struct {
+	Format string
+	// Source is set for export files of other tools, Format is ignored then
+	Source string
+	// FileName is the name of the uploaded file, if known. Some sources name
+	// lists after their files.
+	FileName    string
+	DryRun      bool
+	OnDuplicate string
+}
+
+const (
+	ImportActionCreate = "create"
+	ImportActionMerge  = "merge"
+	ImportActionSkip   = "skip"
+)
+
+type ImportListReport struct {
+	Title        string `json:"title"`
+	Id           int    `json:"id,omitempty"`
+	Action       string `json:"action"`
+	Items        int    `json:"items"`
+	SkippedItems int    `json:"skipped_items"`
+}
+
+// ImportMapping counts the things of a source that were turned into
+// something else, e.g. Trello cards into items, or were skipped.
+type ImportMapping struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+const (
+	MappingList      = "list"
+	MappingItem      = "item"
+	MappingDesc      = "description"
+	MappingTruncated = "truncated"
+	MappingSkipped   = "skipped"
+)
+
+type ImportReport struct {
+	Source       string             `json:"source,omitempty"`
+	Mapping      []ImportMapping    `json:"mapping,omitempty"`
+	DryRun       bool               `json:"dry_run"`
+	CreatedLists int                `json:"created_lists"`
+	CreatedItems int                `json:"created_items"`
+	SkippedLists int                `json:"skipped_lists"`
+	SkippedItems int                `json:"skipped_items"`
+	Lists        []ImportListReport `json:"lists"`
+}
+
+type ErrUnsupportedFormat struct {
+	Format string
+}
+
+func (e *ErrUnsupportedFormat) Error() string {
+	return "Unsupported format: " + e.Format
+}
+
+type ErrUnsupportedSource struct {
+	Source string
+}
+
+func (e *ErrUnsupportedSource) Error() string {
+	return "Unsupported import source: " + e.Source
+}
+
+type ErrInvalidImport struct {
+	Reason string
+}
+
+func (e *ErrInvalidImport) Error() string {
+	return "Invalid import: " + e.Reason
+}
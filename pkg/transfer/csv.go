@@ -0,0 +1,172 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+const (
+	columnList            = "list"
+	columnListDescription = "list_description"
+	columnTitle           = "title"
+	columnDescription     = "description"
+	columnDone            = "done"
+	columnDue             = "due"
+	columnPriority        = "priority"
+)
+
+// csvHeader lists the columns of an export. There is a row per item, and a
+// row without a title for a list without items.
+var csvHeader = []string{
+	columnList, columnListDescription, columnTitle, columnDescription,
+	columnDone, columnDue, columnPriority,
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	e := &csvEncoder{w: csv.NewWriter(w)}
+	e.w.Write(csvHeader)
+	return e
+}
+
+func (e *csvEncoder) WriteList(list todo.ExportedList) error {
+	if len(list.Items) == 0 {
+		e.w.Write([]string{list.Title, list.Description, "", "", "", "", ""})
+	}
+
+	for _, item := range list.Items {
+		var due string
+		if item.Due != nil {
+			due = item.Due.UTC().Format(time.RFC3339)
+		}
+
+		e.w.Write([]string{
+			list.Title,
+			list.Description,
+			item.Title,
+			item.Description,
+			strconv.FormatBool(item.Done),
+			due,
+			strconv.Itoa(item.Priority),
+		})
+	}
+
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// decodeCSV reads rows into lists by the list column, in the order the lists
+// first appear. Rows without a list go to the default one. Only the list and title columns are required, the others may
+// be left out or come in any order.
+func decodeCSV(r io.Reader) ([]todo.ExportedList, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{columnList, columnTitle} {
+		if _, ok := columns[name]; !ok {
+			return nil, &todo.ErrInvalidImport{Reason: fmt.Sprintf("missing %q column", name)}
+		}
+	}
+
+	var lists []todo.ExportedList
+	indexes := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		title := field(columnList)
+		if title == "" {
+			title = DefaultListTitle
+		}
+		i, ok := indexes[title]
+		if !ok {
+			i = len(lists)
+			indexes[title] = i
+			lists = append(lists, todo.ExportedList{TodoList: todo.TodoList{Title: title}})
+		}
+		if description := field(columnListDescription); description != "" {
+			lists[i].Description = description
+		}
+
+		if field(columnTitle) == "" {
+			continue
+		}
+		item, err := csvItem(field)
+		if err != nil {
+			return nil, &todo.ErrInvalidImport{Reason: fmt.Sprintf("line %d: %s", line, err)}
+		}
+		lists[i].Items = append(lists[i].Items, item)
+	}
+
+	return lists, nil
+}
+
+func csvItem(field func(string) string) (todo.TodoItem, error) {
+	item := todo.TodoItem{
+		Title:       field(columnTitle),
+		Description: field(columnDescription),
+	}
+
+	if done := field(columnDone); done != "" {
+		var err error
+		if item.Done, err = strconv.ParseBool(done); err != nil {
+			return item, fmt.Errorf("invalid done %q", done)
+		}
+	}
+
+	if due := field(columnDue); due != "" {
+		t, err := time.Parse(time.RFC3339, due)
+		if err != nil {
+			return item, fmt.Errorf("invalid due %q", due)
+		}
+		item.Due = &t
+	}
+
+	if priority := field(columnPriority); priority != "" {
+		var err error
+		if item.Priority, err = strconv.Atoi(priority); err != nil {
+			return item, fmt.Errorf("invalid priority %q", priority)
+		}
+	}
+
+	return item, nil
+}
@@ -0,0 +1,140 @@
+package transfer
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+const maxMarkdownLine = 1 << 20
+
+// markdownEncoder writes a GitHub-style checklist per list. Only titles,
+// descriptions and the done state are kept, due dates and priorities are
+// left out.
+type markdownEncoder struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (e *markdownEncoder) WriteList(list todo.ExportedList) error {
+	if e.count > 0 {
+		e.w.WriteString("\n")
+	}
+	e.count++
+
+	e.w.WriteString("# " + singleLine(list.Title) + "\n\n")
+	if description := strings.TrimSpace(list.Description); description != "" {
+		e.w.WriteString(description + "\n\n")
+	}
+
+	for _, item := range list.Items {
+		box := "[ ]"
+		if item.Done {
+			box = "[x]"
+		}
+		e.w.WriteString("- " + box + " " + singleLine(item.Title) + "\n")
+
+		for _, line := range strings.Split(strings.TrimSpace(item.Description), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				e.w.WriteString("  " + line + "\n")
+			}
+		}
+	}
+
+	return e.w.Flush()
+}
+
+func (e *markdownEncoder) Close() error {
+	return e.w.Flush()
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// decodeMarkdown reads a heading per list and list items as its items, with
+// "[x]" marking done ones. Text under a heading before the items is the list
+// description, indented lines under an item are the item description. Items
+// before the first heading go to the default list, other text there is
+// ignored.
+func decodeMarkdown(r io.Reader) ([]todo.ExportedList, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMarkdownLine)
+
+	var lists []todo.ExportedList
+	var list *todo.ExportedList
+	var item *todo.TodoItem
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" {
+			continue
+		}
+
+		if title, ok := markdownHeading(line); ok {
+			lists = append(lists, todo.ExportedList{TodoList: todo.TodoList{Title: title}})
+			list, item = &lists[len(lists)-1], nil
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		if title, done, ok := markdownItem(line); ok && !indented {
+			if list == nil {
+				lists = append(lists, todo.ExportedList{TodoList: todo.TodoList{Title: DefaultListTitle}})
+				list = &lists[len(lists)-1]
+			}
+			list.Items = append(list.Items, todo.TodoItem{Title: title, Done: done})
+			item = &list.Items[len(list.Items)-1]
+			continue
+		}
+
+		switch {
+		case item != nil:
+			item.Description = appendLine(item.Description, strings.TrimSpace(line))
+		case list != nil:
+			list.Description = appendLine(list.Description, strings.TrimSpace(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+	}
+
+	return lists, nil
+}
+
+func markdownHeading(line string) (string, bool) {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 || len(line) == level || line[level] != ' ' {
+		return "", false
+	}
+
+	title := strings.TrimSpace(strings.TrimRight(line[level:], "#"))
+	return title, title != ""
+}
+
+// markdownItem parses a bullet, which is a task if it starts with a checkbox.
+func markdownItem(line string) (title string, done bool, ok bool) {
+	line = strings.TrimLeft(line, " \t")
+	if len(line) < 2 || !strings.ContainsRune("-*+", rune(line[0])) || line[1] != ' ' {
+		return "", false, false
+	}
+	line = strings.TrimSpace(line[2:])
+
+	switch {
+	case strings.HasPrefix(line, "[ ] "), line == "[ ]":
+		line = line[3:]
+	case strings.HasPrefix(line, "[x] "), strings.HasPrefix(line, "[X] "), line == "[x]", line == "[X]":
+		line, done = line[3:], true
+	}
+
+	title = strings.TrimSpace(line)
+	return title, done, title != ""
+}
+
+func appendLine(text, line string) string {
+	if text == "" {
+		return line
+	}
+	return text + "\n" + line
+}
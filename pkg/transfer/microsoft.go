@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+const msDateTimeLayout = "2006-01-02T15:04:05.9999999"
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// msTodoExport is either a Graph API response with task lists, or an object
+// with the lists under "lists". A plain array of lists is accepted as well.
+type msTodoExport struct {
+	Lists []msTodoList `json:"lists"`
+	Value []msTodoList `json:"value"`
+}
+
+type msTodoList struct {
+	DisplayName string       `json:"displayName"`
+	Tasks       []msTodoTask `json:"tasks"`
+}
+
+type msTodoTask struct {
+	Title string `json:"title"`
+	Body  struct {
+		Content     string `json:"content"`
+		ContentType string `json:"contentType"`
+	} `json:"body"`
+	Status      string `json:"status"`
+	Importance  string `json:"importance"`
+	DueDateTime *struct {
+		DateTime string `json:"dateTime"`
+		TimeZone string `json:"timeZone"`
+	} `json:"dueDateTime"`
+	ChecklistItems []struct {
+		DisplayName string `json:"displayName"`
+		IsChecked   bool   `json:"isChecked"`
+	} `json:"checklistItems"`
+}
+
+// decodeMicrosoftToDo maps task lists to lists, and tasks and the steps of
+// their checklists to items.
+func decodeMicrosoftToDo(data []byte, name string, m *mapping) ([]todo.ExportedList, error) {
+	var msLists []msTodoList
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &msLists); err != nil {
+			return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+		}
+	} else {
+		var export msTodoExport
+		if err := json.Unmarshal(trimmed, &export); err != nil {
+			return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+		}
+		msLists = append(export.Lists, export.Value...)
+	}
+
+	lists := make([]todo.ExportedList, 0, len(msLists))
+	for _, msList := range msLists {
+		list := todo.ExportedList{TodoList: todo.TodoList{Title: strings.TrimSpace(msList.DisplayName)}}
+		if list.Title == "" {
+			list.Title = DefaultListTitle
+		}
+		m.add("task list", todo.MappingList)
+
+		for _, task := range msList.Tasks {
+			item := msTodoItem(task, m)
+			if item.Title == "" {
+				m.add("untitled task", todo.MappingSkipped)
+				continue
+			}
+			list.Items = append(list.Items, item)
+			m.add("task", todo.MappingItem)
+
+			for _, step := range task.ChecklistItems {
+				if strings.TrimSpace(step.DisplayName) == "" {
+					m.add("untitled checklist item", todo.MappingSkipped)
+					continue
+				}
+				list.Items = append(list.Items, todo.TodoItem{
+					Title:       strings.TrimSpace(step.DisplayName),
+					Description: "Checklist of " + item.Title,
+					Done:        step.IsChecked,
+				})
+				m.add("checklist item", todo.MappingItem)
+			}
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func msTodoItem(task msTodoTask, m *mapping) todo.TodoItem {
+	item := todo.TodoItem{
+		Title: strings.TrimSpace(task.Title),
+		Done:  task.Status == "completed",
+	}
+
+	item.Description = task.Body.Content
+	if strings.EqualFold(task.Body.ContentType, "html") {
+		item.Description = html.UnescapeString(htmlTag.ReplaceAllString(item.Description, " "))
+		item.Description = strings.Join(strings.Fields(item.Description), " ")
+	}
+	item.Description = strings.TrimSpace(item.Description)
+
+	switch task.Importance {
+	case "high":
+		item.Priority = todo.PriorityHigh
+	case "low":
+		item.Priority = todo.PriorityLow
+	}
+
+	if due := task.DueDateTime; due != nil {
+		location := time.UTC
+		if loc, err := time.LoadLocation(due.TimeZone); err == nil && due.TimeZone != "" {
+			location = loc
+		}
+		if t, err := time.ParseInLocation(msDateTimeLayout, due.DateTime, location); err == nil {
+			item.Due = &t
+		} else {
+			m.add("invalid due date", todo.MappingSkipped)
+		}
+	}
+
+	return item
+}
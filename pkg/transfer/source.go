@@ -0,0 +1,80 @@
+package transfer
+
+import (
+	"io"
+	"unicode/utf8"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+// maxTextLength is the length of titles and descriptions that fit into the
+// database. Longer texts of other tools are cut instead of failing the
+// import.
+const maxTextLength = 255
+
+// DecodeSource maps an export file of another tool to lists and reports how
+// its things were mapped. The name of the file is optional.
+func DecodeSource(r io.Reader, source, name string) ([]todo.ExportedList, []todo.ImportMapping, error) {
+	var decode func(data []byte, name string, m *mapping) ([]todo.ExportedList, error)
+	switch source {
+	case todo.SourceTodoist:
+		decode = decodeTodoist
+	case todo.SourceMicrosoftToDo:
+		decode = decodeMicrosoftToDo
+	case todo.SourceTrello:
+		decode = decodeTrello
+	default:
+		return nil, nil, &todo.ErrUnsupportedSource{Source: source}
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m := &mapping{}
+	lists, err := decode(data, name, m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range lists {
+		lists[i].Title = m.truncate(lists[i].Title)
+		lists[i].Description = m.truncate(lists[i].Description)
+		for j := range lists[i].Items {
+			lists[i].Items[j].Title = m.truncate(lists[i].Items[j].Title)
+			lists[i].Items[j].Description = m.truncate(lists[i].Items[j].Description)
+		}
+	}
+	return lists, m.entries, nil
+}
+
+// mapping counts mapped things in the order they are first seen.
+type mapping struct {
+	entries []todo.ImportMapping
+}
+
+func (m *mapping) add(from, to string) {
+	for i := range m.entries {
+		if m.entries[i].From == from && m.entries[i].To == to {
+			m.entries[i].Count++
+			return
+		}
+	}
+	m.entries = append(m.entries, todo.ImportMapping{From: from, To: to, Count: 1})
+}
+
+func (m *mapping) truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxTextLength {
+		return s
+	}
+	m.add("long text", todo.MappingTruncated)
+	return string([]rune(s)[:maxTextLength])
+}
+
+func appendParagraph(text, paragraph string) string {
+	if text == "" {
+		return paragraph
+	}
+	return text + "\n\n" + paragraph
+}
@@ -0,0 +1,200 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+const todoistProjectCSV = "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+	"section,Errands,,,,,,,,\n" +
+	"task,Pay rent,Card,1,1,Me (1),,2023-10-02,en,UTC\n" +
+	"note,Landlord changed,,,,Me (1),,,,\n" +
+	"task,Water plants,,4,2,Me (1),,every monday,en,UTC\n" +
+	",,,,,,,,,\n"
+
+func todoistBackup(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"Work [2203306141].csv":  "TYPE,CONTENT,PRIORITY\ntask,Report,2\n",
+		"Inbox [2203306140].csv": "TYPE,CONTENT,PRIORITY\ntask,Buy milk,3\n",
+		"README.txt":             "not a project",
+	} {
+		f, err := w.Create(name)
+		assert.Equal(t, nil, err)
+		f.Write([]byte(content))
+	}
+	assert.Equal(t, nil, w.Close())
+	return buf.Bytes()
+}
+
+const msToDoExport = `{"value":[{"displayName":"Tasks","tasks":[
+	{"title":"Pay rent","status":"completed","importance":"high",
+	 "body":{"content":"<p>Card &amp; cash</p>","contentType":"html"},
+	 "dueDateTime":{"dateTime":"2023-10-02T00:00:00.0000000","timeZone":"UTC"},
+	 "checklistItems":[{"displayName":"Find card","isChecked":true}]},
+	{"title":"","status":"notStarted"},
+	{"title":"Buy milk","importance":"low","body":{"content":"2%","contentType":"text"}}
+]}]}`
+
+const trelloExport = `{"name":"Sprint","desc":"Board",
+	"lists":[{"id":"l2","name":"Done","pos":2},{"id":"l1","name":"To do","pos":1},{"id":"l3","name":"Old","closed":true,"pos":3}],
+	"cards":[
+		{"id":"c2","idList":"l2","name":"Deploy","dueComplete":true,"pos":1},
+		{"id":"c1","idList":"l1","name":"Write code","desc":"Fast","due":"2023-10-02T09:00:00.000Z","pos":2,"labels":[{"name":"dev"}]},
+		{"id":"c3","idList":"l1","name":"Archived","closed":true,"pos":3},
+		{"id":"c4","idList":"l3","name":"In archived list","pos":1}
+	],
+	"checklists":[{"id":"k1","idCard":"c1","name":"Steps","checkItems":[
+		{"name":"Test","state":"incomplete","pos":2},{"name":"Design","state":"complete","pos":1}]}]}`
+
+func TestDecodeSource(t *testing.T) {
+	due := time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC)
+	trelloDue := time.Date(2023, 10, 2, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name            string
+		source          string
+		fileName        string
+		input           []byte
+		expected        []todo.ExportedList
+		expectedMapping []todo.ImportMapping
+		expectedError   bool
+	}{
+		{
+			name:     "Todoist project",
+			source:   todo.SourceTodoist,
+			fileName: "Home [2203306142].csv",
+			input:    []byte(todoistProjectCSV),
+			expected: []todo.ExportedList{
+				{
+					TodoList: todo.TodoList{Title: "Home"},
+					Items: []todo.TodoItem{
+						{Title: "Pay rent", Description: "Card\n\nLandlord changed", Due: &due, Priority: todo.PriorityHigh},
+						{Title: "Water plants", Description: "Due: every monday"},
+					},
+				},
+			},
+			expectedMapping: []todo.ImportMapping{
+				{From: "project", To: todo.MappingList, Count: 1},
+				{From: "section", To: todo.MappingSkipped, Count: 1},
+				{From: "task", To: todo.MappingItem, Count: 2},
+				{From: "note", To: todo.MappingDesc, Count: 1},
+				{From: "recurring date", To: todo.MappingDesc, Count: 1},
+			},
+		},
+		{
+			name:     "Todoist backup",
+			source:   todo.SourceTodoist,
+			fileName: "backup.zip",
+			input:    todoistBackup(t),
+			expected: []todo.ExportedList{
+				{
+					TodoList: todo.TodoList{Title: "Inbox"},
+					Items:    []todo.TodoItem{{Title: "Buy milk", Priority: todo.PriorityLow}},
+				},
+				{
+					TodoList: todo.TodoList{Title: "Work"},
+					Items:    []todo.TodoItem{{Title: "Report", Priority: todo.PriorityMedium}},
+				},
+			},
+			expectedMapping: []todo.ImportMapping{
+				{From: "project", To: todo.MappingList, Count: 2},
+				{From: "task", To: todo.MappingItem, Count: 2},
+			},
+		},
+		{
+			name:          "Todoist without columns",
+			source:        todo.SourceTodoist,
+			input:         []byte("list,title\nHome,Task\n"),
+			expectedError: true,
+		},
+		{
+			name:   "Microsoft To Do",
+			source: todo.SourceMicrosoftToDo,
+			input:  []byte(msToDoExport),
+			expected: []todo.ExportedList{
+				{
+					TodoList: todo.TodoList{Title: "Tasks"},
+					Items: []todo.TodoItem{
+						{Title: "Pay rent", Description: "Card & cash", Done: true, Due: &due, Priority: todo.PriorityHigh},
+						{Title: "Find card", Description: "Checklist of Pay rent", Done: true},
+						{Title: "Buy milk", Description: "2%", Priority: todo.PriorityLow},
+					},
+				},
+			},
+			expectedMapping: []todo.ImportMapping{
+				{From: "task list", To: todo.MappingList, Count: 1},
+				{From: "task", To: todo.MappingItem, Count: 2},
+				{From: "checklist item", To: todo.MappingItem, Count: 1},
+				{From: "untitled task", To: todo.MappingSkipped, Count: 1},
+			},
+		},
+		{
+			name:   "Microsoft To Do array",
+			source: todo.SourceMicrosoftToDo,
+			input:  []byte(`[{"displayName":"` + strings.Repeat("a", 300) + `"}]`),
+			expected: []todo.ExportedList{
+				{TodoList: todo.TodoList{Title: strings.Repeat("a", 255)}},
+			},
+			expectedMapping: []todo.ImportMapping{
+				{From: "task list", To: todo.MappingList, Count: 1},
+				{From: "long text", To: todo.MappingTruncated, Count: 1},
+			},
+		},
+		{
+			name:   "Trello",
+			source: todo.SourceTrello,
+			input:  []byte(trelloExport),
+			expected: []todo.ExportedList{
+				{
+					TodoList: todo.TodoList{Title: "Sprint", Description: "Board"},
+					Items: []todo.TodoItem{
+						{Title: "Write code", Description: "Fast\n\nList: To do", Due: &trelloDue},
+						{Title: "Design", Description: "Checklist Steps of Write code", Done: true},
+						{Title: "Test", Description: "Checklist Steps of Write code"},
+						{Title: "Deploy", Description: "List: Done", Done: true},
+					},
+				},
+			},
+			expectedMapping: []todo.ImportMapping{
+				{From: "board", To: todo.MappingList, Count: 1},
+				{From: "list", To: todo.MappingDesc, Count: 2},
+				{From: "label", To: todo.MappingSkipped, Count: 1},
+				{From: "card", To: todo.MappingItem, Count: 2},
+				{From: "checklist item", To: todo.MappingItem, Count: 2},
+				{From: "archived card", To: todo.MappingSkipped, Count: 1},
+				{From: "archived list", To: todo.MappingSkipped, Count: 1},
+			},
+		},
+		{
+			name:          "Invalid Trello",
+			source:        todo.SourceTrello,
+			input:         []byte(`[]`),
+			expectedError: true,
+		},
+		{
+			name:          "Unsupported source",
+			source:        "asana",
+			input:         []byte(`{}`),
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			lists, mapping, err := DecodeSource(bytes.NewReader(testCase.input), testCase.source, testCase.fileName)
+			assert.Equal(t, testCase.expectedError, err != nil)
+			if !testCase.expectedError {
+				assert.Equal(t, testCase.expected, lists)
+				assert.Equal(t, testCase.expectedMapping, mapping)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+const defaultTodoistProject = "Todoist"
+
+// todoistProjectId is the id Todoist appends to the names of backup files.
+var todoistProjectId = regexp.MustCompile(`\s*\[\d+\]$`)
+
+var todoistDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// decodeTodoist reads a project template CSV, or a backup, which is a zip of
+// them. Projects are named after their files.
+func decodeTodoist(data []byte, name string, m *mapping) ([]todo.ExportedList, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		list, err := decodeTodoistProject(bytes.NewReader(data), todoistProject(name), m)
+		if err != nil {
+			return nil, err
+		}
+		return []todo.ExportedList{list}, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+	}
+
+	files := make([]*zip.File, 0, len(archive.File))
+	for _, file := range archive.File {
+		if strings.EqualFold(path.Ext(file.Name), ".csv") {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	lists := make([]todo.ExportedList, 0, len(files))
+	for _, file := range files {
+		f, err := file.Open()
+		if err != nil {
+			return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+		}
+		list, err := decodeTodoistProject(f, todoistProject(file.Name), m)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
+
+func todoistProject(name string) string {
+	name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	name = strings.TrimSpace(todoistProjectId.ReplaceAllString(name, ""))
+	if name == "" || name == "." {
+		return defaultTodoistProject
+	}
+	return name
+}
+
+func decodeTodoistProject(r io.Reader, title string, m *mapping) (todo.ExportedList, error) {
+	list := todo.ExportedList{TodoList: todo.TodoList{Title: title}}
+	m.add("project", todo.MappingList)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return list, &todo.ErrInvalidImport{Reason: fmt.Sprintf("%s: %s", title, err)}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"TYPE", "CONTENT"} {
+		if _, ok := columns[name]; !ok {
+			return list, &todo.ErrInvalidImport{Reason: fmt.Sprintf("%s: missing %s column", title, name)}
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return list, &todo.ErrInvalidImport{Reason: fmt.Sprintf("%s: %s", title, err)}
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		switch strings.ToLower(field("TYPE")) {
+		case "task":
+			item, ok := todoistTask(field, m)
+			if !ok {
+				m.add("untitled task", todo.MappingSkipped)
+				continue
+			}
+			list.Items = append(list.Items, item)
+			m.add("task", todo.MappingItem)
+		case "note":
+			// notes are the comments of the task above them
+			if len(list.Items) == 0 || field("CONTENT") == "" {
+				m.add("note", todo.MappingSkipped)
+				continue
+			}
+			item := &list.Items[len(list.Items)-1]
+			item.Description = appendParagraph(item.Description, field("CONTENT"))
+			m.add("note", todo.MappingDesc)
+		case "section":
+			m.add("section", todo.MappingSkipped)
+		}
+	}
+
+	return list, nil
+}
+
+func todoistTask(field func(string) string, m *mapping) (todo.TodoItem, bool) {
+	item := todo.TodoItem{
+		Title:       field("CONTENT"),
+		Description: field("DESCRIPTION"),
+	}
+	if item.Title == "" {
+		return item, false
+	}
+
+	// Todoist priorities go from 1, the most urgent, to 4, no priority
+	switch field("PRIORITY") {
+	case "1":
+		item.Priority = todo.PriorityHigh
+	case "2":
+		item.Priority = todo.PriorityMedium
+	case "3":
+		item.Priority = todo.PriorityLow
+	}
+
+	if date := field("DATE"); date != "" {
+		if due, ok := parseTodoistDate(date, field("TIMEZONE")); ok {
+			item.Due = &due
+		} else {
+			// recurring and natural language dates are kept as text
+			item.Description = appendParagraph(item.Description, "Due: "+date)
+			m.add("recurring date", todo.MappingDesc)
+		}
+	}
+
+	return item, true
+}
+
+func parseTodoistDate(value, timezone string) (time.Time, bool) {
+	location := time.UTC
+	if loc, err := time.LoadLocation(timezone); err == nil && timezone != "" {
+		location = loc
+	}
+
+	for _, layout := range todoistDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
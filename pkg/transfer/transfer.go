@@ -0,0 +1,132 @@
+// Package transfer encodes and decodes lists with their items as JSON, CSV
+// and Markdown checklists for export and import.
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+// DefaultListTitle is the title of the list holding imported items that don't
+// belong to any list, e.g. a Markdown checklist without a heading.
+const DefaultListTitle = "Imported"
+
+var contentTypes = map[string]string{
+	todo.FormatJSON:     "application/json; charset=utf-8",
+	todo.FormatCSV:      "text/csv; charset=utf-8",
+	todo.FormatMarkdown: "text/markdown; charset=utf-8",
+}
+
+var extensions = map[string]string{
+	todo.FormatJSON:     ".json",
+	todo.FormatCSV:      ".csv",
+	todo.FormatMarkdown: ".md",
+}
+
+// Encoder writes lists one by one, so an export doesn't have to be held in
+// memory. Close must be called to finish the document.
+type Encoder interface {
+	WriteList(list todo.ExportedList) error
+	Close() error
+}
+
+func NewEncoder(w io.Writer, format string) (Encoder, error) {
+	switch format {
+	case todo.FormatJSON:
+		return &jsonEncoder{w: bufio.NewWriter(w)}, nil
+	case todo.FormatCSV:
+		return newCSVEncoder(w), nil
+	case todo.FormatMarkdown:
+		return &markdownEncoder{w: bufio.NewWriter(w)}, nil
+	}
+	return nil, &todo.ErrUnsupportedFormat{Format: format}
+}
+
+func Decode(r io.Reader, format string) ([]todo.ExportedList, error) {
+	switch format {
+	case todo.FormatJSON:
+		return decodeJSON(r)
+	case todo.FormatCSV:
+		return decodeCSV(r)
+	case todo.FormatMarkdown:
+		return decodeMarkdown(r)
+	}
+	return nil, &todo.ErrUnsupportedFormat{Format: format}
+}
+
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+func Extension(format string) string {
+	return extensions[format]
+}
+
+// FormatOf returns the format of a media type, or of a file name extension,
+// and false if it is not supported.
+func FormatOf(s string) (string, bool) {
+	if mediaType, _, err := mime.ParseMediaType(s); err == nil {
+		for format, contentType := range contentTypes {
+			if strings.HasPrefix(contentType, mediaType+";") {
+				return format, true
+			}
+		}
+	}
+
+	for format, extension := range extensions {
+		if strings.HasSuffix(strings.ToLower(s), extension) {
+			return format, true
+		}
+	}
+	if strings.HasSuffix(strings.ToLower(s), ".markdown") {
+		return todo.FormatMarkdown, true
+	}
+	return "", false
+}
+
+type jsonEncoder struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (e *jsonEncoder) WriteList(list todo.ExportedList) error {
+	if list.Items == nil {
+		list.Items = []todo.TodoItem{}
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if e.count == 0 {
+		sep = "["
+	}
+	e.count++
+
+	e.w.WriteString(sep)
+	e.w.Write(data)
+	return e.w.Flush()
+}
+
+func (e *jsonEncoder) Close() error {
+	if e.count == 0 {
+		e.w.WriteString("[")
+	}
+	e.w.WriteString("]\n")
+	return e.w.Flush()
+}
+
+func decodeJSON(r io.Reader) ([]todo.ExportedList, error) {
+	var lists []todo.ExportedList
+	if err := json.NewDecoder(r).Decode(&lists); err != nil {
+		return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+	}
+	return lists, nil
+}
@@ -0,0 +1,219 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+	"github.com/go-playground/assert/v2"
+)
+
+func testLists() []todo.ExportedList {
+	due := time.Date(2023, 10, 2, 9, 30, 0, 0, time.UTC)
+	return []todo.ExportedList{
+		{
+			TodoList: todo.TodoList{Id: 1, Title: "Home", Description: "Chores"},
+			Items: []todo.TodoItem{
+				{Id: 1, Title: "Pay rent", Description: "Card, not cash", Due: &due, Priority: todo.PriorityHigh},
+				{Id: 2, Title: "Buy milk", Done: true},
+			},
+		},
+		{
+			TodoList: todo.TodoList{Id: 2, Title: "Empty"},
+		},
+	}
+}
+
+func encode(t *testing.T, format string, lists []todo.ExportedList) string {
+	var buf bytes.Buffer
+	enc, err := NewEncoder(&buf, format)
+	assert.Equal(t, nil, err)
+	for _, list := range lists {
+		assert.Equal(t, nil, enc.WriteList(list))
+	}
+	assert.Equal(t, nil, enc.Close())
+	return buf.String()
+}
+
+func TestEncoder(t *testing.T) {
+	testTable := []struct {
+		name     string
+		format   string
+		lists    []todo.ExportedList
+		expected string
+	}{
+		{
+			name:   "JSON",
+			format: todo.FormatJSON,
+			lists:  testLists(),
+			expected: `[{"id":1,"title":"Home","description":"Chores","items":[` +
+				`{"id":1,"title":"Pay rent","description":"Card, not cash","done":false,"due":"2023-10-02T09:30:00Z","priority":3},` +
+				`{"id":2,"title":"Buy milk","description":"","done":true}]},` +
+				`{"id":2,"title":"Empty","description":"","items":[]}]` + "\n",
+		},
+		{
+			name:     "JSON empty",
+			format:   todo.FormatJSON,
+			expected: "[]\n",
+		},
+		{
+			name:   "CSV",
+			format: todo.FormatCSV,
+			lists:  testLists(),
+			expected: "list,list_description,title,description,done,due,priority\n" +
+				"Home,Chores,Pay rent,\"Card, not cash\",false,2023-10-02T09:30:00Z,3\n" +
+				"Home,Chores,Buy milk,,true,,0\n" +
+				"Empty,,,,,,\n",
+		},
+		{
+			name:     "CSV empty",
+			format:   todo.FormatCSV,
+			expected: "list,list_description,title,description,done,due,priority\n",
+		},
+		{
+			name:   "Markdown",
+			format: todo.FormatMarkdown,
+			lists:  testLists(),
+			expected: "# Home\n\nChores\n\n" +
+				"- [ ] Pay rent\n  Card, not cash\n" +
+				"- [x] Buy milk\n" +
+				"\n# Empty\n\n",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, encode(t, testCase.format, testCase.lists))
+		})
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	for _, format := range []string{todo.FormatJSON, todo.FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			lists, err := Decode(strings.NewReader(encode(t, format, testLists())), format)
+			assert.Equal(t, nil, err)
+
+			expected := testLists()
+			if format == todo.FormatCSV {
+				// ids are not exported to CSV
+				for i := range expected {
+					expected[i].Id = 0
+					for j := range expected[i].Items {
+						expected[i].Items[j].Id = 0
+					}
+				}
+			} else {
+				expected[1].Items = []todo.TodoItem{}
+			}
+			assert.Equal(t, expected, lists)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	testTable := []struct {
+		name          string
+		format        string
+		input         string
+		expected      []todo.ExportedList
+		expectedError bool
+	}{
+		{
+			name:   "Markdown",
+			format: todo.FormatMarkdown,
+			input: "Some notes\n- [ ] Loose task\n\n## Home ##\nChores\nfor the weekend\n\n" +
+				"- [ ] Pay rent\n  Card\n\tnot cash\n* [X] Buy milk\n- Plain bullet\n    - [ ] Nested\n",
+			expected: []todo.ExportedList{
+				{
+					TodoList: todo.TodoList{Title: DefaultListTitle},
+					Items:    []todo.TodoItem{{Title: "Loose task"}},
+				},
+				{
+					TodoList: todo.TodoList{Title: "Home", Description: "Chores\nfor the weekend"},
+					Items: []todo.TodoItem{
+						{Title: "Pay rent", Description: "Card\nnot cash"},
+						{Title: "Buy milk", Done: true},
+						{Title: "Plain bullet", Description: "- [ ] Nested"},
+					},
+				},
+			},
+		},
+		{
+			name:   "CSV columns in any order",
+			format: todo.FormatCSV,
+			input:  "Title,List,Done\nA,Home,1\n B ,,\nC,Home,false\n",
+			expected: []todo.ExportedList{
+				{
+					TodoList: todo.TodoList{Title: "Home"},
+					Items:    []todo.TodoItem{{Title: "A", Done: true}, {Title: "C"}},
+				},
+				{
+					TodoList: todo.TodoList{Title: DefaultListTitle},
+					Items:    []todo.TodoItem{{Title: "B"}},
+				},
+			},
+		},
+		{
+			name:          "CSV missing column",
+			format:        todo.FormatCSV,
+			input:         "list,description\nHome,x\n",
+			expectedError: true,
+		},
+		{
+			name:          "CSV invalid due",
+			format:        todo.FormatCSV,
+			input:         "list,title,due\nHome,A,tomorrow\n",
+			expectedError: true,
+		},
+		{
+			name:          "Invalid JSON",
+			format:        todo.FormatJSON,
+			input:         `{"title":"Home"}`,
+			expectedError: true,
+		},
+		{
+			name:          "Unsupported format",
+			format:        "xml",
+			input:         "<lists/>",
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			lists, err := Decode(strings.NewReader(testCase.input), testCase.format)
+			assert.Equal(t, testCase.expectedError, err != nil)
+			if !testCase.expectedError {
+				assert.Equal(t, testCase.expected, lists)
+			}
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	testTable := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"application/json", todo.FormatJSON, true},
+		{"text/csv; charset=utf-8", todo.FormatCSV, true},
+		{"text/markdown", todo.FormatMarkdown, true},
+		{"lists.MD", todo.FormatMarkdown, true},
+		{"todo.markdown", todo.FormatMarkdown, true},
+		{"export.csv", todo.FormatCSV, true},
+		{"text/plain", "", false},
+		{"", "", false},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.input, func(t *testing.T) {
+			format, ok := FormatOf(testCase.input)
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.expected, format)
+		})
+	}
+}
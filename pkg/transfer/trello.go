@@ -0,0 +1,151 @@
+package transfer
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OrIX219/todo/pkg"
+)
+
+type trelloBoard struct {
+	Name       string            `json:"name"`
+	Desc       string            `json:"desc"`
+	Lists      []trelloList      `json:"lists"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+}
+
+type trelloList struct {
+	Id     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloCard struct {
+	Id          string  `json:"id"`
+	IdList      string  `json:"idList"`
+	Name        string  `json:"name"`
+	Desc        string  `json:"desc"`
+	Closed      bool    `json:"closed"`
+	Due         *string `json:"due"`
+	DueComplete bool    `json:"dueComplete"`
+	Pos         float64 `json:"pos"`
+	Labels      []any   `json:"labels"`
+}
+
+type trelloChecklist struct {
+	Id         string  `json:"id"`
+	IdCard     string  `json:"idCard"`
+	Name       string  `json:"name"`
+	Pos        float64 `json:"pos"`
+	CheckItems []struct {
+		Name  string  `json:"name"`
+		State string  `json:"state"`
+		Pos   float64 `json:"pos"`
+	} `json:"checkItems"`
+}
+
+// decodeTrello maps a board to a list, and its cards and the items of their
+// checklists to items. Cards are ordered as on the board, the names of their
+// Trello lists are kept in the descriptions. Archived cards and lists are
+// skipped.
+func decodeTrello(data []byte, name string, m *mapping) ([]todo.ExportedList, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, &todo.ErrInvalidImport{Reason: err.Error()}
+	}
+
+	list := todo.ExportedList{TodoList: todo.TodoList{
+		Title:       strings.TrimSpace(board.Name),
+		Description: strings.TrimSpace(board.Desc),
+	}}
+	if list.Title == "" {
+		list.Title = DefaultListTitle
+	}
+	m.add("board", todo.MappingList)
+
+	checklists := make(map[string][]trelloChecklist)
+	for _, checklist := range board.Checklists {
+		checklists[checklist.IdCard] = append(checklists[checklist.IdCard], checklist)
+	}
+
+	sort.SliceStable(board.Lists, func(i, j int) bool { return board.Lists[i].Pos < board.Lists[j].Pos })
+	sort.SliceStable(board.Cards, func(i, j int) bool { return board.Cards[i].Pos < board.Cards[j].Pos })
+
+	for _, trelloList := range board.Lists {
+		if trelloList.Closed {
+			m.add("archived list", todo.MappingSkipped)
+			continue
+		}
+		m.add("list", todo.MappingDesc)
+
+		for _, card := range board.Cards {
+			if card.IdList != trelloList.Id {
+				continue
+			}
+			if card.Closed {
+				m.add("archived card", todo.MappingSkipped)
+				continue
+			}
+			if strings.TrimSpace(card.Name) == "" {
+				m.add("untitled card", todo.MappingSkipped)
+				continue
+			}
+
+			list.Items = append(list.Items, trelloItem(card, trelloList.Name, m))
+			m.add("card", todo.MappingItem)
+
+			cardChecklists := checklists[card.Id]
+			sort.SliceStable(cardChecklists, func(i, j int) bool { return cardChecklists[i].Pos < cardChecklists[j].Pos })
+			for _, checklist := range cardChecklists {
+				list.Items = append(list.Items, trelloChecklistItems(checklist, card.Name, m)...)
+			}
+		}
+	}
+
+	return []todo.ExportedList{list}, nil
+}
+
+func trelloItem(card trelloCard, listName string, m *mapping) todo.TodoItem {
+	item := todo.TodoItem{
+		Title:       strings.TrimSpace(card.Name),
+		Description: appendParagraph(strings.TrimSpace(card.Desc), "List: "+listName),
+		Done:        card.DueComplete,
+	}
+
+	if card.Due != nil {
+		if due, err := time.Parse(time.RFC3339, *card.Due); err == nil {
+			item.Due = &due
+		} else {
+			m.add("invalid due date", todo.MappingSkipped)
+		}
+	}
+	for range card.Labels {
+		m.add("label", todo.MappingSkipped)
+	}
+
+	return item
+}
+
+func trelloChecklistItems(checklist trelloChecklist, cardName string, m *mapping) []todo.TodoItem {
+	checkItems := checklist.CheckItems
+	sort.SliceStable(checkItems, func(i, j int) bool { return checkItems[i].Pos < checkItems[j].Pos })
+
+	var items []todo.TodoItem
+	for _, checkItem := range checkItems {
+		if strings.TrimSpace(checkItem.Name) == "" {
+			m.add("untitled checklist item", todo.MappingSkipped)
+			continue
+		}
+		items = append(items, todo.TodoItem{
+			Title:       strings.TrimSpace(checkItem.Name),
+			Description: "Checklist " + checklist.Name + " of " + strings.TrimSpace(cardName),
+			Done:        checkItem.State == "complete",
+		})
+		m.add("checklist item", todo.MappingItem)
+	}
+	return items
+}
@@ -0,0 +1,65 @@
+package todo
+
+// TwoFactor is the TOTP state of a user. Secret is set on enrollment and
+// Enabled once a code confirmed it, LastStep being the step of the last
+// code used, which can't be used again.
+type TwoFactor struct {
+	UserId   int    `db:"user_id"`
+	Username string `db:"username"`
+	Secret   string `db:"secret"`
+	Enabled  bool   `db:"enabled"`
+	LastStep int64  `db:"last_step"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TwoFactorEnrollInput struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorConfirmInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableInput struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type ErrTwoFactorRequired struct {
+	// Challenge is exchanged for a token with a code at /auth/2fa, it's
+	// empty for clients that can't answer one.
+	Challenge string
+}
+
+func (e *ErrTwoFactorRequired) Error() string {
+	return "Two-factor authentication required"
+}
+
+type ErrInvalidChallenge struct{}
+
+func (e *ErrInvalidChallenge) Error() string {
+	return "Invalid or expired challenge"
+}
+
+type ErrInvalidTwoFactorCode struct{}
+
+func (e *ErrInvalidTwoFactorCode) Error() string {
+	return "Invalid two-factor code"
+}
+
+type ErrTwoFactorEnabled struct{}
+
+func (e *ErrTwoFactorEnabled) Error() string {
+	return "Two-factor authentication is already enabled"
+}
+
+type ErrTwoFactorNotEnrolled struct{}
+
+func (e *ErrTwoFactorNotEnrolled) Error() string {
+	return "Two-factor authentication isn't enrolled"
+}
@@ -1,14 +1,77 @@
 package todo
 
+import "time"
+
 type User struct {
 	Id       int    `json:"-" db:"id"`
 	Name     string `json:"name" binding:"required"`
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required,max=255"`
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginClient is where a sign-in comes from.
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// Session is a sign-in kept in the login history, lasting as long as the
+// token it gave.
+type Session struct {
+	Id        int       `json:"id" db:"id"`
+	UserId    int       `json:"-" db:"user_id"`
+	IP        string    `json:"ip" db:"ip"`
+	UserAgent string    `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// LoginFailures are the failed sign-ins of a username, which is tracked
+// whether or not such a user exists.
+type LoginFailures struct {
+	Count       int       `db:"count"`
+	LockedUntil time.Time `db:"locked_until"`
+}
+
+// LockoutPolicy slows down password guessing. Failed sign-ins past the
+// first few make the next one wait, twice as long every time, and after
+// MaxFailures the username is locked for Lockout.
+type LockoutPolicy struct {
+	MaxFailures int
+	Lockout     time.Duration
+}
+
+// freeLoginFailures are the typos let through without waiting.
+const freeLoginFailures = 3
+
+// Delay returns how long sign-ins have to wait after the count-th failure.
+func (p LockoutPolicy) Delay(count int) time.Duration {
+	if p.MaxFailures > 0 && count >= p.MaxFailures {
+		return p.Lockout
+	}
+	if count <= freeLoginFailures {
+		return 0
+	}
+
+	delay := time.Second << (count - freeLoginFailures - 1)
+	if delay > p.Lockout || delay <= 0 {
+		return p.Lockout
+	}
+	return delay
+}
+
 type ErrInvalidCredentials struct{}
 
 func (e *ErrInvalidCredentials) Error() string {
 	return "Invalid credentials"
 }
+
+// ErrAccountLocked is the same for every username, so it doesn't tell
+// whether one exists.
+type ErrAccountLocked struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrAccountLocked) Error() string {
+	return "Too many failed sign-ins, try again later"
+}
@@ -0,0 +1,129 @@
+package todo
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookEventPing = "ping"
+
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	Id           int       `json:"id" db:"id"`
+	UserId       int       `json:"-" db:"user_id"`
+	URL          string    `json:"url" db:"url"`
+	Events       []string  `json:"events" db:"-"`
+	Secret       string    `json:"secret,omitempty" db:"secret"`
+	Active       bool      `json:"active" db:"active"`
+	FailureCount int       `json:"failure_count" db:"failure_count"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Accepts reports whether the event filter matches eventType. Filters are
+// exact event types, "*" or a "<resource>.*" wildcard.
+func (w Webhook) Accepts(eventType string) bool {
+	for _, filter := range w.Events {
+		if filter == "*" || filter == eventType {
+			return true
+		}
+		if strings.HasSuffix(filter, ".*") &&
+			strings.HasPrefix(eventType, strings.TrimSuffix(filter, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+type ErrNoSuchWebhook struct{}
+
+func (e *ErrNoSuchWebhook) Error() string {
+	return "No webhook with such id"
+}
+
+type ErrInvalidWebhookInput struct {
+	Reason string
+}
+
+func (e *ErrInvalidWebhookInput) Error() string {
+	return "Invalid webhook input: " + e.Reason
+}
+
+type WebhookInput struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required,min=1"`
+	Secret string   `json:"secret"`
+}
+
+func (i WebhookInput) Validate() error {
+	if err := validateWebhookURL(i.URL); err != nil {
+		return err
+	}
+	return validateEventFilters(i.Events)
+}
+
+type UpdateWebhookInput struct {
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+func (i UpdateWebhookInput) Validate() error {
+	if i.URL == nil && i.Events == nil && i.Active == nil {
+		return &ErrInvalidWebhookInput{"nothing to update"}
+	}
+	if i.URL != nil {
+		if err := validateWebhookURL(*i.URL); err != nil {
+			return err
+		}
+	}
+	if i.Events != nil {
+		if len(*i.Events) == 0 {
+			return &ErrInvalidWebhookInput{"events must not be empty"}
+		}
+		return validateEventFilters(*i.Events)
+	}
+	return nil
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ErrInvalidWebhookInput{"url must be an absolute http(s) url"}
+	}
+	return nil
+}
+
+func validateEventFilters(filters []string) error {
+	for _, filter := range filters {
+		switch filter {
+		case "*", "list.*", "item.*",
+			EventListCreated, EventListUpdated, EventListDeleted,
+			EventItemCreated, EventItemUpdated, EventItemDeleted, EventItemMoved:
+		default:
+			return &ErrInvalidWebhookInput{"unknown event " + filter}
+		}
+	}
+	return nil
+}
+
+type WebhookDelivery struct {
+	Id             int        `json:"id" db:"id"`
+	WebhookId      int        `json:"webhook_id" db:"webhook_id"`
+	EventType      string     `json:"event_type" db:"event_type"`
+	Payload        string     `json:"payload" db:"payload"`
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status" db:"response_status"`
+	Error          string     `json:"error" db:"error"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at" db:"delivered_at"`
+	URL            string     `json:"-" db:"url"`
+	Secret         string     `json:"-" db:"secret"`
+}
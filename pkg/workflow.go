@@ -0,0 +1,228 @@
+package todo
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxStatuses         = 20
+	maxStatusNameLength = 64
+)
+
+// ListStatus is a column of the workflow of a list. Items in the terminal
+// status are done.
+type ListStatus struct {
+	Id       int    `json:"id" db:"id"`
+	ListId   int    `json:"list_id" db:"list_id"`
+	Name     string `json:"name" db:"name"`
+	Position int    `json:"position" db:"position"`
+	Terminal bool   `json:"terminal" db:"terminal"`
+	// Transitions are the names of the statuses items can move to, any of
+	// them when empty.
+	Transitions StatusNames `json:"transitions" db:"transitions"`
+}
+
+// Allows reports whether items can move from the status to the named one.
+func (s ListStatus) Allows(name string) bool {
+	if len(s.Transitions) == 0 || strings.EqualFold(s.Name, name) {
+		return true
+	}
+	for _, transition := range s.Transitions {
+		if strings.EqualFold(transition, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusNames are stored as a JSON array, like tags.
+type StatusNames []string
+
+func (n StatusNames) Value() (driver.Value, error) {
+	return Tags(n).Value()
+}
+
+func (n *StatusNames) Scan(src any) error {
+	return (*Tags)(n).Scan(src)
+}
+
+// Workflow is the ordered statuses of a list. Lists without one only have
+// done and undone items.
+type Workflow []ListStatus
+
+// DefaultWorkflow shows the items of lists without a workflow on a board.
+var DefaultWorkflow = Workflow{
+	{Name: "Open"},
+	{Name: "Done", Terminal: true},
+}
+
+// Find returns the status with the name, ignoring case.
+func (w Workflow) Find(name string) (ListStatus, bool) {
+	for _, status := range w {
+		if strings.EqualFold(status.Name, name) {
+			return status, true
+		}
+	}
+	return ListStatus{}, false
+}
+
+// Initial is the first status that isn't terminal, which new items start in.
+func (w Workflow) Initial() ListStatus {
+	for _, status := range w {
+		if !status.Terminal {
+			return status
+		}
+	}
+	return ListStatus{}
+}
+
+func (w Workflow) Terminal() ListStatus {
+	for _, status := range w {
+		if status.Terminal {
+			return status
+		}
+	}
+	return ListStatus{}
+}
+
+// Current returns the status of the item. Items without a status of the
+// workflow, e.g. created before it or moved from another list, are in the
+// initial or the terminal one depending on done.
+func (w Workflow) Current(item TodoItem) ListStatus {
+	if status, ok := w.Find(item.Status); ok && item.Status != "" {
+		return status
+	}
+	if item.Done {
+		return w.Terminal()
+	}
+	return w.Initial()
+}
+
+// Move returns the status the item ends up in when it's moved to the named
+// status, or done is set for clients unaware of workflows. Either may be
+// empty. Undoing a done item moves it to the initial status.
+func (w Workflow) Move(item TodoItem, name string, done *bool) (ListStatus, error) {
+	from := w.Current(item)
+	to := from
+	switch {
+	case name != "":
+		status, ok := w.Find(name)
+		if !ok {
+			return ListStatus{}, &ErrNoSuchStatus{Name: name}
+		}
+		if done != nil && *done != status.Terminal {
+			return ListStatus{}, &ErrInvalidUpdateItemInput{}
+		}
+		to = status
+	case done != nil && *done && !from.Terminal:
+		to = w.Terminal()
+	case done != nil && !*done && from.Terminal:
+		to = w.Initial()
+	}
+
+	if !from.Allows(to.Name) {
+		return ListStatus{}, &ErrInvalidTransition{From: from.Name, To: to.Name}
+	}
+	return to, nil
+}
+
+type ErrNoSuchStatus struct {
+	Name string
+}
+
+func (e *ErrNoSuchStatus) Error() string {
+	return fmt.Sprintf("No status %q in the workflow of the list", e.Name)
+}
+
+type ErrInvalidTransition struct {
+	From, To string
+}
+
+func (e *ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("Items can't move from %q to %q", e.From, e.To)
+}
+
+type ErrInvalidWorkflowInput struct {
+	Reason string
+}
+
+func (e *ErrInvalidWorkflowInput) Error() string {
+	return "Invalid workflow input: " + e.Reason
+}
+
+type StatusInput struct {
+	// Id keeps an existing status, renaming it when the name changed. Items
+	// in statuses left out lose their status.
+	Id          int      `json:"id"`
+	Name        string   `json:"name" binding:"required"`
+	Terminal    bool     `json:"terminal"`
+	Transitions []string `json:"transitions"`
+}
+
+// WorkflowInput replaces the whole workflow of a list, no statuses removing
+// it.
+type WorkflowInput struct {
+	Statuses []StatusInput `json:"statuses" binding:"max=20,dive"`
+}
+
+func (i WorkflowInput) Validate() error {
+	if len(i.Statuses) == 0 {
+		return nil
+	}
+	if len(i.Statuses) > maxStatuses {
+		return &ErrInvalidWorkflowInput{fmt.Sprintf("more than %d statuses", maxStatuses)}
+	}
+
+	names := make(map[string]bool, len(i.Statuses))
+	ids := make(map[int]bool, len(i.Statuses))
+	terminal := 0
+	for _, status := range i.Statuses {
+		name := strings.ToLower(status.Name)
+		if strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > maxStatusNameLength {
+			return &ErrInvalidWorkflowInput{fmt.Sprintf("invalid status name %q", status.Name)}
+		}
+		if names[name] {
+			return &ErrInvalidWorkflowInput{fmt.Sprintf("duplicate status %q", status.Name)}
+		}
+		names[name] = true
+		if status.Id != 0 {
+			if ids[status.Id] {
+				return &ErrInvalidWorkflowInput{fmt.Sprintf("duplicate status id %d", status.Id)}
+			}
+			ids[status.Id] = true
+		}
+		if status.Terminal {
+			terminal++
+		}
+	}
+	if terminal != 1 {
+		return &ErrInvalidWorkflowInput{"exactly one status must be terminal"}
+	}
+	if len(i.Statuses) == 1 {
+		return &ErrInvalidWorkflowInput{"a status besides the terminal one is needed"}
+	}
+
+	for _, status := range i.Statuses {
+		for _, transition := range status.Transitions {
+			if !names[strings.ToLower(transition)] {
+				return &ErrInvalidWorkflowInput{
+					fmt.Sprintf("status %q moves to unknown status %q", status.Name, transition),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type BoardColumn struct {
+	Status ListStatus `json:"status"`
+	Items  []TodoItem `json:"items"`
+}
+
+type Board struct {
+	ListId  int           `json:"list_id"`
+	Columns []BoardColumn `json:"columns"`
+}